@@ -6,6 +6,7 @@ package acodec
 import "C"
 import (
 	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
 )
 
 // TODO: get Allegro to recognize the .oga extension.
@@ -20,6 +21,17 @@ func Install() error {
 	return nil
 }
 
+// Addon installs the acodec addon through allegro.Init(). It must come after
+// audio.Addon.
+var Addon = allegro.Addon{Name: "acodec", Install: Install, Installed: IsInstalled}
+
+// Returns true if the acodec addon is initialized, otherwise returns false.
+// The addon has no shutdown function; it is shut down along with the audio
+// addon.
+func IsInstalled() bool {
+	return bool(C.al_is_acodec_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
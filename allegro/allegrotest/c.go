@@ -0,0 +1,4 @@
+package allegrotest
+
+// #cgo !windows pkg-config: allegro-5
+import "C"
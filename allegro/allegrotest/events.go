@@ -0,0 +1,175 @@
+// Package allegrotest provides helpers for testing code written against the
+// allegro package.
+package allegrotest
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+//
+// // Allegro's internal emitter, which unlike al_emit_user_event() accepts any
+// // event type and leaves the timestamp alone. al_emit_user_event() can't be
+// // used for these events: it overwrites the timestamp, and its destructor
+// // field overlaps the display of keyboard and mouse events and the count of
+// // timer events. The function is exported by liballegro, though declared
+// // only in its internal headers.
+// extern void _al_event_source_lock(ALLEGRO_EVENT_SOURCE *source);
+// extern void _al_event_source_unlock(ALLEGRO_EVENT_SOURCE *source);
+// extern void _al_event_source_emit_event(ALLEGRO_EVENT_SOURCE *source, ALLEGRO_EVENT *event);
+//
+// static void emit_event(ALLEGRO_EVENT_SOURCE *source, ALLEGRO_EVENT *event) {
+//     _al_event_source_lock(source);
+//     _al_event_source_emit_event(source, event);
+//     _al_event_source_unlock(source);
+// }
+import "C"
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+// Source is an event source for injecting synthetic input into a real event
+// queue. Events carry Time as their timestamp, which only changes when the
+// test says so, so input handling can be tested deterministically.
+//
+// Allegro sets the source of an event to the source that emits it, so the
+// Source() of injected keyboard and mouse events is the Source itself, as a
+// *allegro.Keyboard or *allegro.Mouse, rather than the real device. Code that
+// checks where input came from should compare against EventSource(). Timer
+// events are the exception; see Timer().
+type Source struct {
+	Time float64
+	ptr  *C.ALLEGRO_EVENT_SOURCE
+}
+
+// NewSource() creates a source, which can be registered with a queue like any
+// other.
+func NewSource() *Source {
+	ptr := (*C.ALLEGRO_EVENT_SOURCE)(C.calloc(1, C.sizeof_ALLEGRO_EVENT_SOURCE))
+	C.al_init_user_event_source(ptr)
+	return &Source{ptr: ptr}
+}
+
+// EventSource() returns the underlying event source, which lets the source be
+// passed to EventQueue.Register().
+func (s *Source) EventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(s.ptr))
+}
+
+// Destroy() unregisters the source from all queues and frees it.
+func (s *Source) Destroy() {
+	C.al_destroy_user_event_source(s.ptr)
+	C.free(unsafe.Pointer(s.ptr))
+	s.ptr = nil
+}
+
+// Advance() moves the source's clock forward by dt seconds.
+func (s *Source) Advance(dt float64) {
+	s.Time += dt
+}
+
+func (s *Source) emit(event *C.ALLEGRO_EVENT) {
+	C.emit_event(s.ptr, event)
+}
+
+func (s *Source) key(t C.ALLEGRO_EVENT_TYPE, display *allegro.Display, keycode allegro.KeyCode, unichar rune, modifiers allegro.KeyModifier, repeat bool) {
+	var event C.ALLEGRO_EVENT
+	e := (*C.struct_ALLEGRO_KEYBOARD_EVENT)(unsafe.Pointer(&event))
+	e._type = t
+	e.timestamp = C.double(s.Time)
+	e.display = (*C.ALLEGRO_DISPLAY)(unsafe.Pointer(display))
+	e.keycode = C.int(keycode)
+	e.unichar = C.int(unichar)
+	e.modifiers = C.uint(modifiers)
+	e.repeat = C.bool(repeat)
+	s.emit(&event)
+}
+
+// KeyDown() injects a KeyDownEvent.
+func (s *Source) KeyDown(display *allegro.Display, keycode allegro.KeyCode) {
+	s.key(C.ALLEGRO_EVENT_KEY_DOWN, display, keycode, 0, 0, false)
+}
+
+// KeyUp() injects a KeyUpEvent.
+func (s *Source) KeyUp(display *allegro.Display, keycode allegro.KeyCode) {
+	s.key(C.ALLEGRO_EVENT_KEY_UP, display, keycode, 0, 0, false)
+}
+
+// KeyChar() injects a KeyCharEvent.
+func (s *Source) KeyChar(display *allegro.Display, keycode allegro.KeyCode, unichar rune, modifiers allegro.KeyModifier, repeat bool) {
+	s.key(C.ALLEGRO_EVENT_KEY_CHAR, display, keycode, unichar, modifiers, repeat)
+}
+
+// KeyPress() injects the usual sequence for a single key stroke: KeyDown,
+// KeyChar and KeyUp, all with the current timestamp.
+func (s *Source) KeyPress(display *allegro.Display, keycode allegro.KeyCode, unichar rune) {
+	s.KeyDown(display, keycode)
+	s.KeyChar(display, keycode, unichar, 0, false)
+	s.KeyUp(display, keycode)
+}
+
+func (s *Source) mouse(t C.ALLEGRO_EVENT_TYPE, display *allegro.Display, x, y, z, w, dx, dy, dz, dw int, button uint) {
+	var event C.ALLEGRO_EVENT
+	e := (*C.struct_ALLEGRO_MOUSE_EVENT)(unsafe.Pointer(&event))
+	e._type = t
+	e.timestamp = C.double(s.Time)
+	e.display = (*C.ALLEGRO_DISPLAY)(unsafe.Pointer(display))
+	e.x, e.y, e.z, e.w = C.int(x), C.int(y), C.int(z), C.int(w)
+	e.dx, e.dy, e.dz, e.dw = C.int(dx), C.int(dy), C.int(dz), C.int(dw)
+	e.button = C.uint(button)
+	e.pressure = 1
+	s.emit(&event)
+}
+
+// MouseAxes() injects a MouseAxesEvent for a move to (x, y) by (dx, dy).
+func (s *Source) MouseAxes(display *allegro.Display, x, y, dx, dy int) {
+	s.mouse(C.ALLEGRO_EVENT_MOUSE_AXES, display, x, y, 0, 0, dx, dy, 0, 0, 0)
+}
+
+// MouseWheel() injects a MouseAxesEvent for a wheel movement of dz at (x, y).
+func (s *Source) MouseWheel(display *allegro.Display, x, y, z, dz int) {
+	s.mouse(C.ALLEGRO_EVENT_MOUSE_AXES, display, x, y, z, 0, 0, 0, dz, 0, 0)
+}
+
+// MouseButtonDown() injects a MouseButtonDownEvent. Buttons start at 1.
+func (s *Source) MouseButtonDown(display *allegro.Display, x, y int, button uint) {
+	s.mouse(C.ALLEGRO_EVENT_MOUSE_BUTTON_DOWN, display, x, y, 0, 0, 0, 0, 0, 0, button)
+}
+
+// MouseButtonUp() injects a MouseButtonUpEvent. Buttons start at 1.
+func (s *Source) MouseButtonUp(display *allegro.Display, x, y int, button uint) {
+	s.mouse(C.ALLEGRO_EVENT_MOUSE_BUTTON_UP, display, x, y, 0, 0, 0, 0, 0, 0, button)
+}
+
+// Click() injects a button press and release at (x, y).
+func (s *Source) Click(display *allegro.Display, x, y int, button uint) {
+	s.MouseButtonDown(display, x, y, button)
+	s.MouseButtonUp(display, x, y, button)
+}
+
+// Timer() injects a TimerEvent with the given count. If timer isn't nil the
+// event is emitted through the timer's own event source, so that its Source()
+// is timer and it reaches the queues timer is registered with, just like a
+// real tick; the timer itself is left alone. Otherwise it is emitted through
+// s.
+func (s *Source) Timer(timer *allegro.Timer, count int64) {
+	var event C.ALLEGRO_EVENT
+	e := (*C.struct_ALLEGRO_TIMER_EVENT)(unsafe.Pointer(&event))
+	e._type = C.ALLEGRO_EVENT_TIMER
+	e.timestamp = C.double(s.Time)
+	e.count = C.int64_t(count)
+	if timer == nil {
+		s.emit(&event)
+		return
+	}
+	C.emit_event(C.al_get_timer_event_source((*C.ALLEGRO_TIMER)(unsafe.Pointer(timer))), &event)
+}
+
+// Ticks() advances the clock by dt and injects a TimerEvent n times, with
+// counts continuing from start. It returns the count after the last tick.
+func (s *Source) Ticks(timer *allegro.Timer, start int64, n int, dt float64) int64 {
+	for i := 0; i < n; i++ {
+		s.Advance(dt)
+		start++
+		s.Timer(timer, start)
+	}
+	return start
+}
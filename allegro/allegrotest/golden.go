@@ -0,0 +1,138 @@
+package allegrotest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// When UPDATE_GOLDEN is set in the environment, AssertGolden() writes the
+// rendered image as the new reference instead of comparing against it.
+const updateGoldenEnv = "UPDATE_GOLDEN"
+
+// Render() draws into a new w x h memory bitmap and returns a copy of the
+// result. The bitmap is the target while draw runs and is cleared to
+// transparent black beforehand. Allegro must already be initialized, e.g. with
+// InitHeadless().
+func Render(w, h int, draw func()) (*image.RGBA, error) {
+	flags := allegro.NewBitmapFlags()
+	allegro.SetNewBitmapFlags(allegro.MEMORY_BITMAP)
+	bmp := allegro.CreateBitmap(w, h)
+	allegro.SetNewBitmapFlags(flags)
+	if bmp == nil {
+		return nil, errors.New("failed to create bitmap")
+	}
+	defer bmp.Destroy()
+	bmp.AsTarget(func() {
+		allegro.ClearToColor(allegro.MapRGBA(0, 0, 0, 0))
+		draw()
+	})
+	return Snapshot(bmp)
+}
+
+// Snapshot() copies the contents of bmp into an image.
+func Snapshot(bmp *allegro.Bitmap) (*image.RGBA, error) {
+	l, err := bmp.LockImage(0, 0, bmp.Width(), bmp.Height(), allegro.LOCK_READONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Unlock()
+	img := image.NewRGBA(image.Rect(0, 0, l.Width, l.Height))
+	for y := 0; y < l.Height; y++ {
+		copy(img.Pix[y*img.Stride:], l.Row(y)[:l.Width*4])
+	}
+	return img, nil
+}
+
+// Diff() compares two images channel by channel and returns the number of
+// pixels differing by more than tolerance, along with an image highlighting
+// them in red over a faded copy of want.
+func Diff(got, want image.Image, tolerance uint8) (int, *image.RGBA) {
+	b := want.Bounds()
+	diff := image.NewRGBA(b)
+	n := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.RGBAModel.Convert(got.At(x, y)).(color.RGBA)
+			w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+			if exceeds(g.R, w.R, tolerance) || exceeds(g.G, w.G, tolerance) ||
+				exceeds(g.B, w.B, tolerance) || exceeds(g.A, w.A, tolerance) {
+				n++
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				grey := uint8((uint(w.R) + uint(w.G) + uint(w.B)) / 3 / 4)
+				diff.SetRGBA(x, y, color.RGBA{grey, grey, grey, 255})
+			}
+		}
+	}
+	return n, diff
+}
+
+func exceeds(a, b, tolerance uint8) bool {
+	if a > b {
+		return a-b > tolerance
+	}
+	return b-a > tolerance
+}
+
+// AssertGolden() renders draw into a w x h memory bitmap and compares the
+// result against the reference PNG at path, allowing each channel to differ by
+// up to tolerance. On a mismatch the test fails, and the rendered image and a
+// diff image are written next to the reference as path+".actual.png" and
+// path+".diff.png".
+func AssertGolden(t testing.TB, path string, w, h int, tolerance uint8, draw func()) {
+	t.Helper()
+	got, err := Render(w, h, draw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv(updateGoldenEnv) != "" {
+		if err := writePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("%v (set %s=1 to create it)", err, updateGoldenEnv)
+	}
+	if !want.Bounds().Eq(got.Bounds()) {
+		writePNG(path+".actual.png", got)
+		t.Fatalf("%s: size is %v, want %v", path, got.Bounds().Size(), want.Bounds().Size())
+	}
+	if n, diff := Diff(got, want, tolerance); n > 0 {
+		writePNG(path+".actual.png", got)
+		writePNG(path+".diff.png", diff)
+		t.Errorf("%s: %d pixels differ by more than %d; see %s.diff.png", path, n, tolerance, path)
+	}
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode '%s': %v", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
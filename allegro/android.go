@@ -0,0 +1,44 @@
+//go:build android
+// +build android
+
+package allegro
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_android.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// This function will set up a custom ALLEGRO_FILE_INTERFACE that makes all
+// future calls of al_fopen read from the applicatons's APK file.
+func SetAPKFileInterface() {
+	C.al_android_set_apk_file_interface()
+}
+
+// This function will set up a custom ALLEGRO_FS_INTERFACE which allows working
+// within the APK filesystem. The filesystem root is your assets directory and
+// there is read-only access to all files within.
+func SetAPKFsInterface() {
+	C.al_android_set_apk_fs_interface()
+}
+
+// Returns a pointer to a static buffer that contains the version string of the
+// Android platform that the calling Allegro program is running on.
+func AndroidOSVersion() string {
+	return C.GoString(C.al_android_get_os_version())
+}
+
+// Returns the Android JNI environment used by Allegro to call into Java. As a
+// convenience this function provides it to the user so there is no need to
+// obtain it yourself.
+func AndroidJNIEnv() unsafe.Pointer {
+	return unsafe.Pointer(C.al_android_get_jni_env())
+}
+
+// Returns the Java Android activity used by Allegro. This is the same object
+// created by Android from the class you specify in your manifest and either an
+// instance of AllegroActivity or a derived class.
+func AndroidActivity() unsafe.Pointer {
+	return unsafe.Pointer(C.al_android_get_activity())
+}
@@ -0,0 +1,236 @@
+// Package anim plays frame-by-frame sprite animations, such as the frames of
+// a sprite sheet sliced with Bitmap.SubBitmaps() or sprites from an atlas.
+//
+// An Animation describes the frames and how they repeat, and can be shared; a
+// Player tracks the progress of one sprite through an animation. Players
+// advance through Update(dt float64), like the animators of the tween
+// package, or through the ticks of a timer with HandleEvent().
+package anim
+
+import (
+	"math"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// LoopMode determines what happens when an animation reaches its last frame.
+type LoopMode int
+
+const (
+	// LOOP starts over from the first frame.
+	LOOP LoopMode = iota
+
+	// ONCE stops on the last frame.
+	ONCE
+
+	// PING_PONG plays backwards to the first frame, then forwards again.
+	PING_PONG
+)
+
+// Frame is a single image of an animation.
+type Frame struct {
+	Bitmap *allegro.Bitmap
+
+	// Duration is how long the frame is shown, in seconds.
+	Duration float64
+}
+
+// Animation is a sequence of frames.
+type Animation struct {
+	Frames []Frame
+	Loop   LoopMode
+}
+
+// New() creates an animation that shows each bitmap for the same duration.
+func New(bitmaps []*allegro.Bitmap, frameDuration float64, loop LoopMode) *Animation {
+	frames := make([]Frame, len(bitmaps))
+	for i, bmp := range bitmaps {
+		frames[i] = Frame{Bitmap: bmp, Duration: frameDuration}
+	}
+	return &Animation{Frames: frames, Loop: loop}
+}
+
+// Duration() returns the time taken to show every frame once.
+func (a *Animation) Duration() float64 {
+	var d float64
+	for _, f := range a.Frames {
+		d += f.Duration
+	}
+	return d
+}
+
+// Player shows an animation, keeping track of the current frame.
+type Player struct {
+	// Speed scales the passage of time, e.g. 2 plays twice as fast. Zero
+	// means 1; to pause, stop calling Update().
+	Speed float64
+
+	// Timer is the timer whose ticks advance the player in HandleEvent().
+	Timer *allegro.Timer
+
+	// OnLoop, if set, is called whenever a LOOP or PING_PONG animation
+	// returns to its first frame.
+	OnLoop func()
+
+	// OnDone, if set, is called when a ONCE animation reaches the end of its
+	// last frame.
+	OnDone func()
+
+	anim    *Animation
+	frame   int
+	dir     int
+	elapsed float64
+	done    bool
+}
+
+// NewPlayer() creates a player showing the first frame of a, which may be nil.
+func NewPlayer(a *Animation) *Player {
+	p := Player{}
+	p.Play(a)
+	return &p
+}
+
+// Play() switches to another animation from its first frame. Playing the
+// animation that is already playing does nothing, so it can be called every
+// update with the animation matching the sprite's state.
+func (p *Player) Play(a *Animation) {
+	if a == p.anim && p.dir != 0 {
+		return
+	}
+	p.anim = a
+	p.Reset()
+}
+
+// Animation() returns the animation being played.
+func (p *Player) Animation() *Animation {
+	return p.anim
+}
+
+// Frame() returns the index of the current frame.
+func (p *Player) Frame() int {
+	return p.frame
+}
+
+// SetFrame() jumps to the given frame, e.g. to start walking animations on a
+// random frame.
+func (p *Player) SetFrame(i int) {
+	if p.anim == nil || i < 0 || i >= len(p.anim.Frames) {
+		return
+	}
+	p.frame = i
+	p.elapsed = 0
+}
+
+// Bitmap() returns the bitmap of the current frame, or nil if there is none.
+func (p *Player) Bitmap() *allegro.Bitmap {
+	if p.anim == nil || len(p.anim.Frames) == 0 {
+		return nil
+	}
+	return p.anim.Frames[p.frame].Bitmap
+}
+
+// Draw() draws the current frame, as Bitmap.Draw() does.
+func (p *Player) Draw(dx, dy float32, flags allegro.DrawFlags) {
+	if bmp := p.Bitmap(); bmp != nil {
+		bmp.Draw(dx, dy, flags)
+	}
+}
+
+// Update() advances the animation by dt seconds and returns whether it has
+// finished, which only ONCE animations do.
+func (p *Player) Update(dt float64) bool {
+	if p.done || p.anim == nil || p.anim.Duration() <= 0 {
+		return p.done
+	}
+	speed := p.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	p.elapsed += dt * speed
+	frames := p.anim.Frames
+	for p.elapsed >= frames[p.frame].Duration {
+		p.elapsed -= frames[p.frame].Duration
+		if p.advance() {
+			break
+		}
+	}
+	return p.done
+}
+
+// advance() moves to the next frame and returns whether the animation has
+// finished.
+func (p *Player) advance() bool {
+	n := len(p.anim.Frames)
+	switch p.anim.Loop {
+	case ONCE:
+		if p.frame == n-1 {
+			p.elapsed = 0
+			p.done = true
+			if p.OnDone != nil {
+				p.OnDone()
+			}
+			return true
+		}
+		p.frame++
+	case PING_PONG:
+		if n == 1 {
+			p.loop()
+			break
+		}
+		if p.frame+p.dir < 0 || p.frame+p.dir >= n {
+			p.dir = -p.dir
+		}
+		p.frame += p.dir
+		if p.frame == 0 {
+			p.loop()
+		}
+	default:
+		p.frame = (p.frame + 1) % n
+		if p.frame == 0 {
+			p.loop()
+		}
+	}
+	return false
+}
+
+func (p *Player) loop() {
+	if p.OnLoop != nil {
+		p.OnLoop()
+	}
+}
+
+// HandleEvent() advances the animation by one tick if ev is a TimerEvent from
+// Timer, and returns whether it was.
+func (p *Player) HandleEvent(ev interface{}) bool {
+	e, ok := ev.(allegro.TimerEvent)
+	if !ok || p.Timer == nil || e.Source() != p.Timer {
+		return false
+	}
+	p.Update(p.Timer.Speed())
+	return true
+}
+
+// Done() returns whether a ONCE animation has finished.
+func (p *Player) Done() bool {
+	return p.done
+}
+
+// Reset() rewinds the animation to its first frame.
+func (p *Player) Reset() {
+	p.frame = 0
+	p.dir = 1
+	p.elapsed = 0
+	p.done = false
+}
+
+// TotalDuration() returns the duration of a ONCE animation, or +Inf for one
+// that repeats.
+func (p *Player) TotalDuration() float64 {
+	if p.anim == nil {
+		return 0
+	}
+	if p.anim.Loop != ONCE {
+		return math.Inf(1)
+	}
+	return p.anim.Duration()
+}
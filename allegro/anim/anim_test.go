@@ -0,0 +1,42 @@
+package anim
+
+import (
+	"testing"
+)
+
+func TestPlayer(t *testing.T) {
+	frames := []Frame{{Duration: 0.25}, {Duration: 0.5}, {Duration: 0.25}}
+	steps := []float64{0.125, 0.125, 0.375, 0.25, 0.25, 0.25, 0.25}
+
+	cases := []struct {
+		loop  LoopMode
+		want  []int
+		loops int
+		done  bool
+	}{
+		{LOOP, []int{0, 1, 1, 2, 0, 1, 1}, 1, false},
+		{ONCE, []int{0, 1, 1, 2, 2, 2, 2}, 0, true},
+		{PING_PONG, []int{0, 1, 1, 2, 1, 1, 0}, 1, false},
+	}
+	for _, c := range cases {
+		loops, dones := 0, 0
+		p := NewPlayer(&Animation{Frames: frames, Loop: c.loop})
+		p.OnLoop = func() { loops++ }
+		p.OnDone = func() { dones++ }
+		for i, dt := range steps {
+			p.Update(dt)
+			if p.Frame() != c.want[i] {
+				t.Errorf("loop mode %d, step %d: frame %d, want %d", c.loop, i, p.Frame(), c.want[i])
+			}
+		}
+		if loops != c.loops {
+			t.Errorf("loop mode %d: OnLoop called %d times, want %d", c.loop, loops, c.loops)
+		}
+		if p.Done() != c.done {
+			t.Errorf("loop mode %d: Done() = %v, want %v", c.loop, p.Done(), c.done)
+		}
+		if c.done && dones != 1 || !c.done && dones != 0 {
+			t.Errorf("loop mode %d: OnDone called %d times", c.loop, dones)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package assets provides a manager that loads and caches bitmaps, fonts,
+// samples and shaders. The manager hands out handles rather than the resources
+// themselves, so that a resource can be replaced, e.g. after its file changes
+// on disk, without the rest of the game needing to know.
+//
+// Assets must be loaded and reloaded on the thread that owns the display,
+// unless they are loaded in the background with a Loader.
+package assets
+
+import (
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/audio"
+	"github.com/ccollins476ad/go-allegro/allegro/font"
+	"sync"
+	"time"
+)
+
+type asset interface {
+	files() []string
+	reload() error
+	destroy()
+}
+
+// Manager owns a collection of assets. Requesting the same asset twice
+// returns the same handle.
+type Manager struct {
+	// OnReload, if set, is called from Update() after each attempt to reload
+	// an asset whose file has changed. err is nil if the reload succeeded;
+	// on failure the previous version of the asset is kept.
+	OnReload func(path string, err error)
+
+	assets map[string]asset
+
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+	changed  map[string]bool
+	stop     chan struct{}
+}
+
+// NewManager() creates an empty asset manager.
+func NewManager() *Manager {
+	return &Manager{
+		assets:   make(map[string]asset),
+		modTimes: make(map[string]time.Time),
+		changed:  make(map[string]bool),
+	}
+}
+
+func (m *Manager) add(key string, a asset) {
+	m.assets[key] = a
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, f := range a.files() {
+		if _, ok := m.modTimes[f]; !ok {
+			m.modTimes[f] = modTime(f)
+		}
+	}
+}
+
+// Destroy() stops watching for changes and destroys every asset owned by the
+// manager. Handles obtained from the manager must not be used afterwards.
+func (m *Manager) Destroy() {
+	m.StopWatching()
+	for key, a := range m.assets {
+		a.destroy()
+		delete(m.assets, key)
+	}
+}
+
+/* -- Bitmaps -- */
+
+// Bitmap is a handle to a bitmap loaded by a Manager.
+type Bitmap struct {
+	path string
+	bmp  *allegro.Bitmap
+}
+
+// Bitmap() returns a handle to the bitmap stored in the given file, loading it
+// if it hasn't been loaded already.
+func (m *Manager) Bitmap(path string) (*Bitmap, error) {
+	key := "bitmap:" + path
+	if a, ok := m.assets[key]; ok {
+		return a.(*Bitmap), nil
+	}
+	b := Bitmap{path: path}
+	if err := b.reload(); err != nil {
+		return nil, err
+	}
+	m.add(key, &b)
+	return &b, nil
+}
+
+// Get() returns the current version of the bitmap. Don't hold on to the
+// result across frames, as it is destroyed when the bitmap is reloaded.
+func (b *Bitmap) Get() *allegro.Bitmap {
+	return b.bmp
+}
+
+// Path() returns the file the bitmap was loaded from.
+func (b *Bitmap) Path() string {
+	return b.path
+}
+
+func (b *Bitmap) files() []string {
+	return []string{b.path}
+}
+
+func (b *Bitmap) reload() error {
+	bmp, err := allegro.LoadBitmap(b.path)
+	if err != nil {
+		return err
+	}
+	b.destroy()
+	b.bmp = bmp
+	return nil
+}
+
+func (b *Bitmap) destroy() {
+	if b.bmp != nil {
+		b.bmp.Destroy()
+		b.bmp = nil
+	}
+}
+
+/* -- Fonts -- */
+
+// Font is a handle to a font loaded by a Manager.
+type Font struct {
+	path        string
+	size, flags int
+	f           *font.Font
+}
+
+// Font() returns a handle to the font stored in the given file at the given
+// size, loading it if it hasn't been loaded already. size and flags are
+// passed to font.LoadFont().
+func (m *Manager) Font(path string, size, flags int) (*Font, error) {
+	key := fmt.Sprintf("font:%s:%d:%d", path, size, flags)
+	if a, ok := m.assets[key]; ok {
+		return a.(*Font), nil
+	}
+	f := Font{path: path, size: size, flags: flags}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	m.add(key, &f)
+	return &f, nil
+}
+
+// Get() returns the current version of the font. Don't hold on to the result
+// across frames, as it is destroyed when the font is reloaded.
+func (f *Font) Get() *font.Font {
+	return f.f
+}
+
+// Path() returns the file the font was loaded from.
+func (f *Font) Path() string {
+	return f.path
+}
+
+func (f *Font) files() []string {
+	return []string{f.path}
+}
+
+func (f *Font) reload() error {
+	fnt, err := font.LoadFont(f.path, f.size, f.flags)
+	if err != nil {
+		return err
+	}
+	f.destroy()
+	f.f = fnt
+	return nil
+}
+
+func (f *Font) destroy() {
+	if f.f != nil {
+		f.f.Destroy()
+		f.f = nil
+	}
+}
+
+/* -- Samples -- */
+
+// Sample is a handle to an audio sample loaded by a Manager.
+type Sample struct {
+	path string
+	s    *audio.Sample
+}
+
+// Sample() returns a handle to the audio sample stored in the given file,
+// loading it if it hasn't been loaded already.
+func (m *Manager) Sample(path string) (*Sample, error) {
+	key := "sample:" + path
+	if a, ok := m.assets[key]; ok {
+		return a.(*Sample), nil
+	}
+	s := Sample{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	m.add(key, &s)
+	return &s, nil
+}
+
+// Get() returns the current version of the sample.
+func (s *Sample) Get() *audio.Sample {
+	return s.s
+}
+
+// Path() returns the file the sample was loaded from.
+func (s *Sample) Path() string {
+	return s.path
+}
+
+func (s *Sample) files() []string {
+	return []string{s.path}
+}
+
+func (s *Sample) reload() error {
+	smp, err := audio.LoadSample(s.path)
+	if err != nil {
+		return err
+	}
+	s.destroy()
+	s.s = smp
+	return nil
+}
+
+func (s *Sample) destroy() {
+	if s.s != nil {
+		s.s.Destroy()
+		s.s = nil
+	}
+}
+
+/* -- Shaders -- */
+
+// Shader is a handle to a shader built by a Manager.
+type Shader struct {
+	platform      allegro.ShaderPlatform
+	vertex, pixel string
+	s             *allegro.Shader
+}
+
+// Shader() returns a handle to a shader built from the given vertex and pixel
+// shader source files, building it if it hasn't been built already. Either
+// file may be empty, in which case Allegro's default source is used for that
+// stage.
+func (m *Manager) Shader(platform allegro.ShaderPlatform, vertexFile, pixelFile string) (*Shader, error) {
+	key := fmt.Sprintf("shader:%d:%s:%s", platform, vertexFile, pixelFile)
+	if a, ok := m.assets[key]; ok {
+		return a.(*Shader), nil
+	}
+	s := Shader{platform: platform, vertex: vertexFile, pixel: pixelFile}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	m.add(key, &s)
+	return &s, nil
+}
+
+// Get() returns the current version of the shader. Don't hold on to the
+// result across frames, as it is destroyed when the shader is rebuilt.
+func (s *Shader) Get() *allegro.Shader {
+	return s.s
+}
+
+func (s *Shader) files() []string {
+	var files []string
+	for _, f := range []string{s.vertex, s.pixel} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (s *Shader) reload() error {
+	sh, err := allegro.LoadShaderProgram(s.platform, s.vertex, s.pixel)
+	if err != nil {
+		return err
+	}
+	old := s.s
+	s.s = sh
+	if old != nil {
+		return allegro.ReplaceShader(old, sh)
+	}
+	return nil
+}
+
+func (s *Shader) destroy() {
+	if s.s != nil {
+		s.s.Destroy()
+		s.s = nil
+	}
+}
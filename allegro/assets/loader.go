@@ -0,0 +1,225 @@
+package assets
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/audio"
+	"sync"
+	"time"
+)
+
+// Loader loads assets in the background. Files are read and decoded on the
+// worker goroutines of a Pool, and any work that must happen on the thread
+// that owns the display, such as turning a decoded image into a video bitmap,
+// is queued until the next call to Update().
+//
+// Handles returned by a Loader are registered with its Manager straight away,
+// but their Get() method returns nil until loading has finished.
+type Loader struct {
+	// Uploads converts the bitmaps decoded by Bitmap() to video bitmaps.
+	// Update() drains it within its budget, so setting Uploads.MaxPixels
+	// limits how much texture data is uploaded per frame.
+	Uploads UploadQueue
+
+	m    *Manager
+	pool *Pool
+	own  bool
+
+	mu      sync.Mutex
+	total   int
+	done    int
+	errs    []error
+	backlog []Job
+	feeding bool
+}
+
+// NewLoader() creates a loader with its own pool of the given number of
+// workers. If workers is 0, one worker is started per CPU.
+func (m *Manager) NewLoader(workers int) *Loader {
+	l := m.NewLoaderPool(NewPool(workers, 0))
+	l.own = true
+	return l
+}
+
+// NewLoaderPool() creates a loader that runs its jobs on an existing pool. A
+// pool should only be used by one loader at a time.
+func (m *Manager) NewLoaderPool(pool *Pool) *Loader {
+	return &Loader{m: m, pool: pool}
+}
+
+// Queue() submits a custom job to the loader, for asset types the manager
+// doesn't know about. It counts towards Progress() like any other asset.
+//
+// Queue() never blocks; jobs beyond what the pool can hold are kept in a
+// backlog and fed to the pool as it makes room.
+func (l *Loader) Queue(job Job) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total++
+	l.backlog = append(l.backlog, job)
+	if !l.feeding {
+		l.feeding = true
+		go l.feed()
+	}
+}
+
+func (l *Loader) feed() {
+	for {
+		l.mu.Lock()
+		if len(l.backlog) == 0 {
+			l.feeding = false
+			l.mu.Unlock()
+			return
+		}
+		job := l.backlog[0]
+		l.backlog[0] = nil
+		l.backlog = l.backlog[1:]
+		l.mu.Unlock()
+		l.pool.Submit(job)
+	}
+}
+
+// Bitmap() queues a bitmap to be loaded and returns its handle. If the
+// manager already has the bitmap, its existing handle is returned.
+func (l *Loader) Bitmap(path string) *Bitmap {
+	key := "bitmap:" + path
+	if a, ok := l.m.assets[key]; ok {
+		return a.(*Bitmap)
+	}
+	b := &Bitmap{path: path}
+	l.m.add(key, b)
+	l.Queue(func() func() error {
+		bmp, err := allegro.LoadBitmap(path)
+		return func() error {
+			if err != nil {
+				return err
+			}
+			l.Uploads.Push(bmp, func(bmp *allegro.Bitmap, err error) {
+				if err != nil {
+					bmp.Destroy()
+				} else {
+					b.destroy()
+					b.bmp = bmp
+				}
+				l.complete(err)
+			})
+			return errUploadQueued
+		}
+	})
+	return b
+}
+
+// Sample() queues an audio sample to be loaded and returns its handle. If the
+// manager already has the sample, its existing handle is returned.
+func (l *Loader) Sample(path string) *Sample {
+	key := "sample:" + path
+	if a, ok := l.m.assets[key]; ok {
+		return a.(*Sample)
+	}
+	s := &Sample{path: path}
+	l.m.add(key, s)
+	l.Queue(func() func() error {
+		smp, err := audio.LoadSample(path)
+		return func() error {
+			if err != nil {
+				return err
+			}
+			s.destroy()
+			s.s = smp
+			return nil
+		}
+	})
+	return s
+}
+
+// Update() finishes loading assets that have been decoded, spending at most
+// roughly the given amount of time doing so, and then converts bitmaps
+// through Uploads with whatever time is left. At least one bitmap is
+// converted if any are waiting, so that loading always makes progress. A
+// budget of 0 finishes everything that is ready. It must be called from the
+// thread that owns the display, e.g. once per frame while a loading screen is
+// shown.
+func (l *Loader) Update(budget time.Duration) {
+	start := time.Now()
+results:
+	for {
+		select {
+		case finish := <-l.pool.Results():
+			l.finish(finish)
+		default:
+			break results
+		}
+		if budget > 0 && time.Since(start) >= budget {
+			break
+		}
+	}
+	if budget == 0 {
+		l.Uploads.Update(0)
+		return
+	}
+	left := budget - time.Since(start)
+	if left <= 0 {
+		// UploadQueue treats 0 as no limit; the smallest positive budget
+		// converts just the one bitmap it always converts.
+		left = 1
+	}
+	l.Uploads.Update(left)
+}
+
+// Returned by the finishing function of a bitmap job once the bitmap has been
+// handed to Uploads, which completes the job later.
+var errUploadQueued = errors.New("bitmap queued for upload")
+
+func (l *Loader) finish(f func() error) {
+	if err := f(); err != errUploadQueued {
+		l.complete(err)
+	}
+}
+
+func (l *Loader) complete(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.done++
+	if err != nil {
+		l.errs = append(l.errs, err)
+	}
+}
+
+// Progress() returns the number of assets that have finished loading, and the
+// total number queued.
+func (l *Loader) Progress() (done, total int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done, l.total
+}
+
+// Done() returns true once every queued asset has finished loading.
+func (l *Loader) Done() bool {
+	done, total := l.Progress()
+	return done == total
+}
+
+// Errors() returns the errors encountered so far. Assets that failed to load
+// keep a nil resource.
+func (l *Loader) Errors() []error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]error(nil), l.errs...)
+}
+
+// Close() waits for every queued asset to finish loading. If the loader
+// created its own pool, the pool is shut down. It must be called from the
+// thread that owns the display. No more assets may be queued afterwards.
+func (l *Loader) Close() {
+	for !l.Done() {
+		if l.Uploads.Len() > 0 {
+			l.Uploads.Flush()
+			continue
+		}
+		l.finish(<-l.pool.Results())
+	}
+	if l.own {
+		l.pool.Close()
+		l.pool.Wait()
+	}
+}
@@ -0,0 +1,84 @@
+package assets
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"runtime"
+	"sync"
+)
+
+// Job decodes an asset on a worker goroutine and returns a function that
+// finishes it on the thread that owns the display. The returned function may
+// be nil if there is nothing left to do.
+type Job func() (finish func() error)
+
+// Pool is a set of worker goroutines that run Jobs. Both the queue of pending
+// jobs and the queue of finished jobs are bounded, so a pool never holds more
+// than a fixed number of decoded assets waiting for the display thread.
+type Pool struct {
+	jobs    chan Job
+	results chan func() error
+	wg      sync.WaitGroup
+	close   sync.Once
+}
+
+// NewPool() starts a pool with the given number of workers. If workers is 0,
+// one worker is started per CPU. queueSize bounds both the pending and
+// finished queues; if it is 0, four times the number of workers is used.
+func NewPool(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if queueSize <= 0 {
+		queueSize = 4 * workers
+	}
+	p := Pool{
+		jobs:    make(chan Job, queueSize),
+		results: make(chan func() error, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return &p
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	// Allegro's new bitmap flags are per thread, so pin the goroutine to
+	// make sure every bitmap it loads is a memory bitmap.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	allegro.SetNewBitmapFlags(allegro.MEMORY_BITMAP)
+
+	for job := range p.jobs {
+		finish := job()
+		if finish == nil {
+			finish = func() error { return nil }
+		}
+		p.results <- finish
+	}
+}
+
+// Submit() queues a job, blocking if the queue is full.
+func (p *Pool) Submit(job Job) {
+	p.jobs <- job
+}
+
+// Results() returns the channel that finished jobs are delivered on. Each
+// value must be called on the thread that owns the display.
+func (p *Pool) Results() <-chan func() error {
+	return p.results
+}
+
+// Close() stops accepting jobs. Workers exit once the queued jobs have been
+// run and their results received.
+func (p *Pool) Close() {
+	p.close.Do(func() { close(p.jobs) })
+}
+
+// Wait() blocks until every worker has exited. Results must keep being
+// received from another goroutine, or this may never return.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
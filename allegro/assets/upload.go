@@ -0,0 +1,112 @@
+package assets
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"sync"
+	"time"
+)
+
+var ErrUploadFailed = errors.New("bitmap is still a memory bitmap after conversion")
+
+type upload struct {
+	bmp  *allegro.Bitmap
+	done func(bmp *allegro.Bitmap, err error)
+}
+
+// UploadQueue stages memory bitmaps, typically decoded on other goroutines,
+// until they can be converted to video bitmaps on the thread that owns the
+// display. Converting a large bitmap can take several milliseconds, so
+// Update() only converts as many as its per-frame budget allows, which spreads
+// a streaming load over several frames instead of stalling one.
+type UploadQueue struct {
+	// MaxPixels limits the total area of the bitmaps converted by one call to
+	// Update(). Zero means no limit.
+	MaxPixels int
+
+	mu      sync.Mutex
+	pending []upload
+}
+
+// Push() stages a memory bitmap for conversion. It may be called from any
+// goroutine. done, if not nil, is called from Update() on the display's
+// thread once the bitmap has been converted; err is non-nil if it is still a
+// memory bitmap, e.g. because no display was current.
+func (q *UploadQueue) Push(bmp *allegro.Bitmap, done func(bmp *allegro.Bitmap, err error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, upload{bmp, done})
+}
+
+// Len() returns the number of bitmaps waiting to be converted.
+func (q *UploadQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *UploadQueue) pop() (upload, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return upload{}, false
+	}
+	u := q.pending[0]
+	q.pending[0] = upload{}
+	q.pending = q.pending[1:]
+	return u, true
+}
+
+func (q *UploadQueue) peekPixels() (int, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return 0, false
+	}
+	bmp := q.pending[0].bmp
+	return bmp.Width() * bmp.Height(), true
+}
+
+// Update() converts staged bitmaps in the order they were pushed until either
+// budget has elapsed or MaxPixels would be exceeded, and returns how many were
+// converted. At least one bitmap is converted per call, so that bitmaps larger
+// than the budget still get through. A budget of 0 means no time limit. It
+// must be called from the thread that owns the display, e.g. once per frame.
+func (q *UploadQueue) Update(budget time.Duration) int {
+	start := time.Now()
+	n, pixels := 0, 0
+	for {
+		area, ok := q.peekPixels()
+		if !ok {
+			return n
+		}
+		if n > 0 {
+			if budget > 0 && time.Since(start) >= budget {
+				return n
+			}
+			if q.MaxPixels > 0 && pixels+area > q.MaxPixels {
+				return n
+			}
+		}
+		u, _ := q.pop()
+		u.bmp.Convert()
+		var err error
+		if u.bmp.Flags()&allegro.MEMORY_BITMAP != 0 {
+			err = ErrUploadFailed
+		}
+		if u.done != nil {
+			u.done(u.bmp, err)
+		}
+		n++
+		pixels += area
+	}
+}
+
+// Flush() converts every staged bitmap regardless of the budget.
+func (q *UploadQueue) Flush() int {
+	n := 0
+	for q.Len() > 0 {
+		n += q.Update(0)
+	}
+	return n
+}
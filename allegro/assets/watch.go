@@ -0,0 +1,97 @@
+package assets
+
+import (
+	"os"
+	"time"
+)
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Watch() starts a goroutine that checks the files of every loaded asset for
+// changes at the given interval. Changed assets are reloaded during the next
+// call to Update(). Calling Watch() while already watching changes the
+// interval.
+func (m *Manager) Watch(interval time.Duration) {
+	m.StopWatching()
+	stop := make(chan struct{})
+	m.mu.Lock()
+	m.stop = stop
+	m.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.poll()
+			}
+		}
+	}()
+}
+
+// StopWatching() stops checking files for changes.
+func (m *Manager) StopWatching() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+func (m *Manager) poll() {
+	m.mu.Lock()
+	paths := make([]string, 0, len(m.modTimes))
+	for path := range m.modTimes {
+		paths = append(paths, path)
+	}
+	m.mu.Unlock()
+
+	for _, path := range paths {
+		t := modTime(path)
+		m.mu.Lock()
+		if !t.IsZero() && !t.Equal(m.modTimes[path]) {
+			m.modTimes[path] = t
+			m.changed[path] = true
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Update() reloads any assets whose files have changed since the last call.
+// It should be called regularly from the thread that owns the display, e.g.
+// once per frame. Handles keep working throughout; only the resource behind
+// them is replaced.
+func (m *Manager) Update() {
+	m.mu.Lock()
+	if len(m.changed) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	changed := m.changed
+	m.changed = make(map[string]bool)
+	m.mu.Unlock()
+
+	for path := range changed {
+		for _, a := range m.assets {
+			for _, f := range a.files() {
+				if f == path {
+					err := a.reload()
+					if m.OnReload != nil {
+						m.OnReload(path, err)
+					}
+					break
+				}
+			}
+		}
+	}
+}
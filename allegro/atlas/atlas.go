@@ -0,0 +1,198 @@
+// Package atlas packs many small bitmaps into one large texture at runtime.
+//
+// Drawing sprites that share a texture lets Allegro's deferred drawing and
+// primitives.Batcher submit them together, instead of switching textures for
+// every sprite.
+package atlas
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Sprite is one image packed into an atlas.
+type Sprite struct {
+	Name string
+
+	// X, Y, W and H locate the sprite in the atlas bitmap, in pixels. They
+	// are the source region to pass to primitives.Batcher or
+	// Bitmap.DrawRegion() along with the atlas bitmap.
+	X, Y, W, H int
+
+	// U0, V0, U1 and V1 are the same region in texture coordinates from 0
+	// to 1, for shaders and custom vertex formats.
+	U0, V0, U1, V1 float32
+
+	// Bitmap is a sub-bitmap of the atlas covering the sprite, which can be
+	// drawn like the original.
+	Bitmap *allegro.Bitmap
+}
+
+// Region() returns the sprite's source region as the floats taken by the
+// region drawing functions.
+func (s *Sprite) Region() (sx, sy, sw, sh float32) {
+	return float32(s.X), float32(s.Y), float32(s.W), float32(s.H)
+}
+
+// Atlas is a bitmap holding packed sprites.
+type Atlas struct {
+	Bitmap *allegro.Bitmap
+
+	sprites []*Sprite
+	byName  map[string]*Sprite
+}
+
+// Sprite() returns the sprite with the given name, or nil if there is none.
+func (a *Atlas) Sprite(name string) *Sprite {
+	return a.byName[name]
+}
+
+// Sprites() returns every sprite in the order it was added to the packer.
+func (a *Atlas) Sprites() []*Sprite {
+	return a.sprites
+}
+
+// Destroy() frees the atlas bitmap and the sprites' sub-bitmaps.
+func (a *Atlas) Destroy() {
+	for _, s := range a.sprites {
+		if s.Bitmap != nil {
+			s.Bitmap.Destroy()
+			s.Bitmap = nil
+		}
+	}
+	if a.Bitmap != nil {
+		a.Bitmap.Destroy()
+		a.Bitmap = nil
+	}
+}
+
+type entry struct {
+	name string
+	bmp  *allegro.Bitmap
+	img  image.Image
+}
+
+func (e *entry) size() image.Point {
+	if e.bmp != nil {
+		return image.Pt(e.bmp.Width(), e.bmp.Height())
+	}
+	return e.img.Bounds().Size()
+}
+
+// Packer collects the images to put in an atlas. The zero value is ready to
+// use.
+type Packer struct {
+	// Padding is the number of transparent pixels left around each sprite,
+	// so that filtering doesn't bleed neighbouring sprites into it.
+	Padding int
+
+	// MaxSize limits the width and height of the atlas. If zero, the
+	// MAX_BITMAP_SIZE of the current display is used, or 2048 if there is
+	// no display.
+	MaxSize int
+
+	entries []entry
+}
+
+// AddBitmap() adds a bitmap under the given name. Its pixels are copied when
+// Pack() is called, after which the caller may destroy it.
+func (p *Packer) AddBitmap(name string, bmp *allegro.Bitmap) {
+	p.entries = append(p.entries, entry{name: name, bmp: bmp})
+}
+
+// AddImage() adds an image under the given name.
+func (p *Packer) AddImage(name string, img image.Image) {
+	p.entries = append(p.entries, entry{name: name, img: img})
+}
+
+// Pack() creates an atlas bitmap, with the new bitmap flags and format of the
+// calling thread, and copies every image added so far into it. It fails if
+// the images don't fit within MaxSize.
+func (p *Packer) Pack() (*Atlas, error) {
+	if len(p.entries) == 0 {
+		return nil, fmt.Errorf("no images to pack")
+	}
+	sizes := make([]image.Point, len(p.entries))
+	byName := make(map[string]*Sprite, len(p.entries))
+	for i := range p.entries {
+		e := &p.entries[i]
+		if e.bmp == nil && e.img == nil {
+			return nil, fmt.Errorf("image '%s' is nil", e.name)
+		}
+		if _, ok := byName[e.name]; ok {
+			return nil, fmt.Errorf("duplicate image name '%s'", e.name)
+		}
+		byName[e.name] = nil
+		sizes[i] = e.size()
+		if sizes[i].X <= 0 || sizes[i].Y <= 0 {
+			return nil, fmt.Errorf("image '%s' is empty", e.name)
+		}
+	}
+
+	maxSize := p.MaxSize
+	if maxSize <= 0 {
+		maxSize = 2048
+		if d := allegro.CurrentDisplay(); d != nil {
+			maxSize = d.DisplayOption(allegro.MAX_BITMAP_SIZE)
+		}
+	}
+	rects, w, h, err := pack(sizes, p.Padding, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	bmp := allegro.CreateBitmap(w, h)
+	if bmp == nil {
+		return nil, fmt.Errorf("failed to create %dx%d atlas bitmap", w, h)
+	}
+	a := Atlas{Bitmap: bmp, byName: byName}
+	allegro.RenderTo(bmp, func() {
+		allegro.ClearToColor(allegro.MapRGBA(0, 0, 0, 0))
+	})
+
+	for i := range p.entries {
+		e := &p.entries[i]
+		r := rects[i]
+		if err := e.copyTo(bmp, r.Min); err != nil {
+			a.Destroy()
+			return nil, fmt.Errorf("image '%s': %s", e.name, err.Error())
+		}
+		sub, err := bmp.CreateSubBitmap(r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+		if err != nil {
+			a.Destroy()
+			return nil, err
+		}
+		s := Sprite{
+			Name:   e.name,
+			X:      r.Min.X,
+			Y:      r.Min.Y,
+			W:      r.Dx(),
+			H:      r.Dy(),
+			U0:     float32(r.Min.X) / float32(w),
+			V0:     float32(r.Min.Y) / float32(h),
+			U1:     float32(r.Max.X) / float32(w),
+			V1:     float32(r.Max.Y) / float32(h),
+			Bitmap: sub,
+		}
+		a.sprites = append(a.sprites, &s)
+		byName[e.name] = &s
+	}
+	return &a, nil
+}
+
+func (e *entry) copyTo(dst *allegro.Bitmap, at image.Point) error {
+	if e.bmp != nil {
+		bounds := image.Rect(0, 0, e.bmp.Width(), e.bmp.Height())
+		return allegro.CopyBitmapRegion(dst, at.X, at.Y, e.bmp, bounds)
+	}
+	// ImageToBitmap() keeps the image's coordinates, so the bitmap extends
+	// from the origin to the bottom-right of its bounds.
+	src, err := allegro.ImageToBitmap(e.img)
+	if err != nil {
+		return err
+	}
+	defer src.Destroy()
+	return allegro.CopyBitmapRegion(dst, at.X, at.Y, src, e.img.Bounds())
+}
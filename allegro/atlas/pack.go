@@ -0,0 +1,91 @@
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// pack() places rectangles of the given sizes in the smallest power-of-two
+// area it can find, up to maxSize on each side, with padding pixels around
+// each. It returns the rectangles in the order of sizes, and the atlas size.
+func pack(sizes []image.Point, padding, maxSize int) ([]image.Rectangle, int, int, error) {
+	if padding < 0 {
+		padding = 0
+	}
+	area, maxW, maxH := 0, 0, 0
+	for _, s := range sizes {
+		area += (s.X + padding) * (s.Y + padding)
+		if s.X > maxW {
+			maxW = s.X
+		}
+		if s.Y > maxH {
+			maxH = s.Y
+		}
+	}
+
+	// Tallest first, so that each shelf wastes little space above the
+	// shorter sprites on it.
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := sizes[order[i]], sizes[order[j]]
+		if a.Y != b.Y {
+			return a.Y > b.Y
+		}
+		return a.X > b.X
+	})
+
+	w := nextPowerOfTwo(maxW + 2*padding)
+	h := nextPowerOfTwo(maxH + 2*padding)
+	for w*h < area {
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+	for w <= maxSize && h <= maxSize {
+		if rects, ok := packShelves(sizes, order, padding, w, h); ok {
+			return rects, w, h, nil
+		}
+		if w <= h {
+			w *= 2
+		} else {
+			h *= 2
+		}
+	}
+	return nil, 0, 0, fmt.Errorf("images don't fit in a %dx%d atlas", maxSize, maxSize)
+}
+
+// packShelves() fills rows ("shelves") from left to right, starting a new
+// shelf below the tallest sprite of the current one when a row is full.
+func packShelves(sizes []image.Point, order []int, padding, w, h int) ([]image.Rectangle, bool) {
+	rects := make([]image.Rectangle, len(sizes))
+	x, y, shelf := padding, padding, 0
+	for _, i := range order {
+		s := sizes[i]
+		if x+s.X+padding > w {
+			x, y, shelf = padding, y+shelf+padding, 0
+		}
+		if x+s.X+padding > w || y+s.Y+padding > h {
+			return nil, false
+		}
+		rects[i] = image.Rect(x, y, x+s.X, y+s.Y)
+		x += s.X + padding
+		if s.Y > shelf {
+			shelf = s.Y
+		}
+	}
+	return rects, true
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
@@ -0,0 +1,37 @@
+package atlas
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPack(t *testing.T) {
+	sizes := []image.Point{{30, 10}, {16, 16}, {16, 16}, {60, 4}, {5, 20}}
+	const padding = 1
+
+	rects, w, h, err := pack(sizes, padding, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != 64 || h != 64 {
+		t.Errorf("atlas size = %dx%d, want 64x64", w, h)
+	}
+	bounds := image.Rect(padding, padding, w-padding, h-padding)
+	for i, r := range rects {
+		if r.Size() != sizes[i] {
+			t.Errorf("rect %d: size %v, want %v", i, r.Size(), sizes[i])
+		}
+		if !r.In(bounds) {
+			t.Errorf("rect %d: %v outside %v", i, r, bounds)
+		}
+		for j := i + 1; j < len(rects); j++ {
+			if r.Inset(-padding).Overlaps(rects[j]) {
+				t.Errorf("rects %d and %d overlap: %v, %v", i, j, r, rects[j])
+			}
+		}
+	}
+
+	if _, _, _, err := pack(sizes, padding, 32); err == nil {
+		t.Error("pack into 32x32 succeeded, want error")
+	}
+}
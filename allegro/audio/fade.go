@@ -0,0 +1,174 @@
+package audio
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"sync"
+)
+
+// Gainer is implemented by everything whose volume can be faded: *Mixer,
+// *Stream and *SampleInstance.
+type Gainer interface {
+	Gain() float32
+	SetGain(val float32) error
+}
+
+var (
+	_ Gainer = (*Mixer)(nil)
+	_ Gainer = (*Stream)(nil)
+	_ Gainer = (*SampleInstance)(nil)
+)
+
+// Fade is a gain ramp in progress.
+type Fade struct {
+	// OnDone, if set, is called once the target gain has been reached. It is
+	// not called if the fade is cancelled or replaced.
+	OnDone func()
+
+	target   Gainer
+	from, to float32
+	duration float64
+	elapsed  float64
+}
+
+// Fader ramps the gain of mixers, streams and sample instances over time.
+// Fades can be advanced by calling Update() from the game loop, or by an
+// Allegro timer after calling Start().
+type Fader struct {
+	mu    sync.Mutex
+	fades []*Fade
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewFader() creates a fader with no fades in progress.
+func NewFader() *Fader {
+	return &Fader{}
+}
+
+// FadeTo() ramps the gain of g from its current value to gain over secs
+// seconds, replacing any fade already in progress on g.
+func (f *Fader) FadeTo(g Gainer, gain float32, secs float64) *Fade {
+	fade := &Fade{target: g, from: g.Gain(), to: gain, duration: secs}
+	f.mu.Lock()
+	f.remove(g)
+	f.fades = append(f.fades, fade)
+	f.mu.Unlock()
+	return fade
+}
+
+// FadeIn() silences g and then ramps it up to gain over secs seconds.
+func (f *Fader) FadeIn(g Gainer, gain float32, secs float64) *Fade {
+	g.SetGain(0)
+	return f.FadeTo(g, gain, secs)
+}
+
+// FadeOut() ramps g down to silence over secs seconds and then calls onDone,
+// which is typically used to stop or destroy what was faded.
+func (f *Fader) FadeOut(g Gainer, secs float64, onDone func()) *Fade {
+	fade := f.FadeTo(g, 0, secs)
+	fade.OnDone = onDone
+	return fade
+}
+
+// Cancel() stops any fade in progress on g, leaving its gain where it is.
+func (f *Fader) Cancel(g Gainer) {
+	f.mu.Lock()
+	f.remove(g)
+	f.mu.Unlock()
+}
+
+// remove() drops the fade on g. The caller must hold f.mu.
+func (f *Fader) remove(g Gainer) {
+	for i, fade := range f.fades {
+		if fade.target == g {
+			f.fades = append(f.fades[:i], f.fades[i+1:]...)
+			return
+		}
+	}
+}
+
+// Active() returns whether any fades are in progress.
+func (f *Fader) Active() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.fades) > 0
+}
+
+// Update() advances all fades by dt seconds.
+func (f *Fader) Update(dt float64) {
+	var finished []*Fade
+	f.mu.Lock()
+	fades := f.fades[:0]
+	for _, fade := range f.fades {
+		fade.elapsed += dt
+		t := float32(1)
+		if fade.duration > 0 && fade.elapsed < fade.duration {
+			t = float32(fade.elapsed / fade.duration)
+		}
+		fade.target.SetGain(fade.from + (fade.to-fade.from)*t)
+		if t >= 1 {
+			finished = append(finished, fade)
+		} else {
+			fades = append(fades, fade)
+		}
+	}
+	f.fades = fades
+	f.mu.Unlock()
+
+	// Callbacks run without the lock so that they can start new fades.
+	for _, fade := range finished {
+		if fade.OnDone != nil {
+			fade.OnDone()
+		}
+	}
+}
+
+// Start() drives the fader from an Allegro timer ticking rate times per
+// second, on a goroutine of its own, so that fades progress smoothly however
+// the game loop is structured. OnDone callbacks are then called on that
+// goroutine. Call Stop() to shut it down.
+func (f *Fader) Start(rate float64) error {
+	timer, err := allegro.CreateTimer(1 / rate)
+	if err != nil {
+		return err
+	}
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		timer.Destroy()
+		return err
+	}
+	queue.Register(timer)
+	f.stop = make(chan struct{})
+	f.done = make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		defer queue.Destroy()
+		defer timer.Destroy()
+		var event allegro.Event
+		timer.Start()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if ev, ok := queue.WaitForEventTimed(&event, 0.1); ok {
+				if _, isTimer := ev.(allegro.TimerEvent); isTimer {
+					f.Update(1 / rate)
+				}
+			}
+		}
+	}(f.stop, f.done)
+	return nil
+}
+
+// Stop() shuts down the goroutine started by Start() and waits for it to
+// exit. Fades in progress are kept and can be continued with Update().
+func (f *Fader) Stop() {
+	if f.stop == nil {
+		return
+	}
+	close(f.stop)
+	<-f.done
+	f.stop, f.done = nil, nil
+}
@@ -0,0 +1,142 @@
+package audio
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_audio.h>
+import "C"
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Generator plays audio produced by a Go function, e.g. a synthesizer or a
+// dynamic music system. It owns an AUDIO_DEPTH_FLOAT32 stream attached to the
+// default mixer and a goroutine that waits for the stream's fragment events
+// and asks the function to fill each free fragment.
+type Generator struct {
+	stream   *Stream
+	queue    *allegro.EventQueue
+	fill     func(buf []float32)
+	fragLen  int
+	fragTime time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewGenerator() creates a generator and starts playing. fill is called from
+// the generator's goroutine with a fragment's worth of interleaved samples in
+// the range [-1, 1], fragSamples frames of chanConf.ChannelCount() values
+// each, which it must overwrite completely; the buffer is reused. fragments
+// and fragSamples control the latency as for CreateStream().
+func NewGenerator(freq uint, chanConf ChannelConf, fragments, fragSamples uint, fill func(buf []float32)) (*Generator, error) {
+	if freq == 0 {
+		return nil, errors.New("generator frequency must be positive")
+	}
+	if fill == nil {
+		return nil, errors.New("generator needs a fill function")
+	}
+	mixer := DefaultMixer()
+	if mixer == nil {
+		return nil, errors.New("no default mixer; call ReserveSamples() first")
+	}
+	stream := CreateStream(fragments, fragSamples, freq, AUDIO_DEPTH_FLOAT32, chanConf)
+	if stream.ptr == nil {
+		return nil, errors.New("failed to create audio stream")
+	}
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		stream.Destroy()
+		return nil, err
+	}
+	queue.RegisterEventSource(stream.EventSource())
+	g := Generator{
+		stream:   stream,
+		queue:    queue,
+		fill:     fill,
+		fragLen:  int(fragSamples * chanConf.ChannelCount()),
+		fragTime: time.Duration(fragSamples) * time.Second / time.Duration(freq),
+		stop:     make(chan struct{}),
+	}
+	// Fill every fragment before the mixer starts pulling, so that playback
+	// doesn't begin with silence.
+	g.fillAvailable()
+	if err := stream.AttachToMixer(mixer); err != nil {
+		queue.Destroy()
+		stream.Destroy()
+		return nil, err
+	}
+	g.wg.Add(1)
+	go g.run()
+	return &g, nil
+}
+
+// Stream() returns the underlying audio stream, e.g. to change its gain or
+// pause it.
+func (g *Generator) Stream() *Stream {
+	return g.stream
+}
+
+func (g *Generator) run() {
+	defer g.wg.Done()
+	for {
+		select {
+		case <-g.stop:
+			return
+		default:
+		}
+		// Wake up regularly to notice Close() even if no events arrive,
+		// e.g. while the stream is paused.
+		if _, ok := g.queue.WaitDuration(g.fragTime); ok {
+			g.fillAvailable()
+		}
+	}
+}
+
+// fillAvailable() fills every fragment that is free for writing.
+func (g *Generator) fillAvailable() {
+	for {
+		buffer := C.al_get_audio_stream_fragment(g.stream.ptr)
+		if buffer == nil {
+			return
+		}
+		g.fill(unsafe.Slice((*float32)(buffer), g.fragLen))
+		C.al_set_audio_stream_fragment(g.stream.ptr, buffer)
+	}
+}
+
+// Close() stops the generator's goroutine and destroys the stream. It waits
+// for a fill call in progress, so a fill function that blocks, such as one
+// from FillFromChan(), must be unblocked first. fill is not called again once
+// it returns.
+func (g *Generator) Close() {
+	close(g.stop)
+	g.wg.Wait()
+	g.stream.Destroy()
+	g.queue.Destroy()
+}
+
+// FillFromChan() returns a fill function for NewGenerator() that plays the
+// interleaved sample buffers received from ch, which may be of any length. It
+// blocks until a whole fragment has been received, so the sender must keep
+// ahead of playback; once ch is closed, silence is played.
+func FillFromChan(ch <-chan []float32) func(buf []float32) {
+	var pending []float32
+	return func(buf []float32) {
+		for len(buf) > 0 {
+			if len(pending) == 0 {
+				var ok bool
+				if pending, ok = <-ch; !ok {
+					for i := range buf {
+						buf[i] = 0
+					}
+					return
+				}
+			}
+			n := copy(buf, pending)
+			buf = buf[n:]
+			pending = pending[n:]
+		}
+	}
+}
@@ -3,6 +3,9 @@ package audio
 // #include <allegro5/allegro.h>
 // #include <allegro5/allegro_audio.h>
 import "C"
+import (
+	"unsafe"
+)
 
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
@@ -14,3 +17,14 @@ func Version() (major, minor, revision, release uint8) {
 	release = uint8(v & 255)
 	return
 }
+
+// Fills a buffer with silence, for the given format and channel
+// configuration. Only as many whole samples as fit in buf are filled.
+func FillSilence(buf []byte, depth Depth, chan_conf ChannelConf) {
+	samples := uint(len(buf)) / (chan_conf.ChannelCount() * depth.Size())
+	if samples == 0 {
+		return
+	}
+	C.al_fill_silence(unsafe.Pointer(&buf[0]), C.uint(samples),
+		C.ALLEGRO_AUDIO_DEPTH(depth), C.ALLEGRO_CHANNEL_CONF(chan_conf))
+}
@@ -0,0 +1,271 @@
+package audio
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_audio.h>
+import "C"
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"math"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var ErrPlayerClosed = errors.New("audio player is closed")
+
+var ErrUnsupportedDepth = errors.New("unsupported audio depth for streamer")
+
+// Streamer is implemented by sample producers in the style of beep: Stream()
+// fills samples with stereo frames in the range [-1, 1] and reports how many
+// were written, or ok=false once the producer is exhausted.
+type Streamer interface {
+	Stream(samples [][2]float64) (n int, ok bool)
+}
+
+// Player adapts an audio stream to the io.Writer interface expected by
+// oto-style producers. Written bytes are raw interleaved PCM in the player's
+// depth and channel configuration; they are buffered until a whole fragment is
+// available and then handed to Allegro, so Write() blocks only while every
+// fragment of the stream is queued for playback, or while the stream is
+// paused or detached. Close() unblocks it.
+type Player struct {
+	stream    *Stream
+	queue     *allegro.EventQueue
+	stop      chan struct{}
+	waiters   sync.WaitGroup
+	depth     Depth
+	chanConf  ChannelConf
+	fragBytes int
+	fragTime  time.Duration
+	pending   []byte
+	mu        sync.Mutex
+	closed    bool
+}
+
+// NewPlayer() creates a stream with the given format and attaches it to the
+// default mixer. fragments and fragSamples control the latency: a larger
+// buffer is less prone to underruns but delays what is written.
+func NewPlayer(freq uint, depth Depth, chanConf ChannelConf, fragments, fragSamples uint) (*Player, error) {
+	mixer := DefaultMixer()
+	if mixer == nil {
+		return nil, errors.New("no default mixer; call ReserveSamples() first")
+	}
+	stream := CreateStream(fragments, fragSamples, freq, depth, chanConf)
+	if stream.ptr == nil {
+		return nil, errors.New("failed to create audio stream")
+	}
+	queue, err := allegro.CreateEventQueue()
+	if err != nil {
+		stream.Destroy()
+		return nil, err
+	}
+	queue.RegisterEventSource(stream.EventSource())
+	if err := stream.AttachToMixer(mixer); err != nil {
+		queue.Destroy()
+		stream.Destroy()
+		return nil, err
+	}
+	p := Player{
+		stream:    stream,
+		queue:     queue,
+		stop:      make(chan struct{}),
+		depth:     depth,
+		chanConf:  chanConf,
+		fragBytes: int(fragSamples * chanConf.ChannelCount() * depth.Size()),
+		fragTime:  time.Duration(fragSamples) * time.Second / time.Duration(freq),
+	}
+	p.pending = make([]byte, 0, p.fragBytes)
+	return &p, nil
+}
+
+// Stream() returns the underlying audio stream, e.g. to change its gain or
+// attach it to a different mixer.
+func (p *Player) Stream() *Stream {
+	return p.stream
+}
+
+// Write() queues raw PCM data for playback. It implements io.Writer.
+func (p *Player) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, ErrPlayerClosed
+	}
+	n := 0
+	for len(b) > 0 {
+		c := copy(p.pending[len(p.pending):p.fragBytes], b)
+		p.pending = p.pending[:len(p.pending)+c]
+		b = b[c:]
+		n += c
+		if len(p.pending) == p.fragBytes {
+			if err := p.submit(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// submit() waits for a free fragment and fills it with the pending buffer,
+// padding with silence. The caller must hold p.mu, which is released while
+// waiting, so other writers may have submitted the buffer by the time a
+// fragment is free. It returns ErrPlayerClosed if Close() is called meanwhile.
+func (p *Player) submit() error {
+	for {
+		if p.closed {
+			return ErrPlayerClosed
+		}
+		if len(p.pending) == 0 || p.fill() {
+			return nil
+		}
+		p.waiters.Add(1)
+		p.mu.Unlock()
+		p.wait()
+		p.waiters.Done()
+		p.mu.Lock()
+	}
+}
+
+// wait() waits for the stream to report a free fragment, returning early if
+// the player is closed. It also wakes up regularly, since a fragment event
+// may have been taken by another waiter.
+func (p *Player) wait() {
+	select {
+	case <-p.stop:
+	default:
+		p.queue.WaitDuration(p.fragTime)
+	}
+}
+
+// fill() hands the pending buffer to a free fragment, if there is one. The
+// caller must hold p.mu.
+func (p *Player) fill() bool {
+	buffer := C.al_get_audio_stream_fragment(p.stream.ptr)
+	if buffer == nil {
+		return false
+	}
+	dst := unsafe.Slice((*byte)(buffer), p.fragBytes)
+	c := copy(dst, p.pending)
+	silence(dst[c:], p.depth)
+	C.al_set_audio_stream_fragment(p.stream.ptr, buffer)
+	p.pending = p.pending[:0]
+	return true
+}
+
+// silence() fills b with the zero level of the given depth; unsigned formats
+// are centred on half their range rather than on zero.
+func silence(b []byte, depth Depth) {
+	var fill byte
+	if depth&AUDIO_DEPTH_UNSIGNED != 0 {
+		fill = 0x80
+	}
+	for i := range b {
+		b[i] = fill
+	}
+	if fill != 0 && depth != AUDIO_DEPTH_UINT8 {
+		// Multi-byte unsigned samples are little-endian: only the most
+		// significant byte of each sample carries the offset.
+		size := int(depth.Size())
+		for i := range b {
+			if i%size != size-1 {
+				b[i] = 0
+			}
+		}
+	}
+}
+
+// Play() feeds s to the player from a new goroutine until it is exhausted or
+// the player is closed. Only AUDIO_DEPTH_FLOAT32 and AUDIO_DEPTH_INT16 players
+// with one or two channels are supported; mono players receive the average of
+// both channels. The returned channel is closed when playback has been queued.
+func (p *Player) Play(s Streamer) (<-chan struct{}, error) {
+	if p.depth != AUDIO_DEPTH_FLOAT32 && p.depth != AUDIO_DEPTH_INT16 {
+		return nil, ErrUnsupportedDepth
+	}
+	channels := int(p.chanConf.ChannelCount())
+	if channels > 2 {
+		return nil, ErrUnsupportedDepth
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		size := int(p.depth.Size())
+		frames := p.fragBytes / (size * channels)
+		samples := make([][2]float64, frames)
+		buf := make([]byte, p.fragBytes)
+		for {
+			n, ok := s.Stream(samples)
+			if n > 0 {
+				encode(buf, samples[:n], p.depth, channels)
+				if _, err := p.Write(buf[:n*size*channels]); err != nil {
+					return
+				}
+			}
+			if !ok {
+				return
+			}
+		}
+	}()
+	return done, nil
+}
+
+// encode() converts stereo frames to interleaved PCM.
+func encode(buf []byte, samples [][2]float64, depth Depth, channels int) {
+	i := 0
+	put := func(v float64) {
+		v = math.Max(-1, math.Min(1, v))
+		if depth == AUDIO_DEPTH_FLOAT32 {
+			binary.LittleEndian.PutUint32(buf[i:], math.Float32bits(float32(v)))
+			i += 4
+		} else {
+			binary.LittleEndian.PutUint16(buf[i:], uint16(int16(v*math.MaxInt16)))
+			i += 2
+		}
+	}
+	for _, s := range samples {
+		if channels == 1 {
+			put((s[0] + s[1]) / 2)
+		} else {
+			put(s[0])
+			put(s[1])
+		}
+	}
+}
+
+// Flush() submits any partially filled fragment, padded with silence.
+func (p *Player) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.submit()
+}
+
+// Close() aborts any Write() or Flush() waiting for a fragment, and destroys
+// the stream. While the stream is playing, the pending data is submitted
+// first and the queued audio is played to the end; a paused or detached
+// stream is destroyed straight away.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPlayerClosed
+	}
+	p.closed = true
+	close(p.stop)
+	// The queue must outlive the writers waiting on it, which give up
+	// within a fragment's time now that stop is closed.
+	p.waiters.Wait()
+	playing := func() bool {
+		return p.stream.Attached() && p.stream.Playing()
+	}
+	for len(p.pending) > 0 && playing() && !p.fill() {
+		p.queue.WaitDuration(p.fragTime)
+	}
+	if playing() {
+		p.stream.Drain()
+	}
+	p.stream.Destroy()
+	p.queue.Destroy()
+	return nil
+}
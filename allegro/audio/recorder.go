@@ -0,0 +1,155 @@
+package audio
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_audio.h>
+import "C"
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"sync"
+	"unsafe"
+)
+
+// The audio recorder is part of Allegro's unstable API, so its event is
+// registered here rather than in init.go.
+func init() {
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_AUDIO_RECORDER_FRAGMENT, func(e *allegro.Event) interface{} {
+		return (*audio_recorder_fragment_event)(unsafe.Pointer(e))
+	})
+}
+
+// Recorder captures audio from an input device such as a microphone. Each
+// time a fragment has been filled, it emits an AudioRecorderFragment event to
+// the queues its event source is registered with.
+type Recorder struct {
+	ptr *C.ALLEGRO_AUDIO_RECORDER
+}
+
+// recorderFormat is what a fragment event, which only points at its
+// recorder, needs to know about the layout of its buffer.
+type recorderFormat struct {
+	sampleSize uint // bytes per sample, for all channels
+	depth      Depth
+}
+
+var (
+	recorderFormats   = map[*C.ALLEGRO_AUDIO_RECORDER]recorderFormat{}
+	recorderFormatsMu sync.Mutex
+)
+
+// Creates an audio recorder using the system's default recording device. (So
+// if the returned device does not work, try updating the system's default
+// recording device.) fragment_count fragments of frag_samples samples each are
+// allocated; a fragment is reported whenever one has been filled.
+func CreateRecorder(fragment_count, frag_samples, freq uint, depth Depth, chan_conf ChannelConf) (*Recorder, error) {
+	ptr := C.al_create_audio_recorder(
+		C.size_t(fragment_count),
+		C.unsigned(frag_samples),
+		C.unsigned(freq),
+		C.ALLEGRO_AUDIO_DEPTH(depth),
+		C.ALLEGRO_CHANNEL_CONF(chan_conf))
+	if ptr == nil {
+		return nil, errors.New("failed to create audio recorder")
+	}
+	recorderFormatsMu.Lock()
+	recorderFormats[ptr] = recorderFormat{chan_conf.ChannelCount() * depth.Size(), depth}
+	recorderFormatsMu.Unlock()
+	return &Recorder{ptr: ptr}, nil
+}
+
+// Begin recording into the fragment buffer. Once a complete fragment has been
+// captured (as specified in al_create_audio_recorder), an
+// ALLEGRO_EVENT_AUDIO_RECORDER_FRAGMENT event will be triggered.
+func (r *Recorder) Start() error {
+	if !bool(C.al_start_audio_recorder(r.ptr)) {
+		return errors.New("failed to start audio recorder")
+	}
+	return nil
+}
+
+// Stop capturing audio data. Note that the audio recorder is still active and
+// consuming resources, so if you are finished recording you should destroy it
+// with al_destroy_audio_recorder.
+func (r *Recorder) Stop() {
+	C.al_stop_audio_recorder(r.ptr)
+}
+
+// Returns true if the audio recorder is currently capturing data and
+// generating events.
+func (r *Recorder) IsRecording() bool {
+	return bool(C.al_is_audio_recorder_recording(r.ptr))
+}
+
+// Returns the event source for the recorder that generates the various
+// recording events.
+func (r *Recorder) EventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(C.al_get_audio_recorder_event_source(r.ptr)))
+}
+
+// Destroys the audio recorder and frees all resources associated with it.
+// Fragment events still in a queue must not be used afterwards.
+func (r *Recorder) Destroy() {
+	recorderFormatsMu.Lock()
+	delete(recorderFormats, r.ptr)
+	recorderFormatsMu.Unlock()
+	C.al_destroy_audio_recorder(r.ptr)
+}
+
+/* -- Audio Recorder Fragment -- */
+
+type AudioRecorderFragment interface {
+	audio_recorder_fragment()
+	Timestamp() float64
+	Samples() uint
+	Buffer() unsafe.Pointer
+	Bytes() []byte
+	Int16s() []int16
+}
+
+type audio_recorder_fragment_event C.ALLEGRO_AUDIO_RECORDER_EVENT
+
+func (e *audio_recorder_fragment_event) audio_recorder_fragment() {}
+
+func (e *audio_recorder_fragment_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+// Samples() returns the number of samples in the fragment, per channel.
+func (e *audio_recorder_fragment_event) Samples() uint {
+	return uint(e.samples)
+}
+
+// Buffer() returns the fragment's data, laid out according to the depth and
+// channel configuration of the recorder.
+func (e *audio_recorder_fragment_event) Buffer() unsafe.Pointer {
+	return e.buffer
+}
+
+// Bytes() returns the fragment's data. It refers to the recorder's own
+// buffer, which is reused once the event has been handled, so copy it to keep
+// it.
+func (e *audio_recorder_fragment_event) Bytes() []byte {
+	b, _ := e.bytes()
+	return b
+}
+
+func (e *audio_recorder_fragment_event) bytes() ([]byte, Depth) {
+	recorderFormatsMu.Lock()
+	format, ok := recorderFormats[e.source]
+	recorderFormatsMu.Unlock()
+	if !ok || e.buffer == nil {
+		return nil, format.depth
+	}
+	return unsafe.Slice((*byte)(e.buffer), uint(e.samples)*format.sampleSize), format.depth
+}
+
+// Int16s() is like Bytes(), but for recorders created with AUDIO_DEPTH_INT16,
+// with one value per channel per sample. It returns nil for other depths.
+func (e *audio_recorder_fragment_event) Int16s() []int16 {
+	b, depth := e.bytes()
+	if depth != AUDIO_DEPTH_INT16 || len(b) < 2 {
+		return nil
+	}
+	return unsafe.Slice((*int16)(unsafe.Pointer(&b[0])), len(b)/2)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ccollins476ad/go-allegro/allegro"
+	"io"
 )
 
 type Sample C.ALLEGRO_SAMPLE
@@ -32,9 +33,13 @@ func CreateSample(samples, freq uint, depth Depth, chan_conf ChannelConf) *Sampl
 func LoadSample(filename string) (*Sample, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	s := C.al_load_sample(filename_)
-	if s == nil {
-		return nil, fmt.Errorf("failed to load sample '%s'", filename)
+	var s *C.ALLEGRO_SAMPLE
+	err := allegro.CheckErrno("al_load_sample", filename, func() bool {
+		s = C.al_load_sample(filename_)
+		return s != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*Sample)(s), nil
 }
@@ -45,10 +50,26 @@ func LoadSample(filename string) (*Sample, error) {
 func LoadSampleF(f *allegro.File, ident string) (*Sample, error) {
 	ident_ := C.CString(ident)
 	defer C.free_string(ident_)
-	if sample := C.al_load_sample_f((*C.ALLEGRO_FILE)(f), ident_); sample != nil {
-		return (*Sample)(sample), nil
+	var sample *C.ALLEGRO_SAMPLE
+	err := allegro.CheckErrno("al_load_sample_f", ident, func() bool {
+		sample = C.al_load_sample_f((*C.ALLEGRO_FILE)(f), ident_)
+		return sample != nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("failed to load sample from file")
+	return (*Sample)(sample), nil
+}
+
+// LoadSampleReader() loads a sample from r, e.g. data embedded with go:embed.
+// ident is as for LoadSampleF().
+func LoadSampleReader(r io.Reader, ident string) (*Sample, error) {
+	f, err := allegro.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadSampleF(f, ident)
 }
 
 // Writes a sample into a file. Currently, wav is the only supported format,
@@ -243,6 +264,22 @@ func (s *SampleInstance) Time() float32 {
 	return float32(C.al_get_sample_instance_time((*C.ALLEGRO_SAMPLE_INSTANCE)(s)))
 }
 
+// Return the sample data that the sample instance plays, or nil if it has
+// none.
+func (s *SampleInstance) Sample() *Sample {
+	return (*Sample)(C.al_get_sample((*C.ALLEGRO_SAMPLE_INSTANCE)(s)))
+}
+
+// Change the sample data that a sample instance plays. This can be quite an
+// involved process. The instance is stopped and its position and speed are
+// reset. Passing nil detaches the instance from whatever it is attached to.
+func (s *SampleInstance) SetSample(data *Sample) error {
+	if !bool(C.al_set_sample((*C.ALLEGRO_SAMPLE_INSTANCE)(s), (*C.ALLEGRO_SAMPLE)(data))) {
+		return errors.New("failed to set sample instance data")
+	}
+	return nil
+}
+
 // Detach the sample instance from whatever it's attached to, if anything.
 func (s *SampleInstance) Detach() error {
 	if !bool(C.al_detach_sample_instance((*C.ALLEGRO_SAMPLE_INSTANCE)(s))) {
@@ -6,6 +6,7 @@ package audio
 import "C"
 import (
 	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
 )
 
 // Install the audio subsystem.
@@ -22,6 +23,9 @@ func Uninstall() {
 	C.al_uninstall_audio()
 }
 
+// Addon installs the audio addon through allegro.Init().
+var Addon = allegro.Addon{Name: "audio", Install: Install, Uninstall: Uninstall, Installed: IsAudioInstalled}
+
 // Returns true if al_install_audio was called previously and returned
 // successfully.
 func IsAudioInstalled() bool {
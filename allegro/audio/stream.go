@@ -42,9 +42,13 @@ func CreateStream(fragment_count, frag_samples, freq uint, depth Depth, chan_con
 func LoadStream(filename string, buffer_count, samples uint) (*Stream, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	ptr := C.al_load_audio_stream(filename_, C.size_t(buffer_count), C.unsigned(samples))
-	if ptr == nil {
-		return nil, fmt.Errorf("failed to load audio stream at '%s'", filename)
+	var ptr *C.ALLEGRO_AUDIO_STREAM
+	err := allegro.CheckErrno("al_load_audio_stream", filename, func() bool {
+		ptr = C.al_load_audio_stream(filename_, C.size_t(buffer_count), C.unsigned(samples))
+		return ptr != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &Stream{ptr: ptr, buffer_size: 0}, nil
 }
@@ -53,13 +57,35 @@ func LoadStream(filename string, buffer_count, samples uint) (*Stream, error) {
 func LoadStreamF(f *allegro.File, ident string, buffer_count, samples uint) (*Stream, error) {
 	ident_ := C.CString(ident)
 	defer C.free_string(ident_)
-	ptr := C.al_load_audio_stream_f((*C.ALLEGRO_FILE)(f), ident_, C.size_t(buffer_count), C.unsigned(samples))
-	if ptr == nil {
-		return nil, errors.New("failed to load audio stream from file")
+	var ptr *C.ALLEGRO_AUDIO_STREAM
+	err := allegro.CheckErrno("al_load_audio_stream_f", ident, func() bool {
+		ptr = C.al_load_audio_stream_f((*C.ALLEGRO_FILE)(f), ident_, C.size_t(buffer_count), C.unsigned(samples))
+		return ptr != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return &Stream{ptr: ptr, buffer_size: 0}, nil
 }
 
+// LoadStreamReader() streams audio from r as it is needed, so that music
+// embedded in the binary doesn't have to be decoded up front. ident is as for
+// LoadStreamF(). The stream owns the file it reads r through, so r must stay
+// usable until the stream is destroyed; most codecs also need r to be an
+// io.Seeker to loop or rewind.
+func LoadStreamReader(r io.Reader, ident string, buffer_count, samples uint) (*Stream, error) {
+	f, err := allegro.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	s, err := LoadStreamF(f, ident, buffer_count, samples)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
 // TODO: generalize a "Sound" interface that supports audio stream, sample instance, etc.
 
 // Destroy an audio stream which was created with al_create_audio_stream or
@@ -0,0 +1,96 @@
+package allegro
+
+import (
+	"image"
+)
+
+// CopyBitmapRegion() copies the pixels of srcRect in src to dst with the top
+// left corner at dstX, dstY, replacing what was there; unlike drawing, no
+// blending, transform or clipping rectangle is applied. The rectangle is
+// clipped to both bitmaps.
+//
+// The fastest way to do this depends on where the bitmaps live. If both are
+// memory bitmaps, or src and dst are the same bitmap, the rows are copied
+// directly between locked regions, converting to dst's format where needed.
+// Otherwise dst is made the target and src drawn onto it, which keeps video
+// bitmaps on the GPU. Allegro's target, blender and transform are restored
+// afterwards.
+func CopyBitmapRegion(dst *Bitmap, dstX, dstY int, src *Bitmap, srcRect image.Rectangle) error {
+	if dst == nil || src == nil {
+		return BitmapIsNull
+	}
+	srcRect = srcRect.Intersect(image.Rect(0, 0, src.Width(), src.Height()))
+	dstRect := srcRect.Sub(srcRect.Min).Add(image.Pt(dstX, dstY))
+	dstRect = dstRect.Intersect(image.Rect(0, 0, dst.Width(), dst.Height()))
+	if dstRect.Empty() {
+		return nil
+	}
+	srcRect.Min = srcRect.Min.Add(dstRect.Min.Sub(image.Pt(dstX, dstY)))
+	srcRect.Max = srcRect.Min.Add(dstRect.Size())
+
+	if src == dst {
+		return copyWithinBitmap(dst, dstRect.Min, srcRect)
+	}
+	if src.Flags()&MEMORY_BITMAP != 0 && dst.Flags()&MEMORY_BITMAP != 0 {
+		return copyLocked(dst, dstRect.Min, src, srcRect)
+	}
+	copyDrawn(dst, dstRect.Min, src, srcRect)
+	return nil
+}
+
+func copyLocked(dst *Bitmap, at image.Point, src *Bitmap, r image.Rectangle) error {
+	format := dst.Format()
+	from, err := src.LockPixelsRegion(r.Min.X, r.Min.Y, r.Dx(), r.Dy(), format, LOCK_READONLY)
+	if err != nil {
+		return err
+	}
+	defer from.Unlock()
+	to, err := dst.LockPixelsRegion(at.X, at.Y, r.Dx(), r.Dy(), format, LOCK_WRITEONLY)
+	if err != nil {
+		return err
+	}
+	defer to.Unlock()
+	for y := 0; y < r.Dy(); y++ {
+		copy(to.Row(y), from.Row(y))
+	}
+	return nil
+}
+
+// copyWithinBitmap() locks the area covering both rectangles once and copies
+// rows in the direction that doesn't overwrite rows still to be read.
+func copyWithinBitmap(bmp *Bitmap, at image.Point, r image.Rectangle) error {
+	area := r.Union(r.Sub(r.Min).Add(at))
+	l, err := bmp.LockPixelsRegion(area.Min.X, area.Min.Y, area.Dx(), area.Dy(), bmp.Format(), LOCK_READWRITE)
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	size := l.PixelSize()
+	row := func(x, y int) []byte {
+		b := l.Row(y - area.Min.Y)
+		return b[(x-area.Min.X)*size : (x-area.Min.X+r.Dx())*size]
+	}
+	if at.Y <= r.Min.Y {
+		for y := 0; y < r.Dy(); y++ {
+			copy(row(at.X, at.Y+y), row(r.Min.X, r.Min.Y+y))
+		}
+	} else {
+		for y := r.Dy() - 1; y >= 0; y-- {
+			copy(row(at.X, at.Y+y), row(r.Min.X, r.Min.Y+y))
+		}
+	}
+	return nil
+}
+
+func copyDrawn(dst *Bitmap, at image.Point, src *Bitmap, r image.Rectangle) {
+	state := StoreState(STATE_TARGET_BITMAP | STATE_BLENDER | STATE_TRANSFORM)
+	defer RestoreState(state)
+	SetTargetBitmap(dst)
+	UseTransform(IdentityTransform())
+	SetBlender(ADD, ONE, ZERO)
+	cx, cy, cw, ch := ClippingRectangle()
+	ResetClippingRectangle()
+	src.DrawRegion(float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()),
+		float32(at.X), float32(at.Y), 0)
+	SetClippingRectangle(cx, cy, cw, ch)
+}
@@ -0,0 +1,197 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+//
+// extern ALLEGRO_BITMAP *go_bitmap_loader(char *filename, int flags);
+// extern ALLEGRO_BITMAP *go_bitmap_loader_f(ALLEGRO_FILE *fp, int flags);
+import "C"
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"sync"
+)
+
+// bitmapFormat is an image format registered with RegisterBitmapFormat().
+type bitmapFormat struct {
+	ext    string
+	magic  string
+	decode func(io.Reader) (image.Image, error)
+
+	// slot is the index of the identifier trampoline registered for ext.
+	slot int
+}
+
+var (
+	bitmapFormats     []bitmapFormat
+	bitmapFormatsLock sync.Mutex
+)
+
+// RegisterBitmapFormat() makes LoadBitmap(), File.LoadBitmap() and
+// IdentifyBitmap() handle files with the extension ext (including the leading
+// dot) by decoding them in Go, e.g. with image/gif or golang.org/x/image/webp:
+//
+//	allegro.RegisterBitmapFormat(".gif", "GIF8?a", gif.Decode)
+//
+// magic is the first bytes of the format's files, where "?" matches any byte.
+// IdentifyBitmap() checks each extension against its own magic only. Allegro
+// doesn't tell a loader which extension it was chosen for, so the loaders
+// pick the Go format by magic too. Registering an extension Allegro already
+// handles, such as the image addon's ".png", replaces Allegro's loader for it.
+// Up to maxBitmapFormats extensions can be registered.
+func RegisterBitmapFormat(ext, magic string, decode func(io.Reader) (image.Image, error)) error {
+	if magic == "" {
+		return errors.New("bitmap format needs magic bytes")
+	}
+
+	bitmapFormatsLock.Lock()
+	defer bitmapFormatsLock.Unlock()
+	slot := len(bitmapFormats)
+	index := -1
+	for i, f := range bitmapFormats {
+		if f.ext == ext {
+			slot, index = f.slot, i
+			break
+		}
+	}
+	if slot >= maxBitmapFormats {
+		return fmt.Errorf("failed to register bitmap format '%s': at most %d formats can be registered",
+			ext, maxBitmapFormats)
+	}
+
+	ext_ := C.CString(ext)
+	defer freeString(ext_)
+	if !bool(C.al_register_bitmap_loader(ext_, (*[0]byte)(C.go_bitmap_loader))) ||
+		!bool(C.al_register_bitmap_loader_f(ext_, (*[0]byte)(C.go_bitmap_loader_f))) ||
+		!bool(C.al_register_bitmap_identifier(ext_, bitmapIdentifier(slot))) {
+		return fmt.Errorf("failed to register bitmap format '%s'", ext)
+	}
+
+	format := bitmapFormat{ext, magic, decode, slot}
+	if index >= 0 {
+		bitmapFormats[index] = format
+	} else {
+		bitmapFormats = append(bitmapFormats, format)
+	}
+	return nil
+}
+
+// Tries to guess the bitmap file type of the given file by reading the first
+// few bytes. Returns the extension of the matching type, or "" if it is not
+// known.
+func IdentifyBitmap(filename string) string {
+	filename_ := C.CString(filename)
+	defer freeString(filename_)
+	return C.GoString(C.al_identify_bitmap(filename_))
+}
+
+// This works exactly as IdentifyBitmap() but works on an already open file.
+func IdentifyBitmapF(f *File) string {
+	return C.GoString(C.al_identify_bitmap_f((*C.ALLEGRO_FILE)(f)))
+}
+
+func (format *bitmapFormat) matches(header []byte) bool {
+	if len(header) < len(format.magic) {
+		return false
+	}
+	for i := 0; i < len(format.magic); i++ {
+		if format.magic[i] != '?' && format.magic[i] != header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sniffBitmapFormat() returns the registered format whose magic the file
+// starts with, and rewinds the file to where it was.
+func sniffBitmapFormat(f *File) (*bitmapFormat, error) {
+	bitmapFormatsLock.Lock()
+	defer bitmapFormatsLock.Unlock()
+
+	n := 0
+	for _, format := range bitmapFormats {
+		if len(format.magic) > n {
+			n = len(format.magic)
+		}
+	}
+	header, err := peekHeader(f, n)
+	if err != nil {
+		return nil, err
+	}
+	for i := range bitmapFormats {
+		if bitmapFormats[i].matches(header) {
+			format := bitmapFormats[i]
+			return &format, nil
+		}
+	}
+	return nil, errors.New("unknown bitmap format")
+}
+
+// peekHeader() reads up to n bytes from the file and rewinds it to where it
+// was.
+func peekHeader(f *File, n int) ([]byte, error) {
+	start, err := f.Tell()
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, n)
+	read, _ := io.ReadFull(f, header)
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return header[:read], nil
+}
+
+func loadGoBitmap(f *File) *C.ALLEGRO_BITMAP {
+	format, err := sniffBitmapFormat(f)
+	if err != nil {
+		return nil
+	}
+	img, err := format.decode(f)
+	if err != nil {
+		return nil
+	}
+	bmp, err := ImageToBitmap(img)
+	if err != nil {
+		return nil
+	}
+	return (*C.ALLEGRO_BITMAP)(bmp)
+}
+
+//export go_bitmap_loader
+func go_bitmap_loader(filename *C.char, flags C.int) *C.ALLEGRO_BITMAP {
+	mode := C.CString("rb")
+	defer freeString(mode)
+	fp := C.al_fopen(filename, mode)
+	if fp == nil {
+		return nil
+	}
+	defer C.al_fclose(fp)
+	return loadGoBitmap((*File)(fp))
+}
+
+//export go_bitmap_loader_f
+func go_bitmap_loader_f(fp *C.ALLEGRO_FILE, flags C.int) *C.ALLEGRO_BITMAP {
+	return loadGoBitmap((*File)(fp))
+}
+
+// identifyGoBitmap() is called through the identifier trampoline of the given
+// slot, and checks the file against that slot's format only.
+func identifyGoBitmap(f *File, slot int) bool {
+	bitmapFormatsLock.Lock()
+	defer bitmapFormatsLock.Unlock()
+	for i := range bitmapFormats {
+		if bitmapFormats[i].slot != slot {
+			continue
+		}
+		header, err := peekHeader(f, len(bitmapFormats[i].magic))
+		return err == nil && bitmapFormats[i].matches(header)
+	}
+	return false
+}
+
+//export go_bitmap_identifier
+func go_bitmap_identifier(fp *C.ALLEGRO_FILE, slot C.int) C.bool {
+	return C.bool(identifyGoBitmap((*File)(fp), int(slot)))
+}
@@ -0,0 +1,35 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+//
+// extern bool go_bitmap_identifier(ALLEGRO_FILE *f, int slot);
+//
+// // Allegro's identifiers aren't given any context, so each registered Go
+// // format gets a trampoline of its own that passes its slot along.
+// #define IDENTIFIER(n) \
+//     static bool identify_##n(ALLEGRO_FILE *f) { return go_bitmap_identifier(f, n); }
+// IDENTIFIER(0) IDENTIFIER(1) IDENTIFIER(2) IDENTIFIER(3)
+// IDENTIFIER(4) IDENTIFIER(5) IDENTIFIER(6) IDENTIFIER(7)
+// IDENTIFIER(8) IDENTIFIER(9) IDENTIFIER(10) IDENTIFIER(11)
+// IDENTIFIER(12) IDENTIFIER(13) IDENTIFIER(14) IDENTIFIER(15)
+//
+// typedef bool (*identifier)(ALLEGRO_FILE *f);
+//
+// static identifier bitmap_identifiers[] = {
+//     identify_0, identify_1, identify_2, identify_3,
+//     identify_4, identify_5, identify_6, identify_7,
+//     identify_8, identify_9, identify_10, identify_11,
+//     identify_12, identify_13, identify_14, identify_15,
+// };
+//
+// static identifier bitmap_identifier(int slot) {
+//     return bitmap_identifiers[slot];
+// }
+import "C"
+
+// The number of identifier trampolines, and so of Go bitmap formats.
+const maxBitmapFormats = 16
+
+func bitmapIdentifier(slot int) *[0]byte {
+	return (*[0]byte)(C.bitmap_identifier(C.int(slot)))
+}
@@ -0,0 +1,64 @@
+package allegro
+
+// BlendMode holds the complete blending state of a thread: the separate color
+// and alpha blenders plus the color used by CONST_COLOR and
+// INVERSE_CONST_COLOR. Its zero value is not a useful blender; start from one
+// of the Blend* presets or CurrentBlendMode().
+type BlendMode struct {
+	Op       BlendingOperation
+	Src      BlendingValue
+	Dst      BlendingValue
+	AlphaOp  BlendingOperation
+	AlphaSrc BlendingValue
+	AlphaDst BlendingValue
+	Color    Color
+}
+
+// Common blend modes. Allegro assumes premultiplied alpha by default, so
+// BlendAlpha is what's in effect unless it has been changed.
+var (
+	BlendAlpha    = NewBlendMode(ADD, ONE, INVERSE_ALPHA)
+	BlendAdditive = NewBlendMode(ADD, ONE, ONE)
+	BlendCopy     = NewBlendMode(ADD, ONE, ZERO)
+	BlendMultiply = NewBlendMode(ADD, DEST_COLOR, ZERO)
+
+	// For bitmaps loaded with NO_PREMULTIPLIED_ALPHA.
+	BlendNonPremultiplied = NewBlendMode(ADD, ALPHA, INVERSE_ALPHA)
+)
+
+// NewBlendMode() returns a BlendMode that blends the alpha channel the same
+// way as the color channels, with a white blend color.
+func NewBlendMode(op BlendingOperation, src, dst BlendingValue) BlendMode {
+	return BlendMode{
+		Op:       op,
+		Src:      src,
+		Dst:      dst,
+		AlphaOp:  op,
+		AlphaSrc: src,
+		AlphaDst: dst,
+		Color:    MapRGBAf(1, 1, 1, 1),
+	}
+}
+
+// CurrentBlendMode() returns the blending state of the calling thread.
+func CurrentBlendMode() BlendMode {
+	var m BlendMode
+	m.Op, m.Src, m.Dst, m.AlphaOp, m.AlphaSrc, m.AlphaDst = SeparateBlender()
+	m.Color = BlendColor()
+	return m
+}
+
+// Use() makes m the blending state of the calling thread.
+func (m BlendMode) Use() {
+	SetSeparateBlender(m.Op, m.Src, m.Dst, m.AlphaOp, m.AlphaSrc, m.AlphaDst)
+	SetBlendColor(m.Color)
+}
+
+// WithBlendMode() calls f with m in use, then restores the previous blending
+// state, even if f panics.
+func WithBlendMode(m BlendMode, f func()) {
+	prev := CurrentBlendMode()
+	m.Use()
+	defer prev.Use()
+	f()
+}
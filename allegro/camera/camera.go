@@ -0,0 +1,179 @@
+// Package camera provides a 2D camera that converts between world and screen
+// coordinates and builds the transform needed to draw the world from its
+// point of view.
+package camera
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"math"
+)
+
+// Camera looks at a point in the world. The point it looks at is drawn at the
+// center of the view.
+type Camera struct {
+	// X and Y are the world coordinates at the center of the view.
+	X, Y float32
+
+	// Zoom is the scale applied to the world. Values greater than 1 zoom in.
+	Zoom float32
+
+	// Rotation is the camera's rotation in radians. Rotating the camera
+	// clockwise makes the world appear to rotate counter-clockwise.
+	Rotation float32
+
+	// ViewWidth and ViewHeight are the size of the area being drawn to, in
+	// pixels; usually the size of the display or target bitmap.
+	ViewWidth, ViewHeight float32
+
+	// Smoothing controls how quickly the camera catches up with the target
+	// passed to Follow(). Larger values follow more tightly; 0 snaps
+	// straight to the target.
+	Smoothing float32
+
+	targetX, targetY float32
+	following        bool
+
+	bounded                bool
+	minX, minY, maxX, maxY float32
+}
+
+// New() creates a camera for a view of the given size, centered on the
+// origin with no zoom or rotation.
+func New(viewWidth, viewHeight float32) *Camera {
+	return &Camera{
+		Zoom:       1,
+		ViewWidth:  viewWidth,
+		ViewHeight: viewHeight,
+	}
+}
+
+// SetBounds() restricts the camera so that it never shows anything outside of
+// the given world rectangle. If the rectangle is smaller than the view, the
+// camera is centered on it.
+func (c *Camera) SetBounds(x, y, w, h float32) {
+	c.bounded = true
+	c.minX, c.minY = x, y
+	c.maxX, c.maxY = x+w, y+h
+	c.clamp()
+}
+
+// ClearBounds() removes any restriction placed by SetBounds().
+func (c *Camera) ClearBounds() {
+	c.bounded = false
+}
+
+// LookAt() moves the camera immediately to the given world position.
+func (c *Camera) LookAt(x, y float32) {
+	c.X, c.Y = x, y
+	c.targetX, c.targetY = x, y
+	c.clamp()
+}
+
+// Follow() sets the position that the camera should move towards during
+// Update().
+func (c *Camera) Follow(x, y float32) {
+	c.targetX, c.targetY = x, y
+	c.following = true
+}
+
+// Update() moves the camera towards its target, where dt is the time in
+// seconds since the last update.
+func (c *Camera) Update(dt float64) {
+	if c.following {
+		if c.Smoothing <= 0 {
+			c.X, c.Y = c.targetX, c.targetY
+		} else {
+			k := float32(1 - math.Exp(-float64(c.Smoothing)*dt))
+			c.X += (c.targetX - c.X) * k
+			c.Y += (c.targetY - c.Y) * k
+		}
+	}
+	c.clamp()
+}
+
+func (c *Camera) clamp() {
+	if !c.bounded {
+		return
+	}
+	hw, hh := c.halfExtents()
+	c.X = clampAxis(c.X, c.minX+hw, c.maxX-hw)
+	c.Y = clampAxis(c.Y, c.minY+hh, c.maxY-hh)
+}
+
+func clampAxis(v, lo, hi float32) float32 {
+	if lo > hi {
+		return (lo + hi) / 2
+	}
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// halfExtents() returns half the size of the axis-aligned world region that
+// the view covers, taking zoom and rotation into account.
+func (c *Camera) halfExtents() (float32, float32) {
+	zoom := c.zoom()
+	hw := float64(c.ViewWidth / (2 * zoom))
+	hh := float64(c.ViewHeight / (2 * zoom))
+	sin, cos := math.Sincos(float64(c.Rotation))
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	return float32(hw*cos + hh*sin), float32(hw*sin + hh*cos)
+}
+
+func (c *Camera) zoom() float32 {
+	if c.Zoom <= 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// Transform() returns the transform that maps world coordinates to screen
+// coordinates for the camera's current state.
+func (c *Camera) Transform() *allegro.Transform {
+	zoom := c.zoom()
+	t := allegro.IdentityTransform()
+	t.Translate(-c.X, -c.Y)
+	t.Rotate(-c.Rotation)
+	t.Scale(zoom, zoom)
+	t.Translate(c.ViewWidth/2, c.ViewHeight/2)
+	return t
+}
+
+// Use() makes the camera's transform the current transform of the target
+// bitmap.
+func (c *Camera) Use() {
+	allegro.UseTransform(c.Transform())
+}
+
+// WorldToScreen() converts a point in world coordinates to screen coordinates.
+func (c *Camera) WorldToScreen(x, y float32) (float32, float32) {
+	zoom := float64(c.zoom())
+	dx, dy := float64(x-c.X), float64(y-c.Y)
+	sin, cos := math.Sincos(-float64(c.Rotation))
+	sx := (dx*cos - dy*sin) * zoom
+	sy := (dx*sin + dy*cos) * zoom
+	return float32(sx) + c.ViewWidth/2, float32(sy) + c.ViewHeight/2
+}
+
+// ScreenToWorld() converts a point in screen coordinates, such as the mouse
+// position, to world coordinates.
+func (c *Camera) ScreenToWorld(x, y float32) (float32, float32) {
+	zoom := float64(c.zoom())
+	dx := float64(x-c.ViewWidth/2) / zoom
+	dy := float64(y-c.ViewHeight/2) / zoom
+	sin, cos := math.Sincos(float64(c.Rotation))
+	wx := dx*cos - dy*sin
+	wy := dx*sin + dy*cos
+	return float32(wx) + c.X, float32(wy) + c.Y
+}
+
+// VisibleRect() returns the smallest axis-aligned world rectangle that
+// contains everything the camera can see.
+func (c *Camera) VisibleRect() (x, y, w, h float32) {
+	hw, hh := c.halfExtents()
+	return c.X - hw, c.Y - hh, 2 * hw, 2 * hh
+}
@@ -0,0 +1,296 @@
+// Package capture records the contents of a display so that short gameplay
+// clips can be saved as animated GIF or APNG files.
+//
+// Frames are copied from the backbuffer into a small pool of memory bitmaps
+// on the calling thread, and then converted on a worker goroutine so that
+// recording has as little impact on the frame rate as possible. Only the most
+// recent frames are kept.
+package capture
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"image"
+	"io"
+	"os"
+	"sync"
+)
+
+// Format identifies the file format that a clip is saved in.
+type Format int
+
+const (
+	GIF Format = iota
+	APNG
+)
+
+// The number of memory bitmaps that frames are copied into while waiting to be
+// converted. If the worker falls this far behind, frames are dropped.
+const poolSize = 4
+
+var ErrNoFrames = errors.New("no frames have been captured")
+
+type frame struct {
+	img  *image.RGBA
+	time float64
+}
+
+type grab struct {
+	bmp  *allegro.Bitmap
+	time float64
+}
+
+// Recorder captures frames from a display.
+type Recorder struct {
+	display *allegro.Display
+	every   int
+	count   int
+
+	free    chan *allegro.Bitmap
+	pending chan grab
+	created int
+	done    chan struct{}
+
+	mu     sync.Mutex
+	frames []frame // ring buffer of converted frames
+	next   int
+	full   bool
+	drops  int
+}
+
+// NewRecorder() creates a recorder that keeps the most recent maxFrames
+// frames, grabbing one frame from the display every time Frame() has been
+// called the given number of times.
+func NewRecorder(d *allegro.Display, every, maxFrames int) *Recorder {
+	if every < 1 {
+		every = 1
+	}
+	r := Recorder{
+		display: d,
+		every:   every,
+		free:    make(chan *allegro.Bitmap, poolSize),
+		pending: make(chan grab, poolSize),
+		done:    make(chan struct{}),
+		frames:  make([]frame, maxFrames),
+	}
+	go r.work()
+	return &r
+}
+
+// Frame() should be called once per frame, after everything has been drawn
+// and before the display is flipped. It must be called from the thread that
+// owns the display.
+func (r *Recorder) Frame() {
+	r.count++
+	if r.count < r.every {
+		return
+	}
+	r.count = 0
+
+	bb := r.display.Backbuffer()
+	w, h := bb.Width(), bb.Height()
+
+	var bmp *allegro.Bitmap
+	select {
+	case bmp = <-r.free:
+		if bmp.Width() != w || bmp.Height() != h {
+			bmp.Destroy()
+			r.created--
+			bmp = r.newBitmap(w, h)
+		}
+	default:
+		if r.created < poolSize {
+			bmp = r.newBitmap(w, h)
+		}
+	}
+	if bmp == nil {
+		r.mu.Lock()
+		r.drops++
+		r.mu.Unlock()
+		return
+	}
+
+	if err := copyPixels(bmp, bb); err != nil {
+		r.free <- bmp
+		return
+	}
+	r.pending <- grab{bmp, allegro.Time()}
+}
+
+// newBitmap() creates a memory bitmap for the pool, or returns nil if that
+// fails, e.g. because the backbuffer is too large.
+func (r *Recorder) newBitmap(w, h int) *allegro.Bitmap {
+	bmp := newMemoryBitmap(w, h)
+	if bmp != nil {
+		r.created++
+	}
+	return bmp
+}
+
+// Dropped() returns the number of frames that were skipped because the worker
+// could not keep up, or because no bitmap could be created to copy them into.
+func (r *Recorder) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.drops
+}
+
+// Reset() discards all of the frames captured so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.frames {
+		r.frames[i] = frame{}
+	}
+	r.next, r.full, r.drops = 0, false, 0
+}
+
+// Destroy() stops the worker goroutine and frees the recorder's bitmaps. It
+// must be called from the thread that owns the display.
+func (r *Recorder) Destroy() {
+	close(r.pending)
+	<-r.done
+	close(r.free)
+	for bmp := range r.free {
+		bmp.Destroy()
+	}
+}
+
+func newMemoryBitmap(w, h int) *allegro.Bitmap {
+	flags := allegro.NewBitmapFlags()
+	defer allegro.SetNewBitmapFlags(flags)
+	allegro.SetNewBitmapFlags(allegro.MEMORY_BITMAP)
+	return allegro.CreateBitmap(w, h)
+}
+
+func copyPixels(dst, src *allegro.Bitmap) error {
+	const format = allegro.PIXEL_FORMAT_ABGR_8888_LE
+	sl, err := src.LockPixels(format, allegro.LOCK_READONLY)
+	if err != nil {
+		return err
+	}
+	defer sl.Unlock()
+	dl, err := dst.LockPixels(format, allegro.LOCK_WRITEONLY)
+	if err != nil {
+		return err
+	}
+	defer dl.Unlock()
+
+	for y := 0; y < sl.Height; y++ {
+		copy(dl.Row(y), sl.Row(y))
+	}
+	return nil
+}
+
+func (r *Recorder) work() {
+	for g := range r.pending {
+		img, err := toRGBA(g.bmp)
+		r.free <- g.bmp
+		if err != nil {
+			continue
+		}
+		r.mu.Lock()
+		if len(r.frames) > 0 {
+			r.frames[r.next] = frame{img, g.time}
+			r.next = (r.next + 1) % len(r.frames)
+			if r.next == 0 {
+				r.full = true
+			}
+		}
+		r.mu.Unlock()
+	}
+	close(r.done)
+}
+
+func toRGBA(bmp *allegro.Bitmap) (*image.RGBA, error) {
+	l, err := bmp.LockPixels(allegro.PIXEL_FORMAT_ABGR_8888_LE, allegro.LOCK_READONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Unlock()
+
+	img := image.NewRGBA(image.Rect(0, 0, l.Width, l.Height))
+	for y := 0; y < l.Height; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+l.Width*4]
+		copy(row, l.Row(y))
+		// The backbuffer's alpha channel is meaningless once displayed.
+		for i := 3; i < len(row); i += 4 {
+			row[i] = 0xFF
+		}
+	}
+	return img, nil
+}
+
+// snapshot() returns the captured frames in order, oldest first.
+func (r *Recorder) snapshot() []frame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return append(append([]frame(nil), r.frames[r.next:]...), r.frames[:r.next]...)
+	}
+	return append([]frame(nil), r.frames[:r.next]...)
+}
+
+// delays() returns the time each frame is shown for, in hundredths of a
+// second.
+func delays(frames []frame) []int {
+	d := make([]int, len(frames))
+	for i := range frames {
+		if i+1 < len(frames) {
+			d[i] = int((frames[i+1].time-frames[i].time)*100 + 0.5)
+		} else if i > 0 {
+			d[i] = d[i-1]
+		}
+		if d[i] < 2 {
+			// Most viewers treat delays below 2 as 10.
+			d[i] = 2
+		}
+	}
+	return d
+}
+
+// Save() encodes the frames captured so far. Recording can continue while a
+// clip is being saved, and Save() may be called from any goroutine.
+func (r *Recorder) Save(w io.Writer, format Format) error {
+	return encode(w, r.snapshot(), format)
+}
+
+func encode(w io.Writer, frames []frame, format Format) error {
+	if len(frames) == 0 {
+		return ErrNoFrames
+	}
+	switch format {
+	case GIF:
+		return encodeGIF(w, frames)
+	case APNG:
+		return encodeAPNG(w, frames)
+	}
+	return errUnknownFormat
+}
+
+var errUnknownFormat = errors.New("unknown capture format")
+
+// SaveFile() saves the frames captured so far to a file in the background.
+// The returned channel receives the result once encoding has finished. An
+// unknown format is reported at once, without creating the file.
+func (r *Recorder) SaveFile(filename string, format Format) <-chan error {
+	frames := r.snapshot()
+	result := make(chan error, 1)
+	if format != GIF && format != APNG {
+		result <- errUnknownFormat
+		return result
+	}
+	go func() {
+		f, err := os.Create(filename)
+		if err != nil {
+			result <- err
+			return
+		}
+		err = encode(f, frames, format)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		result <- err
+	}()
+	return result
+}
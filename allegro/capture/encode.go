@@ -0,0 +1,148 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+)
+
+func encodeGIF(w io.Writer, frames []frame) error {
+	d := delays(frames)
+	anim := gif.GIF{
+		Image: make([]*image.Paletted, len(frames)),
+		Delay: d,
+	}
+	for i, f := range frames {
+		p := image.NewPaletted(f.img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(p, p.Bounds(), f.img, image.ZP)
+		anim.Image[i] = p
+	}
+	return gif.EncodeAll(w, &anim)
+}
+
+// APNG is ordinary PNG with a few extra chunks, so each frame is encoded with
+// image/png and its image data is moved into the animation.
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type chunk struct {
+	typ  string
+	data []byte
+}
+
+func readChunks(b []byte) ([]chunk, error) {
+	if !bytes.HasPrefix(b, pngSignature) {
+		return nil, errors.New("invalid png signature")
+	}
+	b = b[len(pngSignature):]
+	var chunks []chunk
+	for len(b) >= 12 {
+		n := int(binary.BigEndian.Uint32(b))
+		if len(b) < 12+n {
+			return nil, errors.New("truncated png chunk")
+		}
+		chunks = append(chunks, chunk{string(b[4:8]), b[8 : 8+n]})
+		b = b[12+n:]
+	}
+	return chunks, nil
+}
+
+func writeChunk(w io.Writer, typ string, data []byte) error {
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(data)))
+	copy(hdr[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(hdr[4:])
+	crc.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	for _, b := range [][]byte{hdr[:], data, sum[:]} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeAPNG() fails if the frames differ in size, e.g. because the display
+// was resized while recording, as every frame of an APNG must fit within the
+// size given by the first one's IHDR.
+func encodeAPNG(w io.Writer, frames []frame) error {
+	size := frames[0].img.Bounds().Size()
+	for i, f := range frames {
+		if s := f.img.Bounds().Size(); s != size {
+			return fmt.Errorf("frame %d is %dx%d, but the clip is %dx%d", i, s.X, s.Y, size.X, size.Y)
+		}
+	}
+	d := delays(frames)
+	seq := uint32(0)
+
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+	for i, f := range frames {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, f.img); err != nil {
+			return err
+		}
+		chunks, err := readChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if i == 0 {
+			for _, c := range chunks {
+				if c.typ == "IHDR" {
+					if err := writeChunk(w, "IHDR", c.data); err != nil {
+						return err
+					}
+				}
+			}
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+			binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+			if err := writeChunk(w, "acTL", actl); err != nil {
+				return err
+			}
+		}
+
+		b := f.img.Bounds()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(d[i]))
+		binary.BigEndian.PutUint16(fctl[22:], 100)
+		seq++
+		if err := writeChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+
+		for _, c := range chunks {
+			if c.typ != "IDAT" {
+				continue
+			}
+			if i == 0 {
+				err = writeChunk(w, "IDAT", c.data)
+			} else {
+				fdat := make([]byte, 4+len(c.data))
+				binary.BigEndian.PutUint32(fdat, seq)
+				copy(fdat[4:], c.data)
+				seq++
+				err = writeChunk(w, "fdAT", fdat)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return writeChunk(w, "IEND", nil)
+}
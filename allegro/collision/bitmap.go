@@ -0,0 +1,28 @@
+package collision
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// MaskFromBitmap() builds a mask from a bitmap's alpha channel. Pixels with
+// an alpha value greater than threshold (0-255) are solid. This reads every
+// pixel of the bitmap, so it should be done once when the bitmap is loaded
+// rather than every frame.
+func MaskFromBitmap(bmp *allegro.Bitmap, threshold byte) (*Mask, error) {
+	l, err := bmp.LockPixels(allegro.PIXEL_FORMAT_ABGR_8888_LE, allegro.LOCK_READONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Unlock()
+
+	m := NewMask(l.Width, l.Height)
+	for y := 0; y < l.Height; y++ {
+		row := l.Row(y)
+		for x := 0; x < l.Width; x++ {
+			if row[x*4+3] > threshold {
+				m.Set(x, y, true)
+			}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,120 @@
+// Package collision provides helpers for detecting overlap between game
+// objects.
+package collision
+
+// Mask is a 1-bit image used for pixel-accurate collision tests. Each row is
+// stored as a sequence of 64-bit words so that masks can be compared a word at
+// a time.
+type Mask struct {
+	Width, Height int
+
+	stride int // words per row
+	bits   []uint64
+}
+
+// NewMask() creates an empty mask of the given size.
+func NewMask(w, h int) *Mask {
+	stride := (w + 63) / 64
+	return &Mask{
+		Width:  w,
+		Height: h,
+		stride: stride,
+		bits:   make([]uint64, stride*h),
+	}
+}
+
+// Set() marks or clears the pixel at x, y. Pixels outside of the mask are
+// ignored.
+func (m *Mask) Set(x, y int, solid bool) {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return
+	}
+	i := y*m.stride + x/64
+	if solid {
+		m.bits[i] |= 1 << uint(x%64)
+	} else {
+		m.bits[i] &^= 1 << uint(x%64)
+	}
+}
+
+// Get() returns true if the pixel at x, y is solid. Pixels outside of the mask
+// are never solid.
+func (m *Mask) Get(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return false
+	}
+	return m.bits[y*m.stride+x/64]&(1<<uint(x%64)) != 0
+}
+
+// Count() returns the number of solid pixels in the mask.
+func (m *Mask) Count() int {
+	n := 0
+	for _, w := range m.bits {
+		for ; w != 0; w &= w - 1 {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *Mask) row(y int) []uint64 {
+	return m.bits[y*m.stride : (y+1)*m.stride]
+}
+
+// bitsAt() returns the 64 bits of a row starting at bit pos. Bits outside of
+// the row are zero.
+func bitsAt(row []uint64, pos int) uint64 {
+	if pos <= -64 || pos >= len(row)*64 {
+		return 0
+	}
+	w := pos >> 6 // rounds towards negative infinity
+	off := uint(pos & 63)
+
+	var lo, hi uint64
+	if w >= 0 {
+		lo = row[w]
+	}
+	if w+1 < len(row) {
+		hi = row[w+1]
+	}
+	if off == 0 {
+		return lo
+	}
+	return lo>>off | hi<<(64-off)
+}
+
+// Overlaps() returns true if any solid pixel of m, placed at ax, ay, coincides
+// with a solid pixel of other, placed at bx, by.
+func (m *Mask) Overlaps(ax, ay int, other *Mask, bx, by int) bool {
+	// The intersection of the two masks, relative to m.
+	x0, y0 := maxInt(0, bx-ax), maxInt(0, by-ay)
+	x1, y1 := minInt(m.Width, bx-ax+other.Width), minInt(m.Height, by-ay+other.Height)
+	if x0 >= x1 || y0 >= y1 {
+		return false
+	}
+
+	dx, dy := bx-ax, by-ay
+	for y := y0; y < y1; y++ {
+		arow, brow := m.row(y), other.row(y-dy)
+		for i := x0 / 64; i <= (x1-1)/64; i++ {
+			if arow[i]&bitsAt(brow, i*64-dx) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,31 @@
+package collision
+
+import (
+	"testing"
+)
+
+func TestOverlaps(t *testing.T) {
+	a := NewMask(100, 3)
+	a.Set(70, 1, true)
+	b := NewMask(10, 10)
+	b.Set(5, 5, true)
+
+	cases := []struct {
+		bx, by int
+		want   bool
+	}{
+		{65, -4, true},
+		{66, -4, false},
+		{65, -3, false},
+		{-10, -10, false},
+		{200, 0, false},
+	}
+	for _, c := range cases {
+		if got := a.Overlaps(0, 0, b, c.bx, c.by); got != c.want {
+			t.Errorf("Overlaps at %d,%d: got %v, want %v", c.bx, c.by, got, c.want)
+		}
+		if got := b.Overlaps(c.bx, c.by, a, 0, 0); got != c.want {
+			t.Errorf("reverse Overlaps at %d,%d: got %v, want %v", c.bx, c.by, got, c.want)
+		}
+	}
+}
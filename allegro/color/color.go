@@ -74,6 +74,16 @@ func HslToRgb(hue, saturation, lightness float32) (red, green, blue float32) {
 	return float32(cred), float32(cgreen), float32(cblue)
 }
 
+// Return an ALLEGRO_COLOR structure from HSV (hue, saturation, value)
+// values.
+func Hsv(hue, saturation, value float32) allegro.Color {
+	col := C.al_color_hsv(
+		C.float(hue),
+		C.float(saturation),
+		C.float(value))
+	return al(col)
+}
+
 // Convert values in HSV color model to RGB color model.
 func HsvToRgb(hue, saturation, value float32) (red, green, blue float32) {
 	var cred, cgreen, cblue C.float
@@ -87,6 +97,14 @@ func HsvToRgb(hue, saturation, value float32) (red, green, blue float32) {
 	return float32(cred), float32(cgreen), float32(cblue)
 }
 
+// Interprets an HTML styled hex number (e.g. #00faff) as a color. Components
+// that are malformed are set to 0.
+func Html(str string) allegro.Color {
+	str_ := C.CString(str)
+	defer C.free_string(str_)
+	return al(C.al_color_html(str_))
+}
+
 // Interprets an HTML styled hex number (e.g. #00faff) as a color. Components
 // that are malformed are set to 0.
 func HtmlToRgb(str string) (red, green, blue float32) {
@@ -146,16 +164,13 @@ func RgbToHsv(red, green, blue float32) (hue, saturation, value float32) {
 
 // Create an HTML-style string representation of an ALLEGRO_COLOR, e.g. #00faff.
 func RgbToHtml(red, green, blue float32) string {
-	var cstr *C.char
+	var buf [8]C.char
 	C.al_color_rgb_to_html(
 		C.float(red),
 		C.float(green),
 		C.float(blue),
-		cstr)
-	if cstr != nil {
-		return C.GoString(cstr)
-	}
-	return ""
+		&buf[0])
+	return C.GoString(&buf[0])
 }
 
 // Convert RGB values to YUV color space.
@@ -171,6 +186,16 @@ func RgbToYuv(red, green, blue float32) (y, u, v float32) {
 	return float32(cy), float32(cu), float32(cv)
 }
 
+// Named() returns the color with the given name, or an error if Allegro
+// doesn't know the name. al_color_name itself returns black in that case.
+func Named(name Name) (allegro.Color, error) {
+	red, green, blue, err := NameToRgb(name)
+	if err != nil {
+		return allegro.Color{}, err
+	}
+	return allegro.MapRGBf(red, green, blue), nil
+}
+
 // Parameters:
 func NameToRgb(name Name) (red, green, blue float32, err error) {
 	name_ := C.CString(string(name))
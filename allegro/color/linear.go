@@ -0,0 +1,58 @@
+package color
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_color.h>
+import "C"
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+// Colors in Allegro are sRGB, which is gamma encoded. Blending, lighting and
+// gradients look more natural when done in linear RGB and converted back
+// afterwards.
+
+// Return an ALLEGRO_COLOR structure from linear sRGB values, i.e. values that
+// have not been gamma corrected.
+func Linear(red, green, blue float32) allegro.Color {
+	col := C.al_color_linear(
+		C.float(red),
+		C.float(green),
+		C.float(blue))
+	return al(col)
+}
+
+// Convert linear sRGB color values to gamma corrected (RGB) values.
+func LinearToRgb(red, green, blue float32) (r, g, b float32) {
+	var cr, cg, cb C.float
+	C.al_color_linear_to_rgb(
+		C.float(red),
+		C.float(green),
+		C.float(blue),
+		&cr,
+		&cg,
+		&cb)
+	return float32(cr), float32(cg), float32(cb)
+}
+
+// Convert gamma corrected (RGB) color values to linear sRGB values.
+func RgbToLinear(red, green, blue float32) (r, g, b float32) {
+	var cr, cg, cb C.float
+	C.al_color_rgb_to_linear(
+		C.float(red),
+		C.float(green),
+		C.float(blue),
+		&cr,
+		&cg,
+		&cb)
+	return float32(cr), float32(cg), float32(cb)
+}
+
+// Checks if all components of the color are between 0 and 1. Some of the
+// color conversions in this addon support color spaces with more colors than
+// can be represented in sRGB and when converted to RGB will result in invalid
+// color components outside the 0..1 range.
+func IsColorValid(col allegro.Color) bool {
+	return bool(C.al_is_color_valid(*(*C.ALLEGRO_COLOR)(unsafe.Pointer(&col))))
+}
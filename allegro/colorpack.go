@@ -0,0 +1,76 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// ALLEGRO_COLOR is a plain struct of four floats, so colors can be built and
+// taken apart in Go without a cgo call. The layout is checked once at start
+// up; if it isn't what we expect, the Map and Unmap functions keep calling
+// into Allegro.
+var fastColor = checkColorLayout()
+
+type colorFloats struct {
+	r, g, b, a float32
+}
+
+func checkColorLayout() bool {
+	if unsafe.Sizeof(Color{}) != unsafe.Sizeof(colorFloats{}) {
+		return false
+	}
+	c := Color(C.al_map_rgba_f(0.125, 0.25, 0.5, 0.75))
+	f := (*colorFloats)(unsafe.Pointer(&c))
+	return *f == colorFloats{0.125, 0.25, 0.5, 0.75}
+}
+
+func packColor(r, g, b, a float32) Color {
+	f := colorFloats{r, g, b, a}
+	return *(*Color)(unsafe.Pointer(&f))
+}
+
+func unpackColor(c Color) (float32, float32, float32, float32) {
+	f := (*colorFloats)(unsafe.Pointer(&c))
+	return f.r, f.g, f.b, f.a
+}
+
+// Converts a float component to a byte the same way al_unmap_rgba does, by
+// truncation.
+func unitToByte(v float32) byte {
+	return byte(v * 255)
+}
+
+// Unmap() returns the components of the color, ranging from 0-255. It is
+// shorthand for UnmapRGBA().
+func (c Color) Unmap() (r, g, b, a byte) {
+	return c.UnmapRGBA()
+}
+
+// UnmapF() returns the components of the color, ranging from 0.0f-1.0f. It is
+// shorthand for UnmapRGBAf().
+func (c Color) UnmapF() (r, g, b, a float32) {
+	return c.UnmapRGBAf()
+}
+
+// Lerp() returns the color a fraction t of the way from c to d, interpolating
+// each component, alpha included. t is not clamped.
+func (c Color) Lerp(d Color, t float32) Color {
+	r1, g1, b1, a1 := c.UnmapRGBAf()
+	r2, g2, b2, a2 := d.UnmapRGBAf()
+	return MapRGBAf(r1+(r2-r1)*t, g1+(g2-g1)*t, b1+(b2-b1)*t, a1+(a2-a1)*t)
+}
+
+// Scale() multiplies every component of the color, alpha included, by f.
+// With premultiplied alpha this fades the color out as f goes to 0.
+func (c Color) Scale(f float32) Color {
+	r, g, b, a := c.UnmapRGBAf()
+	return MapRGBAf(r*f, g*f, b*f, a*f)
+}
+
+// Premultiply() multiplies the color's red, green and blue by its alpha, as
+// Allegro's default blender expects.
+func (c Color) Premultiply() Color {
+	r, g, b, a := c.UnmapRGBAf()
+	return MapRGBAf(r*a, g*a, b*a, a)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unsafe"
 )
 
 type Config C.ALLEGRO_CONFIG
@@ -16,7 +17,7 @@ type ConfigEntryIterator (*C.ALLEGRO_CONFIG_ENTRY)
 // Create an empty configuration structure.
 func CreateConfig() *Config {
 	config := (*Config)(C.al_create_config())
-	//runtime.SetFinalizer(config, config.Destroy)
+	trackResource(unsafe.Pointer(config), "config")
 	return config
 }
 
@@ -25,10 +26,15 @@ func CreateConfig() *Config {
 func LoadConfig(filename string) (*Config, error) {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
-	cfg := C.al_load_config_file(filename_)
-	if cfg == nil {
-		return nil, fmt.Errorf("failed to load config file '%s'", filename)
+	var cfg *C.ALLEGRO_CONFIG
+	err := checkErrno("al_load_config_file", filename, func() bool {
+		cfg = C.al_load_config_file(filename_)
+		return cfg != nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	trackResource(unsafe.Pointer(cfg), "config")
 	return (*Config)(cfg), nil
 }
 
@@ -37,25 +43,30 @@ func LoadConfig(filename string) (*Config, error) {
 // Neither of the input configuration structures are modified. Comments from
 // 'cfg2' are not retained.
 func MergeConfig(cfg1, cfg2 *Config) *Config {
-	return (*Config)(C.al_merge_config((*C.ALLEGRO_CONFIG)(cfg1), (*C.ALLEGRO_CONFIG)(cfg2)))
+	cfg := C.al_merge_config((*C.ALLEGRO_CONFIG)(cfg1), (*C.ALLEGRO_CONFIG)(cfg2))
+	trackResource(unsafe.Pointer(cfg), "config")
+	return (*Config)(cfg)
 }
 
 // Read a configuration file from an already open file.
 func (f *File) LoadConfig() (*Config, error) {
-	cfg := C.al_load_config_file_f((*C.ALLEGRO_FILE)(f))
-	if cfg == nil {
-		return nil, errors.New("failed to load config from file")
+	var cfg *C.ALLEGRO_CONFIG
+	err := checkErrno("al_load_config_file_f", "", func() bool {
+		cfg = C.al_load_config_file_f((*C.ALLEGRO_FILE)(f))
+		return cfg != nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	trackResource(unsafe.Pointer(cfg), "config")
 	return (*Config)(cfg), nil
 }
 
 // Write out a configuration file to an already open file.
 func (f *File) SaveConfig(cfg *Config) error {
-	ok := bool(C.al_save_config_file_f((*C.ALLEGRO_FILE)(f), (*C.ALLEGRO_CONFIG)(cfg)))
-	if !ok {
-		return errors.New("failed to save config from file")
-	}
-	return nil
+	return checkErrno("al_save_config_file_f", "", func() bool {
+		return bool(C.al_save_config_file_f((*C.ALLEGRO_FILE)(f), (*C.ALLEGRO_CONFIG)(cfg)))
+	})
 }
 
 // Config Instance Methods {{{
@@ -108,16 +119,38 @@ func (cfg *Config) AddComment(section, comment string) {
 	C.al_add_config_comment((*C.ALLEGRO_CONFIG)(cfg), section_, comment_)
 }
 
+// Remove a section of a configuration. Returns an error if the section did
+// not exist.
+func (cfg *Config) RemoveSection(section string) error {
+	section_ := C.CString(section)
+	defer freeString(section_)
+	if !bool(C.al_remove_config_section((*C.ALLEGRO_CONFIG)(cfg), section_)) {
+		return fmt.Errorf("config section '%s' not found", section)
+	}
+	return nil
+}
+
+// Remove a key and its associated value in a section of a configuration.
+// Returns an error if the entry did not exist.
+func (cfg *Config) RemoveKey(section, key string) error {
+	section_ := C.CString(section)
+	key_ := C.CString(key)
+	defer freeString(section_)
+	defer freeString(key_)
+	if !bool(C.al_remove_config_key((*C.ALLEGRO_CONFIG)(cfg), section_, key_)) {
+		return fmt.Errorf("config value '%s.%s' not found", section, key)
+	}
+	return nil
+}
+
 // Write out a configuration file to disk. Returns true on success, false on
 // error.
 func (cfg *Config) Save(filename string) error {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
-	ok := bool(C.al_save_config_file(filename_, (*C.ALLEGRO_CONFIG)(cfg)))
-	if !ok {
-		return fmt.Errorf("failed to save config file to '%s'", filename)
-	}
-	return nil
+	return checkErrno("al_save_config_file", filename, func() bool {
+		return bool(C.al_save_config_file(filename_, (*C.ALLEGRO_CONFIG)(cfg)))
+	})
 }
 
 // Merge one configuration structure into another. Values in configuration
@@ -130,6 +163,12 @@ func (cfg *Config) Merge(add *Config) {
 // Free the resources used by a configuration structure. Does nothing if passed
 // NULL.
 func (cfg *Config) Destroy() {
+	if releaseResource(unsafe.Pointer(cfg), "config") == nil {
+		cfg.destroy()
+	}
+}
+
+func (cfg *Config) destroy() {
 	C.al_destroy_config((*C.ALLEGRO_CONFIG)(cfg))
 }
 
@@ -0,0 +1,72 @@
+package allegro
+
+import (
+	"encoding/json"
+)
+
+// ConfigMap is a Go representation of a config, mapping section names to the
+// keys and values in that section. The global section is named "".
+type ConfigMap map[string]map[string]string
+
+// Map() copies every section and entry of the config into a ConfigMap.
+// Comments are not included. Sections without entries are kept as empty maps
+// so that the section structure survives a round trip.
+func (cfg *Config) Map() ConfigMap {
+	m := make(ConfigMap)
+	section, siter := cfg.FirstConfigSection()
+	for {
+		entries := make(map[string]string)
+		key, eiter, err := cfg.FirstConfigEntry(section)
+		for err == nil {
+			if value, verr := cfg.Value(section, key); verr == nil {
+				entries[key] = value
+			}
+			key, err = cfg.NextConfigEntry(eiter)
+		}
+		if section != "" || len(entries) > 0 {
+			m[section] = entries
+		}
+		section, err = cfg.NextConfigSection(siter)
+		if err != nil {
+			break
+		}
+	}
+	return m
+}
+
+// ConfigFromMap() creates a new config holding the sections and entries of m.
+func ConfigFromMap(m ConfigMap) *Config {
+	cfg := CreateConfig()
+	cfg.SetMap(m)
+	return cfg
+}
+
+// SetMap() adds the sections and entries of m to the config, overwriting
+// existing values with the same keys.
+func (cfg *Config) SetMap(m ConfigMap) {
+	for section, entries := range m {
+		if section != "" {
+			cfg.AddSection(section)
+		}
+		for key, value := range entries {
+			cfg.SetValue(section, key, value)
+		}
+	}
+}
+
+// MarshalJSON() encodes the config as a JSON object of sections, each of which
+// is an object of string values.
+func (cfg *Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cfg.Map())
+}
+
+// ConfigFromJSON() creates a new config from a JSON object in the format
+// written by MarshalJSON(). Config has no UnmarshalJSON() method, since it is
+// Allegro's own structure and can't be allocated by encoding/json.
+func ConfigFromJSON(data []byte) (*Config, error) {
+	var m ConfigMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return ConfigFromMap(m), nil
+}
@@ -0,0 +1,127 @@
+package debug
+
+import (
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/font"
+	"github.com/ccollins476ad/go-allegro/allegro/primitives"
+	"sort"
+	"strings"
+)
+
+// CommandFunc is called when a console command is run. The arguments are the
+// whitespace-separated words following the command name. Anything returned is
+// written to the log.
+type CommandFunc func(args []string) string
+
+type command struct {
+	help string
+	fn   CommandFunc
+}
+
+type console struct {
+	overlay  *Overlay
+	open     bool
+	input    []rune
+	history  []string
+	hist     int
+	commands map[string]command
+}
+
+func (c *console) init(o *Overlay) {
+	c.overlay = o
+	c.commands = map[string]command{
+		"help": {"lists the available commands", c.help},
+		"clear": {"clears the log", func([]string) string {
+			o.lines = o.lines[:0]
+			return ""
+		}},
+	}
+}
+
+// Register() adds a command to the console, replacing any existing command
+// with the same name.
+func (o *Overlay) Register(name, help string, fn CommandFunc) {
+	o.console.commands[name] = command{help, fn}
+}
+
+// ConsoleOpen() returns true if the console is currently accepting input.
+func (o *Overlay) ConsoleOpen() bool {
+	return o.console.open
+}
+
+// Exec() runs a line of input as if it had been typed into the console.
+func (o *Overlay) Exec(line string) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return
+	}
+	o.Log("> " + line)
+	cmd, ok := o.console.commands[words[0]]
+	if !ok {
+		o.Logf("unknown command '%s'", words[0])
+		return
+	}
+	if out := cmd.fn(words[1:]); out != "" {
+		for _, l := range strings.Split(out, "\n") {
+			o.Log(l)
+		}
+	}
+}
+
+func (c *console) help([]string) string {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("%s - %s", name, c.commands[name].help)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *console) key(code allegro.KeyCode, ch int) {
+	switch code {
+	case allegro.KEY_ENTER:
+		line := string(c.input)
+		c.input = c.input[:0]
+		if line != "" {
+			c.history = append(c.history, line)
+		}
+		c.hist = len(c.history)
+		c.overlay.Exec(line)
+	case allegro.KEY_BACKSPACE:
+		if len(c.input) > 0 {
+			c.input = c.input[:len(c.input)-1]
+		}
+	case allegro.KEY_ESCAPE:
+		c.open = false
+	case allegro.KEY_UP:
+		if c.hist > 0 {
+			c.hist--
+			c.input = []rune(c.history[c.hist])
+		}
+	case allegro.KEY_DOWN:
+		if c.hist < len(c.history)-1 {
+			c.hist++
+			c.input = []rune(c.history[c.hist])
+		} else {
+			c.hist = len(c.history)
+			c.input = c.input[:0]
+		}
+	default:
+		if ch >= ' ' && ch != 127 {
+			c.input = append(c.input, rune(ch))
+		}
+	}
+}
+
+func (c *console) draw(w, y float32) {
+	f := c.overlay.font
+	lh := float32(f.LineHeight())
+	primitives.DrawFilledRectangle(primitives.Point{X: 0, Y: y},
+		primitives.Point{X: w, Y: y + lh + 4}, allegro.MapRGBAf(0, 0, 0.2, 0.8))
+	font.DrawText(f, allegro.MapRGB(255, 255, 0), 4, y+2, font.ALIGN_LEFT, "> "+string(c.input)+"_")
+}
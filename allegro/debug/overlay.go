@@ -0,0 +1,210 @@
+// Package debug provides an on-screen overlay showing frame statistics and a
+// scrolling log, along with a minimal command console.
+//
+// The font and primitives addons must be installed before the overlay is
+// drawn.
+package debug
+
+import (
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/font"
+	"github.com/ccollins476ad/go-allegro/allegro/primitives"
+	"runtime"
+)
+
+// The number of frames shown in the frame time graph.
+const graphSamples = 120
+
+// Overlay collects per-frame statistics and log output, and draws them on top
+// of the screen when visible.
+type Overlay struct {
+	// Visible determines whether Draw() renders anything.
+	Visible bool
+
+	// ToggleKey shows and hides the overlay. ConsoleKey opens and closes the
+	// console. Both are only checked by HandleEvent().
+	ToggleKey, ConsoleKey allegro.KeyCode
+
+	// MaxLines is the number of log lines kept.
+	MaxLines int
+
+	// GraphScale is the frame time, in seconds, that fills the full height
+	// of the graph.
+	GraphScale float64
+
+	font *font.Font
+
+	lastTime  float64
+	lastCgo   int64
+	frameTime [graphSamples]float64
+	cgoCalls  [graphSamples]int64
+	sample    int
+	fps       float64
+	fpsFrames int
+	fpsTime   float64
+
+	lines []string
+
+	console
+}
+
+// New() creates a hidden overlay that draws its text with the given font. If
+// f is nil, the builtin font is used.
+func New(f *font.Font) (*Overlay, error) {
+	if f == nil {
+		var err error
+		if f, err = font.Builtin(); err != nil {
+			return nil, err
+		}
+	}
+	o := Overlay{
+		ToggleKey:  allegro.KEY_F1,
+		ConsoleKey: allegro.KEY_TILDE,
+		MaxLines:   100,
+		GraphScale: 1.0 / 30,
+		font:       f,
+		lastTime:   allegro.Time(),
+		lastCgo:    runtime.NumCgoCall(),
+	}
+	o.console.init(&o)
+	return &o, nil
+}
+
+// Frame() records the statistics for a frame. Call it once per frame, usually
+// right before flipping the display.
+func (o *Overlay) Frame() {
+	now := allegro.Time()
+	cgo := runtime.NumCgoCall()
+	dt := now - o.lastTime
+
+	o.sample = (o.sample + 1) % graphSamples
+	o.frameTime[o.sample] = dt
+	o.cgoCalls[o.sample] = cgo - o.lastCgo
+	o.lastTime, o.lastCgo = now, cgo
+
+	o.fpsFrames++
+	o.fpsTime += dt
+	if o.fpsTime >= 0.5 {
+		o.fps = float64(o.fpsFrames) / o.fpsTime
+		o.fpsFrames, o.fpsTime = 0, 0
+	}
+}
+
+// FPS() returns the frame rate, averaged over the last half second.
+func (o *Overlay) FPS() float64 {
+	return o.fps
+}
+
+// Log() adds a line to the overlay's log.
+func (o *Overlay) Log(line string) {
+	o.lines = append(o.lines, line)
+	if o.MaxLines > 0 && len(o.lines) > o.MaxLines {
+		o.lines = o.lines[len(o.lines)-o.MaxLines:]
+	}
+}
+
+// Logf() adds a formatted line to the overlay's log.
+func (o *Overlay) Logf(format string, a ...interface{}) {
+	o.Log(fmt.Sprintf(format, a...))
+}
+
+// HandleEvent() processes keyboard events for the overlay and console. It
+// returns true if the event was consumed and should not be passed on to the
+// rest of the game.
+func (o *Overlay) HandleEvent(ev interface{}) bool {
+	switch e := ev.(type) {
+	case allegro.KeyDownEvent:
+		switch {
+		case e.KeyCode() == o.ToggleKey:
+			o.Visible = !o.Visible
+			return true
+		case e.KeyCode() == o.ConsoleKey:
+			o.console.open = !o.console.open
+			if o.console.open {
+				o.Visible = true
+			}
+			return true
+		}
+		return o.console.open
+
+	case allegro.KeyUpEvent:
+		return o.console.open
+
+	case allegro.KeyCharEvent:
+		if !o.console.open || e.KeyCode() == o.ConsoleKey {
+			return o.console.open
+		}
+		o.console.key(e.KeyCode(), e.Unichar())
+		return true
+	}
+	return false
+}
+
+// Draw() renders the overlay in screen space, ignoring the current transform
+// of the target bitmap.
+func (o *Overlay) Draw() {
+	if !o.Visible {
+		return
+	}
+	if cur := allegro.CurrentTransform(); cur != nil {
+		saved := cur.Copy()
+		defer allegro.UseTransform(saved)
+	}
+	allegro.UseTransform(allegro.IdentityTransform())
+
+	target := allegro.TargetBitmap()
+	if target == nil {
+		return
+	}
+	w := float32(target.Width())
+	lh := float32(o.font.LineHeight())
+	white := allegro.MapRGB(255, 255, 255)
+	shade := allegro.MapRGBAf(0, 0, 0, 0.6)
+
+	// Statistics and frame time graph.
+	const graphH = 40
+	primitives.DrawFilledRectangle(primitives.Point{X: 0, Y: 0},
+		primitives.Point{X: w, Y: 3*lh + graphH + 8}, shade)
+	dt := o.frameTime[o.sample]
+	font.DrawTextf(o.font, white, 4, 4, font.ALIGN_LEFT, "FPS: %.1f", o.fps)
+	font.DrawTextf(o.font, white, 4, 4+lh, font.ALIGN_LEFT, "Frame: %.2f ms", dt*1000)
+	font.DrawTextf(o.font, white, 4, 4+2*lh, font.ALIGN_LEFT, "cgo calls/frame: %d", o.cgoCalls[o.sample])
+	o.drawGraph(4, 4+3*lh+graphH, graphH)
+
+	// Log, newest at the bottom, filling what's left of the screen above
+	// the console.
+	bottom := float32(target.Height())
+	if o.console.open {
+		bottom -= lh + 4
+		o.console.draw(w, bottom)
+	}
+	y := bottom - lh
+	for i := len(o.lines) - 1; i >= 0 && y > 3*lh+graphH+8; i-- {
+		font.DrawText(o.font, white, 4, y, font.ALIGN_LEFT, o.lines[i])
+		y -= lh
+	}
+}
+
+func (o *Overlay) drawGraph(x, baseline, height float32) {
+	scale := o.GraphScale
+	if scale <= 0 {
+		scale = 1.0 / 30
+	}
+	green := allegro.MapRGB(0, 255, 0)
+	red := allegro.MapRGB(255, 0, 0)
+
+	var vertices [graphSamples * 2]primitives.Vertex
+	for i := 0; i < graphSamples; i++ {
+		dt := o.frameTime[(o.sample+1+i)%graphSamples]
+		h := float32(dt/scale) * height
+		c := green
+		if h > height {
+			h, c = height, red
+		}
+		px := x + float32(i)*2
+		vertices[i*2] = primitives.Vertex{X: px, Y: baseline, Color: c}
+		vertices[i*2+1] = primitives.Vertex{X: px, Y: baseline - h, Color: c}
+	}
+	primitives.DrawPrim(vertices[:], nil, nil, 0, len(vertices), primitives.PRIM_LINE_LIST)
+}
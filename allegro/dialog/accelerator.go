@@ -0,0 +1,214 @@
+package dialog
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_native_dialog.h>
+/*
+static bool emit_menu_click(ALLEGRO_EVENT_SOURCE *source, uint16_t id,
+		ALLEGRO_DISPLAY *display, ALLEGRO_MENU *menu) {
+	ALLEGRO_EVENT event;
+	event.user.type = ALLEGRO_EVENT_MENU_CLICK;
+	event.user.data1 = id;
+	event.user.data2 = (intptr_t)display;
+	event.user.data3 = (intptr_t)menu;
+	event.user.data4 = 0;
+	return al_emit_user_event(source, &event, NULL);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// Modifiers that take part in accelerator matching; lock keys and accents are
+// ignored.
+const accelModifiers = allegro.KEYMOD_SHIFT | allegro.KEYMOD_CTRL | allegro.KEYMOD_ALT |
+	allegro.KEYMOD_ALTGR | allegro.KEYMOD_COMMAND
+
+var modifierNames = []struct {
+	name string
+	mod  allegro.KeyModifier
+}{
+	{"Ctrl", allegro.KEYMOD_CTRL},
+	{"Shift", allegro.KEYMOD_SHIFT},
+	{"Alt", allegro.KEYMOD_ALT},
+	{"AltGr", allegro.KEYMOD_ALTGR},
+	{"Cmd", allegro.KEYMOD_COMMAND},
+}
+
+var (
+	accelSources   = make(map[*C.ALLEGRO_EVENT_SOURCE]bool)
+	accelSourcesMu sync.Mutex
+)
+
+func isAcceleratorSource(source *C.ALLEGRO_EVENT_SOURCE) bool {
+	accelSourcesMu.Lock()
+	defer accelSourcesMu.Unlock()
+	return accelSources[source]
+}
+
+// Shortcut is a key combination such as Ctrl+S.
+type Shortcut struct {
+	KeyCode   allegro.KeyCode
+	Modifiers allegro.KeyModifier
+}
+
+// ParseShortcut() parses shortcuts of the form "Ctrl+Shift+S". Modifier names
+// are Ctrl, Shift, Alt, AltGr and Cmd (or Command); the key name is anything
+// accepted by allegro.ParseKeyCode(). The comparison ignores case.
+func ParseShortcut(s string) (Shortcut, error) {
+	parts := strings.Split(s, "+")
+	var sc Shortcut
+	for _, part := range parts[:len(parts)-1] {
+		part = strings.TrimSpace(part)
+		if strings.EqualFold(part, "Command") {
+			part = "Cmd"
+		}
+		found := false
+		for _, m := range modifierNames {
+			if strings.EqualFold(part, m.name) {
+				sc.Modifiers |= m.mod
+				found = true
+				break
+			}
+		}
+		if !found {
+			return Shortcut{}, fmt.Errorf("unknown modifier '%s' in shortcut '%s'", part, s)
+		}
+	}
+	key, err := allegro.ParseKeyCode(parts[len(parts)-1])
+	if err != nil {
+		return Shortcut{}, err
+	}
+	sc.KeyCode = key
+	return sc, nil
+}
+
+// String() formats the shortcut the way ParseShortcut() accepts it, e.g.
+// "Ctrl+S".
+func (sc Shortcut) String() string {
+	var b strings.Builder
+	for _, m := range modifierNames {
+		if sc.Modifiers&m.mod != 0 {
+			b.WriteString(m.name)
+			b.WriteByte('+')
+		}
+	}
+	b.WriteString(sc.KeyCode.Name())
+	return b.String()
+}
+
+type accelerator struct {
+	menu *Menu
+	id   uint16
+}
+
+// Accelerators maps keyboard shortcuts to menu items. Allegro's native menus
+// have no shortcut support of their own, so key presses are fed to
+// HandleEvent(), which emits a menu click event for the bound item through the
+// accelerator's event source. Register that source with the same queue as the
+// menu event source and both kinds of click arrive as MenuClickEvent;
+// Accelerator() tells them apart.
+type Accelerators struct {
+	source *C.ALLEGRO_EVENT_SOURCE
+	keys   map[Shortcut]accelerator
+}
+
+// NewAccelerators() creates an empty accelerator table with its own event
+// source.
+func NewAccelerators() *Accelerators {
+	source := (*C.ALLEGRO_EVENT_SOURCE)(C.malloc(C.sizeof_ALLEGRO_EVENT_SOURCE))
+	C.al_init_user_event_source(source)
+	accelSourcesMu.Lock()
+	accelSources[source] = true
+	accelSourcesMu.Unlock()
+	return &Accelerators{
+		source: source,
+		keys:   make(map[Shortcut]accelerator),
+	}
+}
+
+// Destroy() destroys the accelerators' event source.
+func (a *Accelerators) Destroy() {
+	accelSourcesMu.Lock()
+	delete(accelSources, a.source)
+	accelSourcesMu.Unlock()
+	C.al_destroy_user_event_source(a.source)
+	C.free(unsafe.Pointer(a.source))
+	a.source = nil
+}
+
+// EventSource() returns the source through which menu click events for
+// triggered accelerators are emitted.
+func (a *Accelerators) EventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(a.source))
+}
+
+// Bind() parses shortcut with ParseShortcut() and binds it to the item with
+// the given id in menu or one of its sub-menus. The shortcut is appended to
+// the item's caption after a tab, replacing any previous one, which is where
+// native menus show it; "Save" becomes "Save\tCtrl+S".
+func (a *Accelerators) Bind(menu *Menu, id uint16, shortcut string) error {
+	sc, err := ParseShortcut(shortcut)
+	if err != nil {
+		return err
+	}
+	return a.BindShortcut(menu, id, sc)
+}
+
+// BindShortcut() is like Bind() but takes an already parsed shortcut.
+func (a *Accelerators) BindShortcut(menu *Menu, id uint16, sc Shortcut) error {
+	owner, _, ok := menu.FindItem(id)
+	if !ok {
+		return fmt.Errorf("no menu item with id %d", id)
+	}
+	a.keys[sc] = accelerator{owner, id}
+	if caption, ok := owner.ItemCaption(id); ok {
+		if i := strings.IndexByte(caption, '\t'); i >= 0 {
+			caption = caption[:i]
+		}
+		owner.SetItemCaption(id, caption+"\t"+sc.String())
+	}
+	return nil
+}
+
+// Unbind() removes the binding for shortcut, if any. The item's caption is
+// left unchanged.
+func (a *Accelerators) Unbind(sc Shortcut) {
+	delete(a.keys, sc)
+}
+
+// Lookup() returns the menu item bound to sc.
+func (a *Accelerators) Lookup(sc Shortcut) (menu *Menu, id uint16, ok bool) {
+	acc, ok := a.keys[sc]
+	return acc.menu, acc.id, ok
+}
+
+// HandleEvent() checks key char events against the bound shortcuts. When one
+// matches an enabled item, a menu click event is emitted for it and true is
+// returned; checkbox items are toggled first, as a click would. Key repeats are
+// ignored so that holding Ctrl+S saves only once.
+func (a *Accelerators) HandleEvent(ev interface{}) bool {
+	e, ok := ev.(allegro.KeyCharEvent)
+	if !ok || e.Repeat() {
+		return false
+	}
+	sc := Shortcut{e.KeyCode(), e.Modifiers() & accelModifiers}
+	acc, ok := a.keys[sc]
+	if !ok {
+		return false
+	}
+	flags, ok := acc.menu.ItemFlags(acc.id)
+	if !ok || flags&MENU_ITEM_DISABLED != 0 {
+		return false
+	}
+	if flags&MENU_ITEM_CHECKBOX != 0 {
+		acc.menu.ToggleItemFlags(acc.id, MENU_ITEM_CHECKED)
+	}
+	return bool(C.emit_menu_click(a.source, C.uint16_t(acc.id),
+		(*C.ALLEGRO_DISPLAY)(unsafe.Pointer(e.Display())), (*C.ALLEGRO_MENU)(acc.menu)))
+}
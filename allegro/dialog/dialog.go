@@ -71,6 +71,15 @@ func Shutdown() {
 	C.al_shutdown_native_dialog_addon()
 }
 
+// Addon installs the native dialog addon through allegro.Init().
+var Addon = allegro.Addon{Name: "native dialog", Install: Install, Uninstall: Shutdown, Installed: IsInstalled}
+
+// Returns true if the native dialog addon is initialized, otherwise returns
+// false.
+func IsInstalled() bool {
+	return bool(C.al_is_native_dialog_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
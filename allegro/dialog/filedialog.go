@@ -0,0 +1,60 @@
+package dialog
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Paths() returns all of the selected paths, or nil if the dialog was
+// cancelled.
+func (dialog *FileChooser) Paths() []string {
+	n := dialog.Count()
+	if n == 0 {
+		return nil
+	}
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if path, err := dialog.Path(i); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// FileDialogResult is the outcome of a file dialog shown with
+// ShowFileDialogAsync(). Paths is empty if the dialog was cancelled.
+type FileDialogResult struct {
+	Paths []string
+	Err   error
+}
+
+// ShowFileDialog() creates a file dialog, shows it and returns the selected
+// paths, or nil if it was cancelled. It blocks until the dialog is closed.
+func ShowFileDialog(display *allegro.Display, initial_path, title, patterns string, flags FileChooserFlags) ([]string, error) {
+	dialog, err := CreateNativeFileDialog(initial_path, title, patterns, flags)
+	if err != nil {
+		return nil, err
+	}
+	defer dialog.Destroy()
+	if err := ShowNativeFileDialog(display, dialog); err != nil {
+		return nil, err
+	}
+	return dialog.Paths(), nil
+}
+
+// ShowFileDialogAsync() is like ShowFileDialog(), but shows the dialog from a
+// new goroutine and sends the result on the returned channel, so the game
+// loop keeps running while the user picks a file. The channel receives
+// exactly one result and is then closed.
+//
+// Allegro runs native dialogs on their own thread where the platform allows
+// it; on OS X they must be shown from the main thread, so use ShowFileDialog()
+// there instead. Only one file dialog should be open at a time.
+func ShowFileDialogAsync(display *allegro.Display, initial_path, title, patterns string, flags FileChooserFlags) <-chan FileDialogResult {
+	ch := make(chan FileDialogResult, 1)
+	go func() {
+		defer close(ch)
+		paths, err := ShowFileDialog(display, initial_path, title, patterns, flags)
+		ch <- FileDialogResult{paths, err}
+	}()
+	return ch
+}
@@ -0,0 +1,234 @@
+package dialog
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_native_dialog.h>
+// #include "../util.c"
+import "C"
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+type Menu C.ALLEGRO_MENU
+
+type MenuItemFlags int
+
+const (
+	MENU_ITEM_ENABLED  MenuItemFlags = 0
+	MENU_ITEM_CHECKBOX               = C.ALLEGRO_MENU_ITEM_CHECKBOX
+	MENU_ITEM_CHECKED                = C.ALLEGRO_MENU_ITEM_CHECKED
+	MENU_ITEM_DISABLED               = C.ALLEGRO_MENU_ITEM_DISABLED
+)
+
+func init() {
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_MENU_CLICK, func(e *allegro.Event) interface{} {
+		return (*menu_click_event)(unsafe.Pointer(e))
+	})
+}
+
+// Creates a menu container that can hold menu items.
+func CreateMenu() (*Menu, error) {
+	m := C.al_create_menu()
+	if m == nil {
+		return nil, errors.New("failed to create menu")
+	}
+	return (*Menu)(m), nil
+}
+
+// Creates a menu container for "popup" menus. Only the root (outermost) menu
+// should be created with this function. Sub menus of popups should be created
+// with CreateMenu().
+func CreatePopupMenu() (*Menu, error) {
+	m := C.al_create_popup_menu()
+	if m == nil {
+		return nil, errors.New("failed to create popup menu")
+	}
+	return (*Menu)(m), nil
+}
+
+// Destroys an entire menu, including its sub-menus. Any references to it or a
+// sub-menu are no longer valid. It is safe to call this on a menu that is
+// currently being displayed.
+func (menu *Menu) Destroy() {
+	C.al_destroy_menu((*C.ALLEGRO_MENU)(menu))
+}
+
+// Clones a menu, sub-menus included. Any icons are not cloned.
+func (menu *Menu) Clone() *Menu {
+	return (*Menu)(C.al_clone_menu((*C.ALLEGRO_MENU)(menu)))
+}
+
+// Clones a menu into a popup menu, sub-menus included.
+func (menu *Menu) CloneForPopup() *Menu {
+	return (*Menu)(C.al_clone_menu_for_popup((*C.ALLEGRO_MENU)(menu)))
+}
+
+// Appends a menu item to the end of the menu. The id is reported by menu click
+// events and must be greater than zero; it does not need to be unique, but
+// lookups by id only find the first match. If submenu is not nil, the item
+// opens it and is not reported when clicked. Returns the index of the new
+// item.
+func (menu *Menu) AppendItem(title string, id uint16, flags MenuItemFlags, submenu *Menu) int {
+	title_ := C.CString(title)
+	defer C.free_string(title_)
+	return int(C.al_append_menu_item((*C.ALLEGRO_MENU)(menu), title_, C.uint16_t(id),
+		C.int(flags), nil, (*C.ALLEGRO_MENU)(submenu)))
+}
+
+// Appends a separator line to the end of the menu.
+func (menu *Menu) AppendSeparator() int {
+	return int(C.al_append_menu_item((*C.ALLEGRO_MENU)(menu), nil, 0, 0, nil, nil))
+}
+
+// Inserts a menu item before the item with the given id.
+func (menu *Menu) InsertItem(before uint16, title string, id uint16, flags MenuItemFlags, submenu *Menu) int {
+	title_ := C.CString(title)
+	defer C.free_string(title_)
+	return int(C.al_insert_menu_item((*C.ALLEGRO_MENU)(menu), C.int(before), title_,
+		C.uint16_t(id), C.int(flags), nil, (*C.ALLEGRO_MENU)(submenu)))
+}
+
+// Removes the item with the given id from the menu, destroying its sub-menu
+// if it has one.
+func (menu *Menu) RemoveItem(id uint16) bool {
+	return bool(C.al_remove_menu_item((*C.ALLEGRO_MENU)(menu), C.int(id)))
+}
+
+// Returns the caption of the item with the given id.
+func (menu *Menu) ItemCaption(id uint16) (string, bool) {
+	caption := C.al_get_menu_item_caption((*C.ALLEGRO_MENU)(menu), C.int(id))
+	if caption == nil {
+		return "", false
+	}
+	return C.GoString(caption), true
+}
+
+// Updates the caption of the item with the given id.
+func (menu *Menu) SetItemCaption(id uint16, caption string) {
+	caption_ := C.CString(caption)
+	defer C.free_string(caption_)
+	C.al_set_menu_item_caption((*C.ALLEGRO_MENU)(menu), C.int(id), caption_)
+}
+
+// Returns the flags of the item with the given id.
+func (menu *Menu) ItemFlags(id uint16) (MenuItemFlags, bool) {
+	flags := int(C.al_get_menu_item_flags((*C.ALLEGRO_MENU)(menu), C.int(id)))
+	if flags < 0 {
+		return 0, false
+	}
+	return MenuItemFlags(flags), true
+}
+
+// Updates the flags of the item with the given id.
+func (menu *Menu) SetItemFlags(id uint16, flags MenuItemFlags) {
+	C.al_set_menu_item_flags((*C.ALLEGRO_MENU)(menu), C.int(id), C.int(flags))
+}
+
+// Toggles the given flags of the item with the given id and returns the flags
+// that were actually toggled.
+func (menu *Menu) ToggleItemFlags(id uint16, flags MenuItemFlags) MenuItemFlags {
+	return MenuItemFlags(C.al_toggle_menu_item_flags((*C.ALLEGRO_MENU)(menu), C.int(id), C.int(flags)))
+}
+
+// Searches the menu and its sub-menus for the first item with the given id.
+// Returns the menu that directly contains it and its index in that menu.
+func (menu *Menu) FindItem(id uint16) (*Menu, int, bool) {
+	var found *C.ALLEGRO_MENU
+	var index C.int
+	if !bool(C.al_find_menu_item((*C.ALLEGRO_MENU)(menu), C.uint16_t(id), &found, &index)) {
+		return nil, 0, false
+	}
+	return (*Menu)(found), int(index), true
+}
+
+// Searches the menu and its sub-menus for the first sub-menu opened by an item
+// with the given id.
+func (menu *Menu) FindMenu(id uint16) *Menu {
+	return (*Menu)(C.al_find_menu((*C.ALLEGRO_MENU)(menu), C.uint16_t(id)))
+}
+
+// Associates the menu with the display and shows it. The menu must not be a
+// popup menu.
+func SetDisplayMenu(display *allegro.Display, menu *Menu) error {
+	if !bool(C.al_set_display_menu((*C.ALLEGRO_DISPLAY)(unsafe.Pointer(display)),
+		(*C.ALLEGRO_MENU)(menu))) {
+		return errors.New("failed to set display menu")
+	}
+	return nil
+}
+
+// Detaches the menu associated with the display and returns it, or nil if
+// there was none.
+func RemoveDisplayMenu(display *allegro.Display) *Menu {
+	return (*Menu)(C.al_remove_display_menu((*C.ALLEGRO_DISPLAY)(unsafe.Pointer(display))))
+}
+
+// Displays a popup menu at the mouse position. The display may be nil, in
+// which case the current display is used. The popup menu is detached once an
+// item has been selected or the menu is dismissed.
+func (menu *Menu) Popup(display *allegro.Display) error {
+	if !bool(C.al_popup_menu((*C.ALLEGRO_MENU)(menu),
+		(*C.ALLEGRO_DISPLAY)(unsafe.Pointer(display)))) {
+		return errors.New("failed to show popup menu")
+	}
+	return nil
+}
+
+// Returns the default event source used for menu clicks. Menus that have had
+// their own event source enabled don't emit events through it.
+func MenuEventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(C.al_get_default_menu_event_source()))
+}
+
+// Enables a unique event source for the menu and its sub-menus, which is
+// returned.
+func (menu *Menu) EnableEventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(
+		C.al_enable_menu_event_source((*C.ALLEGRO_MENU)(menu))))
+}
+
+// Disables the menu's unique event source; its events go to the default
+// source again.
+func (menu *Menu) DisableEventSource() {
+	C.al_disable_menu_event_source((*C.ALLEGRO_MENU)(menu))
+}
+
+/* -- Menu Click -- */
+
+type MenuClickEvent interface {
+	menu_click()
+	Timestamp() float64
+	ID() uint16
+	Display() *allegro.Display
+	Menu() *Menu
+	Accelerator() bool
+}
+
+type menu_click_event C.ALLEGRO_USER_EVENT // C.ALLEGRO_EVENT_MENU_CLICK
+
+func (e *menu_click_event) menu_click() {}
+
+func (e *menu_click_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+// ID() returns the id of the menu item that was clicked.
+func (e *menu_click_event) ID() uint16 {
+	return uint16(e.data1)
+}
+
+func (e *menu_click_event) Display() *allegro.Display {
+	return (*allegro.Display)(unsafe.Pointer(uintptr(e.data2)))
+}
+
+// Menu() returns the menu that directly contains the item.
+func (e *menu_click_event) Menu() *Menu {
+	return (*Menu)(unsafe.Pointer(uintptr(e.data3)))
+}
+
+// Accelerator() reports whether the click was triggered by a keyboard
+// shortcut through Accelerators rather than by the native menu.
+func (e *menu_click_event) Accelerator() bool {
+	return isAcceleratorSource(e.source)
+}
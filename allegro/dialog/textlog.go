@@ -0,0 +1,36 @@
+package dialog
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_native_dialog.h>
+import "C"
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+func init() {
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_NATIVE_DIALOG_CLOSE, func(e *allegro.Event) interface{} {
+		return (*text_log_close_event)(unsafe.Pointer(e))
+	})
+}
+
+// TextLogCloseEvent is emitted by a text log's event source when the user
+// tries to close its window. The window is not closed automatically.
+type TextLogCloseEvent interface {
+	text_log_close()
+	Timestamp() float64
+	TextLog() *TextLog
+}
+
+type text_log_close_event C.ALLEGRO_USER_EVENT // C.ALLEGRO_EVENT_NATIVE_DIALOG_CLOSE
+
+func (e *text_log_close_event) text_log_close() {}
+
+func (e *text_log_close_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+// TextLog() returns the text log whose window is being closed.
+func (e *text_log_close_event) TextLog() *TextLog {
+	return (*TextLog)(unsafe.Pointer(uintptr(e.data1)))
+}
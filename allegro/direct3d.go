@@ -4,9 +4,13 @@ package allegro
 
 // #include <allegro5/allegro.h>
 // #include <allegro5/allegro_direct3d.h>
+//
+// extern void go_d3d_release(ALLEGRO_DISPLAY *display);
+// extern void go_d3d_restore(ALLEGRO_DISPLAY *display);
 import "C"
 import (
 	"errors"
+	"sync"
 )
 
 const (
@@ -42,6 +46,17 @@ func (bmp *Bitmap) D3DSystemTexture() (Direct3DTexture, error) {
 	return Direct3DTexture(texture), nil
 }
 
+// Returns the video texture (stored with the D3DPOOL_DEFAULT or
+// D3DPOOL_MANAGED flags depending on whether render-to-texture is enabled or
+// disabled respectively).
+func (bmp *Bitmap) D3DVideoTexture() (Direct3DTexture, error) {
+	texture := C.al_get_d3d_video_texture((*C.ALLEGRO_BITMAP)(bmp))
+	if texture == nil {
+		return nil, errors.New("failed to get D3D texture")
+	}
+	return Direct3DTexture(texture), nil
+}
+
 // Returns the u/v coordinates for the top/left corner of the bitmap within the
 // used texture, in pixels.
 func (bmp *Bitmap) TexturePosition() (int, int) {
@@ -50,7 +65,73 @@ func (bmp *Bitmap) TexturePosition() (int, int) {
 	return int(u), int(v)
 }
 
+// Retrieves the size of the Direct3D texture used for the bitmap. This can be
+// different from the bitmap size if Direct3D only supports power-of-two sizes
+// or if it is a sub-bitmap.
+func (bmp *Bitmap) D3DTextureSize() (width, height int, err error) {
+	var w, h C.int
+	if !bool(C.al_get_d3d_texture_size((*C.ALLEGRO_BITMAP)(bmp), &w, &h)) {
+		return 0, 0, errors.New("failed to get D3D texture size")
+	}
+	return int(w), int(h), nil
+}
+
 // Returns whether the Direct3D device supports textures that are not square.
 func HaveD3DNonSquareTextureSupport() bool {
 	return bool(C.al_have_d3d_non_square_texture_support())
 }
+
+var d3dCallbacks struct {
+	sync.Mutex
+	release, restore func(*Display)
+}
+
+//export go_d3d_release
+func go_d3d_release(display *C.ALLEGRO_DISPLAY) {
+	d3dCallbacks.Lock()
+	f := d3dCallbacks.release
+	d3dCallbacks.Unlock()
+	if f != nil {
+		f((*Display)(display))
+	}
+}
+
+//export go_d3d_restore
+func go_d3d_restore(display *C.ALLEGRO_DISPLAY) {
+	d3dCallbacks.Lock()
+	f := d3dCallbacks.restore
+	d3dCallbacks.Unlock()
+	if f != nil {
+		f((*Display)(display))
+	}
+}
+
+// The callback will be called whenever a D3D device is reset (minimize,
+// toggle fullscreen window, etc). In the callback you should release any d3d
+// resources you have created yourself. The callback receives the affected
+// display as a parameter. Pass nil to disable the callback.
+func SetD3DReleaseCallback(f func(*Display)) {
+	d3dCallbacks.Lock()
+	d3dCallbacks.release = f
+	d3dCallbacks.Unlock()
+	if f == nil {
+		C.al_d3d_set_release_callback(nil)
+	} else {
+		C.al_d3d_set_release_callback((*[0]byte)(C.go_d3d_release))
+	}
+}
+
+// The callback will be called whenever a D3D device that has been reset is
+// restored. In the callback you should restore any d3d resources you have
+// created yourself. The callback receives the affected display as a parameter.
+// Pass nil to disable the callback.
+func SetD3DRestoreCallback(f func(*Display)) {
+	d3dCallbacks.Lock()
+	d3dCallbacks.restore = f
+	d3dCallbacks.Unlock()
+	if f == nil {
+		C.al_d3d_set_restore_callback(nil)
+	} else {
+		C.al_d3d_set_restore_callback((*[0]byte)(C.go_d3d_restore))
+	}
+}
@@ -24,6 +24,7 @@ const (
 	NOFRAME                                = C.ALLEGRO_NOFRAME
 	GENERATE_EXPOSE_EVENTS                 = C.ALLEGRO_GENERATE_EXPOSE_EVENTS
 	PROGRAMMABLE_PIPELINE                  = C.ALLEGRO_PROGRAMMABLE_PIPELINE
+	MAXIMIZED                              = C.ALLEGRO_MAXIMIZED
 )
 
 type DisplayMode C.struct_ALLEGRO_DISPLAY_MODE
@@ -62,6 +63,7 @@ const (
 	SUPPORT_NPOT_BITMAP                  = C.ALLEGRO_SUPPORT_NPOT_BITMAP
 	CAN_DRAW_INTO_BITMAP                 = C.ALLEGRO_CAN_DRAW_INTO_BITMAP
 	SUPPORT_SEPARATE_ALPHA               = C.ALLEGRO_SUPPORT_SEPARATE_ALPHA
+	SUPPORTED_ORIENTATIONS               = C.ALLEGRO_SUPPORTED_ORIENTATIONS
 )
 
 type Importance C.int
@@ -81,6 +83,9 @@ const (
 	DISPLAY_ORIENTATION_270_DEGREES                    = C.ALLEGRO_DISPLAY_ORIENTATION_270_DEGREES
 	DISPLAY_ORIENTATION_FACE_UP                        = C.ALLEGRO_DISPLAY_ORIENTATION_FACE_UP
 	DISPLAY_ORIENTATION_FACE_DOWN                      = C.ALLEGRO_DISPLAY_ORIENTATION_FACE_DOWN
+	DISPLAY_ORIENTATION_PORTRAIT                       = C.ALLEGRO_DISPLAY_ORIENTATION_PORTRAIT
+	DISPLAY_ORIENTATION_LANDSCAPE                      = C.ALLEGRO_DISPLAY_ORIENTATION_LANDSCAPE
+	DISPLAY_ORIENTATION_ALL                            = C.ALLEGRO_DISPLAY_ORIENTATION_ALL
 )
 
 // Create a display, or window, with the specified dimensions. The parameters
@@ -89,12 +94,19 @@ const (
 // display will automatically make it the active one, with the backbuffer
 // selected for drawing.
 func CreateDisplay(w, h int) (*Display, error) {
-	d := C.al_create_display(C.int(w), C.int(h))
-	if d == nil {
-		return nil, errors.New("failed to create display!")
+	if headless {
+		return nil, ErrHeadless
+	}
+	var d *C.ALLEGRO_DISPLAY
+	err := checkErrno("al_create_display", fmt.Sprintf("%dx%d", w, h), func() bool {
+		d = C.al_create_display(C.int(w), C.int(h))
+		return d != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	display := (*Display)(d)
-	//runtime.SetFinalizer(display, func(d_ *Display) { d_.Destroy() })
+	trackResource(unsafe.Pointer(display), "display")
 	return display, nil
 }
 
@@ -104,6 +116,7 @@ func CreateDisplay(w, h int) (*Display, error) {
 // semantics as the back buffer, although the contents may have changed.
 func FlipDisplay() {
 	C.al_flip_display()
+	frameArena.reset()
 }
 
 // Does the same as al_flip_display, but tries to update only the specified
@@ -111,6 +124,7 @@ func FlipDisplay() {
 // performance.
 func UpdateDisplayRegion(x, y, width, height int) {
 	C.al_update_display_region(C.int(x), C.int(y), C.int(width), C.int(height))
+	frameArena.reset()
 }
 
 // Get the display flags to be used when creating new displays on the calling
@@ -182,6 +196,28 @@ func ResetNewWindowPosition() {
 	C.al_set_new_window_position(C.INT_MAX, C.INT_MAX)
 }
 
+// The maximum length, in bytes, of the title set with al_set_new_window_title.
+const NEW_WINDOW_TITLE_MAX_SIZE = C.ALLEGRO_NEW_WINDOW_TITLE_MAX_SIZE
+
+// Set the title that will be used when a new display is created. Allegro uses
+// a static buffer of ALLEGRO_NEW_WINDOW_TITLE_MAX_SIZE to store this, so the
+// length of the title you set must be less than this.
+func SetNewWindowTitle(title string) {
+	if len(title) >= NEW_WINDOW_TITLE_MAX_SIZE {
+		title = title[:NEW_WINDOW_TITLE_MAX_SIZE-1]
+	}
+	title_ := C.CString(title)
+	defer freeString(title_)
+	C.al_set_new_window_title(title_)
+}
+
+// Returns the title that will be used when a new display is created. This
+// returns the value that al_set_window_title was called with. If that function
+// wasn't called yet, the value of al_get_app_name is returned as a default.
+func NewWindowTitle() string {
+	return C.GoString(C.al_get_new_window_title())
+}
+
 func ResetDisplayFlags() {
 	C.al_set_new_display_flags(C.int(0))
 }
@@ -255,6 +291,13 @@ func NumDisplayModes() int {
 
 // Destroy a display.
 func (d *Display) Destroy() {
+	if releaseResource(unsafe.Pointer(d), "display") == nil {
+		d.destroy()
+	}
+}
+
+func (d *Display) destroy() {
+	forgetEventSource(d.EventSource())
 	C.al_destroy_display((*C.ALLEGRO_DISPLAY)(d))
 }
 
@@ -313,6 +356,49 @@ func (d *Display) AcknowledgeResize() bool {
 	return bool(C.al_acknowledge_resize((*C.ALLEGRO_DISPLAY)(d)))
 }
 
+// HandleEvent() acknowledges a DisplayResizeEvent from the display, so that
+// drawing doesn't continue at the stale size. It returns true for that and
+// for a DisplayExposeEvent from the display, either of which means the frame
+// should be redrawn. Expose events are only generated for displays created
+// with GENERATE_EXPOSE_EVENTS.
+func (d *Display) HandleEvent(ev interface{}) bool {
+	switch e := ev.(type) {
+	case DisplayResizeEvent:
+		if e.Source() != d {
+			return false
+		}
+		d.AcknowledgeResize()
+		return true
+	case DisplayExposeEvent:
+		return e.Source() == d
+	}
+	return false
+}
+
+// Wait for the beginning of a vertical retrace. Some driver/card/monitor
+// combinations may not be capable of this.
+func WaitForVsync() error {
+	if !bool(C.al_wait_for_vsync()) {
+		return errors.New("failed to wait for vsync")
+	}
+	return nil
+}
+
+// Call this in response to the ALLEGRO_EVENT_DISPLAY_HALT_DRAWING event. This
+// is currently necessary for Android and iOS as you are not allowed to draw to
+// your display while it is not being shown. If you do not call this function
+// to let the operating system know that you have stopped drawing or if you
+// call it to late the application likely will be considered misbehaving and
+// get terminated.
+func (d *Display) AcknowledgeDrawingHalt() {
+	C.al_acknowledge_drawing_halt((*C.ALLEGRO_DISPLAY)(d))
+}
+
+// Call this in response to the ALLEGRO_EVENT_DISPLAY_RESUME_DRAWING event.
+func (d *Display) AcknowledgeDrawingResume() {
+	C.al_acknowledge_drawing_resume((*C.ALLEGRO_DISPLAY)(d))
+}
+
 // Set the title on a display.
 func (d *Display) SetWindowTitle(title string) {
 	title_ := C.CString(title)
@@ -320,6 +406,12 @@ func (d *Display) SetWindowTitle(title string) {
 	C.al_set_window_title((*C.ALLEGRO_DISPLAY)(d), title_)
 }
 
+// SetWindowTitlef() is like SetWindowTitle(), but formats the title, which is
+// handy for showing dynamic information such as the frame rate.
+func (d *Display) SetWindowTitlef(format string, a ...interface{}) {
+	d.SetWindowTitle(fmt.Sprintf(format, a...))
+}
+
 // Return a special bitmap representing the back-buffer of the display.
 func (d *Display) Backbuffer() *Bitmap {
 	return (*Bitmap)(C.al_get_backbuffer((*C.ALLEGRO_DISPLAY)(d)))
@@ -338,20 +430,69 @@ func (d *Display) Resize(width, height int) error {
 
 // Changes the icon associated with the display (window). Same as
 // al_set_display_icons with one icon.
-func (d *Display) SetDisplayIcon(icon *Bitmap) {
+func (d *Display) SetIcon(icon *Bitmap) {
 	C.al_set_display_icon((*C.ALLEGRO_DISPLAY)(d), (*C.ALLEGRO_BITMAP)(icon))
 }
 
 // Changes the icons associated with the display (window). Multiple icons can
 // be provided for use in different contexts, e.g. window frame, taskbar,
 // alt-tab popup. The number of icons must be at least one.
-func (d *Display) SetDisplayIcons(icons []*Bitmap) {
+func (d *Display) SetIcons(icons []*Bitmap) error {
 	n_icons := len(icons)
+	if n_icons == 0 {
+		return errors.New("no icons given")
+	}
 	icons_ := make([]*C.ALLEGRO_BITMAP, n_icons)
 	for i := 0; i < n_icons; i++ {
 		icons_[i] = (*C.ALLEGRO_BITMAP)(icons[i])
 	}
 	C.al_set_display_icons((*C.ALLEGRO_DISPLAY)(d), C.int(n_icons), (**C.ALLEGRO_BITMAP)(unsafe.Pointer(&icons_[0])))
+	return nil
+}
+
+// SetDisplayIcon() is the same as SetIcon().
+//
+// Deprecated: use SetIcon().
+func (d *Display) SetDisplayIcon(icon *Bitmap) {
+	d.SetIcon(icon)
+}
+
+// SetDisplayIcons() is the same as SetIcons(), but ignores an empty slice.
+//
+// Deprecated: use SetIcons().
+func (d *Display) SetDisplayIcons(icons []*Bitmap) {
+	d.SetIcons(icons)
+}
+
+// Constrains a non-fullscreen resizable display. The constraints are a hint
+// only, and are not necessarily respected by the window environment. A value
+// of 0 for any of the parameters indicates no constraint for that parameter.
+//
+// The constraints will be applied to a display only after the
+// ApplyWindowConstraints() function call.
+func (d *Display) SetWindowConstraints(minW, minH, maxW, maxH int) error {
+	ok := bool(C.al_set_window_constraints((*C.ALLEGRO_DISPLAY)(d),
+		C.int(minW), C.int(minH), C.int(maxW), C.int(maxH)))
+	if !ok {
+		return errors.New("failed to set window constraints")
+	}
+	return nil
+}
+
+// Gets the constraints for a non-fullscreen resizable display.
+func (d *Display) WindowConstraints() (minW, minH, maxW, maxH int, err error) {
+	var cminW, cminH, cmaxW, cmaxH C.int
+	ok := bool(C.al_get_window_constraints((*C.ALLEGRO_DISPLAY)(d),
+		&cminW, &cminH, &cmaxW, &cmaxH))
+	if !ok {
+		return 0, 0, 0, 0, errors.New("failed to get window constraints")
+	}
+	return int(cminW), int(cminH), int(cmaxW), int(cmaxH), nil
+}
+
+// Enable or disable previously set constraints by SetWindowConstraints().
+func (d *Display) ApplyWindowConstraints(onoff bool) {
+	C.al_apply_window_constraints((*C.ALLEGRO_DISPLAY)(d), C.bool(onoff))
 }
 
 // Gets the pixel format of the display.
@@ -359,4 +500,34 @@ func (d *Display) DisplayFormat() PixelFormat {
 	return PixelFormat(C.al_get_display_format((*C.ALLEGRO_DISPLAY)(d)))
 }
 
+// Gets the flags of the display.
+func (d *Display) Flags() DisplayFlags {
+	return DisplayFlags(C.al_get_display_flags((*C.ALLEGRO_DISPLAY)(d)))
+}
+
+// Return the display orientation, which can be one of the
+// DISPLAY_ORIENTATION_* values.
+func (d *Display) Orientation() DisplayOrientation {
+	return DisplayOrientation(C.al_get_display_orientation((*C.ALLEGRO_DISPLAY)(d)))
+}
+
+// CreateCompatibleBitmap() creates a video bitmap in the display's pixel
+// format, so that drawing it to the backbuffer, or the backbuffer to it, needs
+// no format conversion. The display must be current on the calling thread.
+// The new bitmap format and flags are left unchanged.
+func (d *Display) CreateCompatibleBitmap(w, h int) (*Bitmap, error) {
+	oldFormat, oldFlags := NewBitmapFormat(), NewBitmapFlags()
+	defer func() {
+		SetNewBitmapFormat(oldFormat)
+		SetNewBitmapFlags(oldFlags)
+	}()
+	SetNewBitmapFormat(d.DisplayFormat())
+	SetNewBitmapFlags(oldFlags&^MEMORY_BITMAP | VIDEO_BITMAP)
+	bmp := CreateBitmap(w, h)
+	if bmp == nil {
+		return nil, fmt.Errorf("failed to create %dx%d bitmap compatible with display", w, h)
+	}
+	return bmp, nil
+}
+
 //}}}
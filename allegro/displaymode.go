@@ -0,0 +1,60 @@
+package allegro
+
+import (
+	"fmt"
+)
+
+// DisplayModes() returns all of the fullscreen modes available for the
+// current new display parameters, as counted by NumDisplayModes().
+func DisplayModes() []DisplayMode {
+	n := NumDisplayModes()
+	modes := make([]DisplayMode, 0, n)
+	for i := 0; i < n; i++ {
+		if m, err := GetDisplayMode(i); err == nil {
+			modes = append(modes, *m)
+		}
+	}
+	return modes
+}
+
+// DisplayModesOnAdapter() returns the fullscreen modes of the given adapter.
+// The new display adapter is restored afterwards.
+func DisplayModesOnAdapter(adapter int) []DisplayMode {
+	old := NewDisplayAdapter()
+	defer SetNewDisplayAdapter(old)
+	SetNewDisplayAdapter(adapter)
+	return DisplayModes()
+}
+
+// String() describes the mode for use in a settings menu, such as
+// "1920x1080 @ 60Hz". It has a value receiver so that fmt also uses it for
+// the values returned by DisplayModes().
+func (m DisplayMode) String() string {
+	if m.RefreshRate() == 0 {
+		return fmt.Sprintf("%dx%d", m.Width(), m.Height())
+	}
+	return fmt.Sprintf("%dx%d @ %dHz", m.Width(), m.Height(), m.RefreshRate())
+}
+
+// SetFullscreenWindow() switches the display between a window and a
+// fullscreen window covering its monitor.
+func (d *Display) SetFullscreenWindow(on bool) error {
+	return d.SetDisplayFlag(FULLSCREEN_WINDOW, on)
+}
+
+// SetFrameless() shows or hides the window's border and title bar.
+func (d *Display) SetFrameless(on bool) error {
+	return d.SetDisplayFlag(FRAMELESS, on)
+}
+
+// SetMaximized() maximizes the window or restores it to its previous size.
+// The window must be resizable.
+func (d *Display) SetMaximized(on bool) error {
+	return d.SetDisplayFlag(MAXIMIZED, on)
+}
+
+// IsFullscreen() returns true if the display is fullscreen or a fullscreen
+// window.
+func (d *Display) IsFullscreen() bool {
+	return d.Flags()&(FULLSCREEN|FULLSCREEN_WINDOW) != 0
+}
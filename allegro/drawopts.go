@@ -0,0 +1,80 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+// A negative sw stands for the whole bitmap, so that its size doesn't have to
+// be fetched with separate cgo calls.
+static void draw_opts(ALLEGRO_BITMAP *bmp, float sx, float sy, float sw,
+		float sh, ALLEGRO_COLOR tint, float cx, float cy, float dx, float dy,
+		float xscale, float yscale, float angle, int flags) {
+	if (sw < 0) {
+		sw = al_get_bitmap_width(bmp);
+		sh = al_get_bitmap_height(bmp);
+	}
+	al_draw_tinted_scaled_rotated_bitmap_region(bmp, sx, sy, sw, sh, tint,
+		cx, cy, dx, dy, xscale, yscale, angle, flags);
+}
+*/
+import "C"
+
+// BitmapRegion is a rectangle within a bitmap, in pixels.
+type BitmapRegion struct {
+	X, Y, W, H float32
+}
+
+// DrawOptions describes how Bitmap.DrawOpts() draws a bitmap. Zero fields are
+// given the defaults below, so DrawOptions{X: x, Y: y} is the same as
+// Draw(x, y, 0).
+type DrawOptions struct {
+	// X and Y are where the centre point ends up on the target.
+	X, Y float32
+
+	Tint           *Color        // untinted
+	Rotation       float32       // radians clockwise
+	ScaleX, ScaleY float32       // 1
+	SourceRegion   *BitmapRegion // the whole bitmap
+	Flags          DrawFlags     // 0
+
+	// CenterX and CenterY are the point of the source region, relative to its
+	// top left corner, that is placed at X, Y and that the bitmap is rotated
+	// and scaled around. Defaults to the top left corner.
+	CenterX, CenterY float32
+}
+
+// DrawOpts() draws the bitmap as described by opts with a single cgo call,
+// which ends in al_draw_tinted_scaled_rotated_bitmap_region.
+func (bmp *Bitmap) DrawOpts(opts DrawOptions) {
+	if bmp == nil {
+		return
+	}
+	tint := packColor(1, 1, 1, 1)
+	if opts.Tint != nil {
+		tint = *opts.Tint
+	}
+	xscale, yscale := opts.ScaleX, opts.ScaleY
+	if xscale == 0 {
+		xscale = 1
+	}
+	if yscale == 0 {
+		yscale = 1
+	}
+	region := BitmapRegion{W: -1, H: -1}
+	if opts.SourceRegion != nil {
+		region = *opts.SourceRegion
+	}
+	C.draw_opts((*C.ALLEGRO_BITMAP)(bmp),
+		C.float(region.X),
+		C.float(region.Y),
+		C.float(region.W),
+		C.float(region.H),
+		C.ALLEGRO_COLOR(tint),
+		C.float(opts.CenterX),
+		C.float(opts.CenterY),
+		C.float(opts.X),
+		C.float(opts.Y),
+		C.float(xscale),
+		C.float(yscale),
+		C.float(opts.Rotation),
+		C.int(opts.Flags),
+	)
+}
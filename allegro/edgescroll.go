@@ -0,0 +1,99 @@
+package allegro
+
+// EdgeScroller implements RTS-style edge scrolling: the cursor is confined to
+// a display, and moving it near the display's edges scrolls the view.
+type EdgeScroller struct {
+	// Margin is the width, in pixels, of the border in which scrolling
+	// starts.
+	Margin int
+
+	// Speed is the scroll speed, in world units per second, when the cursor
+	// is right at an edge. It falls off linearly towards the inner side of
+	// the margin.
+	Speed float32
+
+	display *Display
+	x, y    int
+	inside  bool
+	grabbed bool
+}
+
+// NewEdgeScroller() creates an edge scroller for a display. The mouse isn't
+// confined until Confine() is called.
+func NewEdgeScroller(d *Display, margin int, speed float32) *EdgeScroller {
+	return &EdgeScroller{Margin: margin, Speed: speed, display: d}
+}
+
+// Confine() grabs the mouse, keeping the cursor inside the display.
+func (s *EdgeScroller) Confine() error {
+	if err := s.display.GrabMouse(); err != nil {
+		return err
+	}
+	s.grabbed = true
+	return nil
+}
+
+// Release() stops confining the mouse.
+func (s *EdgeScroller) Release() error {
+	s.grabbed = false
+	return UngrabMouse()
+}
+
+// HandleEvent() tracks the cursor position. It also releases the grab while
+// the display is switched out, so that the user can use other windows, and
+// restores it when the display is switched back in.
+func (s *EdgeScroller) HandleEvent(ev interface{}) {
+	switch e := ev.(type) {
+	case MouseAxesEvent:
+		if e.Display() == s.display {
+			s.x, s.y, s.inside = e.X(), e.Y(), true
+		}
+	case MouseEnterDisplayEvent:
+		if e.Display() == s.display {
+			s.x, s.y, s.inside = e.X(), e.Y(), true
+		}
+	case MouseLeaveDisplayEvent:
+		if e.Display() == s.display {
+			s.inside = false
+		}
+	case DisplaySwitchOutEvent:
+		if e.Source() == s.display {
+			s.inside = false
+			if s.grabbed {
+				UngrabMouse()
+			}
+		}
+	case DisplaySwitchInEvent:
+		if e.Source() == s.display && s.grabbed {
+			s.display.GrabMouse()
+		}
+	}
+}
+
+// Proximity() reports how deep the cursor is into the margin along each axis,
+// from -1 (at the left or top edge) to 1 (at the right or bottom edge). Both
+// are 0 when the cursor is away from the edges or outside the display.
+func (s *EdgeScroller) Proximity() (dx, dy float32) {
+	if !s.inside || s.Margin <= 0 {
+		return 0, 0
+	}
+	w, h := s.display.Width(), s.display.Height()
+	return edgeProximity(s.x, w, s.Margin), edgeProximity(s.y, h, s.Margin)
+}
+
+func edgeProximity(pos, size, margin int) float32 {
+	switch {
+	case pos < margin:
+		return -float32(margin-pos) / float32(margin)
+	case pos >= size-margin:
+		return float32(pos-(size-margin)+1) / float32(margin)
+	}
+	return 0
+}
+
+// Scroll() returns how far the view should move after dt seconds.
+func (s *EdgeScroller) Scroll(dt float64) (dx, dy float32) {
+	px, py := s.Proximity()
+	d := s.Speed * float32(dt)
+	return px * d, py * d
+}
@@ -0,0 +1,13 @@
+//go:build ((linux && !android) || freebsd) && !x11embed
+// +build linux,!android freebsd
+// +build !x11embed
+
+package allegro
+
+import (
+	"errors"
+)
+
+func embedWindow(child, parent uintptr) error {
+	return errors.New("embedding displays on X11 needs a build with the x11embed tag")
+}
@@ -0,0 +1,21 @@
+//go:build (!windows && !linux && !freebsd) || android
+// +build !windows,!linux,!freebsd android
+
+package allegro
+
+import (
+	"errors"
+)
+
+var errEmbedUnsupported = errors.New("embedding displays is not supported on this platform")
+
+// NativeHandle() is only supported on Windows and X11, and returns 0
+// elsewhere.
+func (d *Display) NativeHandle() uintptr {
+	return 0
+}
+
+// EmbedInto() is only supported on Windows and X11.
+func (d *Display) EmbedInto(parent uintptr) error {
+	return errEmbedUnsupported
+}
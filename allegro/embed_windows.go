@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package allegro
+
+// #include <windows.h>
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_windows.h>
+//
+// static BOOL embed_window(HWND child, HWND parent) {
+//     LONG_PTR style = GetWindowLongPtr(child, GWL_STYLE);
+//     style &= ~(WS_POPUP | WS_CAPTION | WS_THICKFRAME | WS_SYSMENU);
+//     style |= WS_CHILD;
+//     SetWindowLongPtr(child, GWL_STYLE, style);
+//     if (SetParent(child, parent) == NULL) {
+//         return FALSE;
+//     }
+//     return SetWindowPos(child, NULL, 0, 0, 0, 0,
+//         SWP_NOSIZE | SWP_NOZORDER | SWP_FRAMECHANGED | SWP_SHOWWINDOW);
+// }
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// Returns the handle to the window that the passed display is using.
+func (d *Display) NativeHandle() uintptr {
+	return uintptr(unsafe.Pointer(C.al_get_win_window_handle((*C.ALLEGRO_DISPLAY)(d))))
+}
+
+// EmbedInto() reparents the display's window into the native window parent
+// (an HWND), turning it into a borderless child positioned at the parent's
+// top-left corner. Create the display as WINDOWED, ideally with FRAMELESS, and
+// keep its size in sync with the host by calling Resize() when the parent is
+// resized.
+func (d *Display) EmbedInto(parent uintptr) error {
+	hwnd := C.al_get_win_window_handle((*C.ALLEGRO_DISPLAY)(d))
+	if C.embed_window(hwnd, C.HWND(unsafe.Pointer(parent))) == 0 {
+		return errors.New("failed to reparent display window")
+	}
+	return nil
+}
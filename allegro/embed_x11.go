@@ -0,0 +1,29 @@
+//go:build (linux && !android) || freebsd
+// +build linux,!android freebsd
+
+package allegro
+
+// #include <allegro5/allegro.h>
+//
+// // Declared here rather than through allegro_x.h, which pulls in the Xlib
+// // headers; an XID is an unsigned long.
+// extern unsigned long al_get_x_window_id(ALLEGRO_DISPLAY *display);
+import "C"
+
+// Retrieves the XID associated with the Allegro display.
+func (d *Display) NativeHandle() uintptr {
+	return uintptr(C.al_get_x_window_id((*C.ALLEGRO_DISPLAY)(d)))
+}
+
+// EmbedInto() reparents the display's window into the native window parent
+// (an X11 window ID, such as the one returned by GTK's gdk_x11_window_get_xid
+// or Qt's QWidget::winId), positioning it at the parent's top-left corner.
+// Create the display as WINDOWED, ideally with FRAMELESS, and keep its size in
+// sync with the host by calling Resize() when the parent is resized.
+//
+// Reparenting needs Xlib. So that the rest of the package builds without the
+// X11 development files, it is only available when building with the
+// x11embed tag; otherwise an error is returned.
+func (d *Display) EmbedInto(parent uintptr) error {
+	return embedWindow(d.NativeHandle(), parent)
+}
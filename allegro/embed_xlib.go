@@ -0,0 +1,31 @@
+//go:build ((linux && !android) || freebsd) && x11embed
+// +build linux,!android freebsd
+// +build x11embed
+
+package allegro
+
+// #cgo pkg-config: x11
+// #include <X11/Xlib.h>
+//
+// static int embed_window(XID child, XID parent) {
+//     Display *dpy = XOpenDisplay(NULL);
+//     if (dpy == NULL) {
+//         return 0;
+//     }
+//     XReparentWindow(dpy, child, parent, 0, 0);
+//     XMapWindow(dpy, child);
+//     XSync(dpy, False);
+//     XCloseDisplay(dpy);
+//     return 1;
+// }
+import "C"
+import (
+	"errors"
+)
+
+func embedWindow(child, parent uintptr) error {
+	if C.embed_window(C.XID(child), C.XID(parent)) == 0 {
+		return errors.New("failed to open X display for reparenting")
+	}
+	return nil
+}
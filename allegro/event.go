@@ -5,6 +5,9 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
@@ -12,6 +15,15 @@ var registeredEvents = make(map[C.ALLEGRO_EVENT_TYPE]func(e *Event) interface{})
 
 var EmptyQueue = errors.New("event queue is empty")
 
+// Each queue has a reusable event buffer allocated in C memory, which is what
+// lets Poll(), Wait() and WaitTimed() avoid allocating per event. The sources
+// registered with each queue are tracked too, since Allegro can't list them.
+var (
+	queueEvents     = make(map[*EventQueue]*Event)
+	queueSources    = make(map[*EventQueue][]*EventSource)
+	queueEventsLock sync.Mutex
+)
+
 type EventSource C.ALLEGRO_EVENT_SOURCE
 
 type EventQueue C.ALLEGRO_EVENT_QUEUE
@@ -54,6 +66,7 @@ func (source *EventSource) EmitUserEvent(data ...uintptr) error {
 
 // Destroy an event source initialised with al_init_user_event_source.
 func (source *EventSource) DestroyUserEventSource() {
+	forgetEventSource(source)
 	C.al_destroy_user_event_source((*C.ALLEGRO_EVENT_SOURCE)(source))
 }
 
@@ -73,24 +86,97 @@ func (source *EventSource) Data() uintptr {
 // Create a new, empty event queue, returning a pointer to object if
 // successful. Returns NULL on error.
 func CreateEventQueue() (*EventQueue, error) {
-	q := C.al_create_event_queue()
-	if q == nil {
-		return nil, errors.New("failed to create event queue!")
+	var q *C.ALLEGRO_EVENT_QUEUE
+	err := checkErrno("al_create_event_queue", "", func() bool {
+		q = C.al_create_event_queue()
+		return q != nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return (*EventQueue)(q), nil
+	queue := (*EventQueue)(q)
+	queue.buffer()
+	trackResource(unsafe.Pointer(queue), "event queue")
+	return queue, nil
 }
 
 // Destroy the event queue specified. All event sources currently registered
 // with the queue will be automatically unregistered before the queue is
 // destroyed.
 func (queue *EventQueue) Destroy() {
+	if releaseResource(unsafe.Pointer(queue), "event queue") == nil {
+		queue.destroy()
+	}
+}
+
+func (queue *EventQueue) destroy() {
+	queue.stopStreams()
+	queueEventsLock.Lock()
+	if event, ok := queueEvents[queue]; ok {
+		free(unsafe.Pointer(event))
+		delete(queueEvents, queue)
+	}
+	delete(queueSources, queue)
+	queueEventsLock.Unlock()
 	C.al_destroy_event_queue((*C.ALLEGRO_EVENT_QUEUE)(queue))
 }
 
+// buffer() returns the queue's reusable event buffer.
+func (queue *EventQueue) buffer() *Event {
+	queueEventsLock.Lock()
+	defer queueEventsLock.Unlock()
+	event, ok := queueEvents[queue]
+	if !ok {
+		event = (*Event)(malloc(C.sizeof_ALLEGRO_EVENT))
+		queueEvents[queue] = event
+	}
+	return event
+}
+
 // Shorthand method for registering anything with an EventSource() method.
+// Generators without a source, such as devices that aren't installed, are
+// skipped.
 func (queue *EventQueue) Register(obs ...EventGenerator) {
 	for _, ob := range obs {
-		queue.RegisterEventSource(ob.EventSource())
+		if source := ob.EventSource(); source != nil {
+			queue.RegisterEventSource(source)
+		}
+	}
+}
+
+// RegisterAll() is like Register(), but reports the generators that had no
+// source, e.g. because their device isn't installed. The others are
+// registered regardless.
+//
+//	queue.RegisterAll(display, timer, allegro.KeyboardEvents, allegro.MouseEvents)
+func (queue *EventQueue) RegisterAll(obs ...EventGenerator) error {
+	var missing []string
+	for _, ob := range obs {
+		source := ob.EventSource()
+		if source == nil {
+			missing = append(missing, generatorName(ob))
+			continue
+		}
+		queue.RegisterEventSource(source)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no event source for %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func generatorName(ob EventGenerator) string {
+	if d, ok := ob.(deviceEvents); ok {
+		return d.name
+	}
+	return fmt.Sprintf("%T", ob)
+}
+
+// RegisterWithQueues() registers the generators with each of the queues, for
+// sources that several parts of a program listen to.
+func RegisterWithQueues(queues []*EventQueue, obs ...EventGenerator) {
+	for _, queue := range queues {
+		queue.Register(obs...)
 	}
 }
 
@@ -100,17 +186,77 @@ func (queue *EventQueue) Register(obs ...EventGenerator) {
 // does nothing.
 func (queue *EventQueue) RegisterEventSource(source *EventSource) {
 	C.al_register_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source))
+	queueEventsLock.Lock()
+	defer queueEventsLock.Unlock()
+	for _, s := range queueSources[queue] {
+		if s == source {
+			return
+		}
+	}
+	queueSources[queue] = append(queueSources[queue], source)
 }
 
-// Shorthand method for registering anything with an EventSource() method.
-func (queue *EventQueue) Unregister(ob EventGenerator) {
-	queue.UnregisterEventSource(ob.EventSource())
+// Shorthand method for unregistering anything with an EventSource() method.
+func (queue *EventQueue) Unregister(obs ...EventGenerator) {
+	for _, ob := range obs {
+		if source := ob.EventSource(); source != nil {
+			queue.UnregisterEventSource(source)
+		}
+	}
 }
 
 // Unregister an event source with an event queue. If the event source is not
 // actually registered with the event queue, nothing happens.
 func (queue *EventQueue) UnregisterEventSource(source *EventSource) {
 	C.al_unregister_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source))
+	queueEventsLock.Lock()
+	defer queueEventsLock.Unlock()
+	sources := queueSources[queue]
+	for i, s := range sources {
+		if s == source {
+			queueSources[queue] = append(sources[:i:i], sources[i+1:]...)
+			break
+		}
+	}
+}
+
+// forgetEventSource() removes a source that is about to be destroyed from the
+// Sources() of every queue, since Allegro unregisters it by itself.
+func forgetEventSource(source *EventSource) {
+	if source == nil {
+		return
+	}
+	queueEventsLock.Lock()
+	defer queueEventsLock.Unlock()
+	for queue, sources := range queueSources {
+		for i, s := range sources {
+			if s == source {
+				queueSources[queue] = append(sources[:i:i], sources[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Return true if the event source is registered with the queue.
+func (queue *EventQueue) IsRegistered(source *EventSource) bool {
+	return bool(C.al_is_event_source_registered((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT_SOURCE)(source)))
+}
+
+// Sources() returns the event sources registered with the queue through this
+// package, in the order they were registered.
+func (queue *EventQueue) Sources() []*EventSource {
+	queueEventsLock.Lock()
+	defer queueEventsLock.Unlock()
+	return append([]*EventSource(nil), queueSources[queue]...)
+}
+
+// UnregisterAll() unregisters every source returned by Sources(), e.g. before
+// the objects behind them are destroyed.
+func (queue *EventQueue) UnregisterAll() {
+	for _, source := range queue.Sources() {
+		queue.UnregisterEventSource(source)
+	}
 }
 
 // Return true if the event queue specified is currently empty.
@@ -122,7 +268,12 @@ func (queue *EventQueue) IsEmpty() bool {
 // ret_event and return true. The original event packet will remain at the head
 // of the queue. If the event queue is actually empty, this function returns
 // false and the contents of ret_event are unspecified.
+//
+// If event is nil, the queue's own buffer is used, as with Poll().
 func (queue *EventQueue) PeekNextEvent(event *Event) (interface{}, error) {
+	if event == nil {
+		event = queue.buffer()
+	}
 	if ok := bool(C.al_peek_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
 		return nil, EmptyQueue
 	}
@@ -144,7 +295,12 @@ func (queue *EventQueue) Flush() {
 // into ret_event, returning true. The original event will be removed from the
 // queue. If the event queue is empty, return false and the contents of
 // ret_event are unspecified.
+//
+// If event is nil, the queue's own buffer is used, as with Poll().
 func (queue *EventQueue) GetNextEvent(event *Event) (interface{}, error) {
+	if event == nil {
+		event = queue.buffer()
+	}
 	if ok := bool(C.al_get_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
 		return nil, EmptyQueue
 	}
@@ -207,6 +363,63 @@ func (queue *EventQueue) WaitForEventUntil(timeout *Timeout, event *Event) (inte
 	return event.cast(), true
 }
 
+// Poll() is like GetNextEvent(), but uses a buffer owned by the queue instead
+// of a caller-provided event, so it never allocates. The returned event is
+// only valid until the next call to Poll(), Wait() or WaitTimed() on the same
+// queue; copy out anything that needs to be kept.
+func (queue *EventQueue) Poll() (interface{}, bool) {
+	event := queue.buffer()
+	if ok := bool(C.al_get_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
+		return nil, false
+	}
+	return event.cast(), true
+}
+
+// Wait() is like WaitForEvent(), but uses a buffer owned by the queue. See
+// Poll() for how long the returned event remains valid.
+func (queue *EventQueue) Wait() interface{} {
+	event := queue.buffer()
+	C.al_wait_for_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))
+	return event.cast()
+}
+
+// WaitTimed() is like WaitForEventTimed(), but uses a buffer owned by the
+// queue. See Poll() for how long the returned event remains valid.
+func (queue *EventQueue) WaitTimed(secs float32) (interface{}, bool) {
+	event := queue.buffer()
+	if ok := bool(C.al_wait_for_event_timed((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event), C.float(secs))); !ok {
+		return nil, false
+	}
+	return event.cast(), true
+}
+
+// WaitDuration() is like WaitTimed(), but takes a time.Duration.
+func (queue *EventQueue) WaitDuration(d time.Duration) (interface{}, bool) {
+	return queue.WaitTimed(float32(d.Seconds()))
+}
+
+// WaitUntil() is like WaitForEventUntil(), but uses a buffer owned by the
+// queue. Combined with NewTimeoutAt() it waits for input until the next frame
+// is due. See Poll() for how long the returned event remains valid.
+func (queue *EventQueue) WaitUntil(timeout *Timeout) (interface{}, bool) {
+	event := queue.buffer()
+	if ok := bool(C.al_wait_for_event_until((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event), (*C.ALLEGRO_TIMEOUT)(timeout))); !ok {
+		return nil, false
+	}
+	return event.cast(), true
+}
+
+// Peek() is like PeekNextEvent(), but uses a buffer owned by the queue. The
+// event stays at the head of the queue. See Poll() for how long the returned
+// event remains valid.
+func (queue *EventQueue) Peek() (interface{}, bool) {
+	event := queue.buffer()
+	if ok := bool(C.al_peek_next_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(event))); !ok {
+		return nil, false
+	}
+	return event.cast(), true
+}
+
 type Event C.union_ALLEGRO_EVENT
 
 // RegisterEventType() lets modules register their own event types.
@@ -264,6 +477,19 @@ func (e *Event) cast() interface{} {
 		return (*display_switch_in_event)(unsafe.Pointer(e))
 	case C.ALLEGRO_EVENT_DISPLAY_ORIENTATION:
 		return (*display_orientation_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_DISPLAY_HALT_DRAWING:
+		return (*display_halt_drawing_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_DISPLAY_RESUME_DRAWING:
+		return (*display_resume_drawing_event)(unsafe.Pointer(e))
+
+	case C.ALLEGRO_EVENT_TOUCH_BEGIN:
+		return (*touch_begin_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_END:
+		return (*touch_end_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_MOVE:
+		return (*touch_move_event)(unsafe.Pointer(e))
+	case C.ALLEGRO_EVENT_TOUCH_CANCEL:
+		return (*touch_cancel_event)(unsafe.Pointer(e))
 
 	default:
 		if f, ok := registeredEvents[t]; ok {
@@ -999,6 +1225,266 @@ func (e *display_orientation_event) Orientation() DisplayOrientation {
 	return DisplayOrientation(e.orientation)
 }
 
+/* -- Display Halt Drawing -- */
+
+type DisplayHaltDrawingEvent interface {
+	display_halt_drawing()
+	Timestamp() float64
+	Source() *Display
+}
+
+type display_halt_drawing_event C.struct_ALLEGRO_DISPLAY_EVENT
+
+func (e *display_halt_drawing_event) display_halt_drawing() {}
+
+func (e *display_halt_drawing_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *display_halt_drawing_event) Source() *Display {
+	return (*Display)(e.source)
+}
+
+/* -- Display Resume Drawing -- */
+
+type DisplayResumeDrawingEvent interface {
+	display_resume_drawing()
+	Timestamp() float64
+	Source() *Display
+}
+
+type display_resume_drawing_event C.struct_ALLEGRO_DISPLAY_EVENT
+
+func (e *display_resume_drawing_event) display_resume_drawing() {}
+
+func (e *display_resume_drawing_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *display_resume_drawing_event) Source() *Display {
+	return (*Display)(e.source)
+}
+
+/* -- Touch Begin -- */
+
+type TouchBeginEvent interface {
+	touch_begin()
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_begin_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_begin_event) touch_begin() {}
+
+func (e *touch_begin_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_begin_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_begin_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_begin_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_begin_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_begin_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_begin_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_begin_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_begin_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch End -- */
+
+type TouchEndEvent interface {
+	touch_end()
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_end_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_end_event) touch_end() {}
+
+func (e *touch_end_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_end_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_end_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_end_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_end_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_end_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_end_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_end_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_end_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch Move -- */
+
+type TouchMoveEvent interface {
+	touch_move()
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_move_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_move_event) touch_move() {}
+
+func (e *touch_move_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_move_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_move_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_move_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_move_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_move_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_move_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_move_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_move_event) Primary() bool {
+	return bool(e.primary)
+}
+
+/* -- Touch Cancel -- */
+
+type TouchCancelEvent interface {
+	touch_cancel()
+	Timestamp() float64
+	Source() *TouchInput
+	Display() *Display
+	Id() int
+	X() float32
+	Y() float32
+	Dx() float32
+	Dy() float32
+	Primary() bool
+}
+
+type touch_cancel_event C.struct_ALLEGRO_TOUCH_EVENT
+
+func (e *touch_cancel_event) touch_cancel() {}
+
+func (e *touch_cancel_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *touch_cancel_event) Source() *TouchInput {
+	return (*TouchInput)(e.source)
+}
+
+func (e *touch_cancel_event) Display() *Display {
+	return (*Display)(e.display)
+}
+
+func (e *touch_cancel_event) Id() int {
+	return int(e.id)
+}
+
+func (e *touch_cancel_event) X() float32 {
+	return float32(e.x)
+}
+
+func (e *touch_cancel_event) Y() float32 {
+	return float32(e.y)
+}
+
+func (e *touch_cancel_event) Dx() float32 {
+	return float32(e.dx)
+}
+
+func (e *touch_cancel_event) Dy() float32 {
+	return float32(e.dy)
+}
+
+func (e *touch_cancel_event) Primary() bool {
+	return bool(e.primary)
+}
+
 /* -- Audio Stream Fragment -- */
 
 type AudioStreamFragment interface {
@@ -0,0 +1,133 @@
+package allegro
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+/*
+static void wake_queue(ALLEGRO_EVENT_SOURCE *source) {
+	ALLEGRO_EVENT event;
+	event.user.type = ALLEGRO_GET_EVENT_TYPE('G', 'W', 'a', 'k');
+	event.user.data1 = 0;
+	event.user.data2 = 0;
+	event.user.data3 = 0;
+	event.user.data4 = 0;
+	al_emit_user_event(source, &event, NULL);
+}
+*/
+import "C"
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// The number of events a stream buffers before it stops taking events out of
+// the queue.
+const eventStreamBuffer = 64
+
+// eventStream pumps events from a queue into a channel. A private user event
+// source registered with the queue is used to wake the pumping goroutine when
+// the stream is stopped, since al_wait_for_event() can't be interrupted
+// otherwise.
+type eventStream struct {
+	source   *C.ALLEGRO_EVENT_SOURCE
+	stopping chan struct{}
+	finished chan struct{}
+	once     sync.Once
+}
+
+var queueStreams = make(map[*EventQueue][]*eventStream)
+
+func (s *eventStream) stop() {
+	s.once.Do(func() {
+		close(s.stopping)
+		C.wake_queue(s.source)
+	})
+}
+
+// Stream() starts a goroutine that takes events out of the queue and sends
+// them on the returned channel, so that a game can select over Allegro events
+// alongside its own channels. Waiting on an event queue doesn't need to happen
+// on any particular OS thread, so the goroutine is not locked to one; events
+// must still be handled on the thread that owns the display wherever they
+// touch it.
+//
+// Unlike those returned by Poll(), each event has its own memory and stays
+// valid after the next one is received. The channel is closed once ctx is
+// done or the queue is destroyed. A queue being streamed should not be read
+// in any other way, including by a second stream.
+func (queue *EventQueue) Stream(ctx context.Context) <-chan interface{} {
+	s := &eventStream{
+		source:   (*C.ALLEGRO_EVENT_SOURCE)(C.malloc(C.sizeof_ALLEGRO_EVENT_SOURCE)),
+		stopping: make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+	C.al_init_user_event_source(s.source)
+	C.al_register_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), s.source)
+
+	queueEventsLock.Lock()
+	queueStreams[queue] = append(queueStreams[queue], s)
+	queueEventsLock.Unlock()
+
+	ch := make(chan interface{}, eventStreamBuffer)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.stop()
+		case <-s.finished:
+		}
+	}()
+	go queue.pump(s, ch)
+	return ch
+}
+
+// Chan() is like Stream() with a context that is never done; the channel is
+// closed when the queue is destroyed.
+func (queue *EventQueue) Chan() <-chan interface{} {
+	return queue.Stream(context.Background())
+}
+
+func (queue *EventQueue) pump(s *eventStream, ch chan<- interface{}) {
+	defer func() {
+		C.al_unregister_event_source((*C.ALLEGRO_EVENT_QUEUE)(queue), s.source)
+		C.al_destroy_user_event_source(s.source)
+		C.free(unsafe.Pointer(s.source))
+		queueEventsLock.Lock()
+		streams := queueStreams[queue]
+		for i, other := range streams {
+			if other == s {
+				queueStreams[queue] = append(streams[:i:i], streams[i+1:]...)
+				break
+			}
+		}
+		if len(queueStreams[queue]) == 0 {
+			delete(queueStreams, queue)
+		}
+		queueEventsLock.Unlock()
+		close(ch)
+		close(s.finished)
+	}()
+	for {
+		event := new(Event)
+		C.al_wait_for_event((*C.ALLEGRO_EVENT_QUEUE)(queue), (*C.ALLEGRO_EVENT)(unsafe.Pointer(event)))
+		if (*C.ALLEGRO_ANY_EVENT)(unsafe.Pointer(event)).source == s.source {
+			return
+		}
+		select {
+		case ch <- event.cast():
+		case <-s.stopping:
+			return
+		}
+	}
+}
+
+// stopStreams() stops the queue's streams and waits for their goroutines to
+// finish with the queue.
+func (queue *EventQueue) stopStreams() {
+	queueEventsLock.Lock()
+	streams := append([]*eventStream(nil), queueStreams[queue]...)
+	queueEventsLock.Unlock()
+	for _, s := range streams {
+		s.stop()
+		<-s.finished
+	}
+}
@@ -0,0 +1,312 @@
+// Package events converts the events returned by an allegro.EventQueue into
+// plain structs. The allegro package hands out events as interfaces backed by
+// the queue's event memory, which Poll() and Wait() reuse; the structs here
+// are copies, so they can be kept, compared, sent on channels and built by
+// hand in tests:
+//
+//	switch e := events.From(queue.Wait()).(type) {
+//	case events.KeyDown:
+//		if e.KeyCode == allegro.KEY_ESCAPE {
+//			running = false
+//		}
+//	case events.MouseMove:
+//		player.Aim(e.X, e.Y)
+//	case events.DisplayResize:
+//		e.Display.AcknowledgeResize()
+//	}
+package events
+
+import (
+	"context"
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+/* -- Joystick -- */
+
+type JoystickAxis struct {
+	Timestamp float64
+	Joystick  *allegro.Joystick
+	Stick     int
+	Axis      int
+	Pos       float32
+}
+
+type JoystickButtonDown struct {
+	Timestamp float64
+	Joystick  *allegro.Joystick
+	Button    int
+}
+
+type JoystickButtonUp struct {
+	Timestamp float64
+	Joystick  *allegro.Joystick
+	Button    int
+}
+
+type JoystickConfiguration struct {
+	Timestamp float64
+}
+
+/* -- Keyboard -- */
+
+type KeyDown struct {
+	Timestamp float64
+	KeyCode   allegro.KeyCode
+	Display   *allegro.Display
+}
+
+type KeyUp struct {
+	Timestamp float64
+	KeyCode   allegro.KeyCode
+	Display   *allegro.Display
+}
+
+type KeyChar struct {
+	Timestamp float64
+	KeyCode   allegro.KeyCode
+	Unichar   rune
+	Modifiers allegro.KeyModifier
+	Repeat    bool
+	Display   *allegro.Display
+}
+
+/* -- Mouse -- */
+
+// MouseMove is produced for both mouse axes events and warps; Warped tells
+// them apart. Z and W are the wheel positions.
+type MouseMove struct {
+	Timestamp      float64
+	X, Y, Z, W     int
+	DX, DY, DZ, DW int
+	Warped         bool
+	Display        *allegro.Display
+}
+
+type MouseButtonDown struct {
+	Timestamp  float64
+	X, Y, Z, W int
+	Button     uint
+	Display    *allegro.Display
+}
+
+type MouseButtonUp struct {
+	Timestamp  float64
+	X, Y, Z, W int
+	Button     uint
+	Display    *allegro.Display
+}
+
+type MouseEnter struct {
+	Timestamp  float64
+	X, Y, Z, W int
+	Display    *allegro.Display
+}
+
+type MouseLeave struct {
+	Timestamp  float64
+	X, Y, Z, W int
+	Display    *allegro.Display
+}
+
+/* -- Touch -- */
+
+// Touch holds the fields shared by the touch events.
+type Touch struct {
+	Timestamp float64
+	ID        int
+	X, Y      float32
+	DX, DY    float32
+	Primary   bool
+	Display   *allegro.Display
+}
+
+type TouchBegin struct{ Touch }
+
+type TouchEnd struct{ Touch }
+
+type TouchMove struct{ Touch }
+
+type TouchCancel struct{ Touch }
+
+/* -- Timer -- */
+
+type Timer struct {
+	Timestamp float64
+	Timer     *allegro.Timer
+	Count     int64
+}
+
+/* -- Display -- */
+
+type DisplayExpose struct {
+	Timestamp     float64
+	Display       *allegro.Display
+	X, Y          int
+	Width, Height int
+}
+
+type DisplayResize struct {
+	Timestamp     float64
+	Display       *allegro.Display
+	X, Y          int
+	Width, Height int
+}
+
+type DisplayClose struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayLost struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayFound struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplaySwitchOut struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplaySwitchIn struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayOrientation struct {
+	Timestamp   float64
+	Display     *allegro.Display
+	Orientation allegro.DisplayOrientation
+}
+
+type DisplayHaltDrawing struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayResumeDrawing struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayConnected struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+type DisplayDisconnected struct {
+	Timestamp float64
+	Display   *allegro.Display
+}
+
+// From() converts an event returned by an allegro.EventQueue into the
+// matching struct from this package. Events without a struct here, such as
+// user events and those registered by addons, are returned unchanged, as is
+// nil.
+func From(ev interface{}) interface{} {
+	switch e := ev.(type) {
+	case allegro.JoystickAxisEvent:
+		return JoystickAxis{e.Timestamp(), e.Id(), e.Stick(), e.Axis(), e.Pos()}
+	case allegro.JoystickButtonDownEvent:
+		return JoystickButtonDown{e.Timestamp(), e.Id(), e.Button()}
+	case allegro.JoystickButtonUpEvent:
+		return JoystickButtonUp{e.Timestamp(), e.Id(), e.Button()}
+	case allegro.JoystickConfigurationEvent:
+		return JoystickConfiguration{e.Timestamp()}
+
+	case allegro.KeyDownEvent:
+		return KeyDown{e.Timestamp(), e.KeyCode(), e.Display()}
+	case allegro.KeyUpEvent:
+		return KeyUp{e.Timestamp(), e.KeyCode(), e.Display()}
+	case allegro.KeyCharEvent:
+		return KeyChar{e.Timestamp(), e.KeyCode(), rune(e.Unichar()), e.Modifiers(), e.Repeat(), e.Display()}
+
+	case allegro.MouseAxesEvent:
+		return MouseMove{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Dx(), e.Dy(), e.Dz(), e.Dw(), false, e.Display()}
+	case allegro.MouseWarpedEvent:
+		return MouseMove{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Dx(), e.Dy(), e.Dz(), e.Dw(), true, e.Display()}
+	case allegro.MouseButtonDownEvent:
+		return MouseButtonDown{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Button(), e.Display()}
+	case allegro.MouseButtonUpEvent:
+		return MouseButtonUp{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Button(), e.Display()}
+	case allegro.MouseEnterDisplayEvent:
+		return MouseEnter{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Display()}
+	case allegro.MouseLeaveDisplayEvent:
+		return MouseLeave{e.Timestamp(), e.X(), e.Y(), e.Z(), e.W(), e.Display()}
+
+	case allegro.TouchBeginEvent:
+		return TouchBegin{Touch{e.Timestamp(), e.Id(), e.X(), e.Y(), e.Dx(), e.Dy(), e.Primary(), e.Display()}}
+	case allegro.TouchEndEvent:
+		return TouchEnd{Touch{e.Timestamp(), e.Id(), e.X(), e.Y(), e.Dx(), e.Dy(), e.Primary(), e.Display()}}
+	case allegro.TouchMoveEvent:
+		return TouchMove{Touch{e.Timestamp(), e.Id(), e.X(), e.Y(), e.Dx(), e.Dy(), e.Primary(), e.Display()}}
+	case allegro.TouchCancelEvent:
+		return TouchCancel{Touch{e.Timestamp(), e.Id(), e.X(), e.Y(), e.Dx(), e.Dy(), e.Primary(), e.Display()}}
+
+	case allegro.TimerEvent:
+		return Timer{e.Timestamp(), e.Source(), e.Count()}
+
+	case allegro.DisplayExposeEvent:
+		return DisplayExpose{e.Timestamp(), e.Source(), e.X(), e.Y(), e.Width(), e.Height()}
+	case allegro.DisplayResizeEvent:
+		return DisplayResize{e.Timestamp(), e.Source(), e.X(), e.Y(), e.Width(), e.Height()}
+	case allegro.DisplayCloseEvent:
+		return DisplayClose{e.Timestamp(), e.Source()}
+	case allegro.DisplayLostEvent:
+		return DisplayLost{e.Timestamp(), e.Source()}
+	case allegro.DisplayFoundEvent:
+		return DisplayFound{e.Timestamp(), e.Source()}
+	case allegro.DisplaySwitchOutEvent:
+		return DisplaySwitchOut{e.Timestamp(), e.Source()}
+	case allegro.DisplaySwitchInEvent:
+		return DisplaySwitchIn{e.Timestamp(), e.Source()}
+	case allegro.DisplayOrientationEvent:
+		return DisplayOrientation{e.Timestamp(), e.Source(), e.Orientation()}
+	case allegro.DisplayHaltDrawingEvent:
+		return DisplayHaltDrawing{e.Timestamp(), e.Source()}
+	case allegro.DisplayResumeDrawingEvent:
+		return DisplayResumeDrawing{e.Timestamp(), e.Source()}
+	case allegro.DisplayConnectedEvent:
+		return DisplayConnected{e.Timestamp(), e.Source()}
+	case allegro.DisplayDisconnectedEvent:
+		return DisplayDisconnected{e.Timestamp(), e.Source()}
+	}
+	return ev
+}
+
+// Wait() waits for the next event on the queue and converts it with From().
+func Wait(queue *allegro.EventQueue) interface{} {
+	return From(queue.Wait())
+}
+
+// Poll() takes the next event off the queue, if there is one, and converts it
+// with From().
+func Poll(queue *allegro.EventQueue) (interface{}, bool) {
+	ev, ok := queue.Poll()
+	if !ok {
+		return nil, false
+	}
+	return From(ev), true
+}
+
+// Stream() is like allegro.EventQueue.Stream(), but the events sent on the
+// returned channel have been converted with From().
+func Stream(ctx context.Context, queue *allegro.EventQueue) <-chan interface{} {
+	in := queue.Stream(ctx)
+	out := make(chan interface{}, cap(in))
+	go func() {
+		defer close(out)
+		for ev := range in {
+			select {
+			case out <- From(ev):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
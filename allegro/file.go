@@ -52,13 +52,24 @@ func OpenFile(path string, mode FileMode) (*File, error) {
 	mode_ := C.CString(mode.String())
 	defer freeString(path_)
 	defer freeString(mode_)
-	f := C.al_fopen(path_, mode_)
-	if f == nil {
-		return nil, fmt.Errorf("failed to open File '%s'", path)
+	var f *C.ALLEGRO_FILE
+	err := checkErrno("al_fopen", path, func() bool {
+		f = C.al_fopen(path_, mode_)
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*File)(f), nil
 }
 
+// Set the file interface for the calling thread back to the default one,
+// which uses the C library's stdio functions, e.g. after the PhysicsFS addon
+// replaced it.
+func UseStandardFileInterface() {
+	C.al_set_standard_file_interface()
+}
+
 // Make a temporary randomly named file given a filename 'template'.
 func MakeTempFile(template string) string {
 	template_ := C.CString(template)
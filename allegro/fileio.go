@@ -0,0 +1,296 @@
+package allegro
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+//
+// extern bool go_file_fclose(ALLEGRO_FILE *f);
+// extern size_t go_file_fread(ALLEGRO_FILE *f, void *ptr, size_t size);
+// extern size_t go_file_fwrite(ALLEGRO_FILE *f, void *ptr, size_t size);
+// extern bool go_file_fflush(ALLEGRO_FILE *f);
+// extern int64_t go_file_ftell(ALLEGRO_FILE *f);
+// extern bool go_file_fseek(ALLEGRO_FILE *f, int64_t offset, int whence);
+// extern bool go_file_feof(ALLEGRO_FILE *f);
+// extern int go_file_ferror(ALLEGRO_FILE *f);
+// extern char *go_file_ferrmsg(ALLEGRO_FILE *f);
+// extern void go_file_fclearerr(ALLEGRO_FILE *f);
+// extern off_t go_file_fsize(ALLEGRO_FILE *f);
+import "C"
+import (
+	"errors"
+	"io"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+// goFile is the state behind an ALLEGRO_FILE created by OpenReader(),
+// OpenWriter() or opened through UseFS(). c, if set, is closed with the file.
+// The file's userdata points to C memory holding a cgo.Handle for it.
+type goFile struct {
+	r      io.Reader
+	w      io.Writer
+	c      io.Closer
+	pos    int64
+	eof    bool
+	err    error
+	errmsg *C.char
+}
+
+var (
+	goFileInterface     *C.ALLEGRO_FILE_INTERFACE
+	goFileInterfaceOnce sync.Once
+	emptyErrmsg         *C.char
+)
+
+func fileInterface() *C.ALLEGRO_FILE_INTERFACE {
+	goFileInterfaceOnce.Do(func() {
+		vt := (*C.ALLEGRO_FILE_INTERFACE)(C.calloc(1, C.sizeof_ALLEGRO_FILE_INTERFACE))
+		vt.fi_fclose = (*[0]byte)(C.go_file_fclose)
+		vt.fi_fread = (*[0]byte)(C.go_file_fread)
+		vt.fi_fwrite = (*[0]byte)(C.go_file_fwrite)
+		vt.fi_fflush = (*[0]byte)(C.go_file_fflush)
+		vt.fi_ftell = (*[0]byte)(C.go_file_ftell)
+		vt.fi_fseek = (*[0]byte)(C.go_file_fseek)
+		vt.fi_feof = (*[0]byte)(C.go_file_feof)
+		vt.fi_ferror = (*[0]byte)(C.go_file_ferror)
+		vt.fi_ferrmsg = (*[0]byte)(C.go_file_ferrmsg)
+		vt.fi_fclearerr = (*[0]byte)(C.go_file_fclearerr)
+		vt.fi_fsize = (*[0]byte)(C.go_file_fsize)
+		goFileInterface = vt
+		emptyErrmsg = C.CString("")
+	})
+	return goFileInterface
+}
+
+func openGoFile(gf *goFile) (*File, error) {
+	vt := fileInterface()
+	userdata := (*cgo.Handle)(C.malloc(C.size_t(unsafe.Sizeof(cgo.Handle(0)))))
+	*userdata = cgo.NewHandle(gf)
+	f := C.al_create_file_handle(vt, unsafe.Pointer(userdata))
+	if f == nil {
+		userdata.Delete()
+		C.free(unsafe.Pointer(userdata))
+		return nil, errors.New("failed to create file handle")
+	}
+	return (*File)(f), nil
+}
+
+// OpenReader() returns a read-only File that reads from r, so that anything
+// that loads from a File, such as File.LoadBitmap(), can be loaded from
+// embedded data, network responses and the like. If r is also an io.Seeker,
+// the file supports seeking and Size(); some loaders need that. Closing the
+// File does not close r, but r must stay usable until then, which for fonts
+// and audio streams is as long as they exist.
+func OpenReader(r io.Reader) (*File, error) {
+	return openGoFile(&goFile{r: r})
+}
+
+// OpenWriter() returns a write-only File that writes to w, e.g. for saving a
+// bitmap with File.SaveBitmap() into a buffer. If w has a Flush() method, Flush() on the
+// File calls it.
+func OpenWriter(w io.Writer) (*File, error) {
+	return openGoFile(&goFile{w: w})
+}
+
+// LoadBitmapReader() loads a bitmap from r. ident is as for File.LoadBitmap().
+func LoadBitmapReader(r io.Reader, ident string) (*Bitmap, error) {
+	f, err := OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.LoadBitmap(ident)
+}
+
+// SaveWriter() saves the bitmap to w, e.g. into a bytes.Buffer for sending
+// over the network. ident is as for File.SaveBitmap().
+func (bmp *Bitmap) SaveWriter(w io.Writer, ident string) error {
+	f, err := OpenWriter(w)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.SaveBitmap(ident, bmp)
+}
+
+// LoadConfigReader() reads a configuration file from r.
+func LoadConfigReader(r io.Reader) (*Config, error) {
+	f, err := OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.LoadConfig()
+}
+
+// SaveWriter() writes the config to w in the same format as Save().
+func (cfg *Config) SaveWriter(w io.Writer) error {
+	f, err := OpenWriter(w)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.SaveConfig(cfg)
+}
+
+func goFileOf(f *C.ALLEGRO_FILE) *goFile {
+	h := *(*cgo.Handle)(C.al_get_file_userdata(f))
+	return h.Value().(*goFile)
+}
+
+func (gf *goFile) setError(err error) {
+	gf.err = err
+	if gf.errmsg != nil {
+		C.free(unsafe.Pointer(gf.errmsg))
+		gf.errmsg = nil
+	}
+}
+
+//export go_file_fclose
+func go_file_fclose(f *C.ALLEGRO_FILE) C.bool {
+	userdata := (*cgo.Handle)(C.al_get_file_userdata(f))
+	gf := userdata.Value().(*goFile)
+	gf.setError(nil)
+	ok := true
+	if gf.c != nil {
+		if err := gf.c.Close(); err != nil {
+			gf.setError(err)
+			ok = false
+		}
+	}
+	userdata.Delete()
+	C.free(unsafe.Pointer(userdata))
+	return C.bool(ok)
+}
+
+//export go_file_fread
+func go_file_fread(f *C.ALLEGRO_FILE, ptr unsafe.Pointer, size C.size_t) C.size_t {
+	gf := goFileOf(f)
+	if gf.r == nil {
+		gf.setError(errors.New("file is not open for reading"))
+		return 0
+	}
+	if size == 0 {
+		return 0
+	}
+	n, err := io.ReadFull(gf.r, unsafe.Slice((*byte)(ptr), int(size)))
+	gf.pos += int64(n)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		gf.eof = true
+	default:
+		gf.setError(err)
+	}
+	return C.size_t(n)
+}
+
+//export go_file_fwrite
+func go_file_fwrite(f *C.ALLEGRO_FILE, ptr unsafe.Pointer, size C.size_t) C.size_t {
+	gf := goFileOf(f)
+	if gf.w == nil {
+		gf.setError(errors.New("file is not open for writing"))
+		return 0
+	}
+	if size == 0 {
+		return 0
+	}
+	n, err := gf.w.Write(unsafe.Slice((*byte)(ptr), int(size)))
+	gf.pos += int64(n)
+	if err != nil {
+		gf.setError(err)
+	}
+	return C.size_t(n)
+}
+
+//export go_file_fflush
+func go_file_fflush(f *C.ALLEGRO_FILE) C.bool {
+	gf := goFileOf(f)
+	if fl, ok := gf.w.(interface{ Flush() error }); ok {
+		if err := fl.Flush(); err != nil {
+			gf.setError(err)
+			return false
+		}
+	}
+	return true
+}
+
+//export go_file_ftell
+func go_file_ftell(f *C.ALLEGRO_FILE) C.int64_t {
+	return C.int64_t(goFileOf(f).pos)
+}
+
+//export go_file_fseek
+func go_file_fseek(f *C.ALLEGRO_FILE, offset C.int64_t, whence C.int) C.bool {
+	gf := goFileOf(f)
+	var s io.Seeker
+	if gf.r != nil {
+		s, _ = gf.r.(io.Seeker)
+	} else {
+		s, _ = gf.w.(io.Seeker)
+	}
+	if s == nil {
+		gf.setError(errors.New("file is not seekable"))
+		return false
+	}
+	// ALLEGRO_SEEK_SET, _CUR and _END match io.SeekStart, io.SeekCurrent
+	// and io.SeekEnd.
+	pos, err := s.Seek(int64(offset), int(whence))
+	if err != nil {
+		gf.setError(err)
+		return false
+	}
+	gf.pos = pos
+	gf.eof = false
+	return true
+}
+
+//export go_file_feof
+func go_file_feof(f *C.ALLEGRO_FILE) C.bool {
+	return C.bool(goFileOf(f).eof)
+}
+
+//export go_file_ferror
+func go_file_ferror(f *C.ALLEGRO_FILE) C.int {
+	if goFileOf(f).err != nil {
+		return 1
+	}
+	return 0
+}
+
+//export go_file_ferrmsg
+func go_file_ferrmsg(f *C.ALLEGRO_FILE) *C.char {
+	gf := goFileOf(f)
+	if gf.err == nil {
+		return emptyErrmsg
+	}
+	if gf.errmsg == nil {
+		gf.errmsg = C.CString(gf.err.Error())
+	}
+	return gf.errmsg
+}
+
+//export go_file_fclearerr
+func go_file_fclearerr(f *C.ALLEGRO_FILE) {
+	gf := goFileOf(f)
+	gf.setError(nil)
+	gf.eof = false
+}
+
+//export go_file_fsize
+func go_file_fsize(f *C.ALLEGRO_FILE) C.off_t {
+	gf := goFileOf(f)
+	s, ok := gf.r.(io.Seeker)
+	if !ok {
+		return -1
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+	if _, err := s.Seek(gf.pos, io.SeekStart); err != nil {
+		gf.setError(err)
+		return -1
+	}
+	return C.off_t(end)
+}
@@ -34,6 +34,19 @@ func Uninstall() {
 	C.al_shutdown_font_addon()
 }
 
+// Addon installs the font addon through allegro.Init().
+var Addon = allegro.Addon{
+	Name:      "font",
+	Install:   func() error { Install(); return nil },
+	Uninstall: Uninstall,
+	Installed: IsInstalled,
+}
+
+// Returns true if the font addon is initialized, otherwise returns false.
+func IsInstalled() bool {
+	return bool(C.al_is_font_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
@@ -47,9 +60,13 @@ func Version() (major, minor, revision, release uint8) {
 
 // Creates a monochrome bitmap font (8x8 pixels per character).
 func Builtin() (*Font, error) {
-	f := C.al_create_builtin_font()
-	if f == nil {
-		return nil, errors.New("failed to create builtin font")
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_create_builtin_font", "", func() bool {
+		f = C.al_create_builtin_font()
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*Font)(f), nil
 }
@@ -59,9 +76,13 @@ func Builtin() (*Font, error) {
 func LoadFont(filename string, size, flags int) (*Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_font(filename_, C.int(size), C.int(flags))
-	if f == nil {
-		return nil, fmt.Errorf("failed to load font '%s'", filename)
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_font", filename, func() bool {
+		f = C.al_load_font(filename_, C.int(size), C.int(flags))
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	font := (*Font)(f)
 	//runtime.SetFinalizer(font, font.Destroy)
@@ -75,9 +96,13 @@ func LoadFont(filename string, size, flags int) (*Font, error) {
 func LoadBitmapFont(filename string) (*Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_bitmap_font(filename_)
-	if f == nil {
-		return nil, fmt.Errorf("failed to load bitmap font '%s'", filename)
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_bitmap_font", filename, func() bool {
+		f = C.al_load_bitmap_font(filename_)
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	font := (*Font)(f)
 	//runtime.SetFinalizer(font, font.Destroy)
@@ -108,28 +133,20 @@ func GrabFontFromBitmap(bmp *allegro.Bitmap, ranges [][2]int) (*Font, error) {
 // Writes the NUL-terminated string text onto the target bitmap at position x,
 // y, using the specified font.
 func DrawText(font *Font, color allegro.Color, x, y float32, flags DrawFlags, text string) {
-	text_ := C.CString(text)
-	defer C.free_string(text_)
-	C.al_draw_text((*C.ALLEGRO_FONT)(font),
-		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))), // is there an easier way to get this converted?
-		C.float(x),
-		C.float(y),
-		C.int(flags),
-		text_)
+	drawGoString(font, color, x, y, flags, text)
+}
+
+// DrawTextCached() is like DrawText(), but holds bitmap drawing through the
+// given state cache, so that consecutive text and bitmap draws are submitted
+// together.
+func DrawTextCached(s *allegro.StateCache, font *Font, color allegro.Color, x, y float32, flags DrawFlags, text string) {
+	s.PrepareDraw()
+	DrawText(font, color, x, y, flags, text)
 }
 
 // Like al_draw_text, but justifies the string to the region x1-x2.
 func DrawJustifiedText(font *Font, color allegro.Color, x1, x2, y, diff float32, flags DrawFlags, text string) {
-	text_ := C.CString(text)
-	defer C.free_string(text_)
-	C.al_draw_justified_text((*C.ALLEGRO_FONT)(font),
-		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))), // is there an easier way to get this converted?
-		C.float(x1),
-		C.float(x2),
-		C.float(y),
-		C.float(diff),
-		C.int(flags),
-		text_)
+	drawJustifiedGoString(font, color, x1, x2, y, diff, flags, text)
 }
 
 func DrawTextf(font *Font, color allegro.Color, x, y float32, flags DrawFlags, format string, a ...interface{}) {
@@ -183,19 +200,117 @@ func (f *Font) Descent() int {
 
 // Calculates the length of a string in a particular font, in pixels.
 func (f *Font) TextWidth(text string) int {
-	text_ := C.CString(text)
-	defer C.free_string(text_)
-	return int(C.al_get_text_width((*C.ALLEGRO_FONT)(f), text_))
+	return f.goStringWidth(text)
 }
 
 // Sometimes, the al_get_text_width and al_get_font_line_height functions are
 // not enough for exact text placement, so this function returns some
 // additional information.
 func (f *Font) TextDimensions(text string) (bbx, bby, bbw, bbh int) {
+	return f.goStringDimensions(text)
+}
+
+// Sets a font which is used instead if a character is not present. Can be
+// chained, but make sure there is no loop as that would crash the
+// application! Pass nil to remove a fallback font again.
+func (f *Font) SetFallback(fallback *Font) {
+	C.al_set_fallback_font((*C.ALLEGRO_FONT)(f), (*C.ALLEGRO_FONT)(fallback))
+}
+
+// Retrieves the fallback font for this font or nil.
+func (f *Font) Fallback() *Font {
+	return (*Font)(C.al_get_fallback_font((*C.ALLEGRO_FONT)(f)))
+}
+
+// SetFallbacks() chains fonts so that each one falls back to the next, e.g.
+// a game font, then a font with wider Unicode coverage, then Builtin().
+func SetFallbacks(fonts ...*Font) {
+	for i := 0; i+1 < len(fonts); i++ {
+		fonts[i].SetFallback(fonts[i+1])
+	}
+}
+
+// Ranges() returns the unicode ranges of the font, as first and last code
+// point of each range, in the same form as GrabFontFromBitmap() accepts. The
+// ranges of a fallback font are not included.
+func (f *Font) Ranges() [][2]int {
+	n := int(C.al_get_font_ranges((*C.ALLEGRO_FONT)(f), 0, nil))
+	if n <= 0 {
+		return nil
+	}
+	cranges := make([]C.int, n*2)
+	n = int(C.al_get_font_ranges((*C.ALLEGRO_FONT)(f), C.int(n), &cranges[0]))
+	ranges := make([][2]int, n)
+	for i := range ranges {
+		ranges[i] = [2]int{int(cranges[i*2]), int(cranges[i*2+1])}
+	}
+	return ranges
+}
+
+// Passed as the previous codepoint to Font.GlyphAdvance() to get the advance
+// without kerning.
+const NO_KERNING = C.ALLEGRO_NO_KERNING
+
+// Draws the glyph that corresponds with codepoint in the given color using the
+// given font. If font does not have such a glyph, nothing will be drawn.
+func DrawGlyph(font *Font, color allegro.Color, x, y float32, codepoint rune) {
+	C.al_draw_glyph((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x),
+		C.float(y),
+		C.int(codepoint))
+}
+
+// This function returns the width in pixels of the glyph that corresponds with
+// codepoint in the font font. Returns zero if the font does not have such a
+// glyph.
+func (f *Font) GlyphWidth(codepoint rune) int {
+	return int(C.al_get_glyph_width((*C.ALLEGRO_FONT)(f), C.int(codepoint)))
+}
+
+// Sometimes, the al_get_glyph_width or al_get_glyph_advance functions are not
+// enough for exact glyph placement, so this function returns some additional
+// information, particularly if you want to draw the font vertically. Returns
+// false if the font has no such glyph.
+func (f *Font) GlyphDimensions(codepoint rune) (bbx, bby, bbw, bbh int, ok bool) {
 	var cbbx, cbby, cbbw, cbbh C.int
-	text_ := C.CString(text)
-	defer C.free_string(text_)
-	C.al_get_text_dimensions((*C.ALLEGRO_FONT)(f), text_,
-		&cbbx, &cbby, &cbbw, &cbbh)
-	return int(cbbx), int(cbby), int(cbbw), int(cbbh)
+	ok = bool(C.al_get_glyph_dimensions((*C.ALLEGRO_FONT)(f), C.int(codepoint),
+		&cbbx, &cbby, &cbbw, &cbbh))
+	return int(cbbx), int(cbby), int(cbbw), int(cbbh), ok
+}
+
+// This function returns by how much the x position should be advanced for
+// left to right text drawing when the glyph that corresponds to codepoint1 has
+// been drawn, and the glyph that corresponds to codepoint2 will be the next to
+// be drawn. This takes into consideration the horizontal advance width of the
+// glyph that corresponds with codepoint1 as well as the kerning between the
+// glyphs of codepoint1 and codepoint2.
+func (f *Font) GlyphAdvance(codepoint1, codepoint2 rune) int {
+	return int(C.al_get_glyph_advance((*C.ALLEGRO_FONT)(f), C.int(codepoint1), C.int(codepoint2)))
+}
+
+// GlyphBitmap() renders a single glyph into a new bitmap that is just large
+// enough to hold it, for effects that treat characters individually, such as
+// per-character animation or outlines drawn by repeating the glyph at
+// offsets. To draw the glyph where DrawGlyph() would at (x, y), draw the
+// bitmap at (x+dx, y+dy). The bitmap is created with the current new bitmap
+// flags and must be destroyed by the caller.
+func (f *Font) GlyphBitmap(codepoint rune, color allegro.Color) (bmp *allegro.Bitmap, dx, dy int, err error) {
+	bbx, bby, bbw, bbh, ok := f.GlyphDimensions(codepoint)
+	if !ok {
+		return nil, 0, 0, fmt.Errorf("font has no glyph for %U", codepoint)
+	}
+	if bbw <= 0 || bbh <= 0 {
+		// Whitespace has an advance but nothing to draw.
+		bbw, bbh = 1, 1
+	}
+	bmp = allegro.CreateBitmap(bbw, bbh)
+	if bmp == nil {
+		return nil, 0, 0, errors.New("failed to create glyph bitmap")
+	}
+	bmp.AsTarget(func() {
+		allegro.ClearToColor(allegro.MapRGBA(0, 0, 0, 0))
+		DrawGlyph(f, color, float32(-bbx), float32(-bby), codepoint)
+	})
+	return bmp, bbx, bby, nil
 }
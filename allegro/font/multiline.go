@@ -0,0 +1,83 @@
+package font
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_font.h>
+//
+// extern bool go_font_multiline_line(int line_num, char *line, int size, void *extra);
+import "C"
+import (
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// Like al_draw_text, but this function supports drawing multiple lines of
+// text. It will break text in lines based on its contents and the max_width
+// parameter. The lines are then layed out vertically depending on the
+// line_height parameter and drawn each as if al_draw_text was called on them.
+//
+// A max_width of 0 disables wrapping, so only newlines break the text, and a
+// line_height of 0 uses the font's line height.
+func DrawMultilineText(font *Font, color allegro.Color, x, y, max_width, line_height float32, flags DrawFlags, text string) {
+	text_ := C.CString(text)
+	defer C.free(unsafe.Pointer(text_))
+	C.al_draw_multiline_text((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x),
+		C.float(y),
+		C.float(max_width),
+		C.float(line_height),
+		C.int(flags),
+		text_)
+}
+
+// Formatted text output, using a printf() style format string. All parameters
+// have the same meaning as with al_draw_multiline_text otherwise.
+func DrawMultilineTextf(font *Font, color allegro.Color, x, y, max_width, line_height float32, flags DrawFlags, format string, a ...interface{}) {
+	DrawMultilineText(font, color, x, y, max_width, line_height, flags, fmt.Sprintf(format, a...))
+}
+
+// MultilineText() splits text into the lines DrawMultilineText() would draw
+// for the given max_width, e.g. to measure a text box before drawing it.
+func (f *Font) MultilineText(max_width float32, text string) []string {
+	var lines []string
+	text_ := C.CString(text)
+	defer C.free(unsafe.Pointer(text_))
+	extra := (*cgo.Handle)(C.malloc(C.size_t(unsafe.Sizeof(cgo.Handle(0)))))
+	*extra = cgo.NewHandle(&lines)
+	defer func() {
+		extra.Delete()
+		C.free(unsafe.Pointer(extra))
+	}()
+	C.al_do_multiline_text((*C.ALLEGRO_FONT)(f), C.float(max_width), text_,
+		(*[0]byte)(C.go_font_multiline_line), unsafe.Pointer(extra))
+	return lines
+}
+
+// MultilineDimensions() returns the size of the box DrawMultilineText() would
+// fill for the given max_width and line_height, where a line_height of 0 uses
+// the font's line height.
+func (f *Font) MultilineDimensions(max_width, line_height float32, text string) (w, h int) {
+	lines := f.MultilineText(max_width, text)
+	for _, line := range lines {
+		if lw := f.TextWidth(line); lw > w {
+			w = lw
+		}
+	}
+	if line_height == 0 {
+		line_height = float32(f.LineHeight())
+	}
+	if len(lines) > 0 {
+		h = int(line_height*float32(len(lines)-1)) + f.LineHeight()
+	}
+	return w, h
+}
+
+//export go_font_multiline_line
+func go_font_multiline_line(line_num C.int, line *C.char, size C.int, extra unsafe.Pointer) C.bool {
+	lines := (*cgo.Handle)(extra).Value().(*[]string)
+	*lines = append(*lines, C.GoStringN(line, size))
+	return true
+}
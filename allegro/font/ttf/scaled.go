@@ -0,0 +1,120 @@
+package ttf
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/font"
+	"math"
+)
+
+// ScaledFont is a TTF font whose pixel size follows the scale of the monitor
+// its display is on, so that text is rendered at native resolution rather than
+// stretched. Sizes are given in pixels at allegro.BASE_DPI and multiplied by the
+// display's Scale().
+//
+// Allegro has no DPI-change event, so HandleEvent() rechecks the scale on the
+// events that accompany a move to another monitor: resizes (which Windows
+// sends on a DPI change), switching back in and monitors being connected or
+// disconnected. Check() can also be called directly, e.g. after the window has
+// been moved.
+type ScaledFont struct {
+	// OnReload, if set, is called after the font has been reloaded at a new
+	// scale, e.g. to recompute cached text layouts.
+	OnReload func(f *font.Font)
+
+	display  *allegro.Display
+	filename string
+	size     int
+	flags    TtfFlags
+	scale    float64
+	font     *font.Font
+}
+
+// LoadScaledFont() loads a TTF font for the display at size multiplied by the
+// display's current scale.
+func LoadScaledFont(d *allegro.Display, filename string, size int, flags TtfFlags) (*ScaledFont, error) {
+	f := ScaledFont{
+		display:  d,
+		filename: filename,
+		size:     size,
+		flags:    flags,
+	}
+	if err := f.load(d.Scale()); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (f *ScaledFont) load(scale float64) error {
+	px := int(math.Round(float64(f.size) * scale))
+	if px < 1 {
+		px = 1
+	}
+	ft, err := LoadFont(f.filename, px, f.flags)
+	if err != nil {
+		return err
+	}
+	if f.font != nil {
+		f.font.Destroy()
+	}
+	f.font, f.scale = ft, scale
+	return nil
+}
+
+// Font() returns the font at the current scale. The returned font is destroyed
+// when the scale changes, so it should be fetched again rather than kept.
+func (f *ScaledFont) Font() *font.Font {
+	return f.font
+}
+
+// Scale() returns the scale the font is currently loaded at.
+func (f *ScaledFont) Scale() float64 {
+	return f.scale
+}
+
+// Size() returns the requested size, before scaling.
+func (f *ScaledFont) Size() int {
+	return f.size
+}
+
+// Check() reloads the font if the display's scale has changed. If reloading
+// fails, the font at the previous scale is kept and the error is returned.
+func (f *ScaledFont) Check() error {
+	scale := f.display.Scale()
+	if scale == f.scale {
+		return nil
+	}
+	if err := f.load(scale); err != nil {
+		return err
+	}
+	if f.OnReload != nil {
+		f.OnReload(f.font)
+	}
+	return nil
+}
+
+// HandleEvent() calls Check() on events that may mean the display moved to a
+// monitor with a different DPI.
+func (f *ScaledFont) HandleEvent(ev interface{}) error {
+	switch e := ev.(type) {
+	case allegro.DisplayResizeEvent:
+		if e.Source() != f.display {
+			return nil
+		}
+	case allegro.DisplaySwitchInEvent:
+		if e.Source() != f.display {
+			return nil
+		}
+	case allegro.DisplayConnectedEvent, allegro.DisplayDisconnectedEvent:
+	default:
+		return nil
+	}
+	return f.Check()
+}
+
+// Destroy() destroys the font.
+func (f *ScaledFont) Destroy() {
+	if f.font != nil {
+		f.font.Destroy()
+		f.font = nil
+	}
+}
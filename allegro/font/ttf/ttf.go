@@ -6,10 +6,9 @@ package ttf
 // #include "../../util.c"
 import "C"
 import (
-	"errors"
-	"fmt"
 	"github.com/ccollins476ad/go-allegro/allegro"
 	"github.com/ccollins476ad/go-allegro/allegro/font"
+	"io"
 	"unsafe"
 )
 
@@ -32,6 +31,20 @@ func Uninstall() {
 	C.al_shutdown_ttf_addon()
 }
 
+// Addon installs the TTF addon through allegro.Init(). It must come after
+// font.Addon.
+var Addon = allegro.Addon{
+	Name:      "ttf",
+	Install:   func() error { Install(); return nil },
+	Uninstall: Uninstall,
+	Installed: IsInstalled,
+}
+
+// Returns true if the TTF addon is initialized, otherwise returns false.
+func IsInstalled() bool {
+	return bool(C.al_is_ttf_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
@@ -48,9 +61,13 @@ func Version() (major, minor, revision, release uint8) {
 func LoadFont(filename string, size int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_ttf_font(filename_, C.int(size), C.int(flags))
-	if f == nil {
-		return nil, fmt.Errorf("failed to load ttf font at '%s'", filename)
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_ttf_font", filename, func() bool {
+		f = C.al_load_ttf_font(filename_, C.int(size), C.int(flags))
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -60,22 +77,41 @@ func LoadFont(filename string, size int, flags TtfFlags) (*font.Font, error) {
 func LoadFontF(file *allegro.File, filename string, size int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_ttf_font_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)), filename_,
-		C.int(size), C.int(flags))
-	if f == nil {
-		return nil, errors.New("failed to load font from file")
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_ttf_font_f", filename, func() bool {
+		f = C.al_load_ttf_font_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)), filename_,
+			C.int(size), C.int(flags))
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
 
+// LoadFontReader() loads a TTF font from r, e.g. data embedded with go:embed.
+// The font keeps reading glyphs from r for as long as it exists, so r must
+// stay valid until the font is destroyed; r should also be an io.Seeker.
+func LoadFontReader(r io.Reader, filename string, size int, flags TtfFlags) (*font.Font, error) {
+	file, err := allegro.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFontF(file, filename, size, flags)
+}
+
 // Like al_load_ttf_font, except it takes separate width and height parameters
 // instead of a single size parameter.
 func LoadFontStretch(filename string, w, h int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_ttf_font_stretch(filename_, C.int(w), C.int(h), C.int(flags))
-	if f == nil {
-		return nil, fmt.Errorf("failed to load ttf font at '%s'", filename)
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_ttf_font_stretch", filename, func() bool {
+		f = C.al_load_ttf_font_stretch(filename_, C.int(w), C.int(h), C.int(flags))
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
@@ -86,10 +122,14 @@ func LoadFontStretch(filename string, w, h int, flags TtfFlags) (*font.Font, err
 func LoadFontStretchF(file *allegro.File, filename string, w, h int, flags TtfFlags) (*font.Font, error) {
 	filename_ := C.CString(filename)
 	defer C.free_string(filename_)
-	f := C.al_load_ttf_font_stretch_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)),
-		filename_, C.int(w), C.int(h), C.int(flags))
-	if f == nil {
-		return nil, errors.New("failed to load font from file")
+	var f *C.ALLEGRO_FONT
+	err := allegro.CheckErrno("al_load_ttf_font_stretch_f", filename, func() bool {
+		f = C.al_load_ttf_font_stretch_f((*C.ALLEGRO_FILE)(unsafe.Pointer(file)),
+			filename_, C.int(w), C.int(h), C.int(flags))
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return (*font.Font)(unsafe.Pointer(f)), nil
 }
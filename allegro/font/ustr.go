@@ -0,0 +1,112 @@
+package font
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_font.h>
+/*
+static void draw_gostring(const ALLEGRO_FONT *font, ALLEGRO_COLOR color,
+	float x, float y, int flags, _GoString_ text)
+{
+	ALLEGRO_USTR_INFO info;
+	const ALLEGRO_USTR *ustr = al_ref_buffer(&info, _GoStringPtr(text), _GoStringLen(text));
+	al_draw_ustr(font, color, x, y, flags, ustr);
+}
+
+static void draw_justified_gostring(const ALLEGRO_FONT *font, ALLEGRO_COLOR color,
+	float x1, float x2, float y, float diff, int flags, _GoString_ text)
+{
+	ALLEGRO_USTR_INFO info;
+	const ALLEGRO_USTR *ustr = al_ref_buffer(&info, _GoStringPtr(text), _GoStringLen(text));
+	al_draw_justified_ustr(font, color, x1, x2, y, diff, flags, ustr);
+}
+
+static int gostring_width(const ALLEGRO_FONT *font, _GoString_ text)
+{
+	ALLEGRO_USTR_INFO info;
+	const ALLEGRO_USTR *ustr = al_ref_buffer(&info, _GoStringPtr(text), _GoStringLen(text));
+	return al_get_ustr_width(font, ustr);
+}
+
+static void gostring_dimensions(const ALLEGRO_FONT *font, _GoString_ text,
+	int *bbx, int *bby, int *bbw, int *bbh)
+{
+	ALLEGRO_USTR_INFO info;
+	const ALLEGRO_USTR *ustr = al_ref_buffer(&info, _GoStringPtr(text), _GoStringLen(text));
+	al_get_ustr_dimensions(font, ustr, bbx, bby, bbw, bbh);
+}
+*/
+import "C"
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+// DrawText(), DrawJustifiedText(), TextWidth() and TextDimensions() pass the
+// Go string to Allegro as an ALLEGRO_USTR that refers to the string's own
+// memory, rather than copying it into a C string. That saves an allocation
+// per call, which adds up for HUDs that redraw a lot of text every frame, and
+// lets the text contain NUL characters.
+
+func drawGoString(font *Font, color allegro.Color, x, y float32, flags DrawFlags, text string) {
+	C.draw_gostring((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x),
+		C.float(y),
+		C.int(flags),
+		text)
+}
+
+func drawJustifiedGoString(font *Font, color allegro.Color, x1, x2, y, diff float32, flags DrawFlags, text string) {
+	C.draw_justified_gostring((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x1),
+		C.float(x2),
+		C.float(y),
+		C.float(diff),
+		C.int(flags),
+		text)
+}
+
+func (f *Font) goStringWidth(text string) int {
+	return int(C.gostring_width((*C.ALLEGRO_FONT)(f), text))
+}
+
+func (f *Font) goStringDimensions(text string) (bbx, bby, bbw, bbh int) {
+	var cbbx, cbby, cbbw, cbbh C.int
+	C.gostring_dimensions((*C.ALLEGRO_FONT)(f), text, &cbbx, &cbby, &cbbw, &cbbh)
+	return int(cbbx), int(cbby), int(cbbw), int(cbbh)
+}
+
+// Like al_draw_text, except the text is passed as an ALLEGRO_USTR.
+func DrawUstr(font *Font, color allegro.Color, x, y float32, flags DrawFlags, text *allegro.Ustr) {
+	C.al_draw_ustr((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x),
+		C.float(y),
+		C.int(flags),
+		(*C.ALLEGRO_USTR)(unsafe.Pointer(text)))
+}
+
+// Like al_draw_justified_text, except the text is passed as an ALLEGRO_USTR.
+func DrawJustifiedUstr(font *Font, color allegro.Color, x1, x2, y, diff float32, flags DrawFlags, text *allegro.Ustr) {
+	C.al_draw_justified_ustr((*C.ALLEGRO_FONT)(font),
+		*((*C.ALLEGRO_COLOR)(unsafe.Pointer(&color))),
+		C.float(x1),
+		C.float(x2),
+		C.float(y),
+		C.float(diff),
+		C.int(flags),
+		(*C.ALLEGRO_USTR)(unsafe.Pointer(text)))
+}
+
+// Like al_get_text_width, except the text is passed as an ALLEGRO_USTR.
+func (f *Font) UstrWidth(text *allegro.Ustr) int {
+	return int(C.al_get_ustr_width((*C.ALLEGRO_FONT)(f), (*C.ALLEGRO_USTR)(unsafe.Pointer(text))))
+}
+
+// Like al_get_text_dimensions, except the text is passed as an ALLEGRO_USTR.
+func (f *Font) UstrDimensions(text *allegro.Ustr) (bbx, bby, bbw, bbh int) {
+	var cbbx, cbby, cbbw, cbbh C.int
+	C.al_get_ustr_dimensions((*C.ALLEGRO_FONT)(f), (*C.ALLEGRO_USTR)(unsafe.Pointer(text)),
+		&cbbx, &cbby, &cbbw, &cbbh)
+	return int(cbbx), int(cbby), int(cbbw), int(cbbh)
+}
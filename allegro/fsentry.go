@@ -0,0 +1,240 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"time"
+	"unsafe"
+)
+
+// FSEntry is a file or directory as seen through the calling thread's
+// filesystem interface, which is the native filesystem unless it was replaced
+// by UseFS() or an addon such as PhysicsFS.
+type FSEntry C.ALLEGRO_FS_ENTRY
+
+type FSEntryMode int
+
+const (
+	FILEMODE_READ    FSEntryMode = C.ALLEGRO_FILEMODE_READ
+	FILEMODE_WRITE               = C.ALLEGRO_FILEMODE_WRITE
+	FILEMODE_EXECUTE             = C.ALLEGRO_FILEMODE_EXECUTE
+	FILEMODE_HIDDEN              = C.ALLEGRO_FILEMODE_HIDDEN
+	FILEMODE_ISFILE              = C.ALLEGRO_FILEMODE_ISFILE
+	FILEMODE_ISDIR               = C.ALLEGRO_FILEMODE_ISDIR
+)
+
+// Creates an ALLEGRO_FS_ENTRY object pointing to path on the filesystem.
+// 'path' can be a file or a directory and must not be NULL.
+func CreateFSEntry(path string) (*FSEntry, error) {
+	path_ := C.CString(path)
+	defer freeString(path_)
+	var e *C.ALLEGRO_FS_ENTRY
+	err := checkErrno("al_create_fs_entry", path, func() bool {
+		e = C.al_create_fs_entry(path_)
+		return e != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newFSEntry(e), nil
+}
+
+func newFSEntry(e *C.ALLEGRO_FS_ENTRY) *FSEntry {
+	entry := (*FSEntry)(e)
+	trackResource(unsafe.Pointer(entry), "fs entry")
+	return entry
+}
+
+// Destroys a fs entry handle. The file or directory represented by it is not
+// destroyed. If the entry was opened, it is closed before being destroyed.
+func (e *FSEntry) Destroy() {
+	if releaseResource(unsafe.Pointer(e), "fs entry") == nil {
+		e.destroy()
+	}
+}
+
+func (e *FSEntry) destroy() {
+	C.al_destroy_fs_entry((*C.ALLEGRO_FS_ENTRY)(e))
+}
+
+// Returns the entry's filename path. Note that the filesystem encoding may not
+// be known and the conversion to UTF-8 could in very rare cases cause this to
+// return an invalid or incomplete string.
+func (e *FSEntry) Name() string {
+	return C.GoString(C.al_get_fs_entry_name((*C.ALLEGRO_FS_ENTRY)(e)))
+}
+
+// Updates file status information for a filesystem entry. File status
+// information is automatically updated when the entry is created, however you
+// may update it again with this function, e.g. in case it changed.
+func (e *FSEntry) Update() error {
+	return checkErrno("al_update_fs_entry", e.Name(), func() bool {
+		return bool(C.al_update_fs_entry((*C.ALLEGRO_FS_ENTRY)(e)))
+	})
+}
+
+// Returns the entry's mode flags, i.e. permissions and whether the entry
+// refers to a file or directory.
+func (e *FSEntry) Mode() FSEntryMode {
+	return FSEntryMode(C.al_get_fs_entry_mode((*C.ALLEGRO_FS_ENTRY)(e)))
+}
+
+// IsDir() returns true if the entry is a directory.
+func (e *FSEntry) IsDir() bool {
+	return e.Mode()&FILEMODE_ISDIR != 0
+}
+
+// Returns the time in seconds since the epoch since the entry was last
+// accessed.
+func (e *FSEntry) Atime() time.Time {
+	return time.Unix(int64(C.al_get_fs_entry_atime((*C.ALLEGRO_FS_ENTRY)(e))), 0)
+}
+
+// Returns the time in seconds since the epoch since the entry was last
+// modified.
+func (e *FSEntry) Mtime() time.Time {
+	return time.Unix(int64(C.al_get_fs_entry_mtime((*C.ALLEGRO_FS_ENTRY)(e))), 0)
+}
+
+// Returns the time in seconds since the epoch this entry was created on the
+// filesystem.
+func (e *FSEntry) Ctime() time.Time {
+	return time.Unix(int64(C.al_get_fs_entry_ctime((*C.ALLEGRO_FS_ENTRY)(e))), 0)
+}
+
+// Returns the size, in bytes, of the given entry. May not return anything
+// sensible for a directory entry.
+func (e *FSEntry) Size() int64 {
+	return int64(C.al_get_fs_entry_size((*C.ALLEGRO_FS_ENTRY)(e)))
+}
+
+// Check if the given entry exists on in the filesystem. Returns true if it
+// does exist or false if it doesn't exist, or an error occurred. Error is
+// indicated in Allegro's errno.
+func (e *FSEntry) Exists() bool {
+	return bool(C.al_fs_entry_exists((*C.ALLEGRO_FS_ENTRY)(e)))
+}
+
+// Delete this filesystem entry from the filesystem. Only files and empty
+// directories may be deleted.
+func (e *FSEntry) Remove() error {
+	return checkErrno("al_remove_fs_entry", e.Name(), func() bool {
+		return bool(C.al_remove_fs_entry((*C.ALLEGRO_FS_ENTRY)(e)))
+	})
+}
+
+// Opens a directory entry object. You must call this before using
+// al_read_directory on an entry and you must call al_close_directory when you
+// no longer need it.
+func (e *FSEntry) OpenDirectory() error {
+	return checkErrno("al_open_directory", e.Name(), func() bool {
+		return bool(C.al_open_directory((*C.ALLEGRO_FS_ENTRY)(e)))
+	})
+}
+
+// Reads the next directory item and returns a filesystem entry for it.
+// Returns nil if there are no more entries or if an error occurs. Call
+// Destroy() on the returned entry when you are done with it.
+func (e *FSEntry) ReadDirectory() *FSEntry {
+	if next := C.al_read_directory((*C.ALLEGRO_FS_ENTRY)(e)); next != nil {
+		return newFSEntry(next)
+	}
+	return nil
+}
+
+// Closes a previously opened directory entry object.
+func (e *FSEntry) CloseDirectory() error {
+	return checkErrno("al_close_directory", e.Name(), func() bool {
+		return bool(C.al_close_directory((*C.ALLEGRO_FS_ENTRY)(e)))
+	})
+}
+
+// Entries() returns the entries of a directory. The caller must Destroy()
+// each of them.
+func (e *FSEntry) Entries() ([]*FSEntry, error) {
+	if err := e.OpenDirectory(); err != nil {
+		return nil, err
+	}
+	defer e.CloseDirectory()
+	var entries []*FSEntry
+	for next := e.ReadDirectory(); next != nil; next = e.ReadDirectory() {
+		entries = append(entries, next)
+	}
+	return entries, nil
+}
+
+// Open an ALLEGRO_FILE handle to a filesystem entry, for the given access
+// mode. This is like calling al_fopen with the name of the filesystem entry,
+// but uses the appropriate file interface, not whatever was set with the
+// latest call to al_set_new_file_interface.
+func (e *FSEntry) OpenFile(mode FileMode) (*File, error) {
+	mode_ := C.CString(mode.String())
+	defer freeString(mode_)
+	var f *C.ALLEGRO_FILE
+	err := checkErrno("al_open_fs_entry", e.Name(), func() bool {
+		f = C.al_open_fs_entry((*C.ALLEGRO_FS_ENTRY)(e), mode_)
+		return f != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*File)(f), nil
+}
+
+// Check if the path exists on the filesystem, without creating an
+// ALLEGRO_FS_ENTRY object explicitly.
+func FilenameExists(path string) bool {
+	path_ := C.CString(path)
+	defer freeString(path_)
+	return bool(C.al_filename_exists(path_))
+}
+
+// Delete the given path from the filesystem, which may be a file or an empty
+// directory. This is the same as al_remove_fs_entry, except it expects the
+// path as a string.
+func RemoveFilename(path string) error {
+	path_ := C.CString(path)
+	defer freeString(path_)
+	return checkErrno("al_remove_filename", path, func() bool {
+		return bool(C.al_remove_filename(path_))
+	})
+}
+
+// Creates a new directory on the filesystem. This function also creates any
+// parent directories as needed.
+func MakeDirectory(path string) error {
+	path_ := C.CString(path)
+	defer freeString(path_)
+	return checkErrno("al_make_directory", path, func() bool {
+		return bool(C.al_make_directory(path_))
+	})
+}
+
+// Returns the path to the current working directory.
+func CurrentDirectory() (string, error) {
+	var dir *C.char
+	err := checkErrno("al_get_current_directory", "", func() bool {
+		dir = C.al_get_current_directory()
+		return dir != nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer free(unsafe.Pointer(dir))
+	return C.GoString(dir), nil
+}
+
+// Changes the current working directory to 'path'.
+func ChangeDirectory(path string) error {
+	path_ := C.CString(path)
+	defer freeString(path_)
+	return checkErrno("al_change_directory", path, func() bool {
+		return bool(C.al_change_directory(path_))
+	})
+}
+
+// Return the ALLEGRO_FS_INTERFACE table to the default, for the calling
+// thread.
+func UseStandardFSInterface() {
+	C.al_set_standard_fs_interface()
+}
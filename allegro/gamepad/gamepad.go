@@ -0,0 +1,146 @@
+// Package gamepad presents joysticks as gamepads with a fixed layout: face
+// buttons A, B, X and Y, shoulder buttons, triggers and two sticks, wherever
+// the device happens to put them. Layouts come from mappings in the format of
+// the community SDL game controller database (gamecontrollerdb.txt).
+package gamepad
+
+import (
+	"encoding/hex"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Gamepad reads a joystick through a mapping.
+//
+// Allegro groups a device's controls into sticks rather than numbering them
+// as SDL does, so the raw axes of the mapping are taken to be the axes of the
+// analogue sticks in order, and its hats the digital sticks with two axes.
+// This matches the usual drivers, but a device whose layout is off can be
+// given a custom mapping.
+type Gamepad struct {
+	Joystick *allegro.Joystick
+	Mapping  *Mapping
+
+	// Deadzone is the distance from the centre within which stick axes
+	// read as 0.
+	Deadzone float32
+
+	state      *allegro.JoystickState
+	axisIndex  [][2]int // stick and axis of each raw axis
+	hatIndex   []int    // stick of each hat
+	raw        raw
+	prevButton [NUM_BUTTONS]bool
+	button     [NUM_BUTTONS]bool
+}
+
+// Open() looks up the joystick in db and returns a gamepad for it, or false if
+// the device has no mapping.
+func Open(j *allegro.Joystick, db *DB) (*Gamepad, bool) {
+	guid := ""
+	if g, ok := j.GUID(); ok {
+		guid = hex.EncodeToString(g[:])
+	}
+	m, ok := db.Lookup(guid, j.Name())
+	if !ok {
+		return nil, false
+	}
+	return New(j, m), true
+}
+
+// New() creates a gamepad for the joystick with the given mapping.
+func New(j *allegro.Joystick, m *Mapping) *Gamepad {
+	g := Gamepad{Joystick: j, Mapping: m, Deadzone: 0.15, state: j.State()}
+	for stick := 0; stick < j.NumSticks(); stick++ {
+		n := j.NumAxes(stick)
+		if j.StickFlags(stick)&allegro.JOYFLAG_DIGITAL != 0 && n == 2 {
+			g.hatIndex = append(g.hatIndex, stick)
+			continue
+		}
+		for axis := 0; axis < n; axis++ {
+			g.axisIndex = append(g.axisIndex, [2]int{stick, axis})
+		}
+	}
+	g.raw = raw{
+		axes:    make([]float32, len(g.axisIndex)),
+		buttons: make([]bool, j.NumButtons()),
+		hats:    make([]int, len(g.hatIndex)),
+	}
+	return &g
+}
+
+// Name() returns the name of the mapping, which is usually friendlier than
+// the name reported by the driver.
+func (g *Gamepad) Name() string {
+	return g.Mapping.Name
+}
+
+// Poll() reads the current state of the joystick. It should be called once
+// per frame, before the buttons and axes are read.
+func (g *Gamepad) Poll() {
+	g.state.Get()
+	for i, sa := range g.axisIndex {
+		g.raw.axes[i] = g.state.Axis(sa[0], sa[1])
+	}
+	for i := range g.raw.buttons {
+		g.raw.buttons[i] = g.state.ButtonDown(i)
+	}
+	for i, stick := range g.hatIndex {
+		x, y := g.state.Axis(stick, 0), g.state.Axis(stick, 1)
+		hat := 0
+		if y < 0 {
+			hat |= 1
+		}
+		if x > 0 {
+			hat |= 2
+		}
+		if y > 0 {
+			hat |= 4
+		}
+		if x < 0 {
+			hat |= 8
+		}
+		g.raw.hats[i] = hat
+	}
+	g.prevButton = g.button
+	for b := range g.button {
+		g.button[b] = g.Mapping.button(Button(b), &g.raw)
+	}
+}
+
+// ButtonDown() returns whether the button was held at the last Poll().
+func (g *Gamepad) ButtonDown(b Button) bool {
+	return b >= 0 && b < NUM_BUTTONS && g.button[b]
+}
+
+// ButtonPressed() returns whether the button went down between the last two
+// polls.
+func (g *Gamepad) ButtonPressed(b Button) bool {
+	return g.ButtonDown(b) && !g.prevButton[b]
+}
+
+// ButtonReleased() returns whether the button came up between the last two
+// polls.
+func (g *Gamepad) ButtonReleased(b Button) bool {
+	return b >= 0 && b < NUM_BUTTONS && !g.button[b] && g.prevButton[b]
+}
+
+// Axis() returns the position of the axis at the last Poll(), with Deadzone
+// applied to the sticks.
+func (g *Gamepad) Axis(a Axis) float32 {
+	if a < 0 || a >= NUM_AXES {
+		return 0
+	}
+	v := g.Mapping.axis(a, &g.raw)
+	if a < AXIS_TRIGGER_LEFT && v > -g.Deadzone && v < g.Deadzone {
+		return 0
+	}
+	return v
+}
+
+// Stick() returns the position of the left or right stick.
+func (g *Gamepad) Stick(right bool) (x, y float32) {
+	if right {
+		return g.Axis(AXIS_RIGHT_X), g.Axis(AXIS_RIGHT_Y)
+	}
+	return g.Axis(AXIS_LEFT_X), g.Axis(AXIS_LEFT_Y)
+}
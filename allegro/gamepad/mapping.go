@@ -0,0 +1,395 @@
+package gamepad
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Button is a logical gamepad button, named after its position on an Xbox
+// style controller.
+type Button int
+
+const (
+	BUTTON_A Button = iota
+	BUTTON_B
+	BUTTON_X
+	BUTTON_Y
+	BUTTON_BACK
+	BUTTON_GUIDE
+	BUTTON_START
+	BUTTON_LEFT_STICK
+	BUTTON_RIGHT_STICK
+	BUTTON_LEFT_SHOULDER
+	BUTTON_RIGHT_SHOULDER
+	BUTTON_DPAD_UP
+	BUTTON_DPAD_DOWN
+	BUTTON_DPAD_LEFT
+	BUTTON_DPAD_RIGHT
+	NUM_BUTTONS
+)
+
+// Axis is a logical gamepad axis. Stick axes range from -1 to 1, with
+// positive values pointing right and down; triggers range from 0 to 1.
+type Axis int
+
+const (
+	AXIS_LEFT_X Axis = iota
+	AXIS_LEFT_Y
+	AXIS_RIGHT_X
+	AXIS_RIGHT_Y
+	AXIS_TRIGGER_LEFT
+	AXIS_TRIGGER_RIGHT
+	NUM_AXES
+)
+
+// The names used for buttons and axes in the SDL game controller database.
+var buttonNames = [NUM_BUTTONS]string{
+	"a", "b", "x", "y", "back", "guide", "start", "leftstick", "rightstick",
+	"leftshoulder", "rightshoulder", "dpup", "dpdown", "dpleft", "dpright",
+}
+
+var axisNames = [NUM_AXES]string{
+	"leftx", "lefty", "rightx", "righty", "lefttrigger", "righttrigger",
+}
+
+type inputKind int
+
+const (
+	inputNone inputKind = iota
+	inputButton
+	inputAxis
+	inputHat
+)
+
+// input is the physical control that a logical button or axis is bound to.
+type input struct {
+	kind  inputKind
+	index int
+	mask  int // for hats: 1 up, 2 right, 4 down, 8 left
+
+	// half is +1 or -1 to use only that half of an axis, as "+a2" or "-a2".
+	half   int
+	invert bool
+}
+
+// raw is a snapshot of a device's physical controls, numbered as in the
+// database.
+type raw struct {
+	axes    []float32
+	buttons []bool
+	hats    []int
+}
+
+// value() returns the input's position, from -1 to 1 for a full axis and
+// from 0 to 1 otherwise.
+func (in input) value(r *raw) float32 {
+	switch in.kind {
+	case inputButton:
+		if in.index < len(r.buttons) && r.buttons[in.index] {
+			return 1
+		}
+	case inputHat:
+		if in.index < len(r.hats) && r.hats[in.index]&in.mask != 0 {
+			return 1
+		}
+	case inputAxis:
+		if in.index >= len(r.axes) {
+			return 0
+		}
+		v := r.axes[in.index]
+		if in.invert {
+			v = -v
+		}
+		switch {
+		case in.half > 0 && v < 0, in.half < 0 && v > 0:
+			return 0
+		case in.half < 0:
+			return -v
+		}
+		return v
+	}
+	return 0
+}
+
+// Mapping describes the layout of one kind of device, as a line of the SDL
+// game controller database does.
+type Mapping struct {
+	// GUID is the device GUID as 32 lowercase hex digits.
+	GUID     string
+	Name     string
+	Platform string
+
+	buttons [NUM_BUTTONS]input
+	axes    [NUM_AXES]input
+}
+
+// ParseMapping() parses a line of the SDL game controller database, such as
+//
+//	030000005e0400008e02000014010000,Xbox 360 Controller,a:b0,b:b1,...,platform:Linux,
+//
+// Buttons and axes the library doesn't know, such as paddles, are ignored.
+func ParseMapping(line string) (*Mapping, error) {
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	if len(fields) < 2 || len(fields[0]) != 32 {
+		return nil, fmt.Errorf("invalid mapping '%s'", line)
+	}
+	m := Mapping{GUID: strings.ToLower(fields[0]), Name: fields[1]}
+	for _, field := range fields[2:] {
+		if field == "" {
+			continue
+		}
+		colon := strings.IndexByte(field, ':')
+		if colon < 0 {
+			return nil, fmt.Errorf("invalid element '%s' in mapping for '%s'", field, m.Name)
+		}
+		name, value := field[:colon], field[colon+1:]
+		if name == "platform" {
+			m.Platform = value
+			continue
+		}
+		in, err := parseInput(value)
+		if err != nil {
+			return nil, fmt.Errorf("element '%s' in mapping for '%s': %s", name, m.Name, err.Error())
+		}
+		for b, n := range buttonNames {
+			if n == name {
+				m.buttons[b] = in
+			}
+		}
+		for a, n := range axisNames {
+			if n == name {
+				m.axes[a] = in
+			}
+		}
+	}
+	return &m, nil
+}
+
+func parseInput(s string) (input, error) {
+	var in input
+	switch {
+	case strings.HasPrefix(s, "+"):
+		in.half, s = 1, s[1:]
+	case strings.HasPrefix(s, "-"):
+		in.half, s = -1, s[1:]
+	}
+	if strings.HasSuffix(s, "~") {
+		in.invert, s = true, s[:len(s)-1]
+	}
+	if len(s) < 2 {
+		return in, fmt.Errorf("invalid input '%s'", s)
+	}
+	var err error
+	switch s[0] {
+	case 'b':
+		in.kind = inputButton
+		in.index, err = strconv.Atoi(s[1:])
+	case 'a':
+		in.kind = inputAxis
+		in.index, err = strconv.Atoi(s[1:])
+	case 'h':
+		in.kind = inputHat
+		dot := strings.IndexByte(s, '.')
+		if dot < 0 {
+			return in, fmt.Errorf("invalid hat '%s'", s)
+		}
+		if in.index, err = strconv.Atoi(s[1:dot]); err == nil {
+			in.mask, err = strconv.Atoi(s[dot+1:])
+		}
+	default:
+		return in, fmt.Errorf("invalid input '%s'", s)
+	}
+	if err == nil && in.index < 0 {
+		err = fmt.Errorf("invalid input '%s'", s)
+	}
+	return in, err
+}
+
+// String() formats the mapping as a database line.
+func (m *Mapping) String() string {
+	var b strings.Builder
+	b.WriteString(m.GUID + "," + m.Name + ",")
+	write := func(name string, in input) {
+		if in.kind == inputNone {
+			return
+		}
+		b.WriteString(name + ":")
+		switch {
+		case in.half > 0:
+			b.WriteString("+")
+		case in.half < 0:
+			b.WriteString("-")
+		}
+		switch in.kind {
+		case inputButton:
+			fmt.Fprintf(&b, "b%d", in.index)
+		case inputAxis:
+			fmt.Fprintf(&b, "a%d", in.index)
+		case inputHat:
+			fmt.Fprintf(&b, "h%d.%d", in.index, in.mask)
+		}
+		if in.invert {
+			b.WriteString("~")
+		}
+		b.WriteString(",")
+	}
+	for i, in := range m.buttons {
+		write(buttonNames[i], in)
+	}
+	for i, in := range m.axes {
+		write(axisNames[i], in)
+	}
+	if m.Platform != "" {
+		b.WriteString("platform:" + m.Platform + ",")
+	}
+	return b.String()
+}
+
+// button() returns whether a logical button is pressed. Axes bound to
+// buttons count as pressed past the halfway point.
+func (m *Mapping) button(b Button, r *raw) bool {
+	return m.buttons[b].value(r) > 0.5
+}
+
+// axis() returns the position of a logical axis. A full physical axis bound
+// to a trigger is rescaled from -1..1 to 0..1.
+func (m *Mapping) axis(a Axis, r *raw) float32 {
+	in := m.axes[a]
+	v := in.value(r)
+	if a >= AXIS_TRIGGER_LEFT && in.kind == inputAxis && in.half == 0 {
+		v = (v + 1) / 2
+	}
+	return v
+}
+
+// DB is a set of mappings, usually loaded from the community maintained
+// gamecontrollerdb.txt.
+type DB struct {
+	byGUID map[string]*Mapping
+	byName map[string]*Mapping
+}
+
+// NewDB() creates an empty database.
+func NewDB() *DB {
+	return &DB{
+		byGUID: make(map[string]*Mapping),
+		byName: make(map[string]*Mapping),
+	}
+}
+
+// platformName() returns the name of the current platform as used in the
+// database.
+func platformName() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "Mac OS X"
+	case "android":
+		return "Android"
+	case "ios":
+		return "iOS"
+	}
+	return "Linux"
+}
+
+// Add() adds a mapping, replacing any with the same GUID.
+func (db *DB) Add(m *Mapping) {
+	db.byGUID[m.GUID] = m
+	db.byName[m.Name] = m
+}
+
+// Load() reads mappings in the database format, one per line. Blank lines,
+// comments starting with '#' and mappings for other platforms are skipped.
+func (db *DB) Load(r io.Reader) error {
+	platform := platformName()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		m, err := ParseMapping(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", n, err.Error())
+		}
+		if m.Platform == "" || m.Platform == platform {
+			db.Add(m)
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadFile() reads mappings from a file, as Load() does.
+func (db *DB) LoadFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := db.Load(f); err != nil {
+		return fmt.Errorf("failed to parse '%s': %s", filename, err.Error())
+	}
+	return nil
+}
+
+// Lookup() returns the mapping for a device, by GUID if it has one, else by
+// name. Recent SDL versions store a checksum of the name in bytes 2 and 3 of
+// the GUID, which many database entries leave zero, so the GUID is also tried
+// without it.
+func (db *DB) Lookup(guid, name string) (*Mapping, bool) {
+	guid = strings.ToLower(guid)
+	if m, ok := db.byGUID[guid]; ok {
+		return m, true
+	}
+	if len(guid) == 32 {
+		if m, ok := db.byGUID[guid[:4]+"0000"+guid[8:]]; ok {
+			return m, true
+		}
+	}
+	m, ok := db.byName[name]
+	return m, ok
+}
+
+// String() returns the button's name in the database format, such as "a" or
+// "leftshoulder".
+func (b Button) String() string {
+	if b < 0 || b >= NUM_BUTTONS {
+		return fmt.Sprintf("Button(%d)", int(b))
+	}
+	return buttonNames[b]
+}
+
+// ParseButton() returns the button with the given name, as returned by
+// String().
+func ParseButton(name string) (Button, error) {
+	for b, n := range buttonNames {
+		if n == name {
+			return Button(b), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gamepad button '%s'", name)
+}
+
+// String() returns the axis's name in the database format, such as "leftx".
+func (a Axis) String() string {
+	if a < 0 || a >= NUM_AXES {
+		return fmt.Sprintf("Axis(%d)", int(a))
+	}
+	return axisNames[a]
+}
+
+// ParseAxis() returns the axis with the given name, as returned by String().
+func ParseAxis(name string) (Axis, error) {
+	for a, n := range axisNames {
+		if n == name {
+			return Axis(a), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown gamepad axis '%s'", name)
+}
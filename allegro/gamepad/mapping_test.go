@@ -0,0 +1,52 @@
+package gamepad
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDB = `# comment
+030000005e0400008e02000014010000,Xbox 360 Controller,a:b0,b:b1,x:b2,y:b3,back:b6,start:b7,leftshoulder:b4,rightshoulder:b5,dpup:h0.1,dpdown:h0.4,leftx:a0,lefty:a1,rightx:a3,righty:a4~,lefttrigger:a2,righttrigger:+a5,paddle1:b11,platform:Linux,
+03000000000000000000000000000000,Other Platform,a:b0,platform:Plan 9,
+`
+
+func TestMapping(t *testing.T) {
+	db := NewDB()
+	if err := db.Load(strings.NewReader(testDB)); err != nil {
+		t.Fatal(err)
+	}
+	// The checksum in bytes 2 and 3 is ignored.
+	m, ok := db.Lookup("030012345E0400008E02000014010000", "")
+	if !ok {
+		t.Fatal("mapping not found by GUID")
+	}
+	if _, ok := db.Lookup("", "Other Platform"); ok && platformName() != "Plan 9" {
+		t.Error("mapping for another platform was loaded")
+	}
+
+	r := raw{
+		axes:    []float32{0.5, -1, 0, 0.25, 0.75, -0.5},
+		buttons: []bool{false, true},
+		hats:    []int{4},
+	}
+	buttons := map[Button]bool{BUTTON_A: false, BUTTON_B: true, BUTTON_DPAD_UP: false, BUTTON_DPAD_DOWN: true}
+	for b, want := range buttons {
+		if got := m.button(b, &r); got != want {
+			t.Errorf("button %s: got %v, want %v", buttonNames[b], got, want)
+		}
+	}
+	axes := map[Axis]float32{AXIS_LEFT_X: 0.5, AXIS_LEFT_Y: -1, AXIS_RIGHT_Y: -0.75, AXIS_TRIGGER_LEFT: 0.5, AXIS_TRIGGER_RIGHT: 0}
+	for a, want := range axes {
+		if got := m.axis(a, &r); got != want {
+			t.Errorf("axis %s: got %v, want %v", axisNames[a], got, want)
+		}
+	}
+
+	again, err := ParseMapping(m.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *again != *m {
+		t.Errorf("String() doesn't round trip: %s", m.String())
+	}
+}
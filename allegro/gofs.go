@@ -0,0 +1,373 @@
+package allegro
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+//
+// typedef struct {
+// 	ALLEGRO_FS_ENTRY base;
+// 	uintptr_t handle;
+// } go_fs_entry;
+//
+// extern ALLEGRO_FS_ENTRY *go_fs_create_entry(char *path);
+// extern void go_fs_destroy_entry(ALLEGRO_FS_ENTRY *e);
+// extern char *go_fs_entry_name(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_update_entry(ALLEGRO_FS_ENTRY *e);
+// extern uint32_t go_fs_entry_mode(ALLEGRO_FS_ENTRY *e);
+// extern time_t go_fs_entry_time(ALLEGRO_FS_ENTRY *e);
+// extern off_t go_fs_entry_size(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_entry_exists(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_remove_entry(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_open_directory(ALLEGRO_FS_ENTRY *e);
+// extern ALLEGRO_FS_ENTRY *go_fs_read_directory(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_close_directory(ALLEGRO_FS_ENTRY *e);
+// extern bool go_fs_filename_exists(char *path);
+// extern bool go_fs_remove_filename(char *path);
+// extern char *go_fs_get_current_directory(void);
+// extern bool go_fs_change_directory(char *path);
+// extern bool go_fs_make_directory(char *path);
+// extern ALLEGRO_FILE *go_fs_open_file(ALLEGRO_FS_ENTRY *e, char *mode);
+// extern void *go_fs_fopen(char *path, char *mode);
+import "C"
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"runtime/cgo"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// goFS is the io/fs.FS installed by UseFS(), along with a working directory
+// for the relative paths Allegro passes in. Paths given to Allegro are
+// slash-separated and rooted at the top of the FS, e.g. "/sprites/hero.png";
+// relative ones are resolved against the working directory.
+type goFS struct {
+	fsys fs.FS
+	cwd  string
+}
+
+// goFSEntry is the state behind an ALLEGRO_FS_ENTRY created through a goFS.
+// The C side is a go_fs_entry holding a cgo.Handle for it.
+type goFSEntry struct {
+	fs   *goFS
+	path string
+	name *C.char
+	info fs.FileInfo
+	dir  []fs.DirEntry
+	pos  int
+}
+
+var (
+	goFSMutex   sync.Mutex
+	currentFS   *goFS
+	goFSVtables struct {
+		sync.Once
+		fs   *C.ALLEGRO_FS_INTERFACE
+		file *C.ALLEGRO_FILE_INTERFACE
+	}
+)
+
+// UseFS() makes the calling thread's filesystem and file interfaces read from
+// fsys, so that every Allegro loader, along with FSEntry, reads from it too.
+// Paths are slash-separated, relative to the top of fsys; a leading slash is
+// allowed. fsys is read-only through Allegro: opening a file for writing,
+// removing files and making directories all fail.
+//
+// Only one Go filesystem can be in use at a time; calling UseFS() again
+// replaces it for every thread that uses it. Use UseStandardFSInterface() and
+// UseStandardFileInterface() to go back to the native filesystem.
+func UseFS(fsys fs.FS) {
+	goFSVtables.Do(func() {
+		vt := (*C.ALLEGRO_FS_INTERFACE)(C.calloc(1, C.sizeof_ALLEGRO_FS_INTERFACE))
+		vt.fs_create_entry = (*[0]byte)(C.go_fs_create_entry)
+		vt.fs_destroy_entry = (*[0]byte)(C.go_fs_destroy_entry)
+		vt.fs_entry_name = (*[0]byte)(C.go_fs_entry_name)
+		vt.fs_update_entry = (*[0]byte)(C.go_fs_update_entry)
+		vt.fs_entry_mode = (*[0]byte)(C.go_fs_entry_mode)
+		vt.fs_entry_atime = (*[0]byte)(C.go_fs_entry_time)
+		vt.fs_entry_mtime = (*[0]byte)(C.go_fs_entry_time)
+		vt.fs_entry_ctime = (*[0]byte)(C.go_fs_entry_time)
+		vt.fs_entry_size = (*[0]byte)(C.go_fs_entry_size)
+		vt.fs_entry_exists = (*[0]byte)(C.go_fs_entry_exists)
+		vt.fs_remove_entry = (*[0]byte)(C.go_fs_remove_entry)
+		vt.fs_open_directory = (*[0]byte)(C.go_fs_open_directory)
+		vt.fs_read_directory = (*[0]byte)(C.go_fs_read_directory)
+		vt.fs_close_directory = (*[0]byte)(C.go_fs_close_directory)
+		vt.fs_filename_exists = (*[0]byte)(C.go_fs_filename_exists)
+		vt.fs_remove_filename = (*[0]byte)(C.go_fs_remove_filename)
+		vt.fs_get_current_directory = (*[0]byte)(C.go_fs_get_current_directory)
+		vt.fs_change_directory = (*[0]byte)(C.go_fs_change_directory)
+		vt.fs_make_directory = (*[0]byte)(C.go_fs_make_directory)
+		vt.fs_open_file = (*[0]byte)(C.go_fs_open_file)
+		goFSVtables.fs = vt
+
+		// The file interface is the one behind OpenReader(), plus an
+		// fopen that opens files from the current Go filesystem.
+		fvt := (*C.ALLEGRO_FILE_INTERFACE)(C.calloc(1, C.sizeof_ALLEGRO_FILE_INTERFACE))
+		*fvt = *fileInterface()
+		fvt.fi_fopen = (*[0]byte)(C.go_fs_fopen)
+		goFSVtables.file = fvt
+	})
+
+	goFSMutex.Lock()
+	currentFS = &goFS{fsys: fsys, cwd: "/"}
+	goFSMutex.Unlock()
+
+	C.al_set_fs_interface(goFSVtables.fs)
+	C.al_set_new_file_interface(goFSVtables.file)
+}
+
+func getCurrentFS() *goFS {
+	goFSMutex.Lock()
+	defer goFSMutex.Unlock()
+	return currentFS
+}
+
+// resolve() turns a path from Allegro into a path that fs.FS accepts.
+func (g *goFS) resolve(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if !strings.HasPrefix(p, "/") {
+		goFSMutex.Lock()
+		p = g.cwd + "/" + p
+		goFSMutex.Unlock()
+	}
+	p = strings.TrimPrefix(path.Clean(p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func (g *goFS) open(name, mode string) (*goFile, error) {
+	if strings.ContainsAny(mode, "wa+") {
+		return nil, errors.New("filesystem is read-only")
+	}
+	f, err := g.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &goFile{r: f, c: f}, nil
+}
+
+func (g *goFS) newEntry(p string) *C.ALLEGRO_FS_ENTRY {
+	ge := &goFSEntry{fs: g, path: g.resolve(p)}
+	if ge.path == "." {
+		ge.name = C.CString("/")
+	} else {
+		ge.name = C.CString("/" + ge.path)
+	}
+	ge.update()
+	e := (*C.go_fs_entry)(C.calloc(1, C.sizeof_go_fs_entry))
+	e.base.vtable = goFSVtables.fs
+	e.handle = C.uintptr_t(cgo.NewHandle(ge))
+	return &e.base
+}
+
+func goFSEntryOf(e *C.ALLEGRO_FS_ENTRY) *goFSEntry {
+	h := cgo.Handle((*C.go_fs_entry)(unsafe.Pointer(e)).handle)
+	return h.Value().(*goFSEntry)
+}
+
+func (ge *goFSEntry) update() bool {
+	info, err := fs.Stat(ge.fs.fsys, ge.path)
+	if err != nil {
+		ge.info = nil
+		return false
+	}
+	ge.info = info
+	return true
+}
+
+//export go_fs_create_entry
+func go_fs_create_entry(p *C.char) *C.ALLEGRO_FS_ENTRY {
+	g := getCurrentFS()
+	if g == nil {
+		return nil
+	}
+	return g.newEntry(C.GoString(p))
+}
+
+//export go_fs_destroy_entry
+func go_fs_destroy_entry(e *C.ALLEGRO_FS_ENTRY) {
+	ge := (*C.go_fs_entry)(unsafe.Pointer(e))
+	h := cgo.Handle(ge.handle)
+	C.free(unsafe.Pointer(h.Value().(*goFSEntry).name))
+	h.Delete()
+	C.free(unsafe.Pointer(ge))
+}
+
+//export go_fs_entry_name
+func go_fs_entry_name(e *C.ALLEGRO_FS_ENTRY) *C.char {
+	return goFSEntryOf(e).name
+}
+
+//export go_fs_update_entry
+func go_fs_update_entry(e *C.ALLEGRO_FS_ENTRY) C.bool {
+	return C.bool(goFSEntryOf(e).update())
+}
+
+//export go_fs_entry_mode
+func go_fs_entry_mode(e *C.ALLEGRO_FS_ENTRY) C.uint32_t {
+	ge := goFSEntryOf(e)
+	if ge.info == nil {
+		return 0
+	}
+	mode := FILEMODE_READ
+	if ge.info.IsDir() {
+		mode |= FILEMODE_ISDIR | FILEMODE_EXECUTE
+	} else {
+		mode |= FILEMODE_ISFILE
+	}
+	if strings.HasPrefix(path.Base(ge.path), ".") && ge.path != "." {
+		mode |= FILEMODE_HIDDEN
+	}
+	return C.uint32_t(mode)
+}
+
+// fs.FileInfo only has a modification time, so it is used for the access and
+// creation times too.
+//
+//export go_fs_entry_time
+func go_fs_entry_time(e *C.ALLEGRO_FS_ENTRY) C.time_t {
+	ge := goFSEntryOf(e)
+	if ge.info == nil {
+		return 0
+	}
+	return C.time_t(ge.info.ModTime().Unix())
+}
+
+//export go_fs_entry_size
+func go_fs_entry_size(e *C.ALLEGRO_FS_ENTRY) C.off_t {
+	ge := goFSEntryOf(e)
+	if ge.info == nil {
+		return 0
+	}
+	return C.off_t(ge.info.Size())
+}
+
+//export go_fs_entry_exists
+func go_fs_entry_exists(e *C.ALLEGRO_FS_ENTRY) C.bool {
+	return C.bool(goFSEntryOf(e).update())
+}
+
+//export go_fs_remove_entry
+func go_fs_remove_entry(e *C.ALLEGRO_FS_ENTRY) C.bool {
+	return false
+}
+
+//export go_fs_open_directory
+func go_fs_open_directory(e *C.ALLEGRO_FS_ENTRY) C.bool {
+	ge := goFSEntryOf(e)
+	dir, err := fs.ReadDir(ge.fs.fsys, ge.path)
+	if err != nil {
+		return false
+	}
+	ge.dir = dir
+	ge.pos = 0
+	return true
+}
+
+//export go_fs_read_directory
+func go_fs_read_directory(e *C.ALLEGRO_FS_ENTRY) *C.ALLEGRO_FS_ENTRY {
+	ge := goFSEntryOf(e)
+	if ge.pos >= len(ge.dir) {
+		return nil
+	}
+	child := ge.dir[ge.pos]
+	ge.pos++
+	return ge.fs.newEntry("/" + path.Join(ge.path, child.Name()))
+}
+
+//export go_fs_close_directory
+func go_fs_close_directory(e *C.ALLEGRO_FS_ENTRY) C.bool {
+	ge := goFSEntryOf(e)
+	ge.dir = nil
+	ge.pos = 0
+	return true
+}
+
+//export go_fs_filename_exists
+func go_fs_filename_exists(p *C.char) C.bool {
+	g := getCurrentFS()
+	if g == nil {
+		return false
+	}
+	_, err := fs.Stat(g.fsys, g.resolve(C.GoString(p)))
+	return err == nil
+}
+
+//export go_fs_remove_filename
+func go_fs_remove_filename(p *C.char) C.bool {
+	return false
+}
+
+//export go_fs_get_current_directory
+func go_fs_get_current_directory() *C.char {
+	g := getCurrentFS()
+	if g == nil {
+		return nil
+	}
+	goFSMutex.Lock()
+	cwd := g.cwd
+	goFSMutex.Unlock()
+
+	// The caller frees the result with al_free().
+	buf := malloc(C.size_t(len(cwd) + 1))
+	b := unsafe.Slice((*byte)(buf), len(cwd)+1)
+	copy(b, cwd)
+	b[len(cwd)] = 0
+	return (*C.char)(buf)
+}
+
+//export go_fs_change_directory
+func go_fs_change_directory(p *C.char) C.bool {
+	g := getCurrentFS()
+	if g == nil {
+		return false
+	}
+	dir := g.resolve(C.GoString(p))
+	info, err := fs.Stat(g.fsys, dir)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	goFSMutex.Lock()
+	g.cwd = path.Join("/", dir)
+	goFSMutex.Unlock()
+	return true
+}
+
+//export go_fs_make_directory
+func go_fs_make_directory(p *C.char) C.bool {
+	return false
+}
+
+//export go_fs_open_file
+func go_fs_open_file(e *C.ALLEGRO_FS_ENTRY, mode *C.char) *C.ALLEGRO_FILE {
+	ge := goFSEntryOf(e)
+	gf, err := ge.fs.open(ge.path, C.GoString(mode))
+	if err != nil {
+		return nil
+	}
+	f, err := openGoFile(gf)
+	if err != nil {
+		gf.c.Close()
+		return nil
+	}
+	return (*C.ALLEGRO_FILE)(f)
+}
+
+//export go_fs_fopen
+func go_fs_fopen(p, mode *C.char) unsafe.Pointer {
+	g := getCurrentFS()
+	if g == nil {
+		return nil
+	}
+	gf, err := g.open(g.resolve(C.GoString(p)), C.GoString(mode))
+	if err != nil {
+		return nil
+	}
+	userdata := (*cgo.Handle)(C.malloc(C.size_t(unsafe.Sizeof(cgo.Handle(0)))))
+	*userdata = cgo.NewHandle(gf)
+	return unsafe.Pointer(userdata)
+}
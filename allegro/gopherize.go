@@ -1,6 +1,7 @@
 package allegro
 
 import (
+	"errors"
 	"image"
 )
 
@@ -13,6 +14,39 @@ type EventGenerator interface {
 	EventSource() *EventSource
 }
 
+// EventSource() returns the source itself, so that plain event sources can be
+// passed wherever an EventGenerator is expected.
+func (source *EventSource) EventSource() *EventSource {
+	return source
+}
+
+type deviceEvents struct {
+	name   string
+	source func() (*EventSource, error)
+}
+
+func (d deviceEvents) EventSource() *EventSource {
+	source, err := d.source()
+	if err != nil {
+		return nil
+	}
+	return source
+}
+
+// The global event sources of the input devices, as event generators. Their
+// EventSource() method returns nil if the device isn't installed.
+var (
+	KeyboardEvents EventGenerator = deviceEvents{"keyboard", KeyboardEventSource}
+	MouseEvents    EventGenerator = deviceEvents{"mouse", MouseEventSource}
+	JoystickEvents EventGenerator = deviceEvents{"joystick", func() (*EventSource, error) {
+		if !IsJoystickInstalled() {
+			return nil, errors.New("joystick not installed")
+		}
+		return JoystickEventSource(), nil
+	}}
+	TouchEvents EventGenerator = deviceEvents{"touch input", TouchInputEventSource}
+)
+
 // ImageToBitmap() converts any image.Image to an Allegro bitmap.
 //
 // This method is experimental and hasn't been tested nor optimized.
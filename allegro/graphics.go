@@ -8,6 +8,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"unsafe"
 )
 
 const rgbaMAX = 0xFFFF
@@ -21,7 +22,13 @@ var (
 
 type Color C.ALLEGRO_COLOR
 type Bitmap C.ALLEGRO_BITMAP
-type LockedRegion C.struct_ALLEGRO_LOCKED_REGION
+
+// LockedRegion is the memory of a bitmap, or an area of one, locked by Lock()
+// or LockRegion(). It is only valid until the bitmap is unlocked.
+type LockedRegion struct {
+	reg           *C.ALLEGRO_LOCKED_REGION
+	width, height int
+}
 
 type DrawFlags int
 
@@ -57,33 +64,37 @@ const (
 type PixelFormat int
 
 const (
-	PIXEL_FORMAT_ANY               PixelFormat = C.ALLEGRO_PIXEL_FORMAT_ANY
-	PIXEL_FORMAT_ANY_NO_ALPHA                  = C.ALLEGRO_PIXEL_FORMAT_ANY_NO_ALPHA
-	PIXEL_FORMAT_ANY_WITH_ALPHA                = C.ALLEGRO_PIXEL_FORMAT_ANY_WITH_ALPHA
-	PIXEL_FORMAT_ANY_15_NO_ALPHA               = C.ALLEGRO_PIXEL_FORMAT_ANY_15_NO_ALPHA
-	PIXEL_FORMAT_ANY_16_NO_ALPHA               = C.ALLEGRO_PIXEL_FORMAT_ANY_16_NO_ALPHA
-	PIXEL_FORMAT_ANY_16_WITH_ALPHA             = C.ALLEGRO_PIXEL_FORMAT_ANY_16_WITH_ALPHA
-	PIXEL_FORMAT_ANY_24_NO_ALPHA               = C.ALLEGRO_PIXEL_FORMAT_ANY_24_NO_ALPHA
-	PIXEL_FORMAT_ANY_32_NO_ALPHA               = C.ALLEGRO_PIXEL_FORMAT_ANY_32_NO_ALPHA
-	PIXEL_FORMAT_ANY_32_WITH_ALPHA             = C.ALLEGRO_PIXEL_FORMAT_ANY_32_WITH_ALPHA
-	PIXEL_FORMAT_ARGB_8888                     = C.ALLEGRO_PIXEL_FORMAT_ARGB_8888
-	PIXEL_FORMAT_RGBA_8888                     = C.ALLEGRO_PIXEL_FORMAT_RGBA_8888
-	PIXEL_FORMAT_ARGB_4444                     = C.ALLEGRO_PIXEL_FORMAT_ARGB_4444
-	PIXEL_FORMAT_RGB_888                       = C.ALLEGRO_PIXEL_FORMAT_RGB_888
-	PIXEL_FORMAT_RGB_565                       = C.ALLEGRO_PIXEL_FORMAT_RGB_565
-	PIXEL_FORMAT_RGB_555                       = C.ALLEGRO_PIXEL_FORMAT_RGB_555
-	PIXEL_FORMAT_RGBA_5551                     = C.ALLEGRO_PIXEL_FORMAT_RGBA_5551
-	PIXEL_FORMAT_ARGB_1555                     = C.ALLEGRO_PIXEL_FORMAT_ARGB_1555
-	PIXEL_FORMAT_ABGR_8888                     = C.ALLEGRO_PIXEL_FORMAT_ABGR_8888
-	PIXEL_FORMAT_XBGR_8888                     = C.ALLEGRO_PIXEL_FORMAT_XBGR_8888
-	PIXEL_FORMAT_BGR_888                       = C.ALLEGRO_PIXEL_FORMAT_BGR_888
-	PIXEL_FORMAT_BGR_565                       = C.ALLEGRO_PIXEL_FORMAT_BGR_565
-	PIXEL_FORMAT_BGR_555                       = C.ALLEGRO_PIXEL_FORMAT_BGR_555
-	PIXEL_FORMAT_RGBX_8888                     = C.ALLEGRO_PIXEL_FORMAT_RGBX_8888
-	PIXEL_FORMAT_XRGB_8888                     = C.ALLEGRO_PIXEL_FORMAT_XRGB_8888
-	PIXEL_FORMAT_ABGR_F32                      = C.ALLEGRO_PIXEL_FORMAT_ABGR_F32
-	PIXEL_FORMAT_ABGR_8888_LE                  = C.ALLEGRO_PIXEL_FORMAT_ABGR_8888_LE
-	PIXEL_FORMAT_RGBA_4444                     = C.ALLEGRO_PIXEL_FORMAT_RGBA_4444
+	PIXEL_FORMAT_ANY                  PixelFormat = C.ALLEGRO_PIXEL_FORMAT_ANY
+	PIXEL_FORMAT_ANY_NO_ALPHA                     = C.ALLEGRO_PIXEL_FORMAT_ANY_NO_ALPHA
+	PIXEL_FORMAT_ANY_WITH_ALPHA                   = C.ALLEGRO_PIXEL_FORMAT_ANY_WITH_ALPHA
+	PIXEL_FORMAT_ANY_15_NO_ALPHA                  = C.ALLEGRO_PIXEL_FORMAT_ANY_15_NO_ALPHA
+	PIXEL_FORMAT_ANY_16_NO_ALPHA                  = C.ALLEGRO_PIXEL_FORMAT_ANY_16_NO_ALPHA
+	PIXEL_FORMAT_ANY_16_WITH_ALPHA                = C.ALLEGRO_PIXEL_FORMAT_ANY_16_WITH_ALPHA
+	PIXEL_FORMAT_ANY_24_NO_ALPHA                  = C.ALLEGRO_PIXEL_FORMAT_ANY_24_NO_ALPHA
+	PIXEL_FORMAT_ANY_32_NO_ALPHA                  = C.ALLEGRO_PIXEL_FORMAT_ANY_32_NO_ALPHA
+	PIXEL_FORMAT_ANY_32_WITH_ALPHA                = C.ALLEGRO_PIXEL_FORMAT_ANY_32_WITH_ALPHA
+	PIXEL_FORMAT_ARGB_8888                        = C.ALLEGRO_PIXEL_FORMAT_ARGB_8888
+	PIXEL_FORMAT_RGBA_8888                        = C.ALLEGRO_PIXEL_FORMAT_RGBA_8888
+	PIXEL_FORMAT_ARGB_4444                        = C.ALLEGRO_PIXEL_FORMAT_ARGB_4444
+	PIXEL_FORMAT_RGB_888                          = C.ALLEGRO_PIXEL_FORMAT_RGB_888
+	PIXEL_FORMAT_RGB_565                          = C.ALLEGRO_PIXEL_FORMAT_RGB_565
+	PIXEL_FORMAT_RGB_555                          = C.ALLEGRO_PIXEL_FORMAT_RGB_555
+	PIXEL_FORMAT_RGBA_5551                        = C.ALLEGRO_PIXEL_FORMAT_RGBA_5551
+	PIXEL_FORMAT_ARGB_1555                        = C.ALLEGRO_PIXEL_FORMAT_ARGB_1555
+	PIXEL_FORMAT_ABGR_8888                        = C.ALLEGRO_PIXEL_FORMAT_ABGR_8888
+	PIXEL_FORMAT_XBGR_8888                        = C.ALLEGRO_PIXEL_FORMAT_XBGR_8888
+	PIXEL_FORMAT_BGR_888                          = C.ALLEGRO_PIXEL_FORMAT_BGR_888
+	PIXEL_FORMAT_BGR_565                          = C.ALLEGRO_PIXEL_FORMAT_BGR_565
+	PIXEL_FORMAT_BGR_555                          = C.ALLEGRO_PIXEL_FORMAT_BGR_555
+	PIXEL_FORMAT_RGBX_8888                        = C.ALLEGRO_PIXEL_FORMAT_RGBX_8888
+	PIXEL_FORMAT_XRGB_8888                        = C.ALLEGRO_PIXEL_FORMAT_XRGB_8888
+	PIXEL_FORMAT_ABGR_F32                         = C.ALLEGRO_PIXEL_FORMAT_ABGR_F32
+	PIXEL_FORMAT_ABGR_8888_LE                     = C.ALLEGRO_PIXEL_FORMAT_ABGR_8888_LE
+	PIXEL_FORMAT_RGBA_4444                        = C.ALLEGRO_PIXEL_FORMAT_RGBA_4444
+	PIXEL_FORMAT_SINGLE_CHANNEL_8                 = C.ALLEGRO_PIXEL_FORMAT_SINGLE_CHANNEL_8
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT1             = C.ALLEGRO_PIXEL_FORMAT_COMPRESSED_RGBA_DXT1
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT3             = C.ALLEGRO_PIXEL_FORMAT_COMPRESSED_RGBA_DXT3
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT5             = C.ALLEGRO_PIXEL_FORMAT_COMPRESSED_RGBA_DXT5
 )
 
 type BlendingOperation int
@@ -143,7 +154,7 @@ func AddNewBitmapFlag(flags BitmapFlags) {
 // memory bitmaps and display bitmaps may be slow.
 func CreateBitmap(w, h int) *Bitmap {
 	bitmap := (*Bitmap)(C.al_create_bitmap(C.int(w), C.int(h)))
-	//runtime.SetFinalizer(bitmap, bitmap.Destroy)
+	trackResource(unsafe.Pointer(bitmap), "bitmap")
 	return bitmap
 }
 
@@ -157,15 +168,26 @@ func ClearToColor(c Color) {
 func LoadBitmap(filename string) (*Bitmap, error) {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
-	bmp := C.al_load_bitmap(filename_)
-	if bmp == nil {
-		return nil, fmt.Errorf("failed to load bitmap at '%s'", filename)
+	var bmp *C.ALLEGRO_BITMAP
+	err := checkErrno("al_load_bitmap", filename, func() bool {
+		bmp = C.al_load_bitmap(filename_)
+		return bmp != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	bitmap := (*Bitmap)(bmp)
-	//runtime.SetFinalizer(bitmap, bitmap.Destroy)
+	trackResource(unsafe.Pointer(bitmap), "bitmap")
 	return bitmap, nil
 }
 
+// LoadBitmapF() is the same as f.LoadBitmap(ident).
+//
+// Deprecated: use File.LoadBitmap().
+func LoadBitmapF(f *File, ident string) (*Bitmap, error) {
+	return f.LoadBitmap(ident)
+}
+
 // Enables or disables deferred bitmap drawing. This allows for efficient
 // drawing of many bitmaps that share a parent bitmap, such as sub-bitmaps from
 // a tilesheet or simply identical bitmaps. Drawing bitmaps that do not share a
@@ -185,12 +207,25 @@ func IsBitmapDrawingHeld() bool {
 	return bool(C.al_is_bitmap_drawing_held())
 }
 
+// WithHeldDrawing() calls f with deferred bitmap drawing turned on, then
+// returns it to its previous state, which flushes the held draws unless an
+// outer call is still holding them. The restrictions of HoldBitmapDrawing()
+// apply inside f.
+func WithHeldDrawing(f func()) {
+	held := IsBitmapDrawingHeld()
+	if !held {
+		HoldBitmapDrawing(true)
+		defer HoldBitmapDrawing(false)
+	}
+	f()
+}
+
 // This function selects the bitmap to which all subsequent drawing operations
 // in the calling thread will draw to. To return to drawing to a display, set
 // the backbuffer of the display as the target bitmap, using al_get_backbuffer.
 // As a convenience, you may also use al_set_target_backbuffer.
 func SetTargetBitmap(bmp *Bitmap) {
-	C.al_set_target_bitmap((*C.ALLEGRO_BITMAP)(bmp))
+	setTargetBitmap(bmp)
 }
 
 // Return the target bitmap of the calling thread.
@@ -276,10 +311,14 @@ func SeparateBlender() (op BlendingOperation, src, dst BlendingValue, alpha_op B
 	return BlendingOperation(cop), BlendingValue(csrc), BlendingValue(cdst), BlendingOperation(calpha_op), BlendingValue(calpha_src), BlendingValue(calpha_dst)
 }
 
+// Returns the color currently used for constant color blending (white by
+// default).
 func BlendColor() Color {
 	return Color(C.al_get_blend_color())
 }
 
+// Sets the color to use for blending when using the CONST_COLOR or
+// INVERSE_CONST_COLOR blend functions.
 func SetBlendColor(c Color) {
 	C.al_set_blend_color(C.ALLEGRO_COLOR(c))
 }
@@ -292,7 +331,7 @@ func CurrentDisplay() *Display {
 
 // Same as al_set_target_bitmap(al_get_backbuffer(display));
 func SetTargetBackbuffer(d *Display) {
-	C.al_set_target_backbuffer((*C.ALLEGRO_DISPLAY)(d))
+	setTargetBackbuffer(d)
 }
 
 //}}}
@@ -304,11 +343,16 @@ func SetTargetBackbuffer(d *Display) {
 func (bmp *Bitmap) Save(filename string) error {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
-	ok := C.al_save_bitmap(filename_, (*C.ALLEGRO_BITMAP)(bmp))
-	if !ok {
-		return fmt.Errorf("failed to save bitmap at '%s'", filename)
-	}
-	return nil
+	return checkErrno("al_save_bitmap", filename, func() bool {
+		return bool(C.al_save_bitmap(filename_, (*C.ALLEGRO_BITMAP)(bmp)))
+	})
+}
+
+// SaveF() is the same as f.SaveBitmap(ident, bmp).
+//
+// Deprecated: use File.SaveBitmap().
+func (bmp *Bitmap) SaveF(f *File, ident string) error {
+	return f.SaveBitmap(ident, bmp)
 }
 
 // Returns the pixel format of a bitmap.
@@ -324,6 +368,13 @@ func (bmp *Bitmap) Flags() BitmapFlags {
 // Destroys the given bitmap, freeing all resources used by it. This function
 // does nothing if the bitmap argument is NULL.
 func (bmp *Bitmap) Destroy() {
+	if releaseResource(unsafe.Pointer(bmp), "bitmap") == nil {
+		bmp.destroy()
+	}
+}
+
+func (bmp *Bitmap) destroy() {
+	bmp.SetMaterial(nil)
 	C.al_destroy_bitmap((*C.ALLEGRO_BITMAP)(bmp))
 }
 
@@ -573,7 +624,7 @@ func (bmp *Bitmap) Lock(format PixelFormat, flags LockFlags) (*LockedRegion, err
 	if reg == nil {
 		return nil, errors.New("failed to lock bitmap; is it already locked?")
 	}
-	return (*LockedRegion)(reg), nil
+	return &LockedRegion{reg: reg, width: bmp.Width(), height: bmp.Height()}, nil
 }
 
 // Like al_lock_bitmap, but only locks a specific area of the bitmap. If the
@@ -595,7 +646,7 @@ func (bmp *Bitmap) LockRegion(x, y, width, height int, format PixelFormat, flags
 	if reg == nil {
 		return nil, errors.New("failed to lock bitmap region; is it already locked?")
 	}
-	return (*LockedRegion)(reg), nil
+	return &LockedRegion{reg: reg, width: width, height: height}, nil
 }
 
 // Returns whether or not a bitmap is already locked.
@@ -629,6 +680,7 @@ func (bmp *Bitmap) CreateSubBitmap(x, y, w, h int) (*Bitmap, error) {
 	if sub == nil {
 		return nil, errors.New("failed to create sub-bitmap")
 	}
+	trackResource(unsafe.Pointer(sub), "bitmap")
 	return (*Bitmap)(sub), nil
 }
 
@@ -651,6 +703,64 @@ func (bmp *Bitmap) ParentBitmap() (*Bitmap, error) {
 	return (*Bitmap)(par), nil
 }
 
+// For a sub-bitmap, changes the parent, position and size. This is the same as
+// destroying the bitmap and re-creating it with al_create_sub_bitmap - except
+// the bitmap pointer stays the same. This has many uses, for example an
+// animation player could return a single bitmap which can just be re-parented
+// to different animation frames without having to re-draw the contents.
+func (bmp *Bitmap) Reparent(parent *Bitmap, x, y, w, h int) error {
+	if bmp == nil || parent == nil {
+		return BitmapIsNull
+	}
+	C.al_reparent_bitmap((*C.ALLEGRO_BITMAP)(bmp), (*C.ALLEGRO_BITMAP)(parent),
+		C.int(x), C.int(y), C.int(w), C.int(h))
+	return nil
+}
+
+// For a sub-bitmap, return its x position within the parent.
+func (bmp *Bitmap) X() int {
+	if bmp == nil {
+		return 0
+	}
+	return int(C.al_get_bitmap_x((*C.ALLEGRO_BITMAP)(bmp)))
+}
+
+// For a sub-bitmap, return its y position within the parent.
+func (bmp *Bitmap) Y() int {
+	if bmp == nil {
+		return 0
+	}
+	return int(C.al_get_bitmap_y((*C.ALLEGRO_BITMAP)(bmp)))
+}
+
+// SubBitmaps() slices the bitmap into a grid of w by h sub-bitmaps, such as
+// the frames of a sprite sheet, in row-major order. Any partial cells at the
+// right and bottom edges are left out. On failure, the sub-bitmaps created so
+// far are destroyed.
+func (bmp *Bitmap) SubBitmaps(w, h int) ([]*Bitmap, error) {
+	if bmp == nil {
+		return nil, BitmapIsNull
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid sub-bitmap size %dx%d", w, h)
+	}
+	cols, rows := bmp.Width()/w, bmp.Height()/h
+	subs := make([]*Bitmap, 0, cols*rows)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			sub, err := bmp.CreateSubBitmap(x*w, y*h, w, h)
+			if err != nil {
+				for _, s := range subs {
+					s.Destroy()
+				}
+				return nil, err
+			}
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
 // Create a new bitmap with al_create_bitmap, and copy the pixel data from the
 // old bitmap across.
 func (bmp *Bitmap) Clone() (*Bitmap, error) {
@@ -661,9 +771,54 @@ func (bmp *Bitmap) Clone() (*Bitmap, error) {
 	if clone == nil {
 		return nil, errors.New("failed to clone bitmap")
 	}
+	trackResource(unsafe.Pointer(clone), "bitmap")
 	return (*Bitmap)(clone), nil
 }
 
+// Converts the bitmap to the current bitmap flags and format. The bitmap will
+// be as if it was created anew with al_create_bitmap but retain its contents.
+// This is typically used to turn a memory bitmap loaded on another thread into
+// a video bitmap.
+func (bmp *Bitmap) Convert() {
+	if bmp == nil {
+		return
+	}
+	C.al_convert_bitmap((*C.ALLEGRO_BITMAP)(bmp))
+}
+
+// ConvertTo() converts the bitmap to the given pixel format, keeping its
+// current flags, e.g. to normalise loaded art before processing its pixels.
+// The new bitmap format and flags are left unchanged.
+func (bmp *Bitmap) ConvertTo(format PixelFormat) error {
+	if bmp == nil {
+		return BitmapIsNull
+	}
+	oldFormat, oldFlags := NewBitmapFormat(), NewBitmapFlags()
+	defer func() {
+		SetNewBitmapFormat(oldFormat)
+		SetNewBitmapFlags(oldFlags)
+	}()
+	SetNewBitmapFormat(format)
+	SetNewBitmapFlags(bmp.Flags())
+	bmp.Convert()
+	// The PIXEL_FORMAT_ANY_* formats, which come first, match whatever the
+	// driver picked.
+	if format > PIXEL_FORMAT_ANY_32_WITH_ALPHA && bmp.Format() != format {
+		return fmt.Errorf("failed to convert bitmap to %s", format)
+	}
+	return nil
+}
+
+// If you create a bitmap when there is no current display (for example because
+// you have not called al_create_display in the current thread) and are using
+// the ALLEGRO_CONVERT_BITMAP bitmap flag (which is set by default) then the
+// bitmap will be created successfully, but as a memory bitmap. This function
+// converts all such bitmaps to proper video bitmaps belonging to the current
+// display.
+func ConvertMemoryBitmaps() {
+	C.al_convert_memory_bitmaps()
+}
+
 // D3D and OpenGL allow sharing a texture in a way so it can be used for
 // multiple windows. Each ALLEGRO_BITMAP created with al_create_bitmap however
 // is usually tied to a single ALLEGRO_DISPLAY. This function can be used to
@@ -760,28 +915,44 @@ var _ draw.Image = (*Bitmap)(nil)
 // Convert r, g, b (ranging from 0-255) into an ALLEGRO_COLOR, using 255 for
 // alpha.
 func MapRGB(r, g, b byte) Color {
+	if fastColor {
+		return packColor(float32(r)/255, float32(g)/255, float32(b)/255, 1)
+	}
 	return Color(C.al_map_rgb(C.uchar(r), C.uchar(g), C.uchar(b)))
 }
 
 // Convert r, g, b, a (ranging from 0-255) into an ALLEGRO_COLOR.
 func MapRGBA(r, g, b, a byte) Color {
+	if fastColor {
+		return packColor(float32(r)/255, float32(g)/255, float32(b)/255, float32(a)/255)
+	}
 	return (Color)(C.al_map_rgba(C.uchar(r), C.uchar(g), C.uchar(b), C.uchar(a)))
 }
 
 // Convert r, g, b, (ranging from 0.0f-1.0f) into an ALLEGRO_COLOR, using 1.0f
 // for alpha.
 func MapRGBf(r, g, b float32) Color {
+	if fastColor {
+		return packColor(r, g, b, 1)
+	}
 	return (Color)(C.al_map_rgb_f(C.float(r), C.float(g), C.float(b)))
 }
 
 // Convert r, g, b, a (ranging from 0.0f-1.0f) into an ALLEGRO_COLOR.
 func MapRGBAf(r, g, b, a float32) Color {
+	if fastColor {
+		return packColor(r, g, b, a)
+	}
 	return (Color)(C.al_map_rgba_f(C.float(r), C.float(g), C.float(b), C.float(a)))
 }
 
 // Retrieves components of an ALLEGRO_COLOR, ignoring alpha Components will
 // range from 0-255.
 func (c Color) UnmapRGB() (byte, byte, byte) {
+	if fastColor {
+		r, g, b, _ := unpackColor(c)
+		return unitToByte(r), unitToByte(g), unitToByte(b)
+	}
 	var r, g, b C.uchar
 	C.al_unmap_rgb((C.ALLEGRO_COLOR)(c), &r, &g, &b)
 	return byte(r), byte(g), byte(b)
@@ -789,6 +960,10 @@ func (c Color) UnmapRGB() (byte, byte, byte) {
 
 // Retrieves components of an ALLEGRO_COLOR. Components will range from 0-255.
 func (c Color) UnmapRGBA() (byte, byte, byte, byte) {
+	if fastColor {
+		r, g, b, a := unpackColor(c)
+		return unitToByte(r), unitToByte(g), unitToByte(b), unitToByte(a)
+	}
 	var r, g, b, a C.uchar
 	C.al_unmap_rgba((C.ALLEGRO_COLOR)(c), &r, &g, &b, &a)
 	return byte(r), byte(g), byte(b), byte(a)
@@ -797,6 +972,10 @@ func (c Color) UnmapRGBA() (byte, byte, byte, byte) {
 // Retrieves components of an ALLEGRO_COLOR, ignoring alpha. Components will
 // range from 0.0f-1.0f.
 func (c Color) UnmapRGBf() (float32, float32, float32) {
+	if fastColor {
+		r, g, b, _ := unpackColor(c)
+		return r, g, b
+	}
 	var r, g, b C.float
 	C.al_unmap_rgb_f((C.ALLEGRO_COLOR)(c), &r, &g, &b)
 	return float32(r), float32(g), float32(b)
@@ -805,6 +984,9 @@ func (c Color) UnmapRGBf() (float32, float32, float32) {
 // Retrieves components of an ALLEGRO_COLOR. Components will range from
 // 0.0f-1.0f.
 func (c Color) UnmapRGBAf() (float32, float32, float32, float32) {
+	if fastColor {
+		return unpackColor(c)
+	}
 	var r, g, b, a C.float
 	C.al_unmap_rgba_f((C.ALLEGRO_COLOR)(c), &r, &g, &b, &a)
 	return float32(r), float32(g), float32(b), float32(a)
@@ -814,20 +996,50 @@ func (c Color) UnmapRGBAf() (float32, float32, float32, float32) {
 
 // Miscellaneous Instance Methods {{{
 
-func (reg *LockedRegion) Data() uintptr {
-	return uintptr((*C.struct_ALLEGRO_LOCKED_REGION)(reg).data)
+// Returns the locked memory as a slice, which refers to Allegro's memory
+// rather than a copy. Row y starts at byte Offset(y) and is followed by
+// width * PixelSize() bytes of pixels. The slice ends with the last pixel of
+// the last row in memory, so it is a little shorter than pitch * height.
+// When the rows are stored bottom-up, the slice begins at the last row and
+// the offsets decrease as y increases. The slice must not be used after the
+// bitmap is unlocked.
+func (reg *LockedRegion) Data() []byte {
+	if reg.width <= 0 || reg.height <= 0 {
+		return nil
+	}
+	pitch := reg.Pitch()
+	p := unsafe.Pointer(reg.reg.data)
+	if pitch < 0 {
+		p = unsafe.Add(p, (reg.height-1)*pitch)
+		pitch = -pitch
+	}
+	n := (reg.height-1)*pitch + reg.width*reg.PixelSize()
+	return unsafe.Slice((*byte)(p), n)
+}
+
+// Returns the index within Data() at which row y starts.
+func (reg *LockedRegion) Offset(y int) int {
+	pitch := reg.Pitch()
+	if pitch < 0 {
+		return (reg.height - 1 - y) * -pitch
+	}
+	return y * pitch
 }
 
+// Returns the pixel format of the locked memory.
 func (reg *LockedRegion) Format() PixelFormat {
-	return PixelFormat((*C.struct_ALLEGRO_LOCKED_REGION)(reg).format)
+	return PixelFormat(reg.reg.format)
 }
 
+// Returns the number of bytes between the start of one row and the next. It
+// is negative if the rows are stored bottom-up.
 func (reg *LockedRegion) Pitch() int {
-	return int((*C.struct_ALLEGRO_LOCKED_REGION)(reg).pitch)
+	return int(reg.reg.pitch)
 }
 
+// Returns the number of bytes used by each pixel.
 func (reg *LockedRegion) PixelSize() int {
-	return int((*C.struct_ALLEGRO_LOCKED_REGION)(reg).pixel_size)
+	return int(reg.reg.pixel_size)
 }
 
 // Return the number of bytes that a pixel of the given format occupies.
@@ -840,16 +1052,53 @@ func (format PixelFormat) PixelFormatBits() int {
 	return int(C.al_get_pixel_format_bits(C.int(format)))
 }
 
+// Return the number of bytes that a block of pixels with this format
+// occupies.
+func (format PixelFormat) BlockSize() int {
+	return int(C.al_get_pixel_block_size(C.int(format)))
+}
+
+// Return the width of the pixel block of this format.
+func (format PixelFormat) BlockWidth() int {
+	return int(C.al_get_pixel_block_width(C.int(format)))
+}
+
+// Return the height of the pixel block of this format.
+func (format PixelFormat) BlockHeight() int {
+	return int(C.al_get_pixel_block_height(C.int(format)))
+}
+
+// IsCompressed() returns true for the block compressed formats, which can't
+// be locked pixel by pixel or drawn to.
+func (format PixelFormat) IsCompressed() bool {
+	switch format {
+	case PIXEL_FORMAT_COMPRESSED_RGBA_DXT1,
+		PIXEL_FORMAT_COMPRESSED_RGBA_DXT3,
+		PIXEL_FORMAT_COMPRESSED_RGBA_DXT5:
+		return true
+	}
+	return false
+}
+
 // Loads an image from an ALLEGRO_FILE stream into an ALLEGRO_BITMAP. The file
 // type is determined by the passed 'ident' parameter, which is a file name
-// extension including the leading dot.
+// extension including the leading dot. If 'ident' is empty, the file type is
+// determined from the contents of the file instead.
 func (f *File) LoadBitmap(ident string) (*Bitmap, error) {
-	ident_ := C.CString(ident)
-	defer freeString(ident_)
-	bmp := C.al_load_bitmap_f((*C.ALLEGRO_FILE)(f), ident_)
-	if bmp == nil {
-		return nil, errors.New("failed to load bitmap from file")
+	var ident_ *C.char
+	if ident != "" {
+		ident_ = C.CString(ident)
+		defer freeString(ident_)
+	}
+	var bmp *C.ALLEGRO_BITMAP
+	err := checkErrno("al_load_bitmap_f", ident, func() bool {
+		bmp = C.al_load_bitmap_f((*C.ALLEGRO_FILE)(f), ident_)
+		return bmp != nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	trackResource(unsafe.Pointer(bmp), "bitmap")
 	return (*Bitmap)(bmp), nil
 }
 
@@ -859,11 +1108,9 @@ func (f *File) LoadBitmap(ident string) (*Bitmap, error) {
 func (f *File) SaveBitmap(ident string, bmp *Bitmap) error {
 	ident_ := C.CString(ident)
 	defer freeString(ident_)
-	ok := bool(C.al_save_bitmap_f((*C.ALLEGRO_FILE)(f), ident_, (*C.ALLEGRO_BITMAP)(bmp)))
-	if !ok {
-		return errors.New("failed to save bitmap to file")
-	}
-	return nil
+	return checkErrno("al_save_bitmap_f", ident, func() bool {
+		return bool(C.al_save_bitmap_f((*C.ALLEGRO_FILE)(f), ident_, (*C.ALLEGRO_BITMAP)(bmp)))
+	})
 }
 
 //}}}
@@ -874,9 +1121,16 @@ func (f *File) SaveBitmap(ident string, bmp *Bitmap) error {
 // functions that operate on the target bitmap, e.g. the drawing methods
 // provided by the primitives addon.
 func (bmp *Bitmap) AsTarget(f func()) *Bitmap {
-	old := TargetBitmap()
-	SetTargetBitmap(bmp)
-	f()
-	SetTargetBitmap(old)
+	RenderTo(bmp, f)
 	return bmp
 }
+
+// RenderTo() calls f with bmp as the target bitmap, then restores the previous
+// target, even if f panics. It is the usual way to draw off-screen, e.g. into
+// a lighting buffer or a minimap.
+func RenderTo(bmp *Bitmap, f func()) {
+	WithState(STATE_TARGET_BITMAP, func() {
+		SetTargetBitmap(bmp)
+		f()
+	})
+}
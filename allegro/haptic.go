@@ -0,0 +1,178 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+/*
+static bool upload_rumble(ALLEGRO_HAPTIC *hap, double strong, double weak,
+		double delay, double length, ALLEGRO_HAPTIC_EFFECT_ID *id) {
+	ALLEGRO_HAPTIC_EFFECT effect = {0};
+	effect.type = ALLEGRO_HAPTIC_RUMBLE;
+	effect.replay.delay = delay;
+	effect.replay.length = length;
+	effect.data.rumble.strong_magnitude = strong;
+	effect.data.rumble.weak_magnitude = weak;
+	return al_upload_haptic_effect(hap, &effect, id);
+}
+*/
+import "C"
+import (
+	"errors"
+)
+
+// The haptic API is part of Allegro's unstable API, so it is bound here with
+// ALLEGRO_UNSTABLE defined. Rumble effects, the only kind supported by most
+// gamepads, have shortcuts here; other effects are in hapticeffect.go.
+
+type Haptic C.ALLEGRO_HAPTIC
+
+type HapticEffectID C.ALLEGRO_HAPTIC_EFFECT_ID
+
+type HapticCapabilities int
+
+const (
+	HAPTIC_RUMBLE     HapticCapabilities = C.ALLEGRO_HAPTIC_RUMBLE
+	HAPTIC_PERIODIC                      = C.ALLEGRO_HAPTIC_PERIODIC
+	HAPTIC_CONSTANT                      = C.ALLEGRO_HAPTIC_CONSTANT
+	HAPTIC_SPRING                        = C.ALLEGRO_HAPTIC_SPRING
+	HAPTIC_FRICTION                      = C.ALLEGRO_HAPTIC_FRICTION
+	HAPTIC_DAMPER                        = C.ALLEGRO_HAPTIC_DAMPER
+	HAPTIC_INERTIA                       = C.ALLEGRO_HAPTIC_INERTIA
+	HAPTIC_RAMP                          = C.ALLEGRO_HAPTIC_RAMP
+	HAPTIC_SQUARE                        = C.ALLEGRO_HAPTIC_SQUARE
+	HAPTIC_TRIANGLE                      = C.ALLEGRO_HAPTIC_TRIANGLE
+	HAPTIC_SINE                          = C.ALLEGRO_HAPTIC_SINE
+	HAPTIC_SAW_UP                        = C.ALLEGRO_HAPTIC_SAW_UP
+	HAPTIC_SAW_DOWN                      = C.ALLEGRO_HAPTIC_SAW_DOWN
+	HAPTIC_CUSTOM                        = C.ALLEGRO_HAPTIC_CUSTOM
+	HAPTIC_GAIN                          = C.ALLEGRO_HAPTIC_GAIN
+	HAPTIC_ANGLE                         = C.ALLEGRO_HAPTIC_ANGLE
+	HAPTIC_RADIUS                        = C.ALLEGRO_HAPTIC_RADIUS
+	HAPTIC_AZIMUTH                       = C.ALLEGRO_HAPTIC_AZIMUTH
+	HAPTIC_AUTOCENTER                    = C.ALLEGRO_HAPTIC_AUTOCENTER
+)
+
+// Installs the haptic (force feedback) device subsystem. This must be called
+// before using any other haptic-related functions.
+func InstallHaptic() error {
+	if !bool(C.al_install_haptic()) {
+		return errors.New("failed to install haptic subsystem")
+	}
+	return nil
+}
+
+// Uninstalls the haptic device subsystem. This is useful since on some
+// platforms haptic effects are bound to the active display.
+func UninstallHaptic() {
+	C.al_uninstall_haptic()
+}
+
+// Returns true if the haptic device subsystem is installed, false if not.
+func IsHapticInstalled() bool {
+	return bool(C.al_is_haptic_installed())
+}
+
+// Returns true if the joystick supports haptic feedback.
+func (joy *Joystick) IsHaptic() bool {
+	return bool(C.al_is_joystick_haptic((*C.ALLEGRO_JOYSTICK)(joy)))
+}
+
+// Returns the haptic device that represents the force feedback of the
+// joystick.
+func (joy *Joystick) Haptic() (*Haptic, error) {
+	h := C.al_get_haptic_from_joystick((*C.ALLEGRO_JOYSTICK)(joy))
+	if h == nil {
+		return nil, errors.New("joystick is not haptic")
+	}
+	return (*Haptic)(h), nil
+}
+
+// Releases the haptic device when it is not needed anymore. All effects
+// uploaded to it become invalid.
+func (h *Haptic) Release() error {
+	if !bool(C.al_release_haptic((*C.ALLEGRO_HAPTIC)(h))) {
+		return errors.New("failed to release haptic device")
+	}
+	return nil
+}
+
+// Returns true if the haptic device can currently be used, false if not.
+func (h *Haptic) IsActive() bool {
+	return bool(C.al_is_haptic_active((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns the capabilities of the haptic device.
+func (h *Haptic) Capabilities() HapticCapabilities {
+	return HapticCapabilities(C.al_get_haptic_capabilities((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns true if the haptic device has all of the given capabilities.
+func (h *Haptic) IsCapable(caps HapticCapabilities) bool {
+	return bool(C.al_is_haptic_capable((*C.ALLEGRO_HAPTIC)(h), C.int(caps)))
+}
+
+// Sets the gain of the haptic device, between 0.0 and 1.0, if it supports
+// HAPTIC_GAIN.
+func (h *Haptic) SetGain(gain float64) bool {
+	return bool(C.al_set_haptic_gain((*C.ALLEGRO_HAPTIC)(h), C.double(gain)))
+}
+
+// Returns the current gain of the device.
+func (h *Haptic) Gain() float64 {
+	return float64(C.al_get_haptic_gain((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns the maximum amount of effects that can be uploaded to the device at
+// the same time.
+func (h *Haptic) MaxEffects() int {
+	return int(C.al_get_max_haptic_effects((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Uploads and plays a simple rumble effect with the given intensity, between
+// 0.0 and 1.0, for duration seconds. The returned effect must be released
+// once it has finished.
+func (h *Haptic) Rumble(intensity, duration float64) (*HapticEffectID, error) {
+	var id HapticEffectID
+	if !bool(C.al_rumble_haptic((*C.ALLEGRO_HAPTIC)(h), C.double(intensity),
+		C.double(duration), (*C.ALLEGRO_HAPTIC_EFFECT_ID)(&id))) {
+		return nil, errors.New("failed to play rumble effect")
+	}
+	return &id, nil
+}
+
+// UploadRumble() uploads a rumble effect driving the strong (low frequency)
+// and weak (high frequency) motors with the given magnitudes, between 0.0 and
+// 1.0. It starts delay seconds after being played and lasts for length
+// seconds.
+func (h *Haptic) UploadRumble(strong, weak, delay, length float64) (*HapticEffectID, error) {
+	var id HapticEffectID
+	if !bool(C.upload_rumble((*C.ALLEGRO_HAPTIC)(h), C.double(strong), C.double(weak),
+		C.double(delay), C.double(length), (*C.ALLEGRO_HAPTIC_EFFECT_ID)(&id))) {
+		return nil, errors.New("failed to upload rumble effect")
+	}
+	return &id, nil
+}
+
+// Plays back a previously uploaded haptic effect. The loop parameter tells how
+// many times to repeat the effect.
+func (id *HapticEffectID) Play(loop int) error {
+	if !bool(C.al_play_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id), C.int(loop))) {
+		return errors.New("failed to play haptic effect")
+	}
+	return nil
+}
+
+// Stops playing a previously uploaded haptic effect.
+func (id *HapticEffectID) Stop() bool {
+	return bool(C.al_stop_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id)))
+}
+
+// Returns true if the haptic effect is currently playing.
+func (id *HapticEffectID) IsPlaying() bool {
+	return bool(C.al_is_haptic_effect_playing((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id)))
+}
+
+// Releases a previously uploaded haptic effect from the device it has been
+// uploaded to, allowing for other effects to be uploaded.
+func (id *HapticEffectID) Release() bool {
+	return bool(C.al_release_haptic_effect((*C.ALLEGRO_HAPTIC_EFFECT_ID)(id)))
+}
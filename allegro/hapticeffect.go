@@ -0,0 +1,249 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+/*
+static void set_haptic_rumble(ALLEGRO_HAPTIC_EFFECT *e, double strong, double weak) {
+	e->data.rumble.strong_magnitude = strong;
+	e->data.rumble.weak_magnitude = weak;
+}
+
+static void set_haptic_envelope(ALLEGRO_HAPTIC_ENVELOPE *env, double attack_length,
+		double attack_level, double fade_length, double fade_level) {
+	env->attack_length = attack_length;
+	env->attack_level = attack_level;
+	env->fade_length = fade_length;
+	env->fade_level = fade_level;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_periodic(ALLEGRO_HAPTIC_EFFECT *e, int waveform,
+		double period, double magnitude, double offset, double phase) {
+	e->data.periodic.waveform = waveform;
+	e->data.periodic.period = period;
+	e->data.periodic.magnitude = magnitude;
+	e->data.periodic.offset = offset;
+	e->data.periodic.phase = phase;
+	return &e->data.periodic.envelope;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_constant(ALLEGRO_HAPTIC_EFFECT *e, double level) {
+	e->data.constant.level = level;
+	return &e->data.constant.envelope;
+}
+
+static ALLEGRO_HAPTIC_ENVELOPE *set_haptic_ramp(ALLEGRO_HAPTIC_EFFECT *e, double start, double end) {
+	e->data.ramp.start_level = start;
+	e->data.ramp.end_level = end;
+	return &e->data.ramp.envelope;
+}
+
+static void set_haptic_condition(ALLEGRO_HAPTIC_EFFECT *e, double right_saturation,
+		double left_saturation, double right_coeff, double left_coeff,
+		double deadband, double center) {
+	e->data.condition.right_saturation = right_saturation;
+	e->data.condition.left_saturation = left_saturation;
+	e->data.condition.right_coeff = right_coeff;
+	e->data.condition.left_coeff = left_coeff;
+	e->data.condition.deadband = deadband;
+	e->data.condition.center = center;
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+)
+
+// HapticEnvelope shapes the start and end of constant, periodic and ramp
+// effects. Lengths are in seconds and levels between 0.0 and 1.0.
+type HapticEnvelope struct {
+	AttackLength, AttackLevel float64
+	FadeLength, FadeLevel     float64
+}
+
+// HapticEffect describes a force feedback effect. Type is one of HAPTIC_RUMBLE,
+// HAPTIC_PERIODIC, HAPTIC_CONSTANT, HAPTIC_RAMP or one of the condition
+// effects HAPTIC_SPRING, HAPTIC_FRICTION, HAPTIC_DAMPER and HAPTIC_INERTIA;
+// only the fields for that type are used. Check the device's Capabilities()
+// before uploading anything but rumble.
+type HapticEffect struct {
+	Type HapticCapabilities
+
+	// Direction of the effect, in radians, for devices that support
+	// HAPTIC_ANGLE, HAPTIC_RADIUS or HAPTIC_AZIMUTH.
+	Angle, Radius, Azimuth float64
+
+	// Delay before the effect starts and how long it lasts, in seconds.
+	Delay, Length float64
+
+	// HAPTIC_RUMBLE
+	StrongMagnitude, WeakMagnitude float64
+
+	// HAPTIC_PERIODIC; Waveform is one of HAPTIC_SQUARE, HAPTIC_TRIANGLE,
+	// HAPTIC_SINE, HAPTIC_SAW_UP and HAPTIC_SAW_DOWN.
+	Waveform                         HapticCapabilities
+	Period, Magnitude, Offset, Phase float64
+
+	// HAPTIC_CONSTANT
+	Level float64
+
+	// HAPTIC_RAMP
+	StartLevel, EndLevel float64
+
+	// Condition effects
+	RightSaturation, LeftSaturation float64
+	RightCoeff, LeftCoeff           float64
+	Deadband, Center                float64
+
+	// Used by periodic, constant and ramp effects.
+	Envelope HapticEnvelope
+}
+
+func (e *HapticEffect) raw() (*C.ALLEGRO_HAPTIC_EFFECT, error) {
+	var raw C.ALLEGRO_HAPTIC_EFFECT
+	raw._type = C.int(e.Type)
+	raw.direction.angle = C.double(e.Angle)
+	raw.direction.radius = C.double(e.Radius)
+	raw.direction.azimuth = C.double(e.Azimuth)
+	raw.replay.delay = C.double(e.Delay)
+	raw.replay.length = C.double(e.Length)
+
+	var env *C.ALLEGRO_HAPTIC_ENVELOPE
+	switch e.Type {
+	case HAPTIC_RUMBLE:
+		C.set_haptic_rumble(&raw, C.double(e.StrongMagnitude), C.double(e.WeakMagnitude))
+	case HAPTIC_PERIODIC:
+		env = C.set_haptic_periodic(&raw, C.int(e.Waveform), C.double(e.Period),
+			C.double(e.Magnitude), C.double(e.Offset), C.double(e.Phase))
+	case HAPTIC_CONSTANT:
+		env = C.set_haptic_constant(&raw, C.double(e.Level))
+	case HAPTIC_RAMP:
+		env = C.set_haptic_ramp(&raw, C.double(e.StartLevel), C.double(e.EndLevel))
+	case HAPTIC_SPRING, HAPTIC_FRICTION, HAPTIC_DAMPER, HAPTIC_INERTIA:
+		C.set_haptic_condition(&raw, C.double(e.RightSaturation), C.double(e.LeftSaturation),
+			C.double(e.RightCoeff), C.double(e.LeftCoeff), C.double(e.Deadband), C.double(e.Center))
+	default:
+		return nil, fmt.Errorf("unsupported haptic effect type %d", e.Type)
+	}
+	if env != nil {
+		C.set_haptic_envelope(env, C.double(e.Envelope.AttackLength), C.double(e.Envelope.AttackLevel),
+			C.double(e.Envelope.FadeLength), C.double(e.Envelope.FadeLevel))
+	}
+	return &raw, nil
+}
+
+// Returns the estimated duration in seconds of a single loop of the effect.
+func (e *HapticEffect) Duration() float64 {
+	raw, err := e.raw()
+	if err != nil {
+		return 0
+	}
+	return float64(C.al_get_haptic_effect_duration(raw))
+}
+
+// Returns true if the haptic device can play the effect, false if not.
+func (h *Haptic) IsEffectOK(effect *HapticEffect) bool {
+	raw, err := effect.raw()
+	if err != nil {
+		return false
+	}
+	return bool(C.al_is_haptic_effect_ok((*C.ALLEGRO_HAPTIC)(h), raw))
+}
+
+// Uploads the haptic effect to the device. The returned id can be used to
+// play, stop and release it.
+func (h *Haptic) UploadEffect(effect *HapticEffect) (*HapticEffectID, error) {
+	raw, err := effect.raw()
+	if err != nil {
+		return nil, err
+	}
+	var id HapticEffectID
+	if !bool(C.al_upload_haptic_effect((*C.ALLEGRO_HAPTIC)(h), raw, (*C.ALLEGRO_HAPTIC_EFFECT_ID)(&id))) {
+		return nil, errors.New("failed to upload haptic effect")
+	}
+	return &id, nil
+}
+
+// Uploads the haptic effect to the device and starts playback immediately,
+// repeating it loop times.
+func (h *Haptic) UploadAndPlayEffect(effect *HapticEffect, loop int) (*HapticEffectID, error) {
+	raw, err := effect.raw()
+	if err != nil {
+		return nil, err
+	}
+	var id HapticEffectID
+	if !bool(C.al_upload_and_play_haptic_effect((*C.ALLEGRO_HAPTIC)(h), raw,
+		(*C.ALLEGRO_HAPTIC_EFFECT_ID)(&id), C.int(loop))) {
+		return nil, errors.New("failed to upload and play haptic effect")
+	}
+	return &id, nil
+}
+
+// Turns on or off the automatic centering feature of the device, with the
+// given intensity between 0.0 and 1.0, if it supports HAPTIC_AUTOCENTER.
+func (h *Haptic) SetAutocenter(intensity float64) bool {
+	return bool(C.al_set_haptic_autocenter((*C.ALLEGRO_HAPTIC)(h), C.double(intensity)))
+}
+
+// Returns the current automatic centering intensity of the device.
+func (h *Haptic) Autocenter() float64 {
+	return float64(C.al_get_haptic_autocenter((*C.ALLEGRO_HAPTIC)(h)))
+}
+
+// Returns true if the display has haptic capabilities, e.g. a phone's
+// vibration motor.
+func (d *Display) IsHaptic() bool {
+	return bool(C.al_is_display_haptic((*C.ALLEGRO_DISPLAY)(d)))
+}
+
+// Returns the haptic device that represents the display.
+func (d *Display) Haptic() (*Haptic, error) {
+	h := C.al_get_haptic_from_display((*C.ALLEGRO_DISPLAY)(d))
+	if h == nil {
+		return nil, errors.New("display is not haptic")
+	}
+	return (*Haptic)(h), nil
+}
+
+// Returns true if the mouse currently in use has haptic capabilities.
+func IsMouseHaptic() bool {
+	return bool(C.al_is_mouse_haptic())
+}
+
+// Returns the haptic device that represents the mouse.
+func MouseHaptic() (*Haptic, error) {
+	h := C.al_get_haptic_from_mouse()
+	if h == nil {
+		return nil, errors.New("mouse is not haptic")
+	}
+	return (*Haptic)(h), nil
+}
+
+// Returns true if the keyboard currently in use has haptic capabilities.
+func IsKeyboardHaptic() bool {
+	return bool(C.al_is_keyboard_haptic())
+}
+
+// Returns the haptic device that represents the keyboard.
+func KeyboardHaptic() (*Haptic, error) {
+	h := C.al_get_haptic_from_keyboard()
+	if h == nil {
+		return nil, errors.New("keyboard is not haptic")
+	}
+	return (*Haptic)(h), nil
+}
+
+// Returns true if the touch input device currently in use has haptic
+// capabilities.
+func IsTouchInputHaptic() bool {
+	return bool(C.al_is_touch_input_haptic())
+}
+
+// Returns the haptic device that represents the touch input device.
+func TouchInputHaptic() (*Haptic, error) {
+	h := C.al_get_haptic_from_touch_input()
+	if h == nil {
+		return nil, errors.New("touch input is not haptic")
+	}
+	return (*Haptic)(h), nil
+}
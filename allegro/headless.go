@@ -0,0 +1,29 @@
+package allegro
+
+import (
+	"errors"
+)
+
+var ErrHeadless = errors.New("no displays can be created in headless mode")
+
+var headless bool
+
+// InitHeadless() initializes Allegro without touching any display or audio
+// device, so that code working on bitmaps can run where there is no GPU or
+// window system, such as in CI containers. New bitmaps are memory bitmaps,
+// which can be loaded, saved, locked and drawn to with the software renderer,
+// and CreateDisplay() fails with ErrHeadless. Unlike Run(), this returns once
+// Allegro is ready, which makes it suitable for calling from TestMain().
+func InitHeadless() error {
+	if err := install(); err != nil {
+		return err
+	}
+	headless = true
+	SetNewBitmapFlags(MEMORY_BITMAP)
+	return nil
+}
+
+// IsHeadless() returns whether Allegro was initialized with InitHeadless().
+func IsHeadless() bool {
+	return headless
+}
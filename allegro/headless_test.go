@@ -0,0 +1,25 @@
+package allegro
+
+import (
+	"testing"
+)
+
+func TestHeadless(t *testing.T) {
+	if err := InitHeadless(); err != nil {
+		t.Skip(err)
+	}
+	if _, err := CreateDisplay(32, 32); err != ErrHeadless {
+		t.Fatalf("CreateDisplay() returned %v, want ErrHeadless", err)
+	}
+	bmp := CreateBitmap(4, 4)
+	if bmp == nil {
+		t.Fatal("failed to create memory bitmap")
+	}
+	defer bmp.Destroy()
+	bmp.AsTarget(func() {
+		ClearToColor(MapRGB(255, 0, 0))
+	})
+	if r, g, b := bmp.Pixel(1, 1).UnmapRGB(); r != 255 || g != 0 || b != 0 {
+		t.Errorf("pixel = (%d, %d, %d), want (255, 0, 0)", r, g, b)
+	}
+}
@@ -6,6 +6,7 @@ package image
 import "C"
 import (
 	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
 )
 
 // Initializes the image addon. This registers bitmap format handlers for
@@ -24,6 +25,14 @@ func Uninstall() {
 	C.al_shutdown_image_addon()
 }
 
+// Addon installs the image addon through allegro.Init().
+var Addon = allegro.Addon{Name: "image", Install: Install, Uninstall: Uninstall, Installed: IsInstalled}
+
+// Returns true if the image addon is initialized, otherwise returns false.
+func IsInstalled() bool {
+	return bool(C.al_is_image_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
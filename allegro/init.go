@@ -0,0 +1,127 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"fmt"
+	"sync"
+)
+
+// Addon describes how to install and uninstall an addon, so that Init() can
+// set it up along with the core. Each addon package provides one, e.g.
+// image.Addon or audio.Addon.
+type Addon struct {
+	Name      string
+	Install   func() error
+	Uninstall func() // may be nil
+
+	// Installed reports whether the addon is already installed, so that
+	// Init() leaves addons it didn't install alone. If nil, the addon is
+	// always uninstalled by the cleanup function.
+	Installed func() bool
+}
+
+// InitOptions selects what Init() installs. Addons are installed in order,
+// after the core subsystems.
+type InitOptions struct {
+	Keyboard, Mouse, Joystick, Touch, Haptic bool
+
+	Addons []Addon
+}
+
+var initMutex sync.Mutex
+
+// Init() initializes Allegro along with the selected subsystems and addons,
+// after checking that the Allegro library found at runtime is compatible with
+// the headers the bindings were built against. It returns a function that
+// uninstalls everything in reverse order. If anything fails to install, what
+// was already installed is uninstalled again and the error is returned.
+//
+// Installing is idempotent, so Init() may be called from inside Run(), which
+// is still needed on OS X, and parts of a program may each call Init() with
+// what they need. A cleanup function only uninstalls the system, subsystems
+// and addons that its Init() call installed; those that were already
+// installed are left to whoever installed them.
+func Init(opts InitOptions) (cleanup func(), err error) {
+	initMutex.Lock()
+	defer initMutex.Unlock()
+
+	if err := checkVersion(); err != nil {
+		return nil, err
+	}
+	wasInstalled := IsSystemInstalled()
+	if err := install(); err != nil {
+		return nil, err
+	}
+
+	var uninstalls []func()
+	undo := func() {
+		for i := len(uninstalls) - 1; i >= 0; i-- {
+			uninstalls[i]()
+		}
+		if !wasInstalled && IsSystemInstalled() {
+			UninstallSystem()
+		}
+	}
+
+	subsystems := []struct {
+		enabled   bool
+		name      string
+		installed func() bool
+		install   func() error
+		uninstall func()
+	}{
+		{opts.Keyboard, "keyboard", IsKeyboardInstalled, InstallKeyboard, UninstallKeyboard},
+		{opts.Mouse, "mouse", IsMouseInstalled, InstallMouse, UninstallMouse},
+		{opts.Joystick, "joystick", IsJoystickInstalled, InstallJoystick, UninstallJoystick},
+		{opts.Touch, "touch input", IsTouchInputInstalled, InstallTouchInput, UninstallTouchInput},
+		{opts.Haptic, "haptic", IsHapticInstalled, InstallHaptic, UninstallHaptic},
+	}
+	for _, s := range subsystems {
+		if !s.enabled || s.installed() {
+			continue
+		}
+		if err := s.install(); err != nil {
+			undo()
+			return nil, fmt.Errorf("init %s: %s", s.name, err)
+		}
+		uninstalls = append(uninstalls, s.uninstall)
+	}
+	for _, a := range opts.Addons {
+		if a.Installed != nil && a.Installed() {
+			continue
+		}
+		if err := a.Install(); err != nil {
+			undo()
+			return nil, fmt.Errorf("init %s addon: %s", a.Name, err)
+		}
+		if a.Uninstall != nil {
+			uninstalls = append(uninstalls, a.Uninstall)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			initMutex.Lock()
+			defer initMutex.Unlock()
+			undo()
+		})
+	}, nil
+}
+
+// checkVersion() fails if the runtime library's major or minor version
+// differs from the headers', or if it is older than the headers.
+func checkVersion() error {
+	compiled := uint32(C.ALLEGRO_VERSION_INT)
+	linked := uint32(C.al_get_allegro_version())
+	if compiled>>16 != linked>>16 || compiled&^0xff > linked&^0xff {
+		return fmt.Errorf("allegro library version %s is not compatible with version %s",
+			versionString(linked), versionString(compiled))
+	}
+	return nil
+}
+
+func versionString(v uint32) string {
+	return fmt.Sprintf("%d.%d.%d", v>>24, (v>>16)&255, (v>>8)&255)
+}
@@ -0,0 +1,89 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+
+// Returns the display that had keyboard focus when the state was saved, if
+// any.
+func (state *KeyboardState) Display() *Display {
+	return (*Display)(state.display)
+}
+
+// Returns the display the mouse was on when the state was saved, if any.
+func (state *MouseState) Display() *Display {
+	return (*Display)(state.display)
+}
+
+// InputState is a snapshot of the keyboard and mouse, for code that prefers
+// polling to events, such as quick prototypes. Devices that aren't installed
+// are reported as idle.
+type InputState struct {
+	Keyboard KeyboardState
+	Mouse    MouseState
+}
+
+// PollInput() takes a snapshot of the keyboard and mouse.
+func PollInput() *InputState {
+	var s InputState
+	s.Poll()
+	return &s
+}
+
+// Poll() refreshes the snapshot in place, so that a game loop can reuse one
+// InputState without allocating.
+func (s *InputState) Poll() {
+	s.Keyboard = KeyboardState{}
+	s.Mouse = MouseState{}
+	if IsKeyboardInstalled() {
+		s.Keyboard.Get()
+	}
+	if IsMouseInstalled() {
+		s.Mouse.Get()
+	}
+}
+
+// KeyDown() returns true if the key was held down.
+func (s *InputState) KeyDown(key KeyCode) bool {
+	return s.Keyboard.IsDown(key)
+}
+
+// ButtonDown() returns true if the mouse button was held down. The first
+// button is 1.
+func (s *InputState) ButtonDown(button int) bool {
+	return s.Mouse.ButtonDown(button)
+}
+
+// MousePosition() returns the mouse position relative to its display.
+func (s *InputState) MousePosition() (x, y int) {
+	return s.Mouse.X(), s.Mouse.Y()
+}
+
+// KeyPressed() returns true if the key is down in s but was not in prev, the
+// snapshot from the previous frame.
+func (s *InputState) KeyPressed(prev *InputState, key KeyCode) bool {
+	return s.KeyDown(key) && !prev.KeyDown(key)
+}
+
+// KeyReleased() returns true if the key was down in prev but no longer is.
+func (s *InputState) KeyReleased(prev *InputState, key KeyCode) bool {
+	return !s.KeyDown(key) && prev.KeyDown(key)
+}
+
+// ButtonPressed() returns true if the mouse button is down in s but was not in
+// prev.
+func (s *InputState) ButtonPressed(prev *InputState, button int) bool {
+	return s.ButtonDown(button) && !prev.ButtonDown(button)
+}
+
+// ButtonReleased() returns true if the mouse button was down in prev but no
+// longer is.
+func (s *InputState) ButtonReleased(prev *InputState, button int) bool {
+	return !s.ButtonDown(button) && prev.ButtonDown(button)
+}
+
+// MouseDelta() returns how far the mouse moved since prev, including the
+// wheels.
+func (s *InputState) MouseDelta(prev *InputState) (dx, dy, dz, dw int) {
+	return s.Mouse.X() - prev.Mouse.X(), s.Mouse.Y() - prev.Mouse.Y(),
+		s.Mouse.Z() - prev.Mouse.Z(), s.Mouse.W() - prev.Mouse.W()
+}
@@ -0,0 +1,191 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/gamepad"
+)
+
+// Device is the kind of physical input a binding refers to.
+type Device int
+
+const (
+	DEVICE_KEY Device = iota + 1
+	DEVICE_MOUSE_BUTTON
+	DEVICE_JOYSTICK_BUTTON
+	DEVICE_JOYSTICK_AXIS
+	DEVICE_GAMEPAD_BUTTON
+	DEVICE_GAMEPAD_AXIS
+)
+
+// Binding is a single physical input. Joystick and gamepad bindings match any
+// connected device.
+type Binding struct {
+	Device Device
+
+	// Code is the key code, the mouse button (starting at 1), the joystick
+	// button, the joystick axis, or the gamepad Button or Axis.
+	Code int
+
+	// Stick is the joystick stick of a DEVICE_JOYSTICK_AXIS binding.
+	Stick int
+
+	// Dir is 1 or -1 for axis bindings, to tell which way the axis must be
+	// pushed.
+	Dir int
+}
+
+// Key() returns a binding for a key.
+func Key(key allegro.KeyCode) Binding {
+	return Binding{Device: DEVICE_KEY, Code: int(key)}
+}
+
+// MouseButton() returns a binding for a mouse button. The first button is 1.
+func MouseButton(button int) Binding {
+	return Binding{Device: DEVICE_MOUSE_BUTTON, Code: button}
+}
+
+// JoystickButton() returns a binding for a raw joystick button.
+func JoystickButton(button int) Binding {
+	return Binding{Device: DEVICE_JOYSTICK_BUTTON, Code: button}
+}
+
+// JoystickAxis() returns a binding for one direction of a raw joystick axis.
+func JoystickAxis(stick, axis, dir int) Binding {
+	return Binding{Device: DEVICE_JOYSTICK_AXIS, Stick: stick, Code: axis, Dir: sign(dir)}
+}
+
+// GamepadButton() returns a binding for a logical gamepad button.
+func GamepadButton(button gamepad.Button) Binding {
+	return Binding{Device: DEVICE_GAMEPAD_BUTTON, Code: int(button)}
+}
+
+// GamepadAxis() returns a binding for one direction of a logical gamepad
+// axis. Triggers only go one way, so use a positive dir for them.
+func GamepadAxis(axis gamepad.Axis, dir int) Binding {
+	return Binding{Device: DEVICE_GAMEPAD_AXIS, Code: int(axis), Dir: sign(dir)}
+}
+
+func sign(dir int) int {
+	if dir < 0 {
+		return -1
+	}
+	return 1
+}
+
+func dirString(dir int) string {
+	if dir < 0 {
+		return "-"
+	}
+	return "+"
+}
+
+// String() describes the binding in the form stored in configs, e.g.
+// "key:SPACE", "mouse:1", "joybutton:3", "joyaxis:0.1-", "pad:a" or
+// "padaxis:lefty+".
+func (b Binding) String() string {
+	switch b.Device {
+	case DEVICE_KEY:
+		return "key:" + allegro.KeyCode(b.Code).Name()
+	case DEVICE_MOUSE_BUTTON:
+		return fmt.Sprintf("mouse:%d", b.Code)
+	case DEVICE_JOYSTICK_BUTTON:
+		return fmt.Sprintf("joybutton:%d", b.Code)
+	case DEVICE_JOYSTICK_AXIS:
+		return fmt.Sprintf("joyaxis:%d.%d%s", b.Stick, b.Code, dirString(b.Dir))
+	case DEVICE_GAMEPAD_BUTTON:
+		return "pad:" + gamepad.Button(b.Code).String()
+	case DEVICE_GAMEPAD_AXIS:
+		return "padaxis:" + gamepad.Axis(b.Code).String() + dirString(b.Dir)
+	}
+	return "none"
+}
+
+// ParseBinding() parses a binding as returned by String().
+func ParseBinding(s string) (Binding, error) {
+	s = strings.TrimSpace(s)
+	colon := strings.IndexByte(s, ':')
+	if colon < 0 {
+		return Binding{}, fmt.Errorf("invalid binding '%s'", s)
+	}
+	kind, value := s[:colon], s[colon+1:]
+
+	// Axis bindings end with their direction.
+	dir := 1
+	if kind == "joyaxis" || kind == "padaxis" {
+		switch {
+		case strings.HasSuffix(value, "+"):
+		case strings.HasSuffix(value, "-"):
+			dir = -1
+		default:
+			return Binding{}, fmt.Errorf("binding '%s' has no direction", s)
+		}
+		value = value[:len(value)-1]
+	}
+
+	var err error
+	var b Binding
+	switch kind {
+	case "key":
+		var key allegro.KeyCode
+		key, err = allegro.ParseKeyCode(value)
+		b = Key(key)
+	case "mouse":
+		b.Device = DEVICE_MOUSE_BUTTON
+		b.Code, err = strconv.Atoi(value)
+	case "joybutton":
+		b.Device = DEVICE_JOYSTICK_BUTTON
+		b.Code, err = strconv.Atoi(value)
+	case "joyaxis":
+		b = Binding{Device: DEVICE_JOYSTICK_AXIS, Dir: dir}
+		dot := strings.IndexByte(value, '.')
+		if dot < 0 {
+			return Binding{}, fmt.Errorf("invalid binding '%s'", s)
+		}
+		if b.Stick, err = strconv.Atoi(value[:dot]); err == nil {
+			b.Code, err = strconv.Atoi(value[dot+1:])
+		}
+	case "pad":
+		var button gamepad.Button
+		button, err = gamepad.ParseButton(value)
+		b = GamepadButton(button)
+	case "padaxis":
+		var axis gamepad.Axis
+		axis, err = gamepad.ParseAxis(value)
+		b = GamepadAxis(axis, dir)
+	default:
+		return Binding{}, fmt.Errorf("unknown input device '%s'", kind)
+	}
+	if err != nil {
+		return Binding{}, fmt.Errorf("invalid binding '%s': %s", s, err.Error())
+	}
+	return b, nil
+}
+
+// formatBindings() and parseBindings() convert a list of bindings to and from
+// the comma-separated form stored in configs.
+func formatBindings(bindings []Binding) string {
+	strs := make([]string, len(bindings))
+	for i, b := range bindings {
+		strs[i] = b.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+func parseBindings(s string) ([]Binding, error) {
+	var bindings []Binding
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		b, err := ParseBinding(field)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, nil
+}
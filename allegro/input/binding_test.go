@@ -0,0 +1,39 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/ccollins476ad/go-allegro/allegro/gamepad"
+)
+
+func TestParseBinding(t *testing.T) {
+	bindings := []Binding{
+		MouseButton(2),
+		JoystickButton(7),
+		JoystickAxis(1, 0, -1),
+		GamepadButton(gamepad.BUTTON_RIGHT_SHOULDER),
+		GamepadAxis(gamepad.AXIS_TRIGGER_LEFT, 1),
+	}
+	s := formatBindings(bindings)
+	if want := "mouse:2, joybutton:7, joyaxis:1.0-, pad:rightshoulder, padaxis:lefttrigger+"; s != want {
+		t.Errorf("formatBindings() = %q, want %q", s, want)
+	}
+	parsed, err := parseBindings(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(bindings) {
+		t.Fatalf("parsed %d bindings, want %d", len(parsed), len(bindings))
+	}
+	for i := range bindings {
+		if parsed[i] != bindings[i] {
+			t.Errorf("binding %d: got %+v, want %+v", i, parsed[i], bindings[i])
+		}
+	}
+
+	for _, s := range []string{"", "mouse", "joyaxis:1.0", "pad:z", "tablet:1"} {
+		if _, err := ParseBinding(s); err == nil {
+			t.Errorf("ParseBinding(%q) succeeded", s)
+		}
+	}
+}
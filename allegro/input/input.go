@@ -0,0 +1,261 @@
+// Package input maps physical inputs (keys, mouse buttons, joystick buttons
+// and axes, and gamepad buttons and axes) to named actions such as "jump" or
+// "fire", so that games test actions rather than devices and players can
+// rebind them.
+//
+// A Map follows the keyboard, mouse and joysticks through the events passed
+// to HandleEvent(), and gamepads through Update(), which also latches the
+// action states for the frame:
+//
+//	m := input.NewMap()
+//	m.Bind("jump", input.Key(allegro.KEY_SPACE), input.GamepadButton(gamepad.BUTTON_A))
+//	...
+//	m.HandleEvent(ev)  // for every event
+//	m.Update(pad)      // once per frame
+//	if m.Pressed("jump") { ... }
+package input
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/gamepad"
+)
+
+type action struct {
+	bindings   []Binding
+	value      float32
+	down, prev bool
+}
+
+// Map binds actions to inputs.
+type Map struct {
+	// Threshold is how far an axis must be pushed for its action to count
+	// as down. If zero, 0.5 is used.
+	Threshold float32
+
+	actions map[string]*action
+	order   []string
+	raw     map[Binding]float32
+	listen  func(b Binding)
+}
+
+// NewMap() creates a map without any actions.
+func NewMap() *Map {
+	return &Map{
+		actions: make(map[string]*action),
+		raw:     make(map[Binding]float32),
+	}
+}
+
+func (m *Map) action(name string) *action {
+	a, ok := m.actions[name]
+	if !ok {
+		a = &action{}
+		m.actions[name] = a
+		m.order = append(m.order, name)
+	}
+	return a
+}
+
+// Actions() returns the names of the actions, in the order they were first
+// bound.
+func (m *Map) Actions() []string {
+	return m.order
+}
+
+// Bind() adds bindings to an action, creating it if needed.
+func (m *Map) Bind(name string, bindings ...Binding) {
+	a := m.action(name)
+	a.bindings = append(a.bindings, bindings...)
+}
+
+// SetBindings() replaces the bindings of an action, e.g. from a rebinding
+// screen. An empty list leaves the action unbound but keeps it in the map.
+func (m *Map) SetBindings(name string, bindings []Binding) {
+	m.action(name).bindings = append([]Binding(nil), bindings...)
+}
+
+// Bindings() returns the bindings of an action.
+func (m *Map) Bindings(name string) []Binding {
+	if a, ok := m.actions[name]; ok {
+		return a.bindings
+	}
+	return nil
+}
+
+// Listen() makes the next input that is pressed call f, for rebinding
+// screens. Inputs already held don't count, and axes count once pushed past
+// Threshold. Passing nil cancels listening.
+func (m *Map) Listen(f func(b Binding)) {
+	m.listen = f
+}
+
+// Listening() returns whether Listen() is waiting for an input.
+func (m *Map) Listening() bool {
+	return m.listen != nil
+}
+
+func (m *Map) threshold() float32 {
+	if m.Threshold == 0 {
+		return 0.5
+	}
+	return m.Threshold
+}
+
+// set() records the value of an input, and hands it to the listener if it
+// has just been pressed.
+func (m *Map) set(b Binding, v float32) {
+	if m.listen != nil && v >= m.threshold() && m.raw[b] < m.threshold() {
+		f := m.listen
+		m.listen = nil
+		f(b)
+	}
+	if v == 0 {
+		delete(m.raw, b)
+	} else {
+		m.raw[b] = v
+	}
+}
+
+// setAxis() records both directions of an axis.
+func (m *Map) setAxis(b Binding, pos float32) {
+	b.Dir = 1
+	m.set(b, clamp(pos))
+	b.Dir = -1
+	m.set(b, clamp(-pos))
+}
+
+func clamp(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// HandleEvent() follows keyboard, mouse and joystick events, and returns
+// whether ev was one of them.
+func (m *Map) HandleEvent(ev interface{}) bool {
+	switch e := ev.(type) {
+	case allegro.KeyDownEvent:
+		m.set(Key(e.KeyCode()), 1)
+	case allegro.KeyUpEvent:
+		m.set(Key(e.KeyCode()), 0)
+	case allegro.MouseButtonDownEvent:
+		m.set(MouseButton(int(e.Button())), 1)
+	case allegro.MouseButtonUpEvent:
+		m.set(MouseButton(int(e.Button())), 0)
+	case allegro.JoystickButtonDownEvent:
+		m.set(JoystickButton(e.Button()), 1)
+	case allegro.JoystickButtonUpEvent:
+		m.set(JoystickButton(e.Button()), 0)
+	case allegro.JoystickAxisEvent:
+		m.setAxis(JoystickAxis(e.Stick(), e.Axis(), 1), e.Pos())
+	case allegro.JoystickConfigurationEvent:
+		// Inputs held on an unplugged device would never be released.
+		for b := range m.raw {
+			if b.Device == DEVICE_JOYSTICK_BUTTON || b.Device == DEVICE_JOYSTICK_AXIS {
+				delete(m.raw, b)
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Update() reads the given gamepads, which must have been polled, and
+// latches the state of every action for this frame. It should be called once
+// per frame, after the frame's events have been handled.
+func (m *Map) Update(pads ...*gamepad.Gamepad) {
+	for b := gamepad.Button(0); b < gamepad.NUM_BUTTONS; b++ {
+		var v float32
+		for _, pad := range pads {
+			if pad.ButtonDown(b) {
+				v = 1
+			}
+		}
+		m.set(GamepadButton(b), v)
+	}
+	for a := gamepad.Axis(0); a < gamepad.NUM_AXES; a++ {
+		var pos float32
+		for _, pad := range pads {
+			if v := pad.Axis(a); v*v > pos*pos {
+				pos = v
+			}
+		}
+		m.setAxis(GamepadAxis(a, 1), pos)
+	}
+
+	for _, a := range m.actions {
+		a.value = 0
+		for _, b := range a.bindings {
+			if v := m.raw[b]; v > a.value {
+				a.value = v
+			}
+		}
+		a.prev = a.down
+		a.down = a.value >= m.threshold()
+	}
+}
+
+// Value() returns how strongly an action is triggered, from 0 to 1: 1 for
+// buttons held down, or how far a bound axis is pushed.
+func (m *Map) Value(name string) float32 {
+	if a, ok := m.actions[name]; ok {
+		return a.value
+	}
+	return 0
+}
+
+// Down() returns whether the action is held.
+func (m *Map) Down(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.down
+}
+
+// Pressed() returns whether the action started being held this frame.
+func (m *Map) Pressed(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.down && !a.prev
+}
+
+// Released() returns whether the action stopped being held this frame.
+func (m *Map) Released(name string) bool {
+	a, ok := m.actions[name]
+	return ok && !a.down && a.prev
+}
+
+// Axis() combines two actions into an axis from -1 to 1, such as "left" and
+// "right" for horizontal movement.
+func (m *Map) Axis(negative, positive string) float32 {
+	return m.Value(positive) - m.Value(negative)
+}
+
+// Save() writes the bindings of every action to a config section, one key per
+// action.
+func (m *Map) Save(cfg *allegro.Config, section string) {
+	for _, name := range m.order {
+		cfg.SetValue(section, name, formatBindings(m.actions[name].bindings))
+	}
+}
+
+// Load() reads the bindings of the map's actions from a config section, as
+// written by Save(). Actions missing from the config keep their bindings, so
+// a map with the default bindings can be loaded over. Keys for unknown
+// actions are ignored.
+func (m *Map) Load(cfg *allegro.Config, section string) error {
+	for _, name := range m.order {
+		value, err := cfg.Value(section, name)
+		if err != nil {
+			continue
+		}
+		bindings, err := parseBindings(value)
+		if err != nil {
+			return err
+		}
+		m.actions[name].bindings = bindings
+	}
+	return nil
+}
@@ -41,6 +41,7 @@ func InstallJoystick() error {
 // structures are invalidated. If no joystick driver was active, this function
 // does nothing.
 func UninstallJoystick() {
+	forgetEventSource(JoystickEvents.EventSource())
 	C.al_uninstall_joystick()
 }
 
@@ -0,0 +1,95 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <string.h>
+// #include <allegro5/allegro.h>
+/*
+// al_get_joystick_guid() appeared in 5.2.10; older versions report no GUID.
+static bool joystick_guid(ALLEGRO_JOYSTICK *joy, uint8_t *out) {
+#if ALLEGRO_VERSION_INT >= ((5 << 24) | (2 << 16) | (10 << 8))
+	ALLEGRO_JOYSTICK_GUID guid = al_get_joystick_guid(joy);
+	int i;
+	memcpy(out, guid.val, sizeof(guid.val));
+	for (i = 0; i < (int)sizeof(guid.val); i++) {
+		if (guid.val[i] != 0) {
+			return true;
+		}
+	}
+#endif
+	return false;
+}
+*/
+import "C"
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+)
+
+// JoystickID identifies a joystick across sessions, unlike the index passed to
+// GetJoystick() or the *Joystick handle. It is a plain string so that it can
+// be stored in config files alongside saved bindings.
+type JoystickID string
+
+// GUID() returns the device GUID reported by the driver. It is only available
+// with Allegro 5.2.10 or later, and not for every driver.
+func (j *Joystick) GUID() ([16]byte, bool) {
+	var guid [16]byte
+	ok := bool(C.joystick_guid((*C.ALLEGRO_JOYSTICK)(j), (*C.uint8_t)(&guid[0])))
+	return guid, ok
+}
+
+// signature() returns the part of the identifier that describes the kind of
+// device: its GUID where available, otherwise a hash of its name and layout.
+func (j *Joystick) signature() string {
+	if guid, ok := j.GUID(); ok {
+		return "guid:" + hex.EncodeToString(guid[:])
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%d", j.Name(), j.NumButtons())
+	for stick := 0; stick < j.NumSticks(); stick++ {
+		fmt.Fprintf(h, "/%d", j.NumAxes(stick))
+	}
+	return fmt.Sprintf("name:%016x", h.Sum64())
+}
+
+// ID() returns a persistent identifier for the joystick. It is derived from
+// the device GUID where available and from the device name and layout
+// otherwise. Several identical devices are told apart by their order among
+// the connected joysticks, which is stable as long as they stay plugged into
+// the same ports; the first one carries no suffix and the others "#1", "#2"
+// and so on.
+func (j *Joystick) ID() JoystickID {
+	sig := j.signature()
+	n := 0
+	for i := 0; i < NumJoysticks(); i++ {
+		other, err := GetJoystick(i)
+		if err != nil {
+			continue
+		}
+		if other == j {
+			break
+		}
+		if other.signature() == sig {
+			n++
+		}
+	}
+	if n == 0 {
+		return JoystickID(sig)
+	}
+	return JoystickID(fmt.Sprintf("%s#%d", sig, n))
+}
+
+// FindJoystick() returns the connected joystick with the given identifier.
+func FindJoystick(id JoystickID) (*Joystick, bool) {
+	for i := 0; i < NumJoysticks(); i++ {
+		j, err := GetJoystick(i)
+		if err != nil {
+			continue
+		}
+		if j.ID() == id {
+			return j, true
+		}
+	}
+	return nil, false
+}
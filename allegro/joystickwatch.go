@@ -0,0 +1,106 @@
+package allegro
+
+// Joysticks() returns the joysticks currently known to Allegro, as numbered
+// by GetJoystick().
+func Joysticks() []*Joystick {
+	n := NumJoysticks()
+	joysticks := make([]*Joystick, 0, n)
+	for i := 0; i < n; i++ {
+		if j, err := GetJoystick(i); err == nil {
+			joysticks = append(joysticks, j)
+		}
+	}
+	return joysticks
+}
+
+// JoystickWatcher keeps track of the connected joysticks and reports when
+// they are plugged in or unplugged. Allegro only announces that the
+// configuration changed; the watcher calls ReconfigureJoysticks() and works
+// out which joysticks came and went.
+type JoystickWatcher struct {
+	// OnConnect is called for every joystick that has been plugged in.
+	OnConnect func(j *Joystick)
+
+	// OnDisconnect is called for every joystick that has been unplugged. The
+	// handle is no longer active, but its ID() and Name() can still be
+	// read until it is released.
+	OnDisconnect func(j *Joystick)
+
+	joysticks []*Joystick
+}
+
+// NewJoystickWatcher() creates a watcher holding the currently connected
+// joysticks. OnConnect is not called for those.
+func NewJoystickWatcher(onConnect, onDisconnect func(j *Joystick)) *JoystickWatcher {
+	return &JoystickWatcher{
+		OnConnect:    onConnect,
+		OnDisconnect: onDisconnect,
+		joysticks:    Joysticks(),
+	}
+}
+
+// Joysticks() returns the joysticks as of the last check.
+func (w *JoystickWatcher) Joysticks() []*Joystick {
+	return w.joysticks
+}
+
+// HandleEvent() checks for connected and disconnected joysticks when ev is a
+// JoystickConfigurationEvent. It returns whether the joysticks changed.
+func (w *JoystickWatcher) HandleEvent(ev interface{}) bool {
+	if _, ok := ev.(JoystickConfigurationEvent); ok {
+		return w.Check()
+	}
+	return false
+}
+
+// Check() reconfigures the joysticks and calls OnDisconnect and OnConnect for
+// the ones that changed since the last check, in that order.
+func (w *JoystickWatcher) Check() bool {
+	ReconfigureJoysticks()
+	joysticks := Joysticks()
+
+	// Allegro keeps the handles of joysticks that stay connected, so they can
+	// be compared by pointer.
+	current := make(map[*Joystick]bool, len(joysticks))
+	for _, j := range joysticks {
+		current[j] = true
+	}
+	previous := make(map[*Joystick]bool, len(w.joysticks))
+	for _, j := range w.joysticks {
+		previous[j] = true
+	}
+
+	changed := false
+	for _, j := range w.joysticks {
+		if !current[j] || !j.Active() {
+			changed = true
+			if w.OnDisconnect != nil {
+				w.OnDisconnect(j)
+			}
+		}
+	}
+	for _, j := range joysticks {
+		if !previous[j] {
+			changed = true
+			if w.OnConnect != nil {
+				w.OnConnect(j)
+			}
+		}
+	}
+	w.joysticks = joysticks
+	return changed
+}
+
+// Axis() returns the position of an axis, between -1 and 1, or 0 if the
+// stick or axis doesn't exist.
+func (state *JoystickState) Axis(stick, axis int) float32 {
+	if stick < 0 || stick >= len(state.Stick) || axis < 0 || axis >= len(state.Stick[stick].Axis) {
+		return 0
+	}
+	return state.Stick[stick].Axis[axis]
+}
+
+// ButtonDown() returns true if the button is held down.
+func (state *JoystickState) ButtonDown(button int) bool {
+	return button >= 0 && button < len(state.Button) && state.Button[button] != 0
+}
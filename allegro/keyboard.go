@@ -4,6 +4,9 @@ package allegro
 import "C"
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 )
 
 type Keyboard C.ALLEGRO_KEYBOARD
@@ -132,6 +135,30 @@ const (
 	KEY_BACKQUOTE            = C.ALLEGRO_KEY_BACKQUOTE
 	KEY_SEMICOLON2           = C.ALLEGRO_KEY_SEMICOLON2
 	KEY_COMMAND              = C.ALLEGRO_KEY_COMMAND
+	KEY_BACK                 = C.ALLEGRO_KEY_BACK
+	KEY_VOLUME_UP            = C.ALLEGRO_KEY_VOLUME_UP
+	KEY_VOLUME_DOWN          = C.ALLEGRO_KEY_VOLUME_DOWN
+	KEY_SEARCH               = C.ALLEGRO_KEY_SEARCH
+	KEY_DPAD_CENTER          = C.ALLEGRO_KEY_DPAD_CENTER
+	KEY_BUTTON_X             = C.ALLEGRO_KEY_BUTTON_X
+	KEY_BUTTON_Y             = C.ALLEGRO_KEY_BUTTON_Y
+	KEY_DPAD_UP              = C.ALLEGRO_KEY_DPAD_UP
+	KEY_DPAD_DOWN            = C.ALLEGRO_KEY_DPAD_DOWN
+	KEY_DPAD_LEFT            = C.ALLEGRO_KEY_DPAD_LEFT
+	KEY_DPAD_RIGHT           = C.ALLEGRO_KEY_DPAD_RIGHT
+	KEY_SELECT               = C.ALLEGRO_KEY_SELECT
+	KEY_START                = C.ALLEGRO_KEY_START
+	KEY_BUTTON_L1            = C.ALLEGRO_KEY_BUTTON_L1
+	KEY_BUTTON_R1            = C.ALLEGRO_KEY_BUTTON_R1
+	KEY_BUTTON_L2            = C.ALLEGRO_KEY_BUTTON_L2
+	KEY_BUTTON_R2            = C.ALLEGRO_KEY_BUTTON_R2
+	KEY_BUTTON_A             = C.ALLEGRO_KEY_BUTTON_A
+	KEY_BUTTON_B             = C.ALLEGRO_KEY_BUTTON_B
+	KEY_THUMBL               = C.ALLEGRO_KEY_THUMBL
+	KEY_THUMBR               = C.ALLEGRO_KEY_THUMBR
+	KEY_UNKNOWN              = C.ALLEGRO_KEY_UNKNOWN
+	KEY_MODIFIERS            = C.ALLEGRO_KEY_MODIFIERS
+	KEY_MAX                  = C.ALLEGRO_KEY_MAX
 )
 
 //}}}
@@ -160,6 +187,37 @@ const (
 
 //}}}
 
+var keyModifierNames = []struct {
+	mod  KeyModifier
+	name string
+}{
+	{KEYMOD_CTRL, "Ctrl"},
+	{KEYMOD_ALT, "Alt"},
+	{KEYMOD_ALTGR, "AltGr"},
+	{KEYMOD_SHIFT, "Shift"},
+	{KEYMOD_COMMAND, "Command"},
+	{KEYMOD_LWIN, "LWin"},
+	{KEYMOD_RWIN, "RWin"},
+	{KEYMOD_MENU, "Menu"},
+}
+
+// Has() returns true if all of the modifiers in m are set.
+func (mods KeyModifier) Has(m KeyModifier) bool {
+	return mods&m == m
+}
+
+// String() lists the held modifiers in the usual order for shortcuts, e.g.
+// "Ctrl+Shift". Lock and accent states are left out.
+func (mods KeyModifier) String() string {
+	var names []string
+	for _, m := range keyModifierNames {
+		if mods&m.mod != 0 {
+			names = append(names, m.name)
+		}
+	}
+	return strings.Join(names, "+")
+}
+
 // Install a keyboard driver. Returns true if successful. If a driver was
 // already installed, nothing happens and true is returned.
 func InstallKeyboard() error {
@@ -178,6 +236,7 @@ func IsKeyboardInstalled() bool {
 // Uninstalls the active keyboard driver, if any. This will automatically
 // unregister the keyboard event source with any event queues.
 func UninstallKeyboard() {
+	forgetEventSource(KeyboardEvents.EventSource())
 	C.al_uninstall_keyboard()
 }
 
@@ -192,6 +251,44 @@ func (state *KeyboardState) IsDown(key KeyCode) bool {
 	return bool(C.al_key_down((*C.ALLEGRO_KEYBOARD_STATE)(state), C.int(key)))
 }
 
+// GetKeyboardState() returns the current state of the keyboard.
+func GetKeyboardState() *KeyboardState {
+	var state KeyboardState
+	state.Get()
+	return &state
+}
+
+// modifierKeys maps each modifier that corresponds to held keys to those keys.
+var modifierKeys = []struct {
+	mod  KeyModifier
+	keys []KeyCode
+}{
+	{KEYMOD_SHIFT, []KeyCode{KEY_LSHIFT, KEY_RSHIFT}},
+	{KEYMOD_CTRL, []KeyCode{KEY_LCTRL, KEY_RCTRL}},
+	{KEYMOD_ALT, []KeyCode{KEY_ALT}},
+	{KEYMOD_ALTGR, []KeyCode{KEY_ALTGR}},
+	{KEYMOD_LWIN, []KeyCode{KEY_LWIN}},
+	{KEYMOD_RWIN, []KeyCode{KEY_RWIN}},
+	{KEYMOD_MENU, []KeyCode{KEY_MENU}},
+	{KEYMOD_COMMAND, []KeyCode{KEY_COMMAND}},
+}
+
+// Modifiers() returns the modifiers whose keys are held down in the state.
+// The keyboard state doesn't record lock keys, so KEYMOD_CAPSLOCK and the
+// like are never set; use the modifiers of a KeyCharEvent for those.
+func (state *KeyboardState) Modifiers() KeyModifier {
+	var mods KeyModifier
+	for _, m := range modifierKeys {
+		for _, key := range m.keys {
+			if state.IsDown(key) {
+				mods |= m.mod
+				break
+			}
+		}
+	}
+	return mods
+}
+
 // Converts the given keycode to a description of the key.
 func (key KeyCode) Name() string {
 	name := C.al_keycode_to_name(C.int(key))
@@ -203,8 +300,73 @@ func (key KeyCode) String() string {
 	return key.Name()
 }
 
-// Overrides the state of the keyboard LED indicators. Set to -1 to return to
-// default behavior. False is returned if the current keyboard driver cannot
+var (
+	keyNames     map[string]KeyCode
+	keyNamesLock sync.Mutex
+)
+
+// ParseKeyCode() returns the key code with the given name, as returned by
+// Name(). The comparison ignores case. Key names come from the keyboard
+// driver, so the keyboard must be installed.
+func ParseKeyCode(name string) (KeyCode, error) {
+	keyNamesLock.Lock()
+	if keyNames == nil {
+		// al_keycode_to_name() needs the keyboard driver. The table is
+		// only built once it is there, so an early call doesn't leave it
+		// empty for good.
+		if !IsKeyboardInstalled() {
+			keyNamesLock.Unlock()
+			return 0, errors.New("keyboard not installed")
+		}
+		keyNames = make(map[string]KeyCode, KEY_MAX)
+		for key := KeyCode(1); key < KEY_MAX; key++ {
+			n := strings.ToUpper(key.Name())
+			if _, ok := keyNames[n]; !ok {
+				keyNames[n] = key
+			}
+		}
+	}
+	key, ok := keyNames[strings.ToUpper(strings.TrimSpace(name))]
+	keyNamesLock.Unlock()
+	if ok {
+		return key, nil
+	}
+	return 0, fmt.Errorf("unknown key name '%s'", name)
+}
+
+// MarshalText() implements encoding.TextMarshaler, so that key codes are
+// written by name to JSON and similar formats.
+func (key KeyCode) MarshalText() ([]byte, error) {
+	return []byte(key.Name()), nil
+}
+
+// UnmarshalText() implements encoding.TextUnmarshaler using ParseKeyCode().
+func (key *KeyCode) UnmarshalText(text []byte) error {
+	k, err := ParseKeyCode(string(text))
+	if err != nil {
+		return err
+	}
+	*key = k
+	return nil
+}
+
+// KeyCodeValue() reads a key name from a config, as written by SetKeyCodeValue().
+func (cfg *Config) KeyCodeValue(section, key string) (KeyCode, error) {
+	str, err := cfg.Value(section, key)
+	if err != nil {
+		return 0, err
+	}
+	return ParseKeyCode(stripComment(str))
+}
+
+// SetKeyCodeValue() stores a key code in a config by name.
+func (cfg *Config) SetKeyCodeValue(section, key string, value KeyCode) {
+	cfg.SetValue(section, key, value.Name())
+}
+
+// Overrides the state of the keyboard LED indicators, given as a combination
+// of KEYMOD_NUMLOCK, KEYMOD_CAPSLOCK and KEYMOD_SCROLLLOCK. Set to -1 to
+// return to default behavior. False is returned if the current keyboard driver cannot
 // set LED indicators.
 func SetKeyboardLeds(leds int) bool {
 	return bool(C.al_set_keyboard_leds(C.int(leds)))
@@ -0,0 +1,73 @@
+package allegro
+
+// Lifecycle handles the events with which mobile platforms such as Android
+// and iOS suspend and resume an application. While the application is in the
+// background, nothing may be drawn to the display, and the halt must be
+// acknowledged promptly or the application will be killed. On desktop
+// platforms these events never arrive, so a Lifecycle can be used
+// unconditionally.
+type Lifecycle struct {
+	// OnHalt is called before the halt is acknowledged, e.g. to pause the
+	// game or save its state.
+	OnHalt func(d *Display)
+
+	// OnResume is called after the resume is acknowledged, e.g. to recreate
+	// bitmaps created with NO_PRESERVE_TEXTURE.
+	OnResume func(d *Display)
+
+	// OnOrientation is called when the device is rotated.
+	OnOrientation func(d *Display, o DisplayOrientation)
+
+	halted bool
+}
+
+// Halted() returns true while drawing is halted, i.e. between a
+// DisplayHaltDrawingEvent and the following DisplayResumeDrawingEvent. The
+// main loop should skip drawing and flipping meanwhile.
+func (l *Lifecycle) Halted() bool {
+	return l.halted
+}
+
+// HandleEvent() acknowledges DisplayHaltDrawingEvent and
+// DisplayResumeDrawingEvent and reports DisplayOrientationEvent. It returns
+// whether ev was one of them.
+func (l *Lifecycle) HandleEvent(ev interface{}) bool {
+	switch e := ev.(type) {
+	case DisplayHaltDrawingEvent:
+		l.halted = true
+		if l.OnHalt != nil {
+			l.OnHalt(e.Source())
+		}
+		e.Source().AcknowledgeDrawingHalt()
+	case DisplayResumeDrawingEvent:
+		e.Source().AcknowledgeDrawingResume()
+		l.halted = false
+		if l.OnResume != nil {
+			l.OnResume(e.Source())
+		}
+	case DisplayOrientationEvent:
+		if l.OnOrientation != nil {
+			l.OnOrientation(e.Source(), e.Orientation())
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// IsPortrait() returns true for the upright and upside down orientations.
+func (o DisplayOrientation) IsPortrait() bool {
+	return o == DISPLAY_ORIENTATION_0_DEGREES || o == DISPLAY_ORIENTATION_180_DEGREES
+}
+
+// IsLandscape() returns true for the orientations rotated by 90 or 270
+// degrees.
+func (o DisplayOrientation) IsLandscape() bool {
+	return o == DISPLAY_ORIENTATION_90_DEGREES || o == DISPLAY_ORIENTATION_270_DEGREES
+}
+
+// SetNewSupportedOrientations() restricts the orientations that displays
+// created afterwards may be rotated to, e.g. DISPLAY_ORIENTATION_LANDSCAPE.
+func SetNewSupportedOrientations(o DisplayOrientation) {
+	SetNewDisplayOption(SUPPORTED_ORIENTATIONS, int(o), REQUIRE)
+}
@@ -0,0 +1,200 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"unsafe"
+)
+
+// LockedBitmap gives direct access to the memory of a locked bitmap or bitmap
+// region. The slices it returns refer to Allegro's memory rather than a copy,
+// so writes are seen by the bitmap when it is unlocked. They must not be used
+// after Unlock() is called.
+type LockedBitmap struct {
+	*LockedRegion
+
+	// The size of the locked area, in pixels.
+	Width, Height int
+
+	bmp  *Bitmap
+	data unsafe.Pointer
+}
+
+// LockPixels() locks the whole bitmap and returns a LockedBitmap for
+// accessing its memory.
+func (bmp *Bitmap) LockPixels(format PixelFormat, flags LockFlags) (*LockedBitmap, error) {
+	reg, err := bmp.Lock(format, flags)
+	if err != nil {
+		return nil, err
+	}
+	return newLockedBitmap(bmp, reg, bmp.Width(), bmp.Height()), nil
+}
+
+// LockPixelsRegion() locks an area of the bitmap and returns a LockedBitmap
+// for accessing its memory. Row 0 of the result is row y of the bitmap.
+func (bmp *Bitmap) LockPixelsRegion(x, y, width, height int, format PixelFormat, flags LockFlags) (*LockedBitmap, error) {
+	reg, err := bmp.LockRegion(x, y, width, height, format, flags)
+	if err != nil {
+		return nil, err
+	}
+	return newLockedBitmap(bmp, reg, width, height), nil
+}
+
+func newLockedBitmap(bmp *Bitmap, reg *LockedRegion, w, h int) *LockedBitmap {
+	return &LockedBitmap{
+		LockedRegion: reg,
+		Width:        w,
+		Height:       h,
+		bmp:          bmp,
+		data:         unsafe.Pointer(reg.reg.data),
+	}
+}
+
+// checkRow() and checkColumn() panic, as indexing a slice would, if y or x
+// lies outside the locked area, rather than letting a slice be made over
+// memory that isn't part of it.
+
+func (l *LockedBitmap) checkRow(y int) {
+	if y < 0 || y >= l.Height {
+		panic(fmt.Sprintf("allegro: locked bitmap row %d out of range [0:%d]", y, l.Height))
+	}
+}
+
+func (l *LockedBitmap) checkColumn(x int) {
+	if x < 0 || x >= l.Width {
+		panic(fmt.Sprintf("allegro: locked bitmap column %d out of range [0:%d]", x, l.Width))
+	}
+}
+
+// Row() returns the memory of row y, which is Width * PixelSize() bytes long.
+// Rows may be stored bottom-up; always use the pitch (or this method) to find
+// a row rather than assuming they follow each other. It panics if y is out of
+// range.
+func (l *LockedBitmap) Row(y int) []byte {
+	l.checkRow(y)
+	n := l.Width * l.PixelSize()
+	p := unsafe.Add(l.data, y*l.Pitch())
+	return unsafe.Slice((*byte)(p), n)
+}
+
+// Pixel() returns the memory of the pixel at x, y, which is PixelSize() bytes
+// long. It panics if x or y is out of range.
+func (l *LockedBitmap) Pixel(x, y int) []byte {
+	l.checkRow(y)
+	l.checkColumn(x)
+	size := l.PixelSize()
+	p := unsafe.Add(l.data, y*l.Pitch()+x*size)
+	return unsafe.Slice((*byte)(p), size)
+}
+
+// Uint32Row() returns row y as 32-bit pixels. It may only be used with 32-bit
+// pixel formats, such as PIXEL_FORMAT_ABGR_8888_LE. It panics if y is out of
+// range.
+func (l *LockedBitmap) Uint32Row(y int) []uint32 {
+	l.checkRow(y)
+	p := unsafe.Add(l.data, y*l.Pitch())
+	return unsafe.Slice((*uint32)(p), l.Width)
+}
+
+// Unlock() unlocks the bitmap. The LockedBitmap and any slices obtained from
+// it must not be used afterwards.
+func (l *LockedBitmap) Unlock() {
+	l.bmp.Unlock()
+	l.data = nil
+}
+
+// LockImage() locks an area of the bitmap in PIXEL_FORMAT_ABGR_8888_LE, whose
+// byte order matches image.RGBA, so that the result can be used as a
+// draw.Image. Like the bitmap itself, the pixels are treated as having
+// premultiplied alpha.
+func (bmp *Bitmap) LockImage(x, y, width, height int, flags LockFlags) (*LockedBitmap, error) {
+	return bmp.LockPixelsRegion(x, y, width, height, PIXEL_FORMAT_ABGR_8888_LE, flags)
+}
+
+var ErrNegativePitch = errors.New("locked region is stored bottom-up")
+
+// RGBA() returns an *image.RGBA that refers directly to the locked memory,
+// which lets the image/draw package use its fast paths. The bitmap must have
+// been locked with LockImage(). Allegro may store rows bottom-up, in which case
+// no such view is possible and ErrNegativePitch is returned; the LockedBitmap
+// itself still works as a draw.Image.
+func (l *LockedBitmap) RGBA() (*image.RGBA, error) {
+	pitch := l.Pitch()
+	if pitch < 0 {
+		return nil, ErrNegativePitch
+	}
+	n := 0
+	if l.Height > 0 {
+		n = (l.Height-1)*pitch + l.Width*4
+	}
+	return &image.RGBA{
+		Pix:    unsafe.Slice((*byte)(l.data), n),
+		Stride: pitch,
+		Rect:   image.Rect(0, 0, l.Width, l.Height),
+	}, nil
+}
+
+// ColorModel(), Bounds(), At() and Set() implement draw.Image for a bitmap
+// locked with LockImage().
+
+func (l *LockedBitmap) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (l *LockedBitmap) Bounds() image.Rectangle {
+	return image.Rect(0, 0, l.Width, l.Height)
+}
+
+func (l *LockedBitmap) At(x, y int) color.Color {
+	return l.RGBAAt(x, y)
+}
+
+func (l *LockedBitmap) Set(x, y int, c color.Color) {
+	l.SetRGBA(x, y, color.RGBAModel.Convert(c).(color.RGBA))
+}
+
+var _ draw.Image = (*LockedBitmap)(nil)
+
+// RGBAAt() and SetRGBA() are like At() and Set() without the conversion
+// through color.Color, for callers that already work in color.RGBA.
+
+func (l *LockedBitmap) RGBAAt(x, y int) color.RGBA {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return color.RGBA{}
+	}
+	p := l.Pixel(x, y)
+	return color.RGBA{p[0], p[1], p[2], p[3]}
+}
+
+func (l *LockedBitmap) SetRGBA(x, y int, c color.RGBA) {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return
+	}
+	p := l.Pixel(x, y)
+	p[0], p[1], p[2], p[3] = c.R, c.G, c.B, c.A
+}
+
+// WithImage() locks the area r of the bitmap with LockImage(), passes it to f
+// as a draw.Image and unlocks it when f returns, so that pure-Go drawing code
+// can rasterize straight into the bitmap. The image's bounds start at 0, 0.
+// Where possible f is given the *image.RGBA view from RGBA(), which the
+// image/draw package handles fastest. The image must not be kept after f
+// returns.
+func (bmp *Bitmap) WithImage(r image.Rectangle, flags LockFlags, f func(img draw.Image)) error {
+	l, err := bmp.LockImage(r.Min.X, r.Min.Y, r.Dx(), r.Dy(), flags)
+	if err != nil {
+		return err
+	}
+	defer l.Unlock()
+	if rgba, err := l.RGBA(); err == nil {
+		f(rgba)
+	} else {
+		f(l)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package allegro
+
+// SetMainLoop() calls frame once per frame until it returns false, which is
+// the inverted style that platforms such as the browser require, where the
+// host owns the loop and a game may only register a callback (Emscripten's
+// emscripten_set_main_loop()). Each call should handle the pending events,
+// update and draw one frame without blocking.
+//
+// fps limits how often frame is called; with fps <= 0 it is called as often
+// as possible, which is usually paced by FlipDisplay() waiting for vsync.
+// Frames that run late are not made up for. SetMainLoop() returns once frame
+// has returned false, so games written this way run unchanged wherever the
+// loop is driven from here:
+//
+//	allegro.SetMainLoop(func() bool {
+//		for ev, ok := queue.Poll(); ok; ev, ok = queue.Poll() {
+//			if _, ok := ev.(allegro.DisplayCloseEvent); ok {
+//				return false
+//			}
+//		}
+//		update()
+//		draw()
+//		allegro.FlipDisplay()
+//		return true
+//	}, 60)
+func SetMainLoop(frame func() bool, fps float64) {
+	var period float64
+	if fps > 0 {
+		period = 1 / fps
+	}
+	next := Time()
+	for frame() {
+		if period <= 0 {
+			continue
+		}
+		next += period
+		if wait := next - Time(); wait > 0 {
+			Rest(wait)
+		} else {
+			next = Time()
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package allegro
+
+/*
+static __thread int on_main_thread;
+
+static void set_on_main_thread(int on) {
+	on_main_thread = on;
+}
+
+static int is_on_main_thread(void) {
+	return on_main_thread;
+}
+*/
+import "C"
+import (
+	"runtime"
+)
+
+// Allegro needs some calls, such as creating displays and, on some platforms,
+// waiting for events, to be made from the main OS thread. Go moves goroutines
+// between threads freely, so the main goroutine is locked to the main thread
+// while the package initializes, and MainLoop() lets other goroutines hand
+// work to it.
+func init() {
+	runtime.LockOSThread()
+}
+
+var mainFuncs = make(chan func())
+
+// MainLoop() runs f on a new goroutine and, until f returns, runs the
+// functions passed to RunOnMainThread() on the calling goroutine. It should be
+// called from main() itself, or from the function passed to Run(). On OS X,
+// al_run_main() calls that function on a secondary thread and keeps the real
+// main thread for Cocoa, so there "the main thread" below means that secondary
+// thread. It is the one Allegro expects its calls from, and Allegro hands the
+// work that Cocoa needs on the real main thread over to it by itself:
+//
+//	func main() {
+//		allegro.MainLoop(game)
+//	}
+//
+//	func game() {
+//		var display *allegro.Display
+//		var err error
+//		allegro.RunOnMainThread(func() {
+//			display, err = allegro.CreateDisplay(640, 480)
+//		})
+//		...
+//	}
+//
+// Panics in f are not recovered, so they still crash the program with f's
+// stack trace.
+func MainLoop(f func()) {
+	C.set_on_main_thread(1)
+	defer C.set_on_main_thread(0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	for {
+		select {
+		case fn := <-mainFuncs:
+			fn()
+		case <-done:
+			return
+		}
+	}
+}
+
+// IsMainThread() returns true if the caller is running on the main thread
+// inside MainLoop().
+func IsMainThread() bool {
+	return C.is_on_main_thread() != 0
+}
+
+// RunOnMainThread() runs f on the main thread and waits for it to return.
+// Called from the main thread, it runs f directly. It must only be called
+// while MainLoop() is running, or it blocks forever.
+func RunOnMainThread(f func()) {
+	if IsMainThread() {
+		f()
+		return
+	}
+	done := make(chan struct{})
+	mainFuncs <- func() {
+		defer close(done)
+		f()
+	}
+	<-done
+}
+
+// RunOnMainThreadAsync() hands f to the main thread without waiting for it to
+// finish. Functions handed over from one goroutine run in order.
+func RunOnMainThreadAsync(f func()) {
+	if IsMainThread() {
+		f()
+		return
+	}
+	mainFuncs <- f
+}
@@ -0,0 +1,149 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Material pairs a shader with the uniform values it should be drawn with.
+// Uniforms are uploaded every time the material is used, since Allegro's
+// uniforms belong to the shader in use rather than being stored per draw.
+//
+// Supported uniform values are bool, int, float32, float64, Color (as a vec4),
+// []int and []float32 (a single vector), [][]int and [][]float32 (arrays of
+// vectors), *Transform and *Bitmap. Bitmaps are bound as samplers on texture
+// units 1 and up, in order of uniform name; unit 0 is the bitmap being drawn,
+// available to the shader as al_tex.
+type Material struct {
+	Shader   *Shader
+	Uniforms map[string]interface{}
+}
+
+// NewMaterial() creates a material for the shader with no uniforms set.
+func NewMaterial(s *Shader) *Material {
+	return &Material{
+		Shader:   s,
+		Uniforms: make(map[string]interface{}),
+	}
+}
+
+// Set() sets a uniform value and returns the material, so that calls can be
+// chained.
+func (m *Material) Set(name string, value interface{}) *Material {
+	m.Uniforms[name] = value
+	return m
+}
+
+// Apply() uploads the material's uniforms to the shader in use, which should
+// be m.Shader.
+func (m *Material) Apply() error {
+	names := make([]string, 0, len(m.Uniforms))
+	for name := range m.Uniforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	unit := 1
+	for _, name := range names {
+		var err error
+		switch v := m.Uniforms[name].(type) {
+		case bool:
+			err = SetShaderBool(name, v)
+		case int:
+			err = SetShaderInt(name, v)
+		case float32:
+			err = SetShaderFloat(name, v)
+		case float64:
+			err = SetShaderFloat(name, float32(v))
+		case Color:
+			r, g, b, a := v.UnmapRGBAf()
+			err = SetShaderFloatVector(name, [][]float32{{r, g, b, a}})
+		case []int:
+			err = SetShaderIntVector(name, [][]int{v})
+		case []float32:
+			err = SetShaderFloatVector(name, [][]float32{v})
+		case [][]int:
+			err = SetShaderIntVector(name, v)
+		case [][]float32:
+			err = SetShaderFloatVector(name, v)
+		case *Transform:
+			err = SetShaderMatrix(name, v)
+		case *Bitmap:
+			err = SetShaderSampler(name, v, unit)
+			unit++
+		default:
+			err = fmt.Errorf("unsupported type %T for uniform \"%s\"", v, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Use() runs f with the material's shader in use and its uniforms uploaded.
+// The shader that was in use before is restored afterwards, even if it was
+// Allegro's built-in one.
+func (m *Material) Use(f func()) error {
+	prev := C.al_get_current_shader()
+	if err := UseShader(m.Shader); err != nil {
+		return err
+	}
+	defer C.al_use_shader(prev)
+	if err := m.Apply(); err != nil {
+		return err
+	}
+	f()
+	return nil
+}
+
+// Draw() draws bmp at the given position with the material.
+func (m *Material) Draw(bmp *Bitmap, dx, dy float32, flags DrawFlags) error {
+	return m.Use(func() {
+		bmp.Draw(dx, dy, flags)
+	})
+}
+
+// DrawScaled() draws a scaled region of bmp with the material.
+func (m *Material) DrawScaled(bmp *Bitmap, sx, sy, sw, sh, dx, dy, dw, dh float32, flags DrawFlags) error {
+	return m.Use(func() {
+		bmp.DrawScaled(sx, sy, sw, sh, dx, dy, dw, dh, flags)
+	})
+}
+
+var (
+	bitmapMaterials     = make(map[*Bitmap]*Material)
+	bitmapMaterialsLock sync.Mutex
+)
+
+// SetMaterial() associates a material with the bitmap, which is then used by
+// DrawMaterial(). Passing nil removes the association. The association is
+// also removed when the bitmap is destroyed.
+func (bmp *Bitmap) SetMaterial(m *Material) {
+	bitmapMaterialsLock.Lock()
+	defer bitmapMaterialsLock.Unlock()
+	if m == nil {
+		delete(bitmapMaterials, bmp)
+	} else {
+		bitmapMaterials[bmp] = m
+	}
+}
+
+// Material() returns the material associated with the bitmap, or nil.
+func (bmp *Bitmap) Material() *Material {
+	bitmapMaterialsLock.Lock()
+	defer bitmapMaterialsLock.Unlock()
+	return bitmapMaterials[bmp]
+}
+
+// DrawMaterial() draws the bitmap with its associated material, or like
+// Draw() if it has none.
+func (bmp *Bitmap) DrawMaterial(dx, dy float32, flags DrawFlags) error {
+	if m := bmp.Material(); m != nil {
+		return m.Draw(bmp, dx, dy, flags)
+	}
+	bmp.Draw(dx, dy, flags)
+	return nil
+}
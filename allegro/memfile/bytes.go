@@ -0,0 +1,82 @@
+package memfile
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_memfile.h>
+import "C"
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"unsafe"
+)
+
+// Memfile is a file opened on a copy of a []byte by OpenBytes(). Its File can
+// be loaded from like any other, e.g. m.LoadBitmap(".png").
+type Memfile struct {
+	*allegro.File
+	mem unsafe.Pointer
+}
+
+// OpenBytes() opens a memfile on a copy of data, e.g. an asset embedded with
+// go:embed. Go memory can't be handed to Allegro for as long as a file stays
+// open, so the copy lives in C memory and is freed by Close(). The copy is as
+// large as data; for FILE_WRITE, size data accordingly and read the result
+// back with Bytes() before closing.
+//
+// Loaders that keep reading after they return, such as those for TTF fonts
+// and audio streams, take ownership of the file and would close it without
+// freeing the copy; use ttf.LoadFontReader() and audio.LoadStreamReader()
+// with a bytes.Reader for those instead.
+func OpenBytes(data []byte, mode FileMode) (*Memfile, error) {
+	size := len(data)
+	if size == 0 {
+		// A memfile needs some memory to point at, even if it is empty.
+		size = 1
+	}
+	mem := C.malloc(C.size_t(size))
+	copy(unsafe.Slice((*byte)(mem), size), data)
+	f, err := Open(mem, int64(len(data)), mode)
+	if err != nil {
+		C.free(mem)
+		return nil, err
+	}
+	return &Memfile{File: f, mem: mem}, nil
+}
+
+// Bytes() returns a copy of the memory behind the file, including anything
+// written to it.
+func (m *Memfile) Bytes() []byte {
+	size, err := m.File.Size()
+	if err != nil || size <= 0 {
+		return nil
+	}
+	return C.GoBytes(m.mem, C.int(size))
+}
+
+// Close() closes the file and frees the copy of its data.
+func (m *Memfile) Close() error {
+	err := m.File.Close()
+	C.free(m.mem)
+	m.mem = nil
+	return err
+}
+
+// LoadBitmap() loads a bitmap from data, which holds an image file in the
+// format named by ident, e.g. ".png".
+func LoadBitmap(data []byte, ident string) (*allegro.Bitmap, error) {
+	m, err := OpenBytes(data, FILE_READ)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+	return m.File.LoadBitmap(ident)
+}
+
+// LoadConfig() loads a configuration file from data.
+func LoadConfig(data []byte) (*allegro.Config, error) {
+	m, err := OpenBytes(data, FILE_READ)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+	return m.File.LoadConfig()
+}
@@ -11,6 +11,7 @@ void _al_free(void *data) {
 */
 import "C"
 import (
+	"sync"
 	"unsafe"
 )
 
@@ -32,3 +33,95 @@ func freeString(data *C.char) {
 func SetMemoryInterface(memory_interface *C.ALLEGRO_MEMORY_INTERFACE) {
 	C.al_set_memory_interface(memory_interface)
 }
+
+// arena hands out short-lived blocks of C memory for passing arguments to
+// Allegro, such as uniform names and vectors, without a malloc/free pair per
+// call. Blocks stay valid until the arena is reset, which happens once per
+// frame when the display is flipped, or by ResetFrameArena(). Anything that
+// Allegro holds on to after a call returns must not be allocated from the
+// arena.
+type arena struct {
+	lock     sync.Mutex
+	base     unsafe.Pointer
+	size     uintptr
+	used     uintptr
+	overflow []unsafe.Pointer
+	spilled  uintptr
+}
+
+const (
+	arenaMinSize = 64 * 1024
+	arenaAlign   = 16
+)
+
+var frameArena arena
+
+func (a *arena) alloc(n uintptr) unsafe.Pointer {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	n = (n + arenaAlign - 1) &^ (arenaAlign - 1)
+	if a.base == nil {
+		a.size = arenaMinSize
+		for a.size < n {
+			a.size *= 2
+		}
+		a.base = malloc(C.size_t(a.size))
+	}
+	if a.base != nil && a.used+n <= a.size {
+		p := unsafe.Pointer(uintptr(a.base) + a.used)
+		a.used += n
+		return p
+	}
+
+	// Out of room until the next reset; fall back to malloc and remember to
+	// grow the arena so that this doesn't happen again next frame.
+	p := malloc(C.size_t(n))
+	if p != nil {
+		a.overflow = append(a.overflow, p)
+		a.spilled += n
+	}
+	return p
+}
+
+// cstring() copies a Go string into the arena as a NUL-terminated C string.
+func (a *arena) cstring(s string) *C.char {
+	p := a.alloc(uintptr(len(s) + 1))
+	if p == nil {
+		return nil
+	}
+	buf := unsafe.Slice((*byte)(p), len(s)+1)
+	copy(buf, s)
+	buf[len(s)] = 0
+	return (*C.char)(p)
+}
+
+func (a *arena) reset() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, p := range a.overflow {
+		free(p)
+	}
+	a.overflow = a.overflow[:0]
+	if a.spilled > 0 && a.base != nil {
+		want := a.used + a.spilled
+		for a.size < want {
+			a.size *= 2
+		}
+		free(a.base)
+		a.base = malloc(C.size_t(a.size))
+	}
+	a.used, a.spilled = 0, 0
+}
+
+// ResetFrameArena() frees the scratch memory that shader uniform setters use
+// for their arguments. FlipDisplay() and UpdateDisplayRegion() call it, so
+// programs that show a display don't need to. Programs that never flip, such
+// as headless tools or ones that only render to bitmaps, must call it
+// themselves, e.g. once per frame or batch of work, or the memory grows with
+// every uniform set. It must not be called while another goroutine is setting
+// uniforms.
+func ResetFrameArena() {
+	frameArena.reset()
+}
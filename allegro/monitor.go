@@ -8,6 +8,13 @@ import (
 
 type MonitorInfo C.struct_ALLEGRO_MONITOR_INFO
 
+// The DPI that corresponds to a scale of 1, as on a traditional desktop
+// monitor.
+const BASE_DPI = 96
+
+// Lets Allegro choose the adapter for new displays.
+const DEFAULT_DISPLAY_ADAPTER = C.ALLEGRO_DEFAULT_DISPLAY_ADAPTER
+
 // Get information about a monitor's position on the desktop. adapter is a number from
 // 0 to al_get_num_video_adapters()-1.
 func GetMonitorInfo(adapter int) (*MonitorInfo, error) {
@@ -69,3 +76,106 @@ func SetNewDisplayAdapter(adapter int) {
 func NumVideoAdapters() int {
 	return int(C.al_get_num_video_adapters())
 }
+
+// Contains() returns whether the desktop point (x, y) lies on the monitor.
+func (m *MonitorInfo) Contains(x, y int) bool {
+	return x >= m.X1() && x < m.X2() && y >= m.Y1() && y < m.Y2()
+}
+
+// AdapterAt() returns the adapter whose monitor contains the desktop point
+// (x, y), or -1 if there is none.
+func AdapterAt(x, y int) int {
+	for i := 0; i < NumVideoAdapters(); i++ {
+		if m, err := GetMonitorInfo(i); err == nil && m.Contains(x, y) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Adapter() returns the adapter whose monitor contains the centre of the
+// display's window, or -1 if it can't be determined.
+func (d *Display) Adapter() int {
+	x, y := d.WindowPosition()
+	return AdapterAt(x+d.Width()/2, y+d.Height()/2)
+}
+
+// PrimaryAdapter() returns the adapter of the primary monitor, or
+// DEFAULT_DISPLAY_ADAPTER if none reports itself as primary.
+func PrimaryAdapter() int {
+	for i := 0; i < NumVideoAdapters(); i++ {
+		if m, err := GetMonitorInfo(i); err == nil && m.IsPrimary() {
+			return i
+		}
+	}
+	return DEFAULT_DISPLAY_ADAPTER
+}
+
+// MoveToAdapter() centres a windowed display on the given adapter's monitor.
+// A fullscreen window is moved there too by toggling it off and on again.
+func (d *Display) MoveToAdapter(adapter int) error {
+	m, err := GetMonitorInfo(adapter)
+	if err != nil {
+		return err
+	}
+	fullscreen := d.Flags()&FULLSCREEN_WINDOW != 0
+	if fullscreen {
+		if err := d.SetDisplayFlag(FULLSCREEN_WINDOW, false); err != nil {
+			return err
+		}
+	}
+	d.SetWindowPosition(m.X1()+(m.Width()-d.Width())/2, m.Y1()+(m.Height()-d.Height())/2)
+	if fullscreen {
+		return d.SetDisplayFlag(FULLSCREEN_WINDOW, true)
+	}
+	return nil
+}
+
+// Get the dots per inch of a monitor attached to the display adapter.
+func MonitorDPI(adapter int) int {
+	return int(C.al_get_monitor_dpi(C.int(adapter)))
+}
+
+// DPI() returns the dots per inch of the monitor the display is on, or of
+// the first monitor if that can't be determined. A result of 0 means the
+// platform doesn't report it.
+func (d *Display) DPI() int {
+	adapter := d.Adapter()
+	if adapter < 0 {
+		adapter = 0
+	}
+	return MonitorDPI(adapter)
+}
+
+// Scale() returns the display's DPI relative to BASE_DPI, e.g. 1.5 on a
+// 144 DPI monitor. It is 1 if the DPI isn't reported.
+func (d *Display) Scale() float64 {
+	dpi := d.DPI()
+	if dpi <= 0 {
+		return 1
+	}
+	return float64(dpi) / BASE_DPI
+}
+
+// CreateDisplayOnAdapter() creates a display on the given adapter, e.g. one
+// chosen in a game's settings. Windowed displays are centred on the adapter's
+// monitor. The new display adapter and window position are restored
+// afterwards, so later displays are unaffected. An adapter that no longer
+// exists, such as after a monitor was unplugged, falls back to the default.
+func CreateDisplayOnAdapter(adapter, w, h int) (*Display, error) {
+	oldAdapter := NewDisplayAdapter()
+	oldX, oldY := NewWindowPosition()
+	defer func() {
+		SetNewDisplayAdapter(oldAdapter)
+		SetNewWindowPosition(oldX, oldY)
+	}()
+	if m, err := GetMonitorInfo(adapter); err == nil {
+		SetNewDisplayAdapter(adapter)
+		if NewDisplayFlags()&(FULLSCREEN|FULLSCREEN_WINDOW) == 0 {
+			SetNewWindowPosition(m.X1()+(m.Width()-w)/2, m.Y1()+(m.Height()-h)/2)
+		}
+	} else {
+		SetNewDisplayAdapter(DEFAULT_DISPLAY_ADAPTER)
+	}
+	return CreateDisplay(w, h)
+}
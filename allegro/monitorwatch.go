@@ -0,0 +1,141 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// The connect and disconnect events are part of Allegro's unstable API and are
+// currently only generated on Windows, so they are registered here rather than
+// in event.go.
+func init() {
+	RegisterEventType(C.ALLEGRO_EVENT_DISPLAY_CONNECTED, func(e *Event) interface{} {
+		return (*display_connected_event)(unsafe.Pointer(e))
+	})
+	RegisterEventType(C.ALLEGRO_EVENT_DISPLAY_DISCONNECTED, func(e *Event) interface{} {
+		return (*display_disconnected_event)(unsafe.Pointer(e))
+	})
+}
+
+/* -- Display Connected -- */
+
+type DisplayConnectedEvent interface {
+	display_connected()
+	Timestamp() float64
+	Source() *Display
+}
+
+type display_connected_event C.struct_ALLEGRO_DISPLAY_EVENT
+
+func (e *display_connected_event) display_connected() {}
+
+func (e *display_connected_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *display_connected_event) Source() *Display {
+	return (*Display)(e.source)
+}
+
+func (e *display_connected_event) String() string {
+	return DumpEvent(e)
+}
+
+/* -- Display Disconnected -- */
+
+type DisplayDisconnectedEvent interface {
+	display_disconnected()
+	Timestamp() float64
+	Source() *Display
+}
+
+type display_disconnected_event C.struct_ALLEGRO_DISPLAY_EVENT
+
+func (e *display_disconnected_event) display_disconnected() {}
+
+func (e *display_disconnected_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *display_disconnected_event) Source() *Display {
+	return (*Display)(e.source)
+}
+
+func (e *display_disconnected_event) String() string {
+	return DumpEvent(e)
+}
+
+// Monitors() returns the monitor info of every video adapter, indexed by
+// adapter number. Adapters whose info can't be retrieved are left nil.
+func Monitors() []*MonitorInfo {
+	monitors := make([]*MonitorInfo, NumVideoAdapters())
+	for i := range monitors {
+		monitors[i], _ = GetMonitorInfo(i)
+	}
+	return monitors
+}
+
+// MonitorWatcher keeps track of the connected monitors and reports when they
+// change, e.g. when a laptop is docked or undocked.
+type MonitorWatcher struct {
+	// OnChange is called with the old and new monitor lists whenever a
+	// change is detected.
+	OnChange func(old, new []*MonitorInfo)
+
+	monitors []*MonitorInfo
+}
+
+// NewMonitorWatcher() creates a watcher holding the current monitors.
+func NewMonitorWatcher(onChange func(old, new []*MonitorInfo)) *MonitorWatcher {
+	return &MonitorWatcher{OnChange: onChange, monitors: Monitors()}
+}
+
+// Monitors() returns the monitors as of the last check.
+func (w *MonitorWatcher) Monitors() []*MonitorInfo {
+	return w.monitors
+}
+
+// HandleEvent() re-enumerates the monitors when ev is a DisplayConnectedEvent
+// or DisplayDisconnectedEvent, or any other display event that may follow a
+// monitor change, such as a resize or a move to another adapter. It returns
+// whether the monitors changed.
+func (w *MonitorWatcher) HandleEvent(ev interface{}) bool {
+	switch ev.(type) {
+	case DisplayConnectedEvent, DisplayDisconnectedEvent, DisplayResizeEvent, DisplayFoundEvent:
+		return w.Check()
+	}
+	return false
+}
+
+// Check() re-enumerates the monitors and calls OnChange if they differ from
+// the last check. On platforms without connect and disconnect events, call
+// this periodically, e.g. once a second.
+func (w *MonitorWatcher) Check() bool {
+	monitors := Monitors()
+	if sameMonitors(w.monitors, monitors) {
+		return false
+	}
+	old := w.monitors
+	w.monitors = monitors
+	if w.OnChange != nil {
+		w.OnChange(old, monitors)
+	}
+	return true
+}
+
+func sameMonitors(a, b []*MonitorInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i] == nil) != (b[i] == nil) {
+			return false
+		}
+		if a[i] != nil && *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
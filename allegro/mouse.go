@@ -4,6 +4,7 @@ package allegro
 import "C"
 import (
 	"errors"
+	"unsafe"
 )
 
 type MouseCursor C.ALLEGRO_MOUSE_CURSOR
@@ -51,6 +52,7 @@ func IsMouseInstalled() bool {
 // Uninstalls the active mouse driver, if any. This will automatically
 // unregister the mouse event source with any event queues.
 func UninstallMouse() {
+	forgetEventSource(MouseEvents.EventSource())
 	C.al_uninstall_mouse()
 }
 
@@ -126,6 +128,43 @@ func SetMouseAxis(which, value int) error {
 	return nil
 }
 
+// Set the mouse z axis (wheel) to the given value.
+func SetMouseZ(z int) error {
+	if !bool(C.al_set_mouse_z(C.int(z))) {
+		return errors.New("failed to set mouse z axis")
+	}
+	return nil
+}
+
+// Set the second mouse wheel axis to the given value.
+func SetMouseW(w int) error {
+	if !bool(C.al_set_mouse_w(C.int(w))) {
+		return errors.New("failed to set mouse w axis")
+	}
+	return nil
+}
+
+// Sets the precision of the mouse wheel (the z and w coordinates). This
+// precision manifests itself as a multiplier on the dz and dw fields in mouse
+// events. It also affects the z and w fields of events and ALLEGRO_MOUSE_STATE,
+// but not in a simple way if you alter the precision often, so it is suggested
+// to reset those axes to 0 when you change precision. Setting this to a high
+// value allows you to detect small changes in those two axes for some high
+// precision mice. A flexible way of using this precision is to set it to a
+// high value (120 is likely sufficient for most, if not all, mice) and use a
+// floating point dz and dw like so:
+//
+//	allegro.SetMouseWheelPrecision(120)
+//	dz := float64(ev.Dz()) / float64(allegro.MouseWheelPrecision())
+func SetMouseWheelPrecision(precision int) {
+	C.al_set_mouse_wheel_precision(C.int(precision))
+}
+
+// Gets the precision of the mouse wheel (the z and w coordinates).
+func MouseWheelPrecision() int {
+	return int(C.al_get_mouse_wheel_precision())
+}
+
 // Retrieve the mouse event source.
 func MouseEventSource() (*EventSource, error) {
 	source := C.al_get_mouse_event_source()
@@ -142,12 +181,18 @@ func CreateMouseCursor(bmp *Bitmap, x_focus, y_focus int) (*MouseCursor, error)
 		return nil, errors.New("failed to create mouse cursor!")
 	}
 	cursor := (*MouseCursor)(c)
-	//runtime.SetFinalizer(cursor, cursor.Destroy)
+	trackResource(unsafe.Pointer(cursor), "mouse cursor")
 	return cursor, nil
 }
 
 // Free the memory used by the given cursor.
 func (cursor *MouseCursor) Destroy() {
+	if releaseResource(unsafe.Pointer(cursor), "mouse cursor") == nil {
+		cursor.destroy()
+	}
+}
+
+func (cursor *MouseCursor) destroy() {
 	C.al_destroy_mouse_cursor((*C.ALLEGRO_MOUSE_CURSOR)(cursor))
 }
 
@@ -0,0 +1,80 @@
+package allegro
+
+// MouseLook turns mouse movement into relative motion for FPS-style cameras.
+// While enabled, the cursor is hidden and confined to the display and is
+// warped back to the display's centre after every move, so the motion never
+// stops at the edge of the screen.
+type MouseLook struct {
+	// Scales the reported motion.
+	Sensitivity float64
+
+	display *Display
+	enabled bool
+	dx, dy  float64
+}
+
+// NewMouseLook() creates a disabled MouseLook for the display.
+func NewMouseLook(d *Display) *MouseLook {
+	return &MouseLook{Sensitivity: 1, display: d}
+}
+
+// Enable() hides and grabs the cursor and centres it.
+func (m *MouseLook) Enable() error {
+	if err := m.display.HideMouseCursor(); err != nil {
+		return err
+	}
+	if err := m.display.GrabMouse(); err != nil {
+		m.display.ShowMouseCursor()
+		return err
+	}
+	m.enabled = true
+	m.dx, m.dy = 0, 0
+	return m.centre()
+}
+
+// Disable() releases and shows the cursor.
+func (m *MouseLook) Disable() error {
+	if !m.enabled {
+		return nil
+	}
+	m.enabled = false
+	UngrabMouse()
+	return m.display.ShowMouseCursor()
+}
+
+// IsEnabled() returns true between Enable() and Disable().
+func (m *MouseLook) IsEnabled() bool {
+	return m.enabled
+}
+
+func (m *MouseLook) centre() error {
+	return m.display.SetMouseXY(m.display.Width()/2, m.display.Height()/2)
+}
+
+// HandleEvent() accumulates the motion of mouse axes events for the display,
+// returning true if it used the event. The warps it causes are ignored.
+func (m *MouseLook) HandleEvent(ev interface{}) bool {
+	if !m.enabled {
+		return false
+	}
+	switch e := ev.(type) {
+	case MouseAxesEvent:
+		if e.Display() != m.display || (e.Dx() == 0 && e.Dy() == 0) {
+			return false
+		}
+		m.dx += float64(e.Dx()) * m.Sensitivity
+		m.dy += float64(e.Dy()) * m.Sensitivity
+		m.centre()
+		return true
+	case MouseWarpedEvent:
+		return e.Display() == m.display
+	}
+	return false
+}
+
+// Delta() returns the motion since the last call, scaled by Sensitivity.
+func (m *MouseLook) Delta() (dx, dy float64) {
+	dx, dy = m.dx, m.dy
+	m.dx, m.dy = 0, 0
+	return dx, dy
+}
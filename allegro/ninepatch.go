@@ -0,0 +1,80 @@
+package allegro
+
+// NinePatch draws a bitmap split into a 3x3 grid. The corners are drawn at
+// their original size, the edges are stretched along one axis, and the
+// center is stretched along both, which allows a single image to be used for
+// panels and buttons of any size.
+type NinePatch struct {
+	Bitmap *Bitmap
+
+	// The size of the border regions of the source bitmap, in pixels.
+	Left, Top, Right, Bottom float32
+}
+
+// NewNinePatch() creates a nine-patch from a bitmap and the size of its
+// borders.
+func NewNinePatch(bmp *Bitmap, left, top, right, bottom float32) *NinePatch {
+	return &NinePatch{
+		Bitmap: bmp,
+		Left:   left,
+		Top:    top,
+		Right:  right,
+		Bottom: bottom,
+	}
+}
+
+// MinSize() returns the smallest size the nine-patch can be drawn at without
+// the borders overlapping.
+func (n *NinePatch) MinSize() (float32, float32) {
+	return n.Left + n.Right, n.Top + n.Bottom
+}
+
+// Draw() draws the nine-patch stretched to fill the given rectangle. If the
+// rectangle is smaller than MinSize(), the borders are shrunk to fit.
+func (n *NinePatch) Draw(dx, dy, dw, dh float32) {
+	n.DrawTinted(MapRGBAf(1, 1, 1, 1), dx, dy, dw, dh)
+}
+
+// DrawTinted() is like Draw(), but tints the bitmap with the given color.
+func (n *NinePatch) DrawTinted(tint Color, dx, dy, dw, dh float32) {
+	if n.Bitmap == nil {
+		return
+	}
+	bw, bh := float32(n.Bitmap.Width()), float32(n.Bitmap.Height())
+
+	// Source columns and rows.
+	sx := [4]float32{0, n.Left, bw - n.Right, bw}
+	sy := [4]float32{0, n.Top, bh - n.Bottom, bh}
+
+	// Destination columns and rows, shrinking the borders proportionally if
+	// there isn't enough room for them.
+	l, r, t, b := n.Left, n.Right, n.Top, n.Bottom
+	if l+r > dw && l+r > 0 {
+		k := dw / (l + r)
+		l, r = l*k, r*k
+	}
+	if t+b > dh && t+b > 0 {
+		k := dh / (t + b)
+		t, b = t*k, b*k
+	}
+	x := [4]float32{dx, dx + l, dx + dw - r, dx + dw}
+	y := [4]float32{dy, dy + t, dy + dh - b, dy + dh}
+
+	held := IsBitmapDrawingHeld()
+	if !held {
+		HoldBitmapDrawing(true)
+	}
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			sw, sh := sx[col+1]-sx[col], sy[row+1]-sy[row]
+			w, h := x[col+1]-x[col], y[row+1]-y[row]
+			if sw <= 0 || sh <= 0 || w <= 0 || h <= 0 {
+				continue
+			}
+			n.Bitmap.DrawTintedScaled(tint, sx[col], sy[row], sw, sh, x[col], y[row], w, h, 0)
+		}
+	}
+	if !held {
+		HoldBitmapDrawing(false)
+	}
+}
@@ -0,0 +1,132 @@
+package allegro
+
+// The OpenGL integration functions live in this package rather than in a
+// subpackage of their own, since they are part of Allegro's core library and
+// a package named gl would clash with go-gl's when both are imported.
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_opengl.h>
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+type OpenGLVariant int
+
+const (
+	DESKTOP_OPENGL OpenGLVariant = C.ALLEGRO_DESKTOP_OPENGL
+	OPENGL_ES                    = C.ALLEGRO_OPENGL_ES
+)
+
+// Returns the OpenGL or OpenGL ES version number of the client (the computer
+// the program is running on), for the current display. "1.0" is returned as
+// 0x01000000, "1.2.1" is returned as 0x01020100, and "1.2.2" as 0x01020200,
+// etc.
+func OpenGLVersion() uint32 {
+	return uint32(C.al_get_opengl_version())
+}
+
+// This function is a helper to determine whether an OpenGL extension is
+// available on the given display or not.
+func HaveOpenGLExtension(extension string) bool {
+	extension_ := C.CString(extension)
+	defer freeString(extension_)
+	return bool(C.al_have_opengl_extension(extension_))
+}
+
+// Helper to get the address of an OpenGL symbol. Its signature matches the
+// getProcAddr argument of go-gl's InitWithProcAddrFunc(), so go-gl can be
+// initialized against the functions of Allegro's context:
+//
+//	gl.InitWithProcAddrFunc(allegro.OpenGLProcAddress)
+func OpenGLProcAddress(name string) unsafe.Pointer {
+	name_ := C.CString(name)
+	defer freeString(name_)
+	return unsafe.Pointer(C.al_get_opengl_proc_address(name_))
+}
+
+// Returns the variant or type of OpenGL used on the running platform.
+func CurrentOpenGLVariant() OpenGLVariant {
+	return OpenGLVariant(C.al_get_opengl_variant())
+}
+
+// Make the OpenGL context associated with the given display current for the
+// calling thread. If there is a current target bitmap which belongs to a
+// different OpenGL context, the target bitmap will be changed to NULL.
+func (d *Display) SetCurrentOpenGLContext() {
+	C.al_set_current_opengl_context((*C.ALLEGRO_DISPLAY)(d))
+}
+
+// Returns the OpenGL texture id internally used by the given bitmap if it uses
+// one, else 0.
+func (bmp *Bitmap) OpenGLTexture() uint32 {
+	return uint32(C.al_get_opengl_texture((*C.ALLEGRO_BITMAP)(bmp)))
+}
+
+// Retrieves the size of the texture used for the bitmap. This can be different
+// from the bitmap size if OpenGL only supports power-of-two sizes or if it is
+// a sub-bitmap.
+func (bmp *Bitmap) OpenGLTextureSize() (width, height int) {
+	var w, h C.int
+	C.al_get_opengl_texture_size((*C.ALLEGRO_BITMAP)(bmp), &w, &h)
+	return int(w), int(h)
+}
+
+// Returns the u/v coordinates for the top/left corner of the bitmap within the
+// used texture, in pixels.
+func (bmp *Bitmap) OpenGLTexturePosition() (u, v int) {
+	var u_, v_ C.int
+	C.al_get_opengl_texture_position((*C.ALLEGRO_BITMAP)(bmp), &u_, &v_)
+	return int(u_), int(v_)
+}
+
+// Returns the OpenGL FBO id internally used by the given bitmap if it uses
+// one, otherwise returns zero. No attempt will be made to create an FBO if the
+// bitmap is not owned by the current display.
+func (bmp *Bitmap) OpenGLFBO() uint32 {
+	return uint32(C.al_get_opengl_fbo((*C.ALLEGRO_BITMAP)(bmp)))
+}
+
+// Explicitly free an OpenGL FBO created for a bitmap, if it has one. Usually
+// you do not need to worry about freeing FBOs, unless you use
+// al_get_opengl_fbo.
+func (bmp *Bitmap) RemoveOpenGLFBO() {
+	C.al_remove_opengl_fbo((*C.ALLEGRO_BITMAP)(bmp))
+}
+
+// Returns the OpenGL program object associated with this shader, if the
+// platform is ALLEGRO_SHADER_GLSL. Otherwise, returns 0.
+func (s *Shader) OpenGLProgram() uint32 {
+	return uint32(C.al_get_opengl_program_object((*C.ALLEGRO_SHADER)(s)))
+}
+
+// OpenGLVersionString() returns OpenGLVersion() in the usual dotted form,
+// e.g. "3.3.0".
+func OpenGLVersionString() string {
+	v := OpenGLVersion()
+	return fmt.Sprintf("%d.%d.%d", v>>24, (v>>16)&255, (v>>8)&255)
+}
+
+// WithOpenGL() runs f with raw OpenGL access to bmp, which must belong to the
+// display whose context is current on the calling thread. Held bitmap drawing
+// is flushed first so that Allegro's pending vertices land before f's, and bmp
+// is made the target so that its framebuffer (or the backbuffer) is bound
+// while f runs. Allegro's target, blender and transform are restored
+// afterwards; any OpenGL state that f changes outside of those, such as bound
+// textures, programs or buffers, must be restored by f itself because Allegro
+// assumes it owns that state. A StateCache in use should be invalidated
+// after calling this.
+func (bmp *Bitmap) WithOpenGL(f func()) {
+	held := IsBitmapDrawingHeld()
+	if held {
+		HoldBitmapDrawing(false)
+	}
+	state := StoreState(STATE_TARGET_BITMAP | STATE_BLENDER | STATE_TRANSFORM)
+	SetTargetBitmap(bmp)
+	f()
+	RestoreState(state)
+	if held {
+		HoldBitmapDrawing(true)
+	}
+}
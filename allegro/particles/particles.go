@@ -0,0 +1,217 @@
+// Package particles provides a simple particle system. Particles are
+// simulated in Go and each emitter submits all of its live particles to the
+// primitives addon in a single draw call.
+//
+// The primitives addon must be installed before an emitter is drawn.
+package particles
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/primitives"
+	"math"
+	"math/rand"
+)
+
+// Particle is a single live particle.
+type Particle struct {
+	X, Y   float32
+	VX, VY float32
+	Angle  float32 // rotation of the particle's quad, in radians
+	Spin   float32 // change in Angle per second
+	Age    float32 // seconds since the particle was spawned
+	Life   float32 // seconds the particle lives for
+}
+
+// Emitter spawns, simulates and draws particles. The exported fields may be
+// changed at any time; changes only affect particles spawned afterwards,
+// except for the "over life" values which apply to every particle.
+type Emitter struct {
+	// X and Y are the position that new particles are spawned at.
+	X, Y float32
+
+	// SpawnWidth and SpawnHeight describe a rectangle centered on X, Y in
+	// which new particles are placed at random. Both 0 spawns at a point.
+	SpawnWidth, SpawnHeight float32
+
+	// Rate is the number of particles spawned per second while the emitter
+	// is active.
+	Rate float32
+
+	// Active determines whether particles are spawned during Update().
+	// Existing particles continue to be simulated regardless.
+	Active bool
+
+	// Life and LifeVariance give the lifetime of new particles, in seconds.
+	Life, LifeVariance float32
+
+	// Direction and Spread give the direction new particles travel in, in
+	// radians. Each particle is given a random direction within Spread/2 of
+	// Direction.
+	Direction, Spread float32
+
+	// Speed and SpeedVariance give the initial speed of new particles, in
+	// pixels per second.
+	Speed, SpeedVariance float32
+
+	// Spin and SpinVariance give the angular velocity of new particles, in
+	// radians per second.
+	Spin, SpinVariance float32
+
+	// GravityX and GravityY are the acceleration applied to every particle,
+	// in pixels per second squared.
+	GravityX, GravityY float32
+
+	// Damping is the fraction of velocity lost per second, from 0 to 1.
+	// Values outside that range are clamped.
+	Damping float32
+
+	// StartColor and EndColor are interpolated over each particle's life.
+	StartColor, EndColor allegro.Color
+
+	// StartSize and EndSize are the width and height of the particle's quad,
+	// in pixels, interpolated over its life.
+	StartSize, EndSize float32
+
+	// Texture is stretched over each particle's quad. If nil, particles are
+	// drawn as solid squares.
+	Texture *allegro.Bitmap
+
+	particles []Particle
+	max       int
+	pending   float32
+	vertices  []primitives.Vertex
+}
+
+// NewEmitter() creates an inactive emitter which can hold at most max live
+// particles at a time. Particles that would exceed the limit are not spawned.
+func NewEmitter(max int) *Emitter {
+	white := allegro.MapRGBAf(1, 1, 1, 1)
+	return &Emitter{
+		Life:       1,
+		Spread:     2 * math.Pi,
+		StartColor: white,
+		EndColor:   allegro.MapRGBAf(0, 0, 0, 0),
+		StartSize:  4,
+		EndSize:    4,
+		particles:  make([]Particle, 0, max),
+		max:        max,
+		vertices:   make([]primitives.Vertex, 0, max*6),
+	}
+}
+
+// Len() returns the number of live particles.
+func (e *Emitter) Len() int {
+	return len(e.particles)
+}
+
+// Particles() returns the live particles. The slice is only valid until the
+// next call to Update(), Burst() or Clear().
+func (e *Emitter) Particles() []Particle {
+	return e.particles
+}
+
+// Clear() removes all live particles.
+func (e *Emitter) Clear() {
+	e.particles = e.particles[:0]
+	e.pending = 0
+}
+
+// Burst() immediately spawns n particles, regardless of whether the emitter
+// is active.
+func (e *Emitter) Burst(n int) {
+	for i := 0; i < n && len(e.particles) < e.max; i++ {
+		e.particles = append(e.particles, e.spawn())
+	}
+}
+
+func vary(base, variance float32) float32 {
+	return base + (rand.Float32()*2-1)*variance
+}
+
+func (e *Emitter) spawn() Particle {
+	dir := float64(e.Direction + (rand.Float32()-0.5)*e.Spread)
+	speed := vary(e.Speed, e.SpeedVariance)
+	sin, cos := math.Sincos(dir)
+	life := vary(e.Life, e.LifeVariance)
+	if life <= 0 {
+		life = 0.001
+	}
+	return Particle{
+		X:    e.X + (rand.Float32()-0.5)*e.SpawnWidth,
+		Y:    e.Y + (rand.Float32()-0.5)*e.SpawnHeight,
+		VX:   float32(cos) * speed,
+		VY:   float32(sin) * speed,
+		Spin: vary(e.Spin, e.SpinVariance),
+		Life: life,
+	}
+}
+
+// Update() advances the simulation by dt seconds, spawning new particles if
+// the emitter is active and removing those that have reached the end of
+// their lives.
+func (e *Emitter) Update(dt float64) {
+	step := float32(dt)
+	damp := float32(1)
+	if e.Damping >= 1 {
+		damp = 0
+	} else if e.Damping > 0 {
+		damp = float32(math.Pow(float64(1-e.Damping), dt))
+	}
+
+	live := e.particles[:0]
+	for _, p := range e.particles {
+		p.Age += step
+		if p.Age >= p.Life {
+			continue
+		}
+		p.VX = (p.VX + e.GravityX*step) * damp
+		p.VY = (p.VY + e.GravityY*step) * damp
+		p.X += p.VX * step
+		p.Y += p.VY * step
+		p.Angle += p.Spin * step
+		live = append(live, p)
+	}
+	e.particles = live
+
+	if e.Active && e.Rate > 0 {
+		e.pending += e.Rate * step
+		n := int(e.pending)
+		e.pending -= float32(n)
+		e.Burst(n)
+	}
+}
+
+// Draw() renders every live particle with a single call to DrawPrim().
+func (e *Emitter) Draw() {
+	if len(e.particles) == 0 {
+		return
+	}
+
+	r0, g0, b0, a0 := e.StartColor.UnmapRGBAf()
+	r1, g1, b1, a1 := e.EndColor.UnmapRGBAf()
+	var tw, th float32
+	if e.Texture != nil {
+		tw, th = float32(e.Texture.Width()), float32(e.Texture.Height())
+	}
+
+	e.vertices = e.vertices[:0]
+	for _, p := range e.particles {
+		t := p.Age / p.Life
+		c := allegro.MapRGBAf(lerp(r0, r1, t), lerp(g0, g1, t), lerp(b0, b1, t), lerp(a0, a1, t))
+		half := lerp(e.StartSize, e.EndSize, t) / 2
+
+		sin, cos := math.Sincos(float64(p.Angle))
+		ax, ay := half*float32(cos), half*float32(sin)
+		tl := primitives.Vertex{X: p.X - ax + ay, Y: p.Y - ay - ax, Color: c, U: 0, V: 0}
+		tr := primitives.Vertex{X: p.X + ax + ay, Y: p.Y + ay - ax, Color: c, U: tw, V: 0}
+		br := primitives.Vertex{X: p.X + ax - ay, Y: p.Y + ay + ax, Color: c, U: tw, V: th}
+		bl := primitives.Vertex{X: p.X - ax - ay, Y: p.Y - ay + ax, Color: c, U: 0, V: th}
+		e.vertices = append(e.vertices, tl, tr, br, tl, br, bl)
+	}
+
+	primitives.DrawPrim(e.vertices, nil, e.Texture, 0, len(e.vertices), primitives.PRIM_TRIANGLE_LIST)
+}
+
+func lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
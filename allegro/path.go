@@ -0,0 +1,225 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// Path is a filesystem path split into a drive, directory components and a
+// filename, which makes it easy to build paths that are valid on every
+// platform, e.g. starting from GetStandardPath().
+type Path C.ALLEGRO_PATH
+
+// The native path separator, i.e. '\' on Windows and '/' elsewhere.
+const NATIVE_PATH_SEP = C.ALLEGRO_NATIVE_PATH_SEP
+
+// Create a path structure from a string. The last component, if it is
+// followed by a directory separator and is neither "." nor "..", is treated
+// as the last directory name in the path. Otherwise the last component is
+// treated as the filename. The string may be NULL for an empty path.
+func CreatePath(str string) *Path {
+	str_ := C.CString(str)
+	defer freeString(str_)
+	return newPath(C.al_create_path(str_))
+}
+
+// This is the same as al_create_path, but interprets the passed string as a
+// directory path. The filename component of the returned path will always be
+// empty.
+func CreatePathForDirectory(str string) *Path {
+	str_ := C.CString(str)
+	defer freeString(str_)
+	return newPath(C.al_create_path_for_directory(str_))
+}
+
+// CreateStandardPath() is like GetStandardPath(), but returns the path as a
+// Path so that components can be added to it.
+func CreateStandardPath(id StandardPath) (*Path, error) {
+	var path *C.ALLEGRO_PATH
+	err := checkErrno("al_get_standard_path", "", func() bool {
+		path = C.al_get_standard_path(C.int(id))
+		return path != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newPath(path), nil
+}
+
+func newPath(path *C.ALLEGRO_PATH) *Path {
+	p := (*Path)(path)
+	trackResource(unsafe.Pointer(p), "path")
+	return p
+}
+
+// Free a path structure. Does nothing if passed NULL.
+func (p *Path) Destroy() {
+	if releaseResource(unsafe.Pointer(p), "path") == nil {
+		p.destroy()
+	}
+}
+
+func (p *Path) destroy() {
+	C.al_destroy_path((*C.ALLEGRO_PATH)(p))
+}
+
+// Clones an ALLEGRO_PATH structure.
+func (p *Path) Clone() *Path {
+	return newPath(C.al_clone_path((*C.ALLEGRO_PATH)(p)))
+}
+
+// String() converts the path to a string using the native separator.
+func (p *Path) String() string {
+	return pathStr((*C.ALLEGRO_PATH)(p))
+}
+
+// Cstr() converts the path to a string, separating directory components with
+// delim.
+func (p *Path) Cstr(delim byte) string {
+	return C.GoString(C.al_path_cstr((*C.ALLEGRO_PATH)(p), C.char(delim)))
+}
+
+// Return the drive letter on a path, or the empty string if there is none.
+func (p *Path) Drive() string {
+	return C.GoString(C.al_get_path_drive((*C.ALLEGRO_PATH)(p)))
+}
+
+// Set the drive string on a path. The drive may be NULL, which is equivalent
+// to setting the drive string to the empty string.
+func (p *Path) SetDrive(drive string) {
+	drive_ := C.CString(drive)
+	defer freeString(drive_)
+	C.al_set_path_drive((*C.ALLEGRO_PATH)(p), drive_)
+}
+
+// Return the filename part of the path, or the empty string if there is none.
+func (p *Path) Filename() string {
+	return C.GoString(C.al_get_path_filename((*C.ALLEGRO_PATH)(p)))
+}
+
+// Set the optional filename part of the path. The filename may be NULL, which
+// is equivalent to setting the filename to the empty string.
+func (p *Path) SetFilename(filename string) {
+	filename_ := C.CString(filename)
+	defer freeString(filename_)
+	C.al_set_path_filename((*C.ALLEGRO_PATH)(p), filename_)
+}
+
+// Return a pointer to the start of the extension of the filename, i.e. the
+// last occurrence of a '.' character in the filename, or an empty string if
+// there is no extension.
+func (p *Path) Extension() string {
+	return C.GoString(C.al_get_path_extension((*C.ALLEGRO_PATH)(p)))
+}
+
+// Replaces the extension of the path with the given one, i.e. replaces
+// everything from the final dot ('.') character onwards, including the dot.
+// If the filename of the path has no extension, the given one is appended.
+// Usually the new extension you supply should include a leading dot. Returns
+// false if the path contains no filename part, i.e. the filename part is the
+// empty string.
+func (p *Path) SetExtension(extension string) bool {
+	extension_ := C.CString(extension)
+	defer freeString(extension_)
+	return bool(C.al_set_path_extension((*C.ALLEGRO_PATH)(p), extension_))
+}
+
+// Return the basename, i.e. filename with the extension removed.
+func (p *Path) Basename() string {
+	return C.GoString(C.al_get_path_basename((*C.ALLEGRO_PATH)(p)))
+}
+
+// Return the number of directory components in a path.
+func (p *Path) NumComponents() int {
+	return int(C.al_get_path_num_components((*C.ALLEGRO_PATH)(p)))
+}
+
+// Return the i'th directory component of a path, counting from zero. If the
+// index is negative then count from the right, i.e. -1 refers to the last
+// path component.
+func (p *Path) Component(i int) string {
+	return C.GoString(C.al_get_path_component((*C.ALLEGRO_PATH)(p), C.int(i)))
+}
+
+// Returns the last directory component, or the empty string if there are no
+// directory components.
+func (p *Path) Tail() string {
+	tail := C.al_get_path_tail((*C.ALLEGRO_PATH)(p))
+	if tail == nil {
+		return ""
+	}
+	return C.GoString(tail)
+}
+
+// Insert a directory component at index i. If the index is negative then
+// count from the right, i.e. -1 refers to the last path component.
+func (p *Path) InsertComponent(i int, s string) {
+	s_ := C.CString(s)
+	defer freeString(s_)
+	C.al_insert_path_component((*C.ALLEGRO_PATH)(p), C.int(i), s_)
+}
+
+// Replace the i'th directory component by another string. If the index is
+// negative then count from the right, i.e. -1 refers to the last path
+// component.
+func (p *Path) ReplaceComponent(i int, s string) {
+	s_ := C.CString(s)
+	defer freeString(s_)
+	C.al_replace_path_component((*C.ALLEGRO_PATH)(p), C.int(i), s_)
+}
+
+// Delete the i'th directory component. If the index is negative then count
+// from the right, i.e. -1 refers to the last path component.
+func (p *Path) RemoveComponent(i int) {
+	C.al_remove_path_component((*C.ALLEGRO_PATH)(p), C.int(i))
+}
+
+// Append a directory component.
+func (p *Path) AppendComponent(s string) {
+	s_ := C.CString(s)
+	defer freeString(s_)
+	C.al_append_path_component((*C.ALLEGRO_PATH)(p), s_)
+}
+
+// Remove the last directory component, if any.
+func (p *Path) DropTail() {
+	C.al_drop_path_tail((*C.ALLEGRO_PATH)(p))
+}
+
+// Concatenate two path structures. The first path structure is modified. If
+// 'tail' is an absolute path, this function does nothing.
+func (p *Path) Join(tail *Path) bool {
+	return bool(C.al_join_paths((*C.ALLEGRO_PATH)(p), (*C.ALLEGRO_PATH)(tail)))
+}
+
+// Concatenate two path structures, modifying the second path structure. If
+// tail is an absolute path, nothing happens. Otherwise, tail becomes a path
+// relative to head.
+func (p *Path) Rebase(head *Path) bool {
+	return bool(C.al_rebase_path((*C.ALLEGRO_PATH)(head), (*C.ALLEGRO_PATH)(p)))
+}
+
+// Removes any leading '..' directory components in absolute paths. Removes all
+// '.' directory components.
+func (p *Path) MakeCanonical() bool {
+	return bool(C.al_make_path_canonical((*C.ALLEGRO_PATH)(p)))
+}
+
+// StandardFilePath() returns the native path of filename within the given
+// standard directory, such as USER_SETTINGS_PATH for a config file, creating
+// the directory first if needed. Set the organization and application names
+// beforehand so that each game gets its own directory.
+func StandardFilePath(id StandardPath, filename string) (string, error) {
+	p, err := CreateStandardPath(id)
+	if err != nil {
+		return "", err
+	}
+	defer p.Destroy()
+	p.SetFilename("")
+	if err := MakeDirectory(p.String()); err != nil {
+		return "", err
+	}
+	p.SetFilename(filename)
+	return p.String(), nil
+}
@@ -1,4 +1,5 @@
 package physfs
 
 // #cgo !windows pkg-config: allegro_physfs-5
+// #cgo !windows LDFLAGS: -lphysfs
 import "C"
@@ -0,0 +1,119 @@
+package physfs
+
+// #include <stdlib.h>
+// #include <physfs.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+// lastError() returns PhysicsFS's description of why the last call failed.
+func lastError() error {
+	msg := C.PHYSFS_getErrorByCode(C.PHYSFS_getLastErrorCode())
+	if msg == nil {
+		return errors.New("unknown physfs error")
+	}
+	return errors.New(C.GoString(msg))
+}
+
+// Init() initializes the PhysicsFS library, which must be done before
+// archives can be mounted. It does nothing if PhysicsFS is already
+// initialized.
+func Init() error {
+	if IsInit() {
+		return nil
+	}
+	argv0 := C.CString(os.Args[0])
+	defer C.free(unsafe.Pointer(argv0))
+	if C.PHYSFS_init(argv0) == 0 {
+		return fmt.Errorf("failed to initialize physfs: %v", lastError())
+	}
+	return nil
+}
+
+// IsInit() returns true if the PhysicsFS library is initialized.
+func IsInit() bool {
+	return C.PHYSFS_isInit() != 0
+}
+
+// Deinit() shuts PhysicsFS down, unmounting everything. Files opened through
+// it must be closed first.
+func Deinit() error {
+	if C.PHYSFS_deinit() == 0 {
+		return fmt.Errorf("failed to shut down physfs: %v", lastError())
+	}
+	return nil
+}
+
+// Mount() adds an archive, such as a ZIP file, or a directory to the search
+// path, so that its files appear under mountPoint ("" or "/" for the root).
+// Archives mounted with appendToPath set are searched after those already
+// mounted; otherwise they are searched first, which lets a patch archive
+// override the files of the base game.
+func Mount(archive, mountPoint string, appendToPath bool) error {
+	archive_ := C.CString(archive)
+	defer C.free(unsafe.Pointer(archive_))
+	var mountPoint_ *C.char
+	if mountPoint != "" {
+		mountPoint_ = C.CString(mountPoint)
+		defer C.free(unsafe.Pointer(mountPoint_))
+	}
+	var append_ C.int
+	if appendToPath {
+		append_ = 1
+	}
+	if C.PHYSFS_mount(archive_, mountPoint_, append_) == 0 {
+		return fmt.Errorf("failed to mount '%s': %v", archive, lastError())
+	}
+	return nil
+}
+
+// Unmount() removes an archive or directory added with Mount() from the
+// search path. It fails if files are still open in it.
+func Unmount(archive string) error {
+	archive_ := C.CString(archive)
+	defer C.free(unsafe.Pointer(archive_))
+	if C.PHYSFS_unmount(archive_) == 0 {
+		return fmt.Errorf("failed to unmount '%s': %v", archive, lastError())
+	}
+	return nil
+}
+
+// Exists() returns true if a file or directory with the given name is found
+// in the search path.
+func Exists(name string) bool {
+	name_ := C.CString(name)
+	defer C.free(unsafe.Pointer(name_))
+	return C.PHYSFS_exists(name_) != 0
+}
+
+// MountAssets() initializes PhysicsFS, mounts the archives in order, so that
+// later ones are searched first, and switches the calling thread's file
+// interface to PhysicsFS. After that, LoadBitmap(), LoadFont(), LoadSample(),
+// LoadConfig() and the rest read from the archives:
+//
+//	runtime.LockOSThread()
+//	if err := physfs.MountAssets("data.zip", "patch.zip"); err != nil {
+//		log.Fatal(err)
+//	}
+//	bmp, err := allegro.LoadBitmap("sprites/hero.png") // from the archives
+//
+// Allegro keeps the file interface per thread, so assets must be loaded from
+// the OS thread that called MountAssets(); lock the goroutine to its thread,
+// as above, or call UseFileInterface() again on the loading thread. Paths
+// use "/" as the separator and are relative to the archive roots.
+func MountAssets(archives ...string) error {
+	if err := Init(); err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		if err := Mount(archive, "", false); err != nil {
+			return err
+		}
+	}
+	UseFileInterface()
+	return nil
+}
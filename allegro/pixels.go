@@ -0,0 +1,135 @@
+package allegro
+
+// #include <string.h>
+// #include <allegro5/allegro.h>
+/*
+// The rows are copied under a single lock so that reading or writing a whole
+// area costs two cgo calls at most, rather than one per pixel.
+static bool copy_pixels(ALLEGRO_BITMAP *bmp, int x, int y, int w, int h,
+                        int format, char *buf, bool write) {
+	ALLEGRO_LOCKED_REGION *reg = al_lock_bitmap_region(bmp, x, y, w, h, format,
+		write ? ALLEGRO_LOCK_WRITEONLY : ALLEGRO_LOCK_READONLY);
+	if (reg == NULL) {
+		return false;
+	}
+	size_t n = (size_t)w * reg->pixel_size;
+	char *row = reg->data;
+	for (int i = 0; i < h; i++) {
+		if (write) {
+			memcpy(row, buf, n);
+		} else {
+			memcpy(buf, row, n);
+		}
+		row += reg->pitch;
+		buf += n;
+	}
+	al_unlock_bitmap(bmp);
+	return true;
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"image"
+	"unsafe"
+)
+
+// checkPixelRect() returns an error unless r is a non-empty area within the
+// bitmap.
+func (bmp *Bitmap) checkPixelRect(r image.Rectangle) error {
+	if bmp == nil {
+		return BitmapIsNull
+	}
+	if r.Empty() || !r.In(image.Rect(0, 0, bmp.Width(), bmp.Height())) {
+		return fmt.Errorf("rectangle %v is outside the %dx%d bitmap", r, bmp.Width(), bmp.Height())
+	}
+	return nil
+}
+
+func (bmp *Bitmap) copyPixels(r image.Rectangle, format PixelFormat, buf unsafe.Pointer, write bool) error {
+	ok := C.copy_pixels((*C.ALLEGRO_BITMAP)(bmp),
+		C.int(r.Min.X), C.int(r.Min.Y), C.int(r.Dx()), C.int(r.Dy()),
+		C.int(format), (*C.char)(buf), C.bool(write))
+	if !ok {
+		return errors.New("failed to lock bitmap region; is it already locked?")
+	}
+	return nil
+}
+
+// ReadPixels() returns the colors of the pixels in r, row by row. It is much
+// faster than calling Pixel() for each of them, as the area is locked once
+// and copied in a single cgo call. r must lie within the bitmap.
+func (bmp *Bitmap) ReadPixels(r image.Rectangle) ([]Color, error) {
+	if err := bmp.checkPixelRect(r); err != nil {
+		return nil, err
+	}
+	// PIXEL_FORMAT_ABGR_F32 is laid out in memory as an ALLEGRO_COLOR.
+	colors := make([]Color, r.Dx()*r.Dy())
+	err := bmp.copyPixels(r, PIXEL_FORMAT_ABGR_F32, unsafe.Pointer(&colors[0]), false)
+	if err != nil {
+		return nil, err
+	}
+	return colors, nil
+}
+
+// WritePixels() replaces the pixels in r with colors, given row by row, in a
+// single lock. No blending is done. r must lie within the bitmap.
+func (bmp *Bitmap) WritePixels(r image.Rectangle, colors []Color) error {
+	if err := bmp.checkPixelRect(r); err != nil {
+		return err
+	}
+	if len(colors) != r.Dx()*r.Dy() {
+		return fmt.Errorf("got %d colors for a %dx%d area", len(colors), r.Dx(), r.Dy())
+	}
+	return bmp.copyPixels(r, PIXEL_FORMAT_ABGR_F32, unsafe.Pointer(&colors[0]), true)
+}
+
+// pixelBytesFormat() resolves PIXEL_FORMAT_ANY to the bitmap's format and
+// rejects formats without a fixed pixel size, i.e. the other PIXEL_FORMAT_ANY_*
+// values, whose real format is only known once locked, and compressed
+// formats.
+func (bmp *Bitmap) pixelBytesFormat(format PixelFormat) (PixelFormat, error) {
+	if format == PIXEL_FORMAT_ANY {
+		format = bmp.Format()
+	}
+	if format.PixelSize() <= 0 {
+		return 0, fmt.Errorf("pixel format %d has no fixed pixel size", format)
+	}
+	return format, nil
+}
+
+// ReadPixelBytes() returns the pixels in r converted to format, with the rows
+// packed together. If format is PIXEL_FORMAT_ANY the bitmap's own format is
+// used, which avoids any conversion. The other PIXEL_FORMAT_ANY_* values and
+// compressed formats aren't supported.
+func (bmp *Bitmap) ReadPixelBytes(r image.Rectangle, format PixelFormat) ([]byte, error) {
+	if err := bmp.checkPixelRect(r); err != nil {
+		return nil, err
+	}
+	format, err := bmp.pixelBytesFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, r.Dx()*r.Dy()*format.PixelSize())
+	if err := bmp.copyPixels(r, format, unsafe.Pointer(&buf[0]), false); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// WritePixelBytes() replaces the pixels in r with data, given in format with
+// the rows packed together, as returned by ReadPixelBytes().
+func (bmp *Bitmap) WritePixelBytes(r image.Rectangle, format PixelFormat, data []byte) error {
+	if err := bmp.checkPixelRect(r); err != nil {
+		return err
+	}
+	format, err := bmp.pixelBytesFormat(format)
+	if err != nil {
+		return err
+	}
+	if n := r.Dx() * r.Dy() * format.PixelSize(); len(data) != n {
+		return fmt.Errorf("got %d bytes for a %dx%d area, want %d", len(data), r.Dx(), r.Dy(), n)
+	}
+	return bmp.copyPixels(r, format, unsafe.Pointer(&data[0]), true)
+}
@@ -0,0 +1,108 @@
+package allegro
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPixelsRoundTrip(t *testing.T) {
+	if err := install(); err != nil {
+		t.Skip(err)
+	}
+	defer SetNewBitmapFlags(NewBitmapFlags())
+	SetNewBitmapFlags(MEMORY_BITMAP)
+	bmp := CreateBitmap(8, 8)
+	if bmp == nil {
+		t.Fatal("failed to create bitmap")
+	}
+	defer bmp.Destroy()
+
+	r := image.Rect(2, 3, 6, 5)
+	colors := make([]Color, r.Dx()*r.Dy())
+	for i := range colors {
+		colors[i] = MapRGB(byte(i*16), 0, 255)
+	}
+	if err := bmp.WritePixels(r, colors); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range colors {
+		x, y := r.Min.X+i%r.Dx(), r.Min.Y+i/r.Dx()
+		if got := bmp.Pixel(x, y); got != c {
+			t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, c)
+		}
+	}
+	got, err := bmp.ReadPixels(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		if got[i] != colors[i] {
+			t.Errorf("ReadPixels()[%d] = %v, want %v", i, got[i], colors[i])
+		}
+	}
+
+	if _, err := bmp.ReadPixels(image.Rect(4, 4, 10, 10)); err == nil {
+		t.Errorf("ReadPixels() outside the bitmap succeeded")
+	}
+}
+
+func BenchmarkPixel(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for y := 0; y < bmp.Height(); y++ {
+			for x := 0; x < bmp.Width(); x++ {
+				bmp.Pixel(x, y)
+			}
+		}
+	}
+}
+
+func BenchmarkReadPixels(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	r := image.Rect(0, 0, bmp.Width(), bmp.Height())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bmp.ReadPixels(r)
+	}
+}
+
+func BenchmarkPutPixel(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	c := MapRGB(255, 0, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bmp.AsTarget(func() {
+			for y := 0; y < bmp.Height(); y++ {
+				for x := 0; x < bmp.Width(); x++ {
+					PutPixel(x, y, c)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWritePixels(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	r := image.Rect(0, 0, bmp.Width(), bmp.Height())
+	colors := make([]Color, r.Dx()*r.Dy())
+	for i := range colors {
+		colors[i] = MapRGB(255, 0, 0)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bmp.WritePixels(r, colors)
+	}
+}
@@ -1,4 +1,5 @@
-// +build ignore
+//go:build ios
+// +build ios
 
 package allegro
 
@@ -6,7 +7,15 @@ package allegro
 // #include <allegro5/allegro_iphone.h>
 import "C"
 
-/*
+type IPhoneStatusbarOrientation int
+
+const (
+	IPHONE_STATUSBAR_ORIENTATION_PORTRAIT             IPhoneStatusbarOrientation = C.ALLEGRO_IPHONE_STATUSBAR_ORIENTATION_PORTRAIT
+	IPHONE_STATUSBAR_ORIENTATION_PORTRAIT_UPSIDE_DOWN                            = C.ALLEGRO_IPHONE_STATUSBAR_ORIENTATION_PORTRAIT_UPSIDE_DOWN
+	IPHONE_STATUSBAR_ORIENTATION_LANDSCAPE_RIGHT                                 = C.ALLEGRO_IPHONE_STATUSBAR_ORIENTATION_LANDSCAPE_RIGHT
+	IPHONE_STATUSBAR_ORIENTATION_LANDSCAPE_LEFT                                  = C.ALLEGRO_IPHONE_STATUSBAR_ORIENTATION_LANDSCAPE_LEFT
+)
+
 // Multitasking on iOS is different than on other platforms. When an
 // application receives an ALLEGRO_DISPLAY_SWITCH_OUT or ALLEGRO_DISPLAY_CLOSE
 // event on a multitasking-capable device, it should cease all activity and do
@@ -19,7 +28,7 @@ import "C"
 // can't return until these operations have stopped, or a crash as described
 // before can happen.
 func IPhoneProgramHasHalted() {
-    C.al_iphone_program_has_halted()
+	C.al_iphone_program_has_halted()
 }
 
 // Original iPhones and iPod Touches had a screen resolution of 320x480 (in
@@ -36,6 +45,26 @@ func IPhoneProgramHasHalted() {
 // of the original iPhone resolution, linear filtering will be applied to the
 // final image.
 func IPhoneOverrideScreenScale(scale float32) {
-    C.al_iphone_override_screen_scale(C.float(scale))
+	C.al_iphone_override_screen_scale(C.float(scale))
+}
+
+// Returns the scale factor of the screen, e.g. 2.0 on retina displays.
+func IPhoneScreenScale() float32 {
+	return float32(C.al_iphone_get_screen_scale())
+}
+
+// Sets the orientation of the status bar, which can be one of the
+// IPHONE_STATUSBAR_ORIENTATION_* values.
+func IPhoneSetStatusbarOrientation(orientation IPhoneStatusbarOrientation) {
+	C.al_iphone_set_statusbar_orientation(C.int(orientation))
+}
+
+// Returns the time the device was last shaken, as reported by al_get_time.
+func IPhoneLastShakeTime() float64 {
+	return float64(C.al_iphone_get_last_shake_time())
+}
+
+// Returns the battery level, from 0 to 1.
+func IPhoneBatteryLevel() float32 {
+	return float32(C.al_iphone_get_battery_level())
 }
-*/
@@ -0,0 +1,168 @@
+// Package postfx composes fullscreen post-processing effects, such as bloom,
+// CRT emulation or color grading, out of a chain of shader passes.
+package postfx
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Pass is one step of a chain. Its shader is used to draw the output of the
+// previous pass (or the scene, for the first pass) over the whole target, so
+// the input is available through the default "al_tex" sampler.
+type Pass struct {
+	// Shader is the shader for this pass. A nil shader copies the input
+	// unchanged, which is occasionally useful while tuning a chain.
+	Shader *allegro.Shader
+
+	// Uniforms, if set, is called with the shader in use just before the pass
+	// is drawn, to set any uniforms the shader needs.
+	Uniforms func(c *Chain)
+
+	// Disabled passes are skipped.
+	Disabled bool
+}
+
+// Chain holds the scene bitmap and the intermediate render targets of a list
+// of passes. Intermediate results alternate between two bitmaps, so the cost
+// in video memory does not grow with the number of passes.
+type Chain struct {
+	// Passes are applied in order.
+	Passes []*Pass
+
+	// Time is made available to shaders as the "time" uniform, for animated
+	// effects. Advancing it is up to the caller.
+	Time float32
+
+	width, height int
+	scene         *allegro.Bitmap
+	targets       [2]*allegro.Bitmap
+	old           *allegro.Bitmap
+}
+
+// New() creates a chain for a scene of the given size. The bitmaps are created
+// with the current new bitmap flags.
+func New(width, height int) (*Chain, error) {
+	c := Chain{width: width, height: height}
+	c.scene = allegro.CreateBitmap(width, height)
+	c.targets[0] = allegro.CreateBitmap(width, height)
+	c.targets[1] = allegro.CreateBitmap(width, height)
+	if c.scene == nil || c.targets[0] == nil || c.targets[1] == nil {
+		c.Destroy()
+		return nil, errors.New("failed to create post-processing targets")
+	}
+	return &c, nil
+}
+
+// Add() appends a pass to the chain and returns the chain, so that passes can
+// be declared in one expression.
+func (c *Chain) Add(shader *allegro.Shader, uniforms func(c *Chain)) *Chain {
+	c.Passes = append(c.Passes, &Pass{Shader: shader, Uniforms: uniforms})
+	return c
+}
+
+// Destroy() frees the chain's bitmaps. The passes' shaders are not destroyed.
+func (c *Chain) Destroy() {
+	for _, bmp := range []*allegro.Bitmap{c.scene, c.targets[0], c.targets[1]} {
+		if bmp != nil {
+			bmp.Destroy()
+		}
+	}
+	c.scene, c.targets[0], c.targets[1] = nil, nil, nil
+}
+
+// Scene() returns the bitmap that the scene is drawn to. During a pass it can
+// be bound to a second sampler, e.g. for combining a blurred image with the
+// original when rendering bloom.
+func (c *Chain) Scene() *allegro.Bitmap {
+	return c.scene
+}
+
+// Size() returns the size of the chain's bitmaps.
+func (c *Chain) Size() (width, height int) {
+	return c.width, c.height
+}
+
+// Begin() makes the scene bitmap the target, so that everything drawn until
+// the matching Apply() goes through the chain.
+func (c *Chain) Begin() {
+	c.old = allegro.TargetBitmap()
+	allegro.SetTargetBitmap(c.scene)
+}
+
+// Apply() runs the passes and draws the final result to dst, scaled to fit it
+// exactly. A nil dst means the target that was active when Begin() was
+// called, which is then restored as the target. If a pass fails, that target
+// is restored too, with no shader in use.
+func (c *Chain) Apply(dst *allegro.Bitmap) (err error) {
+	if dst == nil {
+		if c.old == nil {
+			return errors.New("no destination given and Begin() was not called")
+		}
+		dst = c.old
+	}
+	defer func() {
+		if err != nil {
+			allegro.UseShader(nil)
+			if c.old != nil {
+				allegro.SetTargetBitmap(c.old)
+			}
+		}
+	}()
+	var passes []*Pass
+	for _, p := range c.Passes {
+		if !p.Disabled {
+			passes = append(passes, p)
+		}
+	}
+
+	input := c.scene
+	for i, p := range passes {
+		target := dst
+		if i < len(passes)-1 {
+			target = c.targets[i%2]
+		}
+		if err := c.draw(p, input, target); err != nil {
+			return err
+		}
+		input = target
+	}
+	if len(passes) == 0 {
+		if err := c.draw(&Pass{}, input, dst); err != nil {
+			return err
+		}
+	}
+	if dst == c.old {
+		allegro.SetTargetBitmap(dst)
+	}
+	return nil
+}
+
+func (c *Chain) draw(p *Pass, input, target *allegro.Bitmap) error {
+	allegro.SetTargetBitmap(target)
+	if target == c.targets[0] || target == c.targets[1] {
+		// Intermediate results replace the previous contents rather than
+		// blending with them.
+		state := allegro.StoreState(allegro.STATE_BLENDER)
+		defer allegro.RestoreState(state)
+		allegro.SetBlender(allegro.ADD, allegro.ONE, allegro.ZERO)
+	}
+	if p.Shader != nil {
+		if err := allegro.UseShader(p.Shader); err != nil {
+			return err
+		}
+		// Shaders don't have to declare every uniform, so failures to set
+		// these are ignored.
+		allegro.SetShaderFloat("time", c.Time)
+		allegro.SetShaderFloatVector("texel_size", [][]float32{{1 / float32(c.width), 1 / float32(c.height)}})
+		if p.Uniforms != nil {
+			p.Uniforms(c)
+		}
+	}
+	w, h := float32(c.width), float32(c.height)
+	input.DrawScaled(0, 0, w, h, 0, 0, float32(target.Width()), float32(target.Height()), 0)
+	if p.Shader != nil {
+		return allegro.UseShader(nil)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package primitives
+
+import (
+	"math"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Batcher collects bitmap draws as textured quads and submits consecutive
+// draws of the same bitmap with a single call to DrawPrim(), rather than one
+// cgo call per sprite.
+//
+// Draws are only submitted when the bitmap changes or Flush() is called, so
+// Flush() must be called before changing the target bitmap, transform,
+// blender or shader, and at the end of the frame.
+type Batcher struct {
+	texture  *allegro.Bitmap
+	vertices []Vertex
+	white    allegro.Color
+}
+
+// NewBatcher() creates an empty batcher.
+func NewBatcher() *Batcher {
+	return &Batcher{
+		vertices: make([]Vertex, 0, 6*256),
+		white:    allegro.MapRGBAf(1, 1, 1, 1),
+	}
+}
+
+// Flush() submits any pending draws.
+func (b *Batcher) Flush() {
+	if len(b.vertices) > 0 {
+		DrawPrim(b.vertices, nil, b.texture, 0, len(b.vertices), PRIM_TRIANGLE_LIST)
+	}
+	b.vertices = b.vertices[:0]
+	b.texture = nil
+}
+
+// Draw() is the batched equivalent of Bitmap.Draw().
+func (b *Batcher) Draw(bmp *allegro.Bitmap, dx, dy float32, flags allegro.DrawFlags) {
+	w, h := float32(bmp.Width()), float32(bmp.Height())
+	b.add(bmp, 0, 0, w, h, b.white, dx, dy, w, h, flags)
+}
+
+// DrawTinted() is the batched equivalent of Bitmap.DrawTinted().
+func (b *Batcher) DrawTinted(bmp *allegro.Bitmap, tint allegro.Color, dx, dy float32, flags allegro.DrawFlags) {
+	w, h := float32(bmp.Width()), float32(bmp.Height())
+	b.add(bmp, 0, 0, w, h, tint, dx, dy, w, h, flags)
+}
+
+// DrawRegion() is the batched equivalent of Bitmap.DrawRegion().
+func (b *Batcher) DrawRegion(bmp *allegro.Bitmap, sx, sy, sw, sh, dx, dy float32, flags allegro.DrawFlags) {
+	b.add(bmp, sx, sy, sw, sh, b.white, dx, dy, sw, sh, flags)
+}
+
+// DrawTintedRegion() is the batched equivalent of Bitmap.DrawTintedRegion().
+func (b *Batcher) DrawTintedRegion(bmp *allegro.Bitmap, tint allegro.Color, sx, sy, sw, sh, dx, dy float32, flags allegro.DrawFlags) {
+	b.add(bmp, sx, sy, sw, sh, tint, dx, dy, sw, sh, flags)
+}
+
+// DrawScaled() is the batched equivalent of Bitmap.DrawScaled().
+func (b *Batcher) DrawScaled(bmp *allegro.Bitmap, sx, sy, sw, sh, dx, dy, dw, dh float32, flags allegro.DrawFlags) {
+	b.add(bmp, sx, sy, sw, sh, b.white, dx, dy, dw, dh, flags)
+}
+
+// DrawTintedScaled() is the batched equivalent of Bitmap.DrawTintedScaled().
+func (b *Batcher) DrawTintedScaled(bmp *allegro.Bitmap, tint allegro.Color, sx, sy, sw, sh, dx, dy, dw, dh float32, flags allegro.DrawFlags) {
+	b.add(bmp, sx, sy, sw, sh, tint, dx, dy, dw, dh, flags)
+}
+
+// DrawRotated() is the batched equivalent of Bitmap.DrawRotated().
+func (b *Batcher) DrawRotated(bmp *allegro.Bitmap, cx, cy, dx, dy, angle float32, flags allegro.DrawFlags) {
+	w, h := float32(bmp.Width()), float32(bmp.Height())
+	b.DrawTintedScaledRotatedRegion(bmp, 0, 0, w, h, b.white, cx, cy, dx, dy, 1, 1, angle, flags)
+}
+
+// DrawTintedScaledRotatedRegion() is the batched equivalent of
+// Bitmap.DrawTintedScaledRotatedRegion(), and can express every other kind of
+// draw.
+func (b *Batcher) DrawTintedScaledRotatedRegion(bmp *allegro.Bitmap, sx, sy, sw, sh float32, tint allegro.Color, cx, cy, dx, dy, xscale, yscale, angle float32, flags allegro.DrawFlags) {
+	if bmp == nil {
+		return
+	}
+	b.use(bmp)
+
+	u0, v0, u1, v1 := uvs(sx, sy, sw, sh, flags)
+	sin, cos := math.Sincos(float64(angle))
+	s, c := float32(sin), float32(cos)
+
+	// Corners relative to the pivot, scaled and then rotated about it.
+	corner := func(x, y, u, v float32) Vertex {
+		x, y = (x-cx)*xscale, (y-cy)*yscale
+		return Vertex{X: dx + x*c - y*s, Y: dy + x*s + y*c, Color: tint, U: u, V: v}
+	}
+	tl := corner(0, 0, u0, v0)
+	tr := corner(sw, 0, u1, v0)
+	br := corner(sw, sh, u1, v1)
+	bl := corner(0, sh, u0, v1)
+	b.vertices = append(b.vertices, tl, tr, br, tl, br, bl)
+}
+
+func (b *Batcher) use(bmp *allegro.Bitmap) {
+	if bmp != b.texture {
+		b.Flush()
+		b.texture = bmp
+	}
+}
+
+func uvs(sx, sy, sw, sh float32, flags allegro.DrawFlags) (u0, v0, u1, v1 float32) {
+	u0, v0, u1, v1 = sx, sy, sx+sw, sy+sh
+	if flags&allegro.FLIP_HORIZONTAL != 0 {
+		u0, u1 = u1, u0
+	}
+	if flags&allegro.FLIP_VERTICAL != 0 {
+		v0, v1 = v1, v0
+	}
+	return
+}
+
+func (b *Batcher) add(bmp *allegro.Bitmap, sx, sy, sw, sh float32, tint allegro.Color, dx, dy, dw, dh float32, flags allegro.DrawFlags) {
+	if bmp == nil {
+		return
+	}
+	b.use(bmp)
+
+	b.vertices = appendQuad(b.vertices, [4]allegro.Color{tint, tint, tint, tint}, sx, sy, sw, sh, dx, dy, dw, dh, flags)
+}
+
+// DrawTintedQuad() queues a scaled bitmap region with a separate tint for
+// each corner. See the package-level DrawTintedQuad().
+func (b *Batcher) DrawTintedQuad(bmp *allegro.Bitmap, tints [4]allegro.Color, sx, sy, sw, sh, dx, dy, dw, dh float32, flags allegro.DrawFlags) {
+	if bmp == nil {
+		return
+	}
+	b.use(bmp)
+	b.vertices = appendQuad(b.vertices, tints, sx, sy, sw, sh, dx, dy, dw, dh, flags)
+}
@@ -0,0 +1,88 @@
+package primitives
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_primitives.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// IndexBuffer is a buffer of vertex indices stored in video memory, for
+// drawing shared vertices out of a VertexBuffer with DrawIndexedBuffer().
+type IndexBuffer C.ALLEGRO_INDEX_BUFFER
+
+// Creates an index buffer of 32 bit indices. If indices is not empty, it is
+// used to initialize the buffer and must hold at least numIndices indices;
+// otherwise the buffer's contents are undefined.
+func CreateIndexBuffer(indices []int, numIndices int, flags PrimBufferFlags) (*IndexBuffer, error) {
+	var initial unsafe.Pointer
+	if len(indices) > 0 {
+		if numIndices > len(indices) {
+			return nil, fmt.Errorf("index buffer of %d indices initialized with only %d", numIndices, len(indices))
+		}
+		raw := make([]uint32, len(indices))
+		for i, index := range indices {
+			raw[i] = uint32(index)
+		}
+		initial = unsafe.Pointer(&raw[0])
+	}
+	ib := C.al_create_index_buffer(4, initial, C.int(numIndices), C.int(flags))
+	if ib == nil {
+		return nil, errors.New("failed to create index buffer")
+	}
+	return (*IndexBuffer)(ib), nil
+}
+
+// Destroys an index buffer.
+func (ib *IndexBuffer) Destroy() {
+	C.al_destroy_index_buffer((*C.ALLEGRO_INDEX_BUFFER)(ib))
+}
+
+// Returns the size of the index buffer, in indices.
+func (ib *IndexBuffer) Size() int {
+	return int(C.al_get_index_buffer_size((*C.ALLEGRO_INDEX_BUFFER)(ib)))
+}
+
+// Locks an index buffer so you can access its data. Returns nil if the buffer
+// could not be locked.
+func (ib *IndexBuffer) Lock(offset, length int, flags allegro.LockFlags) unsafe.Pointer {
+	return C.al_lock_index_buffer((*C.ALLEGRO_INDEX_BUFFER)(ib), C.int(offset), C.int(length), C.int(flags))
+}
+
+// Unlocks a previously locked index buffer.
+func (ib *IndexBuffer) Unlock() {
+	C.al_unlock_index_buffer((*C.ALLEGRO_INDEX_BUFFER)(ib))
+}
+
+// Write() copies indices into the buffer starting at offset, locking only the
+// range being written.
+func (ib *IndexBuffer) Write(offset int, indices []int) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	p := ib.Lock(offset, len(indices), allegro.LOCK_WRITEONLY)
+	if p == nil {
+		return errors.New("failed to lock index buffer")
+	}
+	dst := unsafe.Slice((*uint32)(p), len(indices))
+	for i, index := range indices {
+		dst[i] = uint32(index)
+	}
+	ib.Unlock()
+	return nil
+}
+
+// Draws a subset of the passed vertex buffer, using the indices in the index
+// buffer between start and end to pick the vertices.
+func DrawIndexedBuffer(vb *VertexBuffer, texture *allegro.Bitmap, ib *IndexBuffer, start, end int, prim_type PrimType) int {
+	return int(C.al_draw_indexed_buffer((*C.ALLEGRO_VERTEX_BUFFER)(vb),
+		(*C.ALLEGRO_BITMAP)(texture),
+		(*C.ALLEGRO_INDEX_BUFFER)(ib),
+		C.int(start),
+		C.int(end),
+		C.int(prim_type)))
+}
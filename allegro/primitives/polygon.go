@@ -0,0 +1,162 @@
+package primitives
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_primitives.h>
+/*
+typedef struct {
+	int *indices;
+	size_t len, cap;
+	bool failed;
+} triangle_list;
+
+static void collect_triangle(int i0, int i1, int i2, void *userdata) {
+	triangle_list *list = userdata;
+	if (list->failed) {
+		return;
+	}
+	if (list->len + 3 > list->cap) {
+		size_t cap = list->cap ? list->cap * 2 : 96;
+		int *indices = realloc(list->indices, cap * sizeof(int));
+		if (!indices) {
+			list->failed = true;
+			return;
+		}
+		list->indices = indices;
+		list->cap = cap;
+	}
+	list->indices[list->len++] = i0;
+	list->indices[list->len++] = i1;
+	list->indices[list->len++] = i2;
+}
+
+static bool triangulate_polygon(const float *vertices, const int *counts, triangle_list *list) {
+	return al_triangulate_polygon(vertices, 2 * sizeof(float), counts,
+		collect_triangle, list) && !list->failed;
+}
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+type LineJoin int
+
+const (
+	LINE_JOIN_NONE  LineJoin = C.ALLEGRO_LINE_JOIN_NONE
+	LINE_JOIN_BEVEL          = C.ALLEGRO_LINE_JOIN_BEVEL
+	LINE_JOIN_ROUND          = C.ALLEGRO_LINE_JOIN_ROUND
+	LINE_JOIN_MITER          = C.ALLEGRO_LINE_JOIN_MITER
+)
+
+type LineCap int
+
+const (
+	LINE_CAP_NONE     LineCap = C.ALLEGRO_LINE_CAP_NONE
+	LINE_CAP_SQUARE           = C.ALLEGRO_LINE_CAP_SQUARE
+	LINE_CAP_ROUND            = C.ALLEGRO_LINE_CAP_ROUND
+	LINE_CAP_TRIANGLE         = C.ALLEGRO_LINE_CAP_TRIANGLE
+	LINE_CAP_CLOSED           = C.ALLEGRO_LINE_CAP_CLOSED
+)
+
+// Point has the same layout as the float pairs the polygon functions take, so
+// a slice of points can be passed to them as is.
+func pointsPtr(points []Point) *C.float {
+	return (*C.float)(unsafe.Pointer(&points[0]))
+}
+
+// Draw a series of line segments. A thickness of 0 or less draws hairlines;
+// miter_limit limits the length of LINE_JOIN_MITER joins, relative to the
+// thickness.
+func DrawPolyline(points []Point, join LineJoin, cap LineCap, color allegro.Color, thickness, miter_limit float32) {
+	if len(points) == 0 {
+		return
+	}
+	C.al_draw_polyline(
+		pointsPtr(points),
+		C.int(unsafe.Sizeof(Point{})),
+		C.int(len(points)),
+		C.int(join),
+		C.int(cap),
+		col(color),
+		C.float(thickness),
+		C.float(miter_limit))
+}
+
+// Draw an unfilled polygon. This is the same as passing LINE_CAP_CLOSED to
+// DrawPolyline().
+func DrawPolygon(points []Point, join LineJoin, color allegro.Color, thickness, miter_limit float32) {
+	if len(points) == 0 {
+		return
+	}
+	C.al_draw_polygon(
+		pointsPtr(points),
+		C.int(len(points)),
+		C.int(join),
+		col(color),
+		C.float(thickness),
+		C.float(miter_limit))
+}
+
+// Draw a filled, simple polygon. Simple means it does not have to be convex
+// but must not be self-overlapping. The vertices are expected in
+// counter-clockwise order.
+func DrawFilledPolygon(points []Point, color allegro.Color) {
+	if len(points) == 0 {
+		return
+	}
+	C.al_draw_filled_polygon(pointsPtr(points), C.int(len(points)), col(color))
+}
+
+// Draws a filled simple polygon with zero or more other simple polygons
+// subtracted from it - the holes. The first polygon in polygons is the outer
+// boundary and the rest are holes; holes are expected in clockwise order.
+func DrawFilledPolygonWithHoles(polygons [][]Point, color allegro.Color) {
+	vertices, counts := flattenPolygons(polygons)
+	if len(vertices) == 0 {
+		return
+	}
+	C.al_draw_filled_polygon_with_holes(pointsPtr(vertices), &counts[0], col(color))
+}
+
+// Divides a simple polygon into triangles, with zero or more other simple
+// polygons subtracted from it - the holes. The polygons are laid out as for
+// DrawFilledPolygonWithHoles(). The triangles are returned as indices into
+// the concatenation of the polygons, three per triangle, ready to be passed
+// to DrawIndexedPrim() with PRIM_TRIANGLE_LIST, so that the shape can be
+// triangulated once and drawn many times.
+func TriangulatePolygon(polygons [][]Point) ([]int, error) {
+	vertices, counts := flattenPolygons(polygons)
+	if len(vertices) == 0 {
+		return nil, nil
+	}
+	var list C.triangle_list
+	ok := C.triangulate_polygon(pointsPtr(vertices), &counts[0], &list)
+	defer C.free(unsafe.Pointer(list.indices))
+	if !bool(ok) {
+		return nil, errors.New("failed to triangulate polygon")
+	}
+	indices := make([]int, int(list.len))
+	for i, index := range unsafe.Slice(list.indices, int(list.len)) {
+		indices[i] = int(index)
+	}
+	return indices, nil
+}
+
+// flattenPolygons() concatenates the non-empty polygons and returns their
+// vertex counts, terminated by a zero.
+func flattenPolygons(polygons [][]Point) ([]Point, []C.int) {
+	var vertices []Point
+	counts := make([]C.int, 0, len(polygons)+1)
+	for _, polygon := range polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		vertices = append(vertices, polygon...)
+		counts = append(counts, C.int(len(polygon)))
+	}
+	return vertices, append(counts, 0)
+}
@@ -51,19 +51,6 @@ type VertexElement struct {
 	Attribute PrimAttr
 	Storage   PrimStorage
 	Offset    int
-
-	raw    C.ALLEGRO_VERTEX_ELEMENT
-	inited bool
-}
-
-func (v VertexElement) init() {
-	if v.inited {
-		return
-	}
-	v.raw.attribute = C.int(v.Attribute)
-	v.raw.storage = C.int(v.Storage)
-	v.raw.offset = C.int(v.Offset)
-	v.inited = true
 }
 
 type VertexDecl C.ALLEGRO_VERTEX_DECL
@@ -87,14 +74,26 @@ const (
 	PRIM_COLOR_ATTR               = C.ALLEGRO_PRIM_COLOR_ATTR
 	PRIM_TEX_COORD                = C.ALLEGRO_PRIM_TEX_COORD
 	PRIM_TEX_COORD_PIXEL          = C.ALLEGRO_PRIM_TEX_COORD_PIXEL
+	PRIM_USER_ATTR                = C.ALLEGRO_PRIM_USER_ATTR
 )
 
 type PrimStorage int
 
 const (
-	PRIM_FLOAT_2 PrimStorage = C.ALLEGRO_PRIM_FLOAT_2
-	PRIM_FLOAT_3             = C.ALLEGRO_PRIM_FLOAT_3
-	PRIM_SHORT_2             = C.ALLEGRO_PRIM_SHORT_2
+	PRIM_FLOAT_2             PrimStorage = C.ALLEGRO_PRIM_FLOAT_2
+	PRIM_FLOAT_3                         = C.ALLEGRO_PRIM_FLOAT_3
+	PRIM_SHORT_2                         = C.ALLEGRO_PRIM_SHORT_2
+	PRIM_FLOAT_1                         = C.ALLEGRO_PRIM_FLOAT_1
+	PRIM_FLOAT_4                         = C.ALLEGRO_PRIM_FLOAT_4
+	PRIM_UBYTE_4                         = C.ALLEGRO_PRIM_UBYTE_4
+	PRIM_SHORT_4                         = C.ALLEGRO_PRIM_SHORT_4
+	PRIM_NORMALIZED_UBYTE_4              = C.ALLEGRO_PRIM_NORMALIZED_UBYTE_4
+	PRIM_NORMALIZED_SHORT_2              = C.ALLEGRO_PRIM_NORMALIZED_SHORT_2
+	PRIM_NORMALIZED_SHORT_4              = C.ALLEGRO_PRIM_NORMALIZED_SHORT_4
+	PRIM_NORMALIZED_USHORT_2             = C.ALLEGRO_PRIM_NORMALIZED_USHORT_2
+	PRIM_NORMALIZED_USHORT_4             = C.ALLEGRO_PRIM_NORMALIZED_USHORT_4
+	PRIM_HALF_FLOAT_2                    = C.ALLEGRO_PRIM_HALF_FLOAT_2
+	PRIM_HALF_FLOAT_4                    = C.ALLEGRO_PRIM_HALF_FLOAT_4
 )
 
 // Initializes the primitives addon.
@@ -112,6 +111,14 @@ func Uninstall() {
 	C.al_shutdown_primitives_addon()
 }
 
+// Addon installs the primitives addon through allegro.Init().
+var Addon = allegro.Addon{Name: "primitives", Install: Install, Uninstall: Uninstall, Installed: IsInstalled}
+
+// Returns true if the primitives addon is initialized, otherwise returns false.
+func IsInstalled() bool {
+	return bool(C.al_is_primitives_addon_initialized())
+}
+
 // Returns the (compiled) version of the addon, in the same format as
 // al_get_allegro_version.
 func Version() (major, minor, revision, release uint8) {
@@ -425,6 +432,9 @@ func DrawRibbon(points []Point, color allegro.Color, thickness float32, num_segm
 
 // Draws a subset of the passed vertex buffer.
 func DrawPrim(vertices []Vertex, decl *VertexDecl, texture *allegro.Bitmap, start, end int, prim_type PrimType) int {
+	if len(vertices) == 0 {
+		return 0
+	}
 	vertices_ := make([]C.ALLEGRO_VERTEX, len(vertices))
 	for i, vertex := range vertices {
 		// how does this perform?
@@ -441,8 +451,15 @@ func DrawPrim(vertices []Vertex, decl *VertexDecl, texture *allegro.Bitmap, star
 }
 
 // Draws a subset of the passed vertex buffer. This function uses an index
-// array to specify which vertices to use.
+// array to specify which vertices to use; num_vertices is the number of
+// indices to use, and is limited to len(indices).
 func DrawIndexedPrim(vertices []Vertex, decl *VertexDecl, texture *allegro.Bitmap, indices []int, num_vertices int, prim_type PrimType) int {
+	if len(vertices) == 0 || len(indices) == 0 {
+		return 0
+	}
+	if num_vertices > len(indices) {
+		num_vertices = len(indices)
+	}
 	vertices_ := make([]C.ALLEGRO_VERTEX, len(vertices))
 	for i, vertex := range vertices {
 		// how does this perform?
@@ -463,12 +480,15 @@ func DrawIndexedPrim(vertices []Vertex, decl *VertexDecl, texture *allegro.Bitma
 }
 
 // Creates a vertex declaration, which describes a custom vertex format.
+// PRIM_USER_ATTR+n is passed to shaders as SHADER_VAR_USER_ATTR followed by
+// n, e.g. "al_user_attr_0".
 func CreateVertexDecl(elements []VertexElement, stride int) *VertexDecl {
-	elements_ := make([]C.ALLEGRO_VERTEX_ELEMENT, len(elements))
+	// The zero element terminates the list.
+	elements_ := make([]C.ALLEGRO_VERTEX_ELEMENT, len(elements)+1)
 	for i, element := range elements {
-		// how does this perform?
-		element.init()
-		elements_[i] = element.raw
+		elements_[i].attribute = C.int(element.Attribute)
+		elements_[i].storage = C.int(element.Storage)
+		elements_[i].offset = C.int(element.Offset)
 	}
 	return (*VertexDecl)(C.al_create_vertex_decl((*C.ALLEGRO_VERTEX_ELEMENT)(unsafe.Pointer(&elements_[0])), C.int(stride)))
 }
@@ -0,0 +1,41 @@
+package primitives
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Indices into the tints of DrawTintedQuad() and DrawGradientRectangle().
+const (
+	TOP_LEFT = iota
+	TOP_RIGHT
+	BOTTOM_RIGHT
+	BOTTOM_LEFT
+)
+
+// appendQuad() appends the two triangles of a textured quad, whose corners are
+// tinted with tints in TOP_LEFT, TOP_RIGHT, BOTTOM_RIGHT, BOTTOM_LEFT order.
+func appendQuad(vertices []Vertex, tints [4]allegro.Color, sx, sy, sw, sh, dx, dy, dw, dh float32, flags allegro.DrawFlags) []Vertex {
+	u0, v0, u1, v1 := uvs(sx, sy, sw, sh, flags)
+	tl := Vertex{X: dx, Y: dy, Color: tints[TOP_LEFT], U: u0, V: v0}
+	tr := Vertex{X: dx + dw, Y: dy, Color: tints[TOP_RIGHT], U: u1, V: v0}
+	br := Vertex{X: dx + dw, Y: dy + dh, Color: tints[BOTTOM_RIGHT], U: u1, V: v1}
+	bl := Vertex{X: dx, Y: dy + dh, Color: tints[BOTTOM_LEFT], U: u0, V: v1}
+	return append(vertices, tl, tr, br, tl, br, bl)
+}
+
+// DrawTintedQuad() draws the region (sx, sy, sw, sh) of a bitmap scaled to
+// (dx, dy, dw, dh), like DrawTintedScaled(), but with a tint per corner that
+// is interpolated across the quad. This gives gradient fades and simple
+// lighting on sprites. The tints are indexed by TOP_LEFT, TOP_RIGHT,
+// BOTTOM_RIGHT and BOTTOM_LEFT.
+func DrawTintedQuad(bmp *allegro.Bitmap, tints [4]allegro.Color, sx, sy, sw, sh, dx, dy, dw, dh float32, flags allegro.DrawFlags) {
+	vertices := appendQuad(make([]Vertex, 0, 6), tints, sx, sy, sw, sh, dx, dy, dw, dh, flags)
+	DrawPrim(vertices, nil, bmp, 0, len(vertices), PRIM_TRIANGLE_LIST)
+}
+
+// DrawGradientRectangle() fills the rectangle between p1 and p2 with colors
+// interpolated between its corners, indexed like DrawTintedQuad()'s tints.
+func DrawGradientRectangle(p1, p2 Point, colors [4]allegro.Color) {
+	vertices := appendQuad(make([]Vertex, 0, 6), colors, 0, 0, 0, 0, p1.X, p1.Y, p2.X-p1.X, p2.Y-p1.Y, 0)
+	DrawPrim(vertices, nil, nil, 0, len(vertices), PRIM_TRIANGLE_LIST)
+}
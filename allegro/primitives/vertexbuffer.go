@@ -0,0 +1,173 @@
+package primitives
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_primitives.h>
+import "C"
+import (
+	"errors"
+	"unsafe"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// VertexBuffer is a buffer of vertices stored in video memory.
+type VertexBuffer C.ALLEGRO_VERTEX_BUFFER
+
+type PrimBufferFlags int
+
+const (
+	PRIM_BUFFER_STREAM    PrimBufferFlags = C.ALLEGRO_PRIM_BUFFER_STREAM
+	PRIM_BUFFER_STATIC                    = C.ALLEGRO_PRIM_BUFFER_STATIC
+	PRIM_BUFFER_DYNAMIC                   = C.ALLEGRO_PRIM_BUFFER_DYNAMIC
+	PRIM_BUFFER_READWRITE                 = C.ALLEGRO_PRIM_BUFFER_READWRITE
+)
+
+func rawVertices(vertices []Vertex) []C.ALLEGRO_VERTEX {
+	raw := make([]C.ALLEGRO_VERTEX, len(vertices))
+	for i, vertex := range vertices {
+		vertex.init()
+		raw[i] = vertex.raw
+	}
+	return raw
+}
+
+// Creates a vertex buffer. If vertices is not empty, it is used to initialize
+// the buffer; otherwise the buffer's contents are undefined. Only the default
+// vertex format is supported here, so decl should be nil.
+func CreateVertexBuffer(decl *VertexDecl, vertices []Vertex, numVertices int, flags PrimBufferFlags) (*VertexBuffer, error) {
+	var initial unsafe.Pointer
+	if len(vertices) > 0 {
+		raw := rawVertices(vertices)
+		initial = unsafe.Pointer(&raw[0])
+	}
+	vb := C.al_create_vertex_buffer((*C.ALLEGRO_VERTEX_DECL)(decl), initial, C.int(numVertices), C.int(flags))
+	if vb == nil {
+		return nil, errors.New("failed to create vertex buffer")
+	}
+	return (*VertexBuffer)(vb), nil
+}
+
+// Destroys a vertex buffer.
+func (vb *VertexBuffer) Destroy() {
+	C.al_destroy_vertex_buffer((*C.ALLEGRO_VERTEX_BUFFER)(vb))
+}
+
+// Returns the size of the vertex buffer, in vertices.
+func (vb *VertexBuffer) Size() int {
+	return int(C.al_get_vertex_buffer_size((*C.ALLEGRO_VERTEX_BUFFER)(vb)))
+}
+
+// Locks a vertex buffer so you can access its data. Returns nil if the buffer
+// could not be locked.
+func (vb *VertexBuffer) Lock(offset, length int, flags allegro.LockFlags) unsafe.Pointer {
+	return C.al_lock_vertex_buffer((*C.ALLEGRO_VERTEX_BUFFER)(vb), C.int(offset), C.int(length), C.int(flags))
+}
+
+// Unlocks a previously locked vertex buffer.
+func (vb *VertexBuffer) Unlock() {
+	C.al_unlock_vertex_buffer((*C.ALLEGRO_VERTEX_BUFFER)(vb))
+}
+
+// Write() copies vertices into the buffer starting at offset, locking only
+// the range being written.
+func (vb *VertexBuffer) Write(offset int, vertices []Vertex) error {
+	if len(vertices) == 0 {
+		return nil
+	}
+	p := vb.Lock(offset, len(vertices), allegro.LOCK_WRITEONLY)
+	if p == nil {
+		return errors.New("failed to lock vertex buffer")
+	}
+	dst := unsafe.Slice((*C.ALLEGRO_VERTEX)(p), len(vertices))
+	for i, vertex := range vertices {
+		vertex.init()
+		dst[i] = vertex.raw
+	}
+	vb.Unlock()
+	return nil
+}
+
+// Draws a subset of the passed vertex buffer.
+func DrawVertexBuffer(vb *VertexBuffer, texture *allegro.Bitmap, start, end int, prim_type PrimType) int {
+	return int(C.al_draw_vertex_buffer((*C.ALLEGRO_VERTEX_BUFFER)(vb),
+		(*C.ALLEGRO_BITMAP)(texture),
+		C.int(start),
+		C.int(end),
+		C.int(prim_type)))
+}
+
+// StreamingVertexBuffer is a vertex buffer for geometry that changes every
+// frame. Each Write() appends to the part of the buffer that hasn't been
+// written to yet, so the GPU never has to wait for vertices it may still be
+// drawing from ("no-overwrite"). When the buffer is full it is replaced by a
+// fresh one rather than overwritten ("discard"), and writing starts again
+// from the beginning.
+type StreamingVertexBuffer struct {
+	vb     *VertexBuffer
+	size   int
+	cursor int
+}
+
+// NewStreamingVertexBuffer() creates a streaming buffer with room for the
+// given number of vertices. Writes larger than the buffer grow it.
+func NewStreamingVertexBuffer(size int) (*StreamingVertexBuffer, error) {
+	vb, err := CreateVertexBuffer(nil, nil, size, PRIM_BUFFER_STREAM)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamingVertexBuffer{vb: vb, size: size}, nil
+}
+
+// Destroy() frees the underlying vertex buffer.
+func (s *StreamingVertexBuffer) Destroy() {
+	if s.vb != nil {
+		s.vb.Destroy()
+		s.vb = nil
+	}
+}
+
+// discard() replaces the vertex buffer with a new one of at least the given
+// size.
+func (s *StreamingVertexBuffer) discard(size int) error {
+	if size < s.size {
+		size = s.size
+	}
+	vb, err := CreateVertexBuffer(nil, nil, size, PRIM_BUFFER_STREAM)
+	if err != nil {
+		return err
+	}
+	s.vb.Destroy()
+	s.vb, s.size, s.cursor = vb, size, 0
+	return nil
+}
+
+// Write() stores vertices in the buffer and returns the index of the first
+// one, for passing to Draw(). Vertices written earlier remain valid until the
+// buffer fills up, at which point it is discarded.
+func (s *StreamingVertexBuffer) Write(vertices []Vertex) (int, error) {
+	if s.cursor+len(vertices) > s.size {
+		if err := s.discard(len(vertices)); err != nil {
+			return 0, err
+		}
+	}
+	start := s.cursor
+	if err := s.vb.Write(start, vertices); err != nil {
+		return 0, err
+	}
+	s.cursor += len(vertices)
+	return start, nil
+}
+
+// Draw() draws n vertices starting at the index returned by Write().
+func (s *StreamingVertexBuffer) Draw(texture *allegro.Bitmap, start, n int, prim_type PrimType) int {
+	return DrawVertexBuffer(s.vb, texture, start, start+n, prim_type)
+}
+
+// WriteAndDraw() writes the vertices and draws them immediately.
+func (s *StreamingVertexBuffer) WriteAndDraw(vertices []Vertex, texture *allegro.Bitmap, prim_type PrimType) (int, error) {
+	start, err := s.Write(vertices)
+	if err != nil {
+		return 0, err
+	}
+	return s.Draw(texture, start, len(vertices), prim_type), nil
+}
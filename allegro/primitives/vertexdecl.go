@@ -0,0 +1,217 @@
+package primitives
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_primitives.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+var colorType = reflect.TypeOf(allegro.Color{})
+
+// VertexElements() describes the fields of a struct type for CreateVertexDecl().
+// sample is a value of, or pointer to, that type; fields are mapped to
+// attributes by their "prim" tag and untagged fields are skipped:
+//
+//	type Vertex struct {
+//		Pos    [3]float32    `prim:"position"`
+//		Color  allegro.Color `prim:"color"`
+//		UV     [2]float32    `prim:"tex_coord"`
+//		Normal [3]float32    `prim:"user_attr_0"`
+//		Bones  [4]uint8      `prim:"user_attr_1,normalized"`
+//	}
+//
+// The other attributes are "tex_coord_pixel" and "user_attr_0" through
+// "user_attr_9". The storage is derived from the field's type: float32 or an
+// array of 2 to 4 float32, [2]int16, [4]int16 or [4]uint8, or, with the
+// "normalized" option, [2]uint16 and [4]uint16 as well. A color must be an
+// allegro.Color. It returns the elements and the stride, the size of the type.
+func VertexElements(sample interface{}) ([]VertexElement, int, error) {
+	t := reflect.TypeOf(sample)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, 0, fmt.Errorf("vertex type %v is not a struct", t)
+	}
+
+	var elements []VertexElement
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("prim")
+		if !ok || tag == "-" {
+			continue
+		}
+		name, opt := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, opt = tag[:comma], tag[comma+1:]
+		}
+		if opt != "" && opt != "normalized" {
+			return nil, 0, fmt.Errorf("field %s: unknown option %q", field.Name, opt)
+		}
+
+		attr, err := parsePrimAttr(name)
+		if err != nil {
+			return nil, 0, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		var storage PrimStorage
+		if attr == PRIM_COLOR_ATTR {
+			// Colors are always stored as an ALLEGRO_COLOR.
+			if field.Type != colorType {
+				return nil, 0, fmt.Errorf("field %s: color must be an allegro.Color", field.Name)
+			}
+		} else {
+			storage, err = primStorageOf(field.Type, opt == "normalized")
+			if err != nil {
+				return nil, 0, fmt.Errorf("field %s: %v", field.Name, err)
+			}
+		}
+		elements = append(elements, VertexElement{
+			Attribute: attr,
+			Storage:   storage,
+			Offset:    int(field.Offset),
+		})
+	}
+	if len(elements) == 0 {
+		return nil, 0, fmt.Errorf("vertex type %v has no prim tags", t)
+	}
+	return elements, int(t.Size()), nil
+}
+
+// VertexDeclOf() creates a vertex declaration for the struct type of sample,
+// as described by VertexElements(). Slices of that type can then be drawn
+// with DrawCustomPrim().
+func VertexDeclOf(sample interface{}) (*VertexDecl, error) {
+	elements, stride, err := VertexElements(sample)
+	if err != nil {
+		return nil, err
+	}
+	decl := CreateVertexDecl(elements, stride)
+	if decl == nil {
+		return nil, errors.New("failed to create vertex declaration")
+	}
+	return decl, nil
+}
+
+func parsePrimAttr(name string) (PrimAttr, error) {
+	switch name {
+	case "position":
+		return PRIM_POSITION, nil
+	case "color":
+		return PRIM_COLOR_ATTR, nil
+	case "tex_coord":
+		return PRIM_TEX_COORD, nil
+	case "tex_coord_pixel":
+		return PRIM_TEX_COORD_PIXEL, nil
+	}
+	if strings.HasPrefix(name, "user_attr_") {
+		n, err := strconv.Atoi(name[len("user_attr_"):])
+		if err == nil && n >= 0 && n < 10 {
+			return PRIM_USER_ATTR + PrimAttr(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown attribute %q", name)
+}
+
+func primStorageOf(t reflect.Type, normalized bool) (PrimStorage, error) {
+	if t.Kind() == reflect.Float32 && !normalized {
+		return PRIM_FLOAT_1, nil
+	}
+	if t.Kind() == reflect.Array {
+		switch elem, n := t.Elem().Kind(), t.Len(); {
+		case elem == reflect.Float32 && !normalized && n == 2:
+			return PRIM_FLOAT_2, nil
+		case elem == reflect.Float32 && !normalized && n == 3:
+			return PRIM_FLOAT_3, nil
+		case elem == reflect.Float32 && !normalized && n == 4:
+			return PRIM_FLOAT_4, nil
+		case elem == reflect.Int16 && n == 2 && normalized:
+			return PRIM_NORMALIZED_SHORT_2, nil
+		case elem == reflect.Int16 && n == 2:
+			return PRIM_SHORT_2, nil
+		case elem == reflect.Int16 && n == 4 && normalized:
+			return PRIM_NORMALIZED_SHORT_4, nil
+		case elem == reflect.Int16 && n == 4:
+			return PRIM_SHORT_4, nil
+		case elem == reflect.Uint16 && n == 2 && normalized:
+			return PRIM_NORMALIZED_USHORT_2, nil
+		case elem == reflect.Uint16 && n == 4 && normalized:
+			return PRIM_NORMALIZED_USHORT_4, nil
+		case elem == reflect.Uint8 && n == 4 && normalized:
+			return PRIM_NORMALIZED_UBYTE_4, nil
+		case elem == reflect.Uint8 && n == 4:
+			return PRIM_UBYTE_4, nil
+		}
+	}
+	if normalized {
+		return 0, fmt.Errorf("no normalized storage for %v", t)
+	}
+	return 0, fmt.Errorf("no storage for %v", t)
+}
+
+// vertexData() returns a pointer to the first element of a non-empty slice of
+// structs, which must not contain Go pointers since it is handed to C.
+func vertexData(vertices interface{}) (unsafe.Pointer, int, error) {
+	v := reflect.ValueOf(vertices)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return nil, 0, fmt.Errorf("vertices must be a slice of structs, not %T", vertices)
+	}
+	if v.Len() == 0 {
+		return nil, 0, errors.New("no vertices")
+	}
+	return unsafe.Pointer(v.Pointer()), v.Len(), nil
+}
+
+// DrawCustomPrim() is like DrawPrim(), but draws a slice of custom vertex
+// structs as laid out by decl, typically one created by VertexDeclOf() for
+// the same type. User attributes reach the shader as "al_user_attr_0" and so
+// on (see SHADER_VAR_USER_ATTR). It returns the number of primitives drawn.
+func DrawCustomPrim(vertices interface{}, decl *VertexDecl, texture *allegro.Bitmap, start, end int, prim_type PrimType) (int, error) {
+	data, n, err := vertexData(vertices)
+	if err != nil {
+		return 0, err
+	}
+	if start < 0 || end > n || start > end {
+		return 0, fmt.Errorf("vertex range [%d, %d) out of bounds for %d vertices", start, end, n)
+	}
+	drawn := C.al_draw_prim(data,
+		(*C.ALLEGRO_VERTEX_DECL)(decl),
+		(*C.ALLEGRO_BITMAP)(texture),
+		C.int(start),
+		C.int(end),
+		C.int(prim_type))
+	return int(drawn), nil
+}
+
+// DrawIndexedCustomPrim() is like DrawCustomPrim(), but uses indices to
+// specify which vertices to draw.
+func DrawIndexedCustomPrim(vertices interface{}, decl *VertexDecl, texture *allegro.Bitmap, indices []int, prim_type PrimType) (int, error) {
+	data, n, err := vertexData(vertices)
+	if err != nil {
+		return 0, err
+	}
+	if len(indices) == 0 {
+		return 0, nil
+	}
+	indices_ := make([]C.int, len(indices))
+	for i, index := range indices {
+		if index < 0 || index >= n {
+			return 0, fmt.Errorf("vertex index %d out of bounds for %d vertices", index, n)
+		}
+		indices_[i] = C.int(index)
+	}
+	drawn := C.al_draw_indexed_prim(data,
+		(*C.ALLEGRO_VERTEX_DECL)(decl),
+		(*C.ALLEGRO_BITMAP)(texture),
+		&indices_[0],
+		C.int(len(indices)),
+		C.int(prim_type))
+	return int(drawn), nil
+}
@@ -0,0 +1,97 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+
+// Allegro only provides a setter for render states, so there is no way to
+// query them; code that changes a state should set it back when it's done.
+// The display must have been created with a DEPTH_SIZE option for depth
+// testing to do anything.
+
+type RenderState int
+
+const (
+	RENDER_ALPHA_TEST       RenderState = C.ALLEGRO_ALPHA_TEST
+	RENDER_WRITE_MASK                   = C.ALLEGRO_WRITE_MASK
+	RENDER_DEPTH_TEST                   = C.ALLEGRO_DEPTH_TEST
+	RENDER_DEPTH_FUNCTION               = C.ALLEGRO_DEPTH_FUNCTION
+	RENDER_ALPHA_FUNCTION               = C.ALLEGRO_ALPHA_FUNCTION
+	RENDER_ALPHA_TEST_VALUE             = C.ALLEGRO_ALPHA_TEST_VALUE
+)
+
+type RenderFunction int
+
+const (
+	RENDER_NEVER         RenderFunction = C.ALLEGRO_RENDER_NEVER
+	RENDER_ALWAYS                       = C.ALLEGRO_RENDER_ALWAYS
+	RENDER_LESS                         = C.ALLEGRO_RENDER_LESS
+	RENDER_EQUAL                        = C.ALLEGRO_RENDER_EQUAL
+	RENDER_LESS_EQUAL                   = C.ALLEGRO_RENDER_LESS_EQUAL
+	RENDER_GREATER                      = C.ALLEGRO_RENDER_GREATER
+	RENDER_NOT_EQUAL                    = C.ALLEGRO_RENDER_NOT_EQUAL
+	RENDER_GREATER_EQUAL                = C.ALLEGRO_RENDER_GREATER_EQUAL
+)
+
+type WriteMask int
+
+const (
+	MASK_RED   WriteMask = C.ALLEGRO_MASK_RED
+	MASK_GREEN           = C.ALLEGRO_MASK_GREEN
+	MASK_BLUE            = C.ALLEGRO_MASK_BLUE
+	MASK_ALPHA           = C.ALLEGRO_MASK_ALPHA
+	MASK_DEPTH           = C.ALLEGRO_MASK_DEPTH
+	MASK_RGB             = C.ALLEGRO_MASK_RGB
+	MASK_RGBA            = C.ALLEGRO_MASK_RGBA
+)
+
+// Set one of several render attributes. This function does nothing if the
+// target bitmap is a memory bitmap.
+func SetRenderState(state RenderState, value int) {
+	C.al_set_render_state(C.ALLEGRO_RENDER_STATE(state), C.int(value))
+}
+
+// Clear the depth buffer (confined by the clipping rectangle) to the given
+// value. A depth buffer is only available if it was requested with
+// al_set_new_display_option and the requirement could be met by the
+// al_create_display call creating the current display. Operations involving
+// the depth buffer are also affected by al_set_render_state.
+func ClearDepthBuffer(z float32) {
+	C.al_clear_depth_buffer(C.float(z))
+}
+
+// SetDepthTest() turns depth testing on or off. While it is on, pixels are
+// only drawn if their depth passes f against the depth buffer.
+func SetDepthTest(enabled bool, f RenderFunction) {
+	on := 0
+	if enabled {
+		on = 1
+	}
+	SetRenderState(RENDER_DEPTH_TEST, on)
+	SetRenderState(RENDER_DEPTH_FUNCTION, int(f))
+}
+
+// SetWriteMask() selects which of the color channels and the depth buffer are
+// written to when drawing.
+func SetWriteMask(mask WriteMask) {
+	SetRenderState(RENDER_WRITE_MASK, int(mask))
+}
+
+// SetAlphaTest() turns alpha testing on or off. While it is on, pixels are
+// only drawn if their alpha, scaled to 0-255, passes f against value.
+func SetAlphaTest(enabled bool, f RenderFunction, value int) {
+	on := 0
+	if enabled {
+		on = 1
+	}
+	SetRenderState(RENDER_ALPHA_TEST, on)
+	SetRenderState(RENDER_ALPHA_FUNCTION, int(f))
+	SetRenderState(RENDER_ALPHA_TEST_VALUE, value)
+}
+
+// ResetRenderState() restores Allegro's default render states: no alpha or
+// depth testing, and writes to all channels and the depth buffer.
+func ResetRenderState() {
+	SetAlphaTest(false, RENDER_ALWAYS, 0)
+	SetDepthTest(false, RENDER_LESS)
+	SetWriteMask(MASK_RGBA | MASK_DEPTH)
+}
@@ -0,0 +1,203 @@
+package allegro
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// Go finalizers can only be set on memory allocated by Go, and the wrapper
+// types are pointers to Allegro's own structures, so resources can't be
+// reclaimed automatically. Instead, the resources created through this
+// package are tracked from creation until they are destroyed: Close()
+// reports a second close as an error instead of handing freed memory back to
+// Allegro, Destroy() ignores it, and LiveResources() lists whatever hasn't
+// been destroyed yet, e.g. to report leaks at shutdown.
+
+// ErrAlreadyClosed is returned by Close() when the resource has already been
+// closed or destroyed.
+var ErrAlreadyClosed = errors.New("resource already closed")
+
+// How many destroyed resources are remembered for reporting a second close.
+// Older ones are forgotten, so a program that creates and destroys resources
+// all the time doesn't grow the tracker forever.
+const maxClosedResources = 4096
+
+type closedResource struct {
+	kind string
+	seq  uint64
+}
+
+type resourceTracker struct {
+	sync.Mutex
+	live   map[unsafe.Pointer]string
+	closed map[unsafe.Pointer]closedResource
+	// order lists closed pointers oldest first, together with the seq they
+	// were closed with; entries whose seq no longer matches are stale.
+	order []closedPointer
+	seq   uint64
+}
+
+type closedPointer struct {
+	p   unsafe.Pointer
+	seq uint64
+}
+
+var resources = resourceTracker{
+	live:   make(map[unsafe.Pointer]string),
+	closed: make(map[unsafe.Pointer]closedResource),
+}
+
+// trackResource() records that a resource of the given kind was created at p.
+// Allegro may reuse the memory of a destroyed resource, so this also forgets
+// that p was closed.
+func trackResource(p unsafe.Pointer, kind string) {
+	if p == nil {
+		return
+	}
+	resources.Lock()
+	resources.live[p] = kind
+	delete(resources.closed, p)
+	resources.Unlock()
+}
+
+// close() moves p from the live to the closed set, forgetting the oldest
+// closed resources once there are more than maxClosedResources. The caller
+// must hold the lock.
+func (t *resourceTracker) close(p unsafe.Pointer, kind string) {
+	delete(t.live, p)
+	t.seq++
+	t.closed[p] = closedResource{kind, t.seq}
+	t.order = append(t.order, closedPointer{p, t.seq})
+	for len(t.closed) > maxClosedResources || len(t.order) > 2*maxClosedResources {
+		old := t.order[0]
+		t.order = t.order[1:]
+		if c, ok := t.closed[old.p]; ok && c.seq == old.seq {
+			delete(t.closed, old.p)
+		}
+	}
+}
+
+// releaseResource() records that the resource at p is being destroyed. It
+// returns an error, and the resource must not be destroyed, if it already
+// was. Resources that were never tracked, such as those created by addons,
+// are always released.
+func releaseResource(p unsafe.Pointer, kind string) error {
+	if p == nil {
+		return nil
+	}
+	resources.Lock()
+	defer resources.Unlock()
+	if c, ok := resources.closed[p]; ok && c.kind == kind {
+		return fmt.Errorf("%s %p: %w", kind, p, ErrAlreadyClosed)
+	}
+	if _, ok := resources.live[p]; ok {
+		resources.close(p, kind)
+	}
+	return nil
+}
+
+// LiveResources() returns how many resources of each kind ("bitmap",
+// "display", "shader", "event queue", "timer", "config", "mouse cursor",
+// "path", "fs entry") have been created
+// and not yet destroyed. Call it before shutting down to find leaks.
+func LiveResources() map[string]int {
+	resources.Lock()
+	defer resources.Unlock()
+	counts := make(map[string]int)
+	for _, kind := range resources.live {
+		counts[kind]++
+	}
+	return counts
+}
+
+// Close() destroys the bitmap like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (bmp *Bitmap) Close() error {
+	if err := releaseResource(unsafe.Pointer(bmp), "bitmap"); err != nil {
+		return err
+	}
+	bmp.destroy()
+	return nil
+}
+
+// Close() destroys the display like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (d *Display) Close() error {
+	if err := releaseResource(unsafe.Pointer(d), "display"); err != nil {
+		return err
+	}
+	d.destroy()
+	return nil
+}
+
+// Close() destroys the shader like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (s *Shader) Close() error {
+	if err := releaseResource(unsafe.Pointer(s), "shader"); err != nil {
+		return err
+	}
+	s.destroy()
+	return nil
+}
+
+// Close() destroys the event queue like Destroy(), but returns
+// ErrAlreadyClosed instead of freeing it twice.
+func (queue *EventQueue) Close() error {
+	if err := releaseResource(unsafe.Pointer(queue), "event queue"); err != nil {
+		return err
+	}
+	queue.destroy()
+	return nil
+}
+
+// Close() destroys the timer like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (t *Timer) Close() error {
+	if err := releaseResource(unsafe.Pointer(t), "timer"); err != nil {
+		return err
+	}
+	t.destroy()
+	return nil
+}
+
+// Close() destroys the config like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (cfg *Config) Close() error {
+	if err := releaseResource(unsafe.Pointer(cfg), "config"); err != nil {
+		return err
+	}
+	cfg.destroy()
+	return nil
+}
+
+// Close() destroys the mouse cursor like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (cursor *MouseCursor) Close() error {
+	if err := releaseResource(unsafe.Pointer(cursor), "mouse cursor"); err != nil {
+		return err
+	}
+	cursor.destroy()
+	return nil
+}
+
+// Close() destroys the path like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (p *Path) Close() error {
+	if err := releaseResource(unsafe.Pointer(p), "path"); err != nil {
+		return err
+	}
+	p.destroy()
+	return nil
+}
+
+// Close() destroys the fs entry like Destroy(), but returns ErrAlreadyClosed
+// instead of freeing it twice.
+func (e *FSEntry) Close() error {
+	if err := releaseResource(unsafe.Pointer(e), "fs entry"); err != nil {
+		return err
+	}
+	e.destroy()
+	return nil
+}
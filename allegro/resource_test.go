@@ -0,0 +1,53 @@
+package allegro
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+func TestReleaseResourceTwice(t *testing.T) {
+	p := unsafe.Pointer(new(int))
+	trackResource(p, "timer")
+	if n := LiveResources()["timer"]; n != 1 {
+		t.Fatalf("live timers = %d, want 1", n)
+	}
+	if err := releaseResource(p, "timer"); err != nil {
+		t.Fatalf("first release: %v", err)
+	}
+	if err := releaseResource(p, "timer"); !errors.Is(err, ErrAlreadyClosed) {
+		t.Fatalf("second release = %v, want ErrAlreadyClosed", err)
+	}
+	if n := LiveResources()["timer"]; n != 0 {
+		t.Fatalf("live timers = %d, want 0", n)
+	}
+
+	// The memory of a destroyed resource may be handed out again.
+	trackResource(p, "timer")
+	if err := releaseResource(p, "timer"); err != nil {
+		t.Fatalf("release after reuse: %v", err)
+	}
+}
+
+func TestReleaseUntrackedResource(t *testing.T) {
+	p := unsafe.Pointer(new(int))
+	for i := 0; i < 2; i++ {
+		if err := releaseResource(p, "bitmap"); err != nil {
+			t.Fatalf("release %d: %v", i, err)
+		}
+	}
+}
+
+func TestClosedResourcesBounded(t *testing.T) {
+	for i := 0; i < 2*maxClosedResources; i++ {
+		p := unsafe.Pointer(new(int))
+		trackResource(p, "path")
+		releaseResource(p, "path")
+	}
+	resources.Lock()
+	n := len(resources.closed)
+	resources.Unlock()
+	if n > maxClosedResources {
+		t.Fatalf("%d closed resources remembered, want at most %d", n, maxClosedResources)
+	}
+}
@@ -0,0 +1,185 @@
+package allegro
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// RumbleStep is one segment of a rumble pattern: the strong (low frequency)
+// and weak (high frequency) motors are driven at the given magnitudes, between
+// 0.0 and 1.0, for Duration seconds. A step with both magnitudes at zero is a
+// pause.
+type RumbleStep struct {
+	Strong, Weak float64
+	Duration     float64
+}
+
+// RumblePattern is a sequence of rumble steps played back to back.
+type RumblePattern []RumbleStep
+
+// Duration() returns the total length of the pattern in seconds.
+func (p RumblePattern) Duration() float64 {
+	var d float64
+	for _, s := range p {
+		d += s.Duration
+	}
+	return d
+}
+
+// Scale() returns a copy of the pattern with every magnitude multiplied by f.
+func (p RumblePattern) Scale(f float64) RumblePattern {
+	q := make(RumblePattern, len(p))
+	for i, s := range p {
+		q[i] = RumbleStep{s.Strong * f, s.Weak * f, s.Duration}
+	}
+	return q
+}
+
+var (
+	RumbleLightTick   = RumblePattern{{0, 0.35, 0.04}}
+	RumbleMediumBump  = RumblePattern{{0.4, 0.5, 0.1}}
+	RumbleHeavyImpact = RumblePattern{{1, 0.8, 0.2}, {0.5, 0.3, 0.15}}
+	RumbleDoubleTap   = RumblePattern{{0, 0.5, 0.05}, {0, 0, 0.08}, {0, 0.5, 0.05}}
+	RumbleHeartbeat   = RumblePattern{{0.6, 0, 0.08}, {0, 0, 0.1}, {0.4, 0, 0.08}, {0, 0, 0.5}}
+	RumbleExplosion   = RumblePattern{{1, 1, 0.15}, {0.7, 0.5, 0.15}, {0.4, 0.2, 0.2}, {0.15, 0.05, 0.3}}
+)
+
+// RumblePresets maps preset names to the patterns above, so that effects can
+// be named in data files.
+var RumblePresets = map[string]RumblePattern{
+	"light tick":   RumbleLightTick,
+	"medium bump":  RumbleMediumBump,
+	"heavy impact": RumbleHeavyImpact,
+	"double tap":   RumbleDoubleTap,
+	"heartbeat":    RumbleHeartbeat,
+	"explosion":    RumbleExplosion,
+}
+
+// RumblePreset() looks up a pattern in RumblePresets, ignoring case.
+func RumblePreset(name string) (RumblePattern, bool) {
+	p, ok := RumblePresets[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+var ErrEmptyRumblePattern = errors.New("rumble pattern has no duration")
+
+// Rumbler plays rumble patterns on a haptic device. Each step is uploaded as
+// its own effect when it starts and released when it ends, so a pattern of any
+// length occupies at most one of the device's effect slots. Call Update()
+// once per frame to advance playback; starting a new pattern replaces the
+// current one.
+type Rumbler struct {
+	// Intensity scales every magnitude, e.g. for a user setting. It defaults
+	// to 1; magnitudes are clamped to 1 after scaling.
+	Intensity float64
+
+	haptic  *Haptic
+	pattern RumblePattern
+	repeat  int
+	step    int
+	left    float64
+	effect  *HapticEffectID
+}
+
+// NewRumbler() creates a rumbler for the haptic device h, which remains owned
+// by the caller.
+func NewRumbler(h *Haptic) *Rumbler {
+	return &Rumbler{Intensity: 1, haptic: h}
+}
+
+// Play() plays the pattern once.
+func (r *Rumbler) Play(p RumblePattern) error {
+	return r.PlayRepeat(p, 0)
+}
+
+// PlayRepeat() plays the pattern and then repeats it n more times, or
+// indefinitely if n is negative. An error is returned if the first step could
+// not be uploaded; failures on later steps skip the step.
+func (r *Rumbler) PlayRepeat(p RumblePattern, n int) error {
+	r.Stop()
+	if p.Duration() <= 0 {
+		return ErrEmptyRumblePattern
+	}
+	r.pattern = p
+	r.repeat = n
+	r.step = 0
+	r.left = p[0].Duration
+	return r.start()
+}
+
+// PlayPreset() plays the named preset once.
+func (r *Rumbler) PlayPreset(name string) error {
+	p, ok := RumblePreset(name)
+	if !ok {
+		return errors.New("unknown rumble preset '" + name + "'")
+	}
+	return r.Play(p)
+}
+
+// Playing() reports whether a pattern is in progress.
+func (r *Rumbler) Playing() bool {
+	return r.pattern != nil
+}
+
+// Stop() stops the current pattern and releases its effect.
+func (r *Rumbler) Stop() {
+	r.release()
+	r.pattern = nil
+}
+
+// Update() advances playback by dt seconds, moving on to the next step, and
+// uploading its effect, whenever the current one has run its course.
+func (r *Rumbler) Update(dt float64) {
+	if r.pattern == nil {
+		return
+	}
+	r.left -= dt
+	for r.left <= 0 {
+		r.release()
+		r.step++
+		if r.step == len(r.pattern) {
+			if r.repeat == 0 {
+				r.pattern = nil
+				return
+			}
+			if r.repeat > 0 {
+				r.repeat--
+			}
+			r.step = 0
+		}
+		r.left += r.pattern[r.step].Duration
+		if r.left > 0 {
+			r.start()
+		}
+	}
+}
+
+// start() uploads and plays the effect for the current step. Pauses don't use
+// an effect.
+func (r *Rumbler) start() error {
+	s := r.pattern[r.step]
+	strong := math.Min(1, s.Strong*r.Intensity)
+	weak := math.Min(1, s.Weak*r.Intensity)
+	if strong <= 0 && weak <= 0 {
+		return nil
+	}
+	id, err := r.haptic.UploadRumble(strong, weak, 0, r.left)
+	if err != nil {
+		return err
+	}
+	if err := id.Play(1); err != nil {
+		id.Release()
+		return err
+	}
+	r.effect = id
+	return nil
+}
+
+func (r *Rumbler) release() {
+	if r.effect != nil {
+		r.effect.Stop()
+		r.effect.Release()
+		r.effect = nil
+	}
+}
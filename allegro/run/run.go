@@ -0,0 +1,274 @@
+// Package run provides an optional game loop, so that programs don't have to
+// set up the display, event queue and frame timer by hand:
+//
+//	type game struct{ x float32 }
+//
+//	func (g *game) Setup(l *run.Loop) error    { return nil }
+//	func (g *game) Update(dt float64)          { g.x += float32(100 * dt) }
+//	func (g *game) HandleEvent(ev interface{}) {}
+//	func (g *game) Render() {
+//		allegro.ClearToColor(allegro.MapRGB(0, 0, 0))
+//		primitives.DrawFilledCircle(primitives.Point{g.x, 240}, 16, allegro.MapRGB(255, 255, 255))
+//	}
+//
+//	func main() {
+//		if err := run.Main(&game{}, run.Config{Title: "Demo"}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The loop uses a fixed timestep: Update() is called once per tick of a
+// timer running at Config.FPS, always with the same dt, and Render() is
+// called whenever the loop has caught up with the timer. A slow frame
+// therefore causes several updates in a row rather than a larger dt.
+package run
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/states"
+	"github.com/ccollins476ad/go-allegro/allegro/tween"
+)
+
+// Game is implemented by the game driven by a Loop.
+type Game interface {
+	// Setup() is called once the display and event queue exist, before the
+	// first update. Returning an error stops the loop.
+	Setup(l *Loop) error
+
+	// Update() advances the game by dt seconds, which is always 1/FPS.
+	Update(dt float64)
+
+	// Render() draws the game to the display's backbuffer. The loop flips
+	// the display afterwards.
+	Render()
+
+	// HandleEvent() is passed every event from the loop's queue, including
+	// the timer's.
+	HandleEvent(ev interface{})
+}
+
+// Shutdowner can be implemented by games that need to release resources
+// before the display is destroyed.
+type Shutdowner interface {
+	Shutdown()
+}
+
+// Config describes the display and timing of a Loop. Zero fields are given
+// the defaults below.
+type Config struct {
+	Width, Height int                  // 640x480
+	Title         string               // no title
+	DisplayFlags  allegro.DisplayFlags // WINDOWED
+	FPS           float64              // 60
+
+	// MaxFrameSkip is the number of updates that may run in a row before a
+	// frame is rendered anyway, so that a game that can't keep up still
+	// shows something. Defaults to 5.
+	MaxFrameSkip int
+
+	// Keyboard and Mouse install and register the devices.
+	Keyboard, Mouse bool
+}
+
+func (c *Config) setDefaults() {
+	if c.Width <= 0 {
+		c.Width = 640
+	}
+	if c.Height <= 0 {
+		c.Height = 480
+	}
+	if c.DisplayFlags == 0 {
+		c.DisplayFlags = allegro.WINDOWED
+	}
+	if c.FPS <= 0 {
+		c.FPS = 60
+	}
+	if c.MaxFrameSkip <= 0 {
+		c.MaxFrameSkip = 5
+	}
+}
+
+// Loop owns the display, event queue and timer of a running game.
+type Loop struct {
+	Config Config
+
+	// Tweens are advanced before every Game.Update().
+	Tweens tween.Group
+
+	display *allegro.Display
+	queue   *allegro.EventQueue
+	timer   *allegro.Timer
+	quit    bool
+}
+
+// Main() runs the game through allegro.Run(), which initializes Allegro and
+// makes it work on every platform. Use Run() when Allegro is already
+// initialized.
+func Main(game Game, config Config) error {
+	var err error
+	allegro.Run(func() {
+		err = Run(game, config)
+	})
+	return err
+}
+
+// Run() creates the display, queue and timer described by config, runs the
+// game until Quit() is called or the display is closed, and destroys them
+// again.
+func Run(game Game, config Config) error {
+	if game == nil {
+		return errors.New("no game to run")
+	}
+	l := &Loop{Config: config}
+	l.Config.setDefaults()
+	if err := l.init(); err != nil {
+		l.destroy()
+		return err
+	}
+	defer l.destroy()
+	if err := game.Setup(l); err != nil {
+		return err
+	}
+	if s, ok := game.(Shutdowner); ok {
+		defer s.Shutdown()
+	}
+	l.run(game)
+	return nil
+}
+
+func (l *Loop) init() error {
+	c := &l.Config
+	if c.Keyboard {
+		if err := allegro.InstallKeyboard(); err != nil {
+			return err
+		}
+	}
+	if c.Mouse {
+		if err := allegro.InstallMouse(); err != nil {
+			return err
+		}
+	}
+
+	allegro.SetNewDisplayFlags(c.DisplayFlags)
+	if c.Title != "" {
+		allegro.SetNewWindowTitle(c.Title)
+	}
+	var err error
+	if l.display, err = allegro.CreateDisplay(c.Width, c.Height); err != nil {
+		return err
+	}
+	if l.queue, err = allegro.CreateEventQueue(); err != nil {
+		return err
+	}
+	if l.timer, err = allegro.CreateTimer(1 / c.FPS); err != nil {
+		return err
+	}
+
+	l.queue.Register(l.display, l.timer)
+	if c.Keyboard {
+		source, err := allegro.KeyboardEventSource()
+		if err != nil {
+			return err
+		}
+		l.queue.RegisterEventSource(source)
+	}
+	if c.Mouse {
+		source, err := allegro.MouseEventSource()
+		if err != nil {
+			return err
+		}
+		l.queue.RegisterEventSource(source)
+	}
+	return nil
+}
+
+func (l *Loop) destroy() {
+	if l.timer != nil {
+		l.timer.Destroy()
+	}
+	if l.queue != nil {
+		l.queue.Destroy()
+	}
+	if l.display != nil {
+		l.display.Destroy()
+	}
+}
+
+func (l *Loop) run(game Game) {
+	dt := 1 / l.Config.FPS
+	redraw := false
+	skipped := 0
+
+	l.timer.Start()
+	defer l.timer.Stop()
+	for !l.quit {
+		ev := l.queue.Wait()
+		game.HandleEvent(ev)
+
+		switch e := ev.(type) {
+		case allegro.TimerEvent:
+			if e.Source() == l.timer {
+				l.Tweens.Update(dt)
+				game.Update(dt)
+				redraw = true
+				skipped++
+			}
+		case allegro.DisplayCloseEvent:
+			if e.Source() == l.display {
+				l.quit = true
+			}
+		case allegro.DisplayResizeEvent:
+			if e.Source() == l.display && l.Config.DisplayFlags&allegro.RESIZABLE != 0 {
+				l.display.AcknowledgeResize()
+			}
+		}
+
+		if redraw && !l.quit && (l.queue.IsEmpty() || skipped >= l.Config.MaxFrameSkip) {
+			game.Render()
+			allegro.FlipDisplay()
+			redraw = false
+			skipped = 0
+		}
+	}
+}
+
+// Display() returns the loop's display.
+func (l *Loop) Display() *allegro.Display {
+	return l.display
+}
+
+// Queue() returns the loop's event queue, so that other event sources can be
+// registered with it.
+func (l *Loop) Queue() *allegro.EventQueue {
+	return l.queue
+}
+
+// Timer() returns the timer that drives the updates.
+func (l *Loop) Timer() *allegro.Timer {
+	return l.timer
+}
+
+// Quit() stops the loop once the current event has been handled.
+func (l *Loop) Quit() {
+	l.quit = true
+}
+
+// Machine() adapts a state machine to the Game interface. setup, which may be
+// nil, is called as Game.Setup(), e.g. to push the initial state once the
+// display exists.
+func Machine(m *states.Machine, setup func(l *Loop) error) Game {
+	return &machineGame{m, setup}
+}
+
+type machineGame struct {
+	*states.Machine
+	setup func(l *Loop) error
+}
+
+func (g *machineGame) Setup(l *Loop) error {
+	if g.setup == nil {
+		return nil
+	}
+	return g.setup(l)
+}
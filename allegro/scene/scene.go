@@ -0,0 +1,226 @@
+// Package scene provides a simple layered renderer built on top of the core
+// bindings.
+package scene
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"sort"
+)
+
+// Drawable represents anything that can be rendered as part of a scene.
+type Drawable interface {
+	Draw()
+}
+
+// DrawFunc adapts an ordinary function into a Drawable.
+type DrawFunc func()
+
+func (f DrawFunc) Draw() {
+	f()
+}
+
+// Entry is a drawable's place in a layer, returned by Layer.Add(). Drawables
+// are identified by their entry rather than compared, so the same drawable can
+// be added more than once and uncomparable ones such as DrawFuncs work.
+type Entry struct {
+	drawable Drawable
+	layer    *Layer
+	z        int
+	seq      int
+}
+
+// Z() returns the entry's Z order within its layer.
+func (e *Entry) Z() int {
+	return e.z
+}
+
+// SetZ() changes the entry's Z order within its layer.
+func (e *Entry) SetZ(z int) {
+	e.z = z
+	if e.layer != nil {
+		e.layer.dirty = true
+	}
+}
+
+// Layer is a group of drawables that share a transform. Layers are rendered
+// in ascending Z order, and the drawables within a layer are rendered in
+// ascending order of their own Z value.
+type Layer struct {
+	// ParallaxX and ParallaxY scale the scene's camera offset before it is
+	// applied to this layer. A value of 1 moves the layer with the camera,
+	// 0 keeps it fixed on screen, and anything in between gives a parallax
+	// effect.
+	ParallaxX, ParallaxY float32
+
+	// Hidden layers are skipped during rendering.
+	Hidden bool
+
+	// NoHold disables deferred bitmap drawing for this layer. Use it for
+	// layers that change state (blenders, shaders, etc.) while drawing.
+	NoHold bool
+
+	scene   *Scene
+	z       int
+	entries []*Entry
+	seq     int
+	dirty   bool
+}
+
+// Z() returns the order in which the layer is rendered.
+func (l *Layer) Z() int {
+	return l.z
+}
+
+// SetZ() changes the order in which the layer is rendered.
+func (l *Layer) SetZ(z int) {
+	l.z = z
+	if l.scene != nil {
+		l.scene.dirty = true
+	}
+}
+
+// Add() registers a drawable with the layer at the given Z order and returns
+// its entry, which is needed to remove it or change its order. Drawables with
+// the same Z order are rendered in the order they were added.
+func (l *Layer) Add(d Drawable, z int) *Entry {
+	l.seq++
+	e := &Entry{drawable: d, layer: l, z: z, seq: l.seq}
+	l.entries = append(l.entries, e)
+	l.dirty = true
+	return e
+}
+
+// Remove() unregisters an entry from the layer. It does nothing if the entry
+// isn't in the layer.
+func (l *Layer) Remove(e *Entry) {
+	if e == nil || e.layer != l {
+		return
+	}
+	for i, x := range l.entries {
+		if x == e {
+			l.entries = append(l.entries[:i], l.entries[i+1:]...)
+			e.layer = nil
+			return
+		}
+	}
+}
+
+// Clear() removes all drawables from the layer.
+func (l *Layer) Clear() {
+	for _, e := range l.entries {
+		e.layer = nil
+	}
+	l.entries = l.entries[:0]
+}
+
+// Len() returns the number of drawables registered with the layer.
+func (l *Layer) Len() int {
+	return len(l.entries)
+}
+
+func (l *Layer) sort() {
+	if !l.dirty {
+		return
+	}
+	sort.Slice(l.entries, func(i, j int) bool {
+		a, b := l.entries[i], l.entries[j]
+		if a.z != b.z {
+			return a.z < b.z
+		}
+		return a.seq < b.seq
+	})
+	l.dirty = false
+}
+
+// Scene is an ordered collection of layers.
+type Scene struct {
+	// CameraX and CameraY are the scene's scroll offset, which is applied to
+	// each layer according to its parallax factors.
+	CameraX, CameraY float32
+
+	layers []*Layer
+	dirty  bool
+}
+
+// New() creates an empty scene.
+func New() *Scene {
+	return &Scene{}
+}
+
+// Layer() returns the layer with the given Z order, creating it if it doesn't
+// exist yet. New layers move fully with the camera.
+func (s *Scene) Layer(z int) *Layer {
+	for _, l := range s.layers {
+		if l.z == z {
+			return l
+		}
+	}
+	l := &Layer{scene: s, z: z, ParallaxX: 1, ParallaxY: 1}
+	s.layers = append(s.layers, l)
+	s.dirty = true
+	return l
+}
+
+// RemoveLayer() removes the layer with the given Z order, if any.
+func (s *Scene) RemoveLayer(z int) {
+	for i, l := range s.layers {
+		if l.z == z {
+			l.scene = nil
+			s.layers = append(s.layers[:i], s.layers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Layers() returns the scene's layers in rendering order.
+func (s *Scene) Layers() []*Layer {
+	s.sort()
+	return s.layers
+}
+
+func (s *Scene) sort() {
+	if !s.dirty {
+		return
+	}
+	sort.SliceStable(s.layers, func(i, j int) bool {
+		return s.layers[i].z < s.layers[j].z
+	})
+	s.dirty = false
+}
+
+// Render() draws every visible layer onto the target bitmap. Each layer is
+// drawn with the camera offset scaled by its parallax factors applied on top
+// of the current transform, and with bitmap drawing held so that consecutive
+// draws from the same parent bitmap are batched. The original transform is
+// restored afterwards.
+func (s *Scene) Render() {
+	s.sort()
+	cur := allegro.CurrentTransform()
+	if cur == nil {
+		return
+	}
+	base := cur.Copy()
+	defer allegro.UseTransform(base)
+
+	for _, l := range s.layers {
+		if l.Hidden || len(l.entries) == 0 {
+			continue
+		}
+		l.sort()
+
+		t := allegro.IdentityTransform()
+		t.Translate(-s.CameraX*l.ParallaxX, -s.CameraY*l.ParallaxY)
+		t.Compose(base)
+		allegro.UseTransform(t)
+
+		if !l.NoHold {
+			allegro.HoldBitmapDrawing(true)
+		}
+		for _, e := range l.entries {
+			e.drawable.Draw()
+		}
+		if !l.NoHold {
+			allegro.HoldBitmapDrawing(false)
+		}
+	}
+}
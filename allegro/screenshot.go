@@ -0,0 +1,318 @@
+package allegro
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+/*
+enum { SCREENSHOT_EVENT = ALLEGRO_GET_EVENT_TYPE('G', 'S', 'h', 't') };
+
+static bool emit_screenshot(ALLEGRO_EVENT_SOURCE *source, intptr_t id) {
+	ALLEGRO_EVENT event;
+	event.user.type = SCREENSHOT_EVENT;
+	event.user.data1 = id;
+	event.user.data2 = 0;
+	event.user.data3 = 0;
+	event.user.data4 = 0;
+	return al_emit_user_event(source, &event, NULL);
+}
+*/
+import "C"
+import (
+	"bufio"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	RegisterEventType(C.SCREENSHOT_EVENT, func(e *Event) interface{} {
+		return (*screenshot_event)(unsafe.Pointer(e))
+	})
+}
+
+// The outcome of each save is kept here and looked up by the id carried in the
+// event, since events can't hold Go pointers. Only the most recent results are
+// kept.
+const maxScreenshotResults = 32
+
+type screenshotResult struct {
+	path string
+	err  error
+}
+
+var (
+	screenshotResults   = make(map[uintptr]screenshotResult)
+	screenshotLastID    uintptr
+	screenshotResultsMu sync.Mutex
+)
+
+func storeScreenshotResult(r screenshotResult) uintptr {
+	screenshotResultsMu.Lock()
+	defer screenshotResultsMu.Unlock()
+	screenshotLastID++
+	screenshotResults[screenshotLastID] = r
+	delete(screenshotResults, screenshotLastID-maxScreenshotResults)
+	return screenshotLastID
+}
+
+// UserPicturesPath() returns the user's pictures directory. Allegro has no
+// standard path for it, so it is derived from USER_HOME_PATH, honouring
+// XDG_PICTURES_DIR from the environment or user-dirs.dirs on Unix desktops.
+func UserPicturesPath() (string, error) {
+	home, err := GetStandardPath(USER_HOME_PATH)
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		if dir := xdgPicturesDir(home); dir != "" {
+			return dir, nil
+		}
+	}
+	return filepath.Join(home, "Pictures"), nil
+}
+
+func xdgPicturesDir(home string) string {
+	expand := func(s string) string {
+		s = strings.Trim(strings.TrimSpace(s), `"`)
+		return strings.Replace(s, "$HOME", strings.TrimSuffix(home, string(filepath.Separator)), 1)
+	}
+	if dir := os.Getenv("XDG_PICTURES_DIR"); dir != "" {
+		return expand(dir)
+	}
+	config := os.Getenv("XDG_CONFIG_HOME")
+	if config == "" {
+		config = filepath.Join(home, ".config")
+	}
+	f, err := os.Open(filepath.Join(config, "user-dirs.dirs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v := strings.TrimPrefix(scanner.Text(), "XDG_PICTURES_DIR="); v != scanner.Text() {
+			return expand(v)
+		}
+	}
+	return ""
+}
+
+// Screenshotter saves the contents of a display when a hotkey is pressed.
+// Pressing the key only requests a screenshot; the backbuffer is read by
+// Snap(), which should be called once the frame has been drawn and before it
+// is flipped. Encoding and writing the file happens on a background goroutine,
+// after which a ScreenshotEvent is emitted through EventSource().
+type Screenshotter struct {
+	// The hotkey and the exact modifiers that must be held with it.
+	Key       KeyCode
+	Modifiers KeyModifier
+
+	// The directory to save to. If empty, UserPicturesPath() is used.
+	Dir string
+
+	// The file name prefix, followed by a timestamp. If empty, AppName() is
+	// used.
+	Prefix string
+
+	display   *Display
+	source    *C.ALLEGRO_EVENT_SOURCE
+	requested bool
+	wg        sync.WaitGroup
+}
+
+// NewScreenshotter() creates a screenshotter for the display, bound to F12.
+func NewScreenshotter(d *Display) *Screenshotter {
+	source := (*C.ALLEGRO_EVENT_SOURCE)(C.malloc(C.sizeof_ALLEGRO_EVENT_SOURCE))
+	C.al_init_user_event_source(source)
+	return &Screenshotter{
+		Key:     KEY_F12,
+		display: d,
+		source:  source,
+	}
+}
+
+// Destroy() waits for pending saves to finish and destroys the event source.
+func (s *Screenshotter) Destroy() {
+	s.wg.Wait()
+	forgetEventSource(s.EventSource())
+	C.al_destroy_user_event_source(s.source)
+	C.free(unsafe.Pointer(s.source))
+	s.source = nil
+}
+
+// EventSource() returns the source that emits a ScreenshotEvent whenever a
+// save completes.
+func (s *Screenshotter) EventSource() *EventSource {
+	return (*EventSource)(unsafe.Pointer(s.source))
+}
+
+// HandleEvent() requests a screenshot when the hotkey is pressed on the
+// screenshotter's display. Key repeats are ignored. It returns true if the
+// event was the hotkey.
+func (s *Screenshotter) HandleEvent(ev interface{}) bool {
+	e, ok := ev.(KeyCharEvent)
+	if !ok || e.Repeat() || e.KeyCode() != s.Key || e.Display() != s.display {
+		return false
+	}
+	const mask = KEYMOD_SHIFT | KEYMOD_CTRL | KEYMOD_ALT | KEYMOD_ALTGR | KEYMOD_COMMAND
+	if e.Modifiers()&mask != s.Modifiers {
+		return false
+	}
+	s.requested = true
+	return true
+}
+
+// Request() requests a screenshot as if the hotkey had been pressed.
+func (s *Screenshotter) Request() {
+	s.requested = true
+}
+
+// Snap() takes a screenshot if one has been requested since the last call.
+func (s *Screenshotter) Snap() error {
+	if !s.requested {
+		return nil
+	}
+	s.requested = false
+	return s.Capture()
+}
+
+// Capture() copies the display's backbuffer and saves it in the background.
+// It must be called from the thread that owns the display.
+func (s *Screenshotter) Capture() error {
+	img, err := s.grab()
+	if err != nil {
+		return err
+	}
+	dir, prefix := s.Dir, s.Prefix
+	if prefix == "" {
+		prefix = AppName()
+	}
+	name := prefix + "-" + time.Now().Format("20060102-150405.000") + ".png"
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		path, err := saveScreenshot(img, dir, name)
+		id := storeScreenshotResult(screenshotResult{path, err})
+		C.emit_screenshot(s.source, C.intptr_t(id))
+	}()
+	return nil
+}
+
+// Wait() blocks until all pending saves have completed.
+func (s *Screenshotter) Wait() {
+	s.wg.Wait()
+}
+
+// grab() copies the display's backbuffer into an image for saving.
+func (s *Screenshotter) grab() (*image.RGBA, error) {
+	return s.display.captureRGBA()
+}
+
+// Capture() copies the display's backbuffer into a new memory bitmap, which
+// stays valid if the display is lost or destroyed and can be saved from any
+// thread. Call it after drawing the frame and before FlipDisplay(), as the
+// backbuffer's contents are undefined after a flip. The display must be
+// current on the calling thread. The new bitmap flags are left unchanged.
+func (d *Display) Capture() (*Bitmap, error) {
+	oldFormat, oldFlags := NewBitmapFormat(), NewBitmapFlags()
+	defer func() {
+		SetNewBitmapFormat(oldFormat)
+		SetNewBitmapFlags(oldFlags)
+	}()
+	SetNewBitmapFormat(d.DisplayFormat())
+	SetNewBitmapFlags(oldFlags&^VIDEO_BITMAP | MEMORY_BITMAP)
+	bmp, err := d.Backbuffer().Clone()
+	if err != nil {
+		return nil, errors.New("failed to capture backbuffer")
+	}
+	return bmp, nil
+}
+
+// CaptureToImage() is like Capture(), but copies the backbuffer straight into
+// an opaque *image.RGBA, since the alpha channel of a backbuffer is rarely
+// meaningful.
+func (d *Display) CaptureToImage() (image.Image, error) {
+	return d.captureRGBA()
+}
+
+func (d *Display) captureRGBA() (*image.RGBA, error) {
+	bb := d.Backbuffer()
+	l, err := bb.LockImage(0, 0, bb.Width(), bb.Height(), LOCK_READONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Unlock()
+	img := image.NewRGBA(image.Rect(0, 0, l.Width, l.Height))
+	var src image.Image = l
+	if rgba, err := l.RGBA(); err == nil {
+		src = rgba
+	}
+	draw.Draw(img, img.Rect, src, image.Point{}, draw.Src)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img, nil
+}
+
+func saveScreenshot(img image.Image, dir, name string) (string, error) {
+	if dir == "" {
+		var err error
+		if dir, err = UserPicturesPath(); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return path, err
+	}
+	return path, f.Close()
+}
+
+/* -- Screenshot -- */
+
+type ScreenshotEvent interface {
+	screenshot()
+	Timestamp() float64
+	Path() string
+	Err() error
+}
+
+type screenshot_event C.struct_ALLEGRO_USER_EVENT
+
+func (e *screenshot_event) screenshot() {}
+
+func (e *screenshot_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *screenshot_event) result() screenshotResult {
+	screenshotResultsMu.Lock()
+	defer screenshotResultsMu.Unlock()
+	return screenshotResults[uintptr(e.data1)]
+}
+
+// Path() returns the file the screenshot was written to.
+func (e *screenshot_event) Path() string {
+	return e.result().path
+}
+
+// Err() returns the error that prevented the screenshot from being saved, if
+// any.
+func (e *screenshot_event) Err() error {
+	return e.result().err
+}
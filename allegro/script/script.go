@@ -0,0 +1,201 @@
+// Package script lets sequences such as cutscenes and tutorials be written as
+// straight-line Go code instead of state machines.
+//
+// Each script runs in its own goroutine, but only ever runs while the game
+// loop is inside Runner.Update(), and only one script runs at a time. Scripts
+// can therefore safely touch game state without any extra locking. A script
+// gives control back to the game loop by calling one of the Wait methods.
+package script
+
+import (
+	"errors"
+)
+
+// ErrStopped is the panic value used to unwind a script that has been stopped.
+// It is recovered automatically and never escapes the script's goroutine.
+var ErrStopped = errors.New("script stopped")
+
+// Runner drives a set of scripts from the game loop.
+type Runner struct {
+	scripts []*Script
+	time    float64
+
+	queued  []interface{}
+	events  []interface{}
+	raised  map[string]bool
+	signals map[string]bool
+}
+
+// NewRunner() creates a runner with no scripts.
+func NewRunner() *Runner {
+	return &Runner{
+		raised:  make(map[string]bool),
+		signals: make(map[string]bool),
+	}
+}
+
+// Time() returns the total time, in seconds, that has been passed to Update().
+func (r *Runner) Time() float64 {
+	return r.time
+}
+
+// Len() returns the number of scripts that have not yet finished.
+func (r *Runner) Len() int {
+	return len(r.scripts)
+}
+
+// Start() begins a new script. The function is first called during the next
+// call to Update().
+func (r *Runner) Start(f func(s *Script)) *Script {
+	s := &Script{
+		r:      r,
+		resume: make(chan struct{}),
+		yield:  make(chan struct{}),
+		wake:   func() bool { return true },
+	}
+	go s.run(f)
+	r.scripts = append(r.scripts, s)
+	return s
+}
+
+// HandleEvent() passes an event to any scripts waiting in WaitForEvent(). The
+// event is seen by scripts during the next call to Update().
+func (r *Runner) HandleEvent(ev interface{}) {
+	r.queued = append(r.queued, ev)
+}
+
+// Signal() raises a named signal, waking any scripts waiting for it during the
+// next call to Update().
+func (r *Runner) Signal(name string) {
+	r.raised[name] = true
+}
+
+// Update() advances time by dt seconds and runs every script that is ready to
+// continue until it waits again or finishes. It should be called once per
+// tick of the game loop.
+func (r *Runner) Update(dt float64) {
+	r.time += dt
+	r.events, r.queued = r.queued, r.events[:0]
+	r.signals, r.raised = r.raised, r.signals
+	for k := range r.raised {
+		delete(r.raised, k)
+	}
+
+	// Scripts started during this update are first run on the next one.
+	n := len(r.scripts)
+	for _, s := range r.scripts[:n] {
+		if !s.done && (s.stopped || s.wake()) {
+			s.resume <- struct{}{}
+			<-s.yield
+		}
+	}
+
+	live := r.scripts[:0]
+	for _, s := range r.scripts {
+		if !s.done {
+			live = append(live, s)
+		}
+	}
+	for i := len(live); i < len(r.scripts); i++ {
+		r.scripts[i] = nil
+	}
+	r.scripts = live
+}
+
+// StopAll() stops every script. Each is unwound during the next Update().
+func (r *Runner) StopAll() {
+	for _, s := range r.scripts {
+		s.Stop()
+	}
+}
+
+// Script is a single running sequence.
+type Script struct {
+	r       *Runner
+	resume  chan struct{}
+	yield   chan struct{}
+	wake    func() bool
+	done    bool
+	stopped bool
+}
+
+func (s *Script) run(f func(s *Script)) {
+	defer func() {
+		v := recover()
+		s.done = true
+		s.yield <- struct{}{}
+		if v != nil && v != ErrStopped {
+			panic(v)
+		}
+	}()
+	<-s.resume
+	if s.stopped {
+		panic(ErrStopped)
+	}
+	f(s)
+}
+
+// pause() returns control to the game loop until wake returns true.
+func (s *Script) pause(wake func() bool) {
+	s.wake = wake
+	s.yield <- struct{}{}
+	<-s.resume
+	if s.stopped {
+		panic(ErrStopped)
+	}
+}
+
+// Done() returns true once the script has finished or been stopped.
+func (s *Script) Done() bool {
+	return s.done
+}
+
+// Stop() ends the script. It is unwound the next time Update() runs; deferred
+// functions in the script are run as normal.
+func (s *Script) Stop() {
+	s.stopped = true
+}
+
+// Runner() returns the runner that the script belongs to.
+func (s *Script) Runner() *Runner {
+	return s.r
+}
+
+// Yield() waits until the next call to Update().
+func (s *Script) Yield() {
+	s.pause(func() bool { return true })
+}
+
+// Wait() waits for the given number of seconds of game time to pass.
+func (s *Script) Wait(seconds float64) {
+	// Allow for rounding error when time is accumulated in small steps.
+	deadline := s.r.time + seconds - 1e-9
+	s.pause(func() bool { return s.r.time >= deadline })
+}
+
+// WaitUntil() waits until the condition returns true. The condition is checked
+// once per call to Update().
+func (s *Script) WaitUntil(cond func() bool) {
+	s.pause(cond)
+}
+
+// WaitForEvent() waits until an event for which match returns true is passed
+// to HandleEvent(), and returns that event.
+func (s *Script) WaitForEvent(match func(ev interface{}) bool) interface{} {
+	var found interface{}
+	s.pause(func() bool {
+		for _, ev := range s.r.events {
+			if match(ev) {
+				found = ev
+				return true
+			}
+		}
+		return false
+	})
+	return found
+}
+
+// WaitForSignal() waits until the named signal is raised with Signal().
+func (s *Script) WaitForSignal(name string) {
+	s.pause(func() bool { return s.r.signals[name] })
+}
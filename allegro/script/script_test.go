@@ -0,0 +1,69 @@
+package script
+
+import (
+	"testing"
+)
+
+func TestWait(t *testing.T) {
+	r := NewRunner()
+	var steps []int
+	r.Start(func(s *Script) {
+		steps = append(steps, 1)
+		s.Wait(1)
+		steps = append(steps, 2)
+		s.WaitForSignal("go")
+		steps = append(steps, 3)
+	})
+
+	r.Update(0.5)
+	if len(steps) != 1 {
+		t.Fatalf("expected 1 step, got %v", steps)
+	}
+	r.Update(0.9)
+	if len(steps) != 1 {
+		t.Fatalf("expected 1 step, got %v", steps)
+	}
+	r.Update(0.1)
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %v", steps)
+	}
+	r.Update(1)
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, got %v", steps)
+	}
+	r.Signal("go")
+	r.Update(0)
+	if len(steps) != 3 || r.Len() != 0 {
+		t.Fatalf("expected script to finish, got %v", steps)
+	}
+}
+
+func TestWaitForEvent(t *testing.T) {
+	r := NewRunner()
+	var got interface{}
+	r.Start(func(s *Script) {
+		got = s.WaitForEvent(func(ev interface{}) bool { return ev == 2 })
+	})
+	r.HandleEvent(1)
+	r.Update(0)
+	r.HandleEvent(2)
+	r.Update(0)
+	if got != 2 || r.Len() != 0 {
+		t.Fatalf("expected event 2, got %v", got)
+	}
+}
+
+func TestStop(t *testing.T) {
+	r := NewRunner()
+	cleaned := false
+	s := r.Start(func(s *Script) {
+		defer func() { cleaned = true }()
+		s.WaitForSignal("never")
+	})
+	r.Update(0)
+	s.Stop()
+	r.Update(0)
+	if !s.Done() || !cleaned || r.Len() != 0 {
+		t.Fatal("expected script to be stopped")
+	}
+}
@@ -23,6 +23,7 @@ const (
 
 var (
 	ShaderIsNull = errors.New("shader is null")
+	errUseShader = errors.New("failed to use shader")
 )
 
 type ShaderType int
@@ -43,13 +44,54 @@ const (
 type Shader C.struct_ALLEGRO_SHADER
 
 func CreateShader(platform ShaderPlatform) (*Shader, error) {
-	s := C.al_create_shader(C.ALLEGRO_SHADER_PLATFORM(platform))
-	if s == nil {
-		return nil, errors.New("failed to create shader")
+	var s *C.ALLEGRO_SHADER
+	err := checkErrno("al_create_shader", "", func() bool {
+		s = C.al_create_shader(C.ALLEGRO_SHADER_PLATFORM(platform))
+		return s != nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	trackResource(unsafe.Pointer(s), "shader")
+	setShaderDisplay((*Shader)(s), CurrentDisplay())
 	return (*Shader)(s), nil
 }
 
+// NewShaderProgram() creates a shader, attaches the given vertex and pixel
+// sources and builds it. An empty source is replaced by Allegro's default
+// source for that stage and the shader's platform. If any step fails, the
+// shader is destroyed and the error includes the shader log.
+func NewShaderProgram(platform ShaderPlatform, vertexSrc, pixelSrc string) (*Shader, error) {
+	s, err := CreateShader(platform)
+	if err != nil {
+		return nil, err
+	}
+	// SHADER_AUTO is resolved when the shader is created.
+	platform, _ = s.Platform()
+	for _, stage := range []struct {
+		typ ShaderType
+		src string
+	}{{VERTEX_SHADER, vertexSrc}, {PIXEL_SHADER, pixelSrc}} {
+		if stage.src == "" {
+			stage.src = DefaultShaderSource(platform, stage.typ)
+		}
+		if err = s.AttachSource(stage.typ, stage.src); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = s.Build()
+	}
+	if err != nil {
+		if log, _ := s.Log(); log != "" {
+			err = fmt.Errorf("%s: %s", err.Error(), log)
+		}
+		s.Destroy()
+		return nil, err
+	}
+	return s, nil
+}
+
 func (s *Shader) AttachSource(stype ShaderType, source string) error {
 	if s == nil {
 		return ShaderIsNull
@@ -58,12 +100,9 @@ func (s *Shader) AttachSource(stype ShaderType, source string) error {
 	source_ := C.CString(source)
 	defer freeString(source_)
 
-	ok := C.al_attach_shader_source((*C.ALLEGRO_SHADER)(s), C.ALLEGRO_SHADER_TYPE(stype), source_)
-	if !ok {
-		return errors.New("failed to attach shader source")
-	}
-
-	return nil
+	return checkErrno("al_attach_shader_source", "", func() bool {
+		return bool(C.al_attach_shader_source((*C.ALLEGRO_SHADER)(s), C.ALLEGRO_SHADER_TYPE(stype), source_))
+	})
 }
 
 func (s *Shader) AttachSourceFile(stype ShaderType, filename string) error {
@@ -74,12 +113,9 @@ func (s *Shader) AttachSourceFile(stype ShaderType, filename string) error {
 	filename_ := C.CString(filename)
 	defer freeString(filename_)
 
-	ok := C.al_attach_shader_source_file((*C.ALLEGRO_SHADER)(s), C.ALLEGRO_SHADER_TYPE(stype), filename_)
-	if !ok {
-		return fmt.Errorf("failed to attach shader source file \"%s\"", filename)
-	}
-
-	return nil
+	return checkErrno("al_attach_shader_source_file", filename, func() bool {
+		return bool(C.al_attach_shader_source_file((*C.ALLEGRO_SHADER)(s), C.ALLEGRO_SHADER_TYPE(stype), filename_))
+	})
 }
 
 func (s *Shader) Build() error {
@@ -87,12 +123,9 @@ func (s *Shader) Build() error {
 		return ShaderIsNull
 	}
 
-	ok := C.al_build_shader((*C.ALLEGRO_SHADER)(s))
-	if !ok {
-		return errors.New("failed to build shader")
-	}
-
-	return nil
+	return checkErrno("al_build_shader", "", func() bool {
+		return bool(C.al_build_shader((*C.ALLEGRO_SHADER)(s)))
+	})
 }
 
 func (s *Shader) Log() (string, error) {
@@ -113,16 +146,39 @@ func (s *Shader) Platform() (ShaderPlatform, error) {
 	return ShaderPlatform(p), nil
 }
 
+// UseShader() uses the shader for subsequent drawing to the current target.
+// Passing nil selects the default shader: the one installed with
+// SetDefaultShader() if any, otherwise Allegro's built-in shader. A shader can
+// only be used while the display it was created for is current, i.e. when the
+// target is that display's backbuffer or one of its video bitmaps; otherwise
+// an error naming both displays is returned.
 func UseShader(s *Shader) error {
+	if s == nil {
+		s = defaultShader
+		// A default shader made for another display can't be used here;
+		// fall back to the built-in one.
+		if !s.usableOnCurrentDisplay() {
+			s = nil
+		}
+	} else if err := s.checkDisplay(); err != nil {
+		return err
+	}
 	ok := C.al_use_shader((*C.ALLEGRO_SHADER)(s))
 	if !ok {
-		return errors.New("failed to use shader")
+		return errUseShader
 	}
 
 	return nil
 }
 
 func (s *Shader) Destroy() {
+	if releaseResource(unsafe.Pointer(s), "shader") == nil {
+		s.destroy()
+	}
+}
+
+func (s *Shader) destroy() {
+	setShaderDisplay(s, nil)
 	C.al_destroy_shader((*C.ALLEGRO_SHADER)(s))
 }
 
@@ -131,8 +187,7 @@ func SetShaderSampler(name string, bmp *Bitmap, unit int) error {
 		return BitmapIsNull
 	}
 
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	ok := C.al_set_shader_sampler(name_, (*C.ALLEGRO_BITMAP)(bmp), C.int(unit))
 	if !ok {
@@ -143,8 +198,7 @@ func SetShaderSampler(name string, bmp *Bitmap, unit int) error {
 }
 
 func SetShaderMatrix(name string, matrix *Transform) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	ok := C.al_set_shader_matrix(name_, (*C.ALLEGRO_TRANSFORM)(matrix))
 	if !ok {
@@ -155,8 +209,7 @@ func SetShaderMatrix(name string, matrix *Transform) error {
 }
 
 func SetShaderInt(name string, i int) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	ok := C.al_set_shader_int(name_, C.int(i))
 	if !ok {
@@ -167,8 +220,7 @@ func SetShaderInt(name string, i int) error {
 }
 
 func SetShaderFloat(name string, f float32) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	ok := C.al_set_shader_float(name_, C.float(f))
 	if !ok {
@@ -179,8 +231,7 @@ func SetShaderFloat(name string, f float32) error {
 }
 
 func SetShaderIntVector(name string, i [][]int) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	var ok C.bool
 
@@ -190,11 +241,10 @@ func SetShaderIntVector(name string, i [][]int) error {
 		elems := len(i)
 		components := len(i[0])
 
-		cmem := malloc(C.size_t(elems*components) * C.size_t(unsafe.Sizeof(C.int(0))))
+		cmem := frameArena.alloc(uintptr(elems*components) * unsafe.Sizeof(C.int(0)))
 		if cmem == nil {
 			return errors.New("failed to allocate int vector")
 		}
-		defer free(cmem)
 
 		garr := (*[1<<30 - 1]C.int)(cmem)
 		idx := 0
@@ -216,8 +266,7 @@ func SetShaderIntVector(name string, i [][]int) error {
 }
 
 func SetShaderFloatVector(name string, f [][]float32) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	var ok C.bool
 
@@ -227,11 +276,10 @@ func SetShaderFloatVector(name string, f [][]float32) error {
 		elems := len(f)
 		components := len(f[0])
 
-		cmem := malloc(C.size_t(elems*components) * C.size_t(unsafe.Sizeof(C.float(0.0))))
+		cmem := frameArena.alloc(uintptr(elems*components) * unsafe.Sizeof(C.float(0.0)))
 		if cmem == nil {
 			return errors.New("failed to allocate float vector")
 		}
-		defer free(cmem)
 
 		garr := (*[1<<30 - 1]C.float)(cmem)
 		idx := 0
@@ -252,9 +300,50 @@ func SetShaderFloatVector(name string, f [][]float32) error {
 	return nil
 }
 
+// SetShaderFloatSlice() sets a uniform array of float vectors with comps
+// components each (1 to 4) from flat data, e.g. the 16 floats of every matrix
+// in a skinning palette. Unlike SetShaderFloatVector(), data is passed to
+// Allegro as is, without being copied, so it suits large uniforms that change
+// every frame. len(data) must be a multiple of comps.
+func SetShaderFloatSlice(name string, comps int, data []float32) error {
+	if comps <= 0 || len(data)%comps != 0 {
+		return fmt.Errorf("float slice for \"%s\" is not a multiple of %d components", name, comps)
+	}
+	name_ := frameArena.cstring(name)
+
+	var ptr *C.float
+	if len(data) > 0 {
+		ptr = (*C.float)(unsafe.Pointer(&data[0]))
+	}
+	ok := C.al_set_shader_float_vector(name_, C.int(comps), ptr, C.int(len(data)/comps))
+	if !ok {
+		return fmt.Errorf("failed to set float vector for \"%s\"", name)
+	}
+
+	return nil
+}
+
+// SetShaderIntSlice() is like SetShaderFloatSlice(), for int vectors.
+func SetShaderIntSlice(name string, comps int, data []int32) error {
+	if comps <= 0 || len(data)%comps != 0 {
+		return fmt.Errorf("int slice for \"%s\" is not a multiple of %d components", name, comps)
+	}
+	name_ := frameArena.cstring(name)
+
+	var ptr *C.int
+	if len(data) > 0 {
+		ptr = (*C.int)(unsafe.Pointer(&data[0]))
+	}
+	ok := C.al_set_shader_int_vector(name_, C.int(comps), ptr, C.int(len(data)/comps))
+	if !ok {
+		return fmt.Errorf("failed to set int vector for \"%s\"", name)
+	}
+
+	return nil
+}
+
 func SetShaderBool(name string, b bool) error {
-	name_ := C.CString(name)
-	defer freeString(name_)
+	name_ := frameArena.cstring(name)
 
 	ok := C.al_set_shader_bool(name_, C.bool(b))
 	if !ok {
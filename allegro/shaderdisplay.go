@@ -0,0 +1,93 @@
+package allegro
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Shaders belong to the display that was current when they were created, and
+// Allegro fails without saying why when one is used with another display's
+// bitmap as the target. Allegro doesn't record the owner, so it is kept here
+// from creation until the shader is destroyed.
+var shaderDisplays = struct {
+	sync.Mutex
+	m map[*Shader]*Display
+}{m: make(map[*Shader]*Display)}
+
+func setShaderDisplay(s *Shader, d *Display) {
+	shaderDisplays.Lock()
+	defer shaderDisplays.Unlock()
+	if d == nil {
+		delete(shaderDisplays.m, s)
+	} else {
+		shaderDisplays.m[s] = d
+	}
+}
+
+// Display() returns the display the shader was created for, or nil if it is
+// unknown, e.g. for shaders created by addons.
+func (s *Shader) Display() *Display {
+	shaderDisplays.Lock()
+	defer shaderDisplays.Unlock()
+	return shaderDisplays.m[s]
+}
+
+// checkDisplay() returns an error if the shader belongs to a display other
+// than the current one.
+func (s *Shader) checkDisplay() error {
+	owner := s.Display()
+	if owner == nil {
+		return nil
+	}
+	if cur := CurrentDisplay(); cur != owner {
+		return fmt.Errorf("shader %p belongs to display %p, but the current display is %p; "+
+			"set the target to that display's backbuffer first", s, owner, cur)
+	}
+	return nil
+}
+
+func (s *Shader) usableOnCurrentDisplay() bool {
+	return s != nil && s.checkDisplay() == nil
+}
+
+// withTargetDisplay() runs f with d's backbuffer as the target bitmap and
+// restores the previous target afterwards.
+func (d *Display) withTargetDisplay(f func()) {
+	if CurrentDisplay() == d {
+		f()
+		return
+	}
+	WithState(STATE_TARGET_BITMAP, func() {
+		SetTargetBackbuffer(d)
+		f()
+	})
+}
+
+// CreateShader() is like the package-level CreateShader(), but creates the
+// shader for d whatever the current target is.
+func (d *Display) CreateShader(platform ShaderPlatform) (*Shader, error) {
+	var s *Shader
+	var err error
+	d.withTargetDisplay(func() {
+		s, err = CreateShader(platform)
+	})
+	return s, err
+}
+
+// NewShaderProgram() is like the package-level NewShaderProgram(), but builds
+// the shader for d whatever the current target is.
+func (d *Display) NewShaderProgram(platform ShaderPlatform, vertexSrc, pixelSrc string) (*Shader, error) {
+	var s *Shader
+	var err error
+	d.withTargetDisplay(func() {
+		s, err = NewShaderProgram(platform, vertexSrc, pixelSrc)
+	})
+	return s, err
+}
+
+// UseShader() makes d's backbuffer the target bitmap and uses s for drawing
+// to it, as UseShader() does.
+func (d *Display) UseShader(s *Shader) error {
+	SetTargetBackbuffer(d)
+	return UseShader(s)
+}
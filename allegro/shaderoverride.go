@@ -0,0 +1,155 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+// Uses the default shader s if the target has no shader of its own and
+// belongs to owner. A NULL owner means the shader's display is unknown, and it
+// is used anywhere.
+static void apply_default_shader(ALLEGRO_SHADER *s, ALLEGRO_DISPLAY *owner) {
+	if (al_get_current_shader() != NULL) {
+		return;
+	}
+	if (owner != NULL && al_get_current_display() != owner) {
+		return;
+	}
+	al_use_shader(s);
+}
+
+// Change the target and apply the default shader in one cgo call. s is NULL
+// if there is no default shader.
+static void set_target_bitmap(ALLEGRO_BITMAP *bmp, ALLEGRO_SHADER *s, ALLEGRO_DISPLAY *owner) {
+	al_set_target_bitmap(bmp);
+	if (s != NULL) {
+		apply_default_shader(s, owner);
+	}
+}
+
+static void set_target_backbuffer(ALLEGRO_DISPLAY *d, ALLEGRO_SHADER *s, ALLEGRO_DISPLAY *owner) {
+	al_set_target_backbuffer(d);
+	if (s != NULL) {
+		apply_default_shader(s, owner);
+	}
+}
+*/
+import "C"
+import (
+	"errors"
+	"regexp"
+)
+
+// Allegro always falls back to its built-in shader when no shader is in use,
+// and it has no way to replace that shader. Instead, a user default shader is
+// kept here and put into use whenever a target bitmap without a shader of its
+// own is selected through SetTargetBitmap() or SetTargetBackbuffer(), and
+// whenever UseShader(nil) is called.
+var defaultShader *Shader
+
+// The display defaultShader belongs to. It is looked up when the shader is
+// installed, so that setTargetBitmap(), which runs on every change of target,
+// needn't take shaderDisplays' lock.
+var defaultShaderDisplay *Display
+
+func setDefaultShader(s *Shader) {
+	defaultShader = s
+	defaultShaderDisplay = nil
+	if s != nil {
+		defaultShaderDisplay = s.Display()
+	}
+}
+
+// SetDefaultShader() installs s as the shader used for ordinary drawing on
+// programmable-pipeline displays in place of Allegro's built-in shader. It is
+// put into use for the current target straight away. Passing nil restores the
+// built-in shader for subsequent targets; bitmaps that were already targeted
+// keep using s until UseShader(nil) is called on them.
+func SetDefaultShader(s *Shader) {
+	setDefaultShader(s)
+	C.al_use_shader((*C.ALLEGRO_SHADER)(s))
+}
+
+// DefaultShader() returns the shader installed by SetDefaultShader(), or nil
+// if Allegro's built-in shader is in effect.
+func DefaultShader() *Shader {
+	return defaultShader
+}
+
+// UseBuiltinShader() uses Allegro's built-in shader for the current target,
+// bypassing any shader installed by SetDefaultShader().
+func UseBuiltinShader() error {
+	if !bool(C.al_use_shader(nil)) {
+		return errors.New("failed to use built-in shader")
+	}
+	return nil
+}
+
+// setTargetBitmap() and setTargetBackbuffer() change the target and then use
+// the installed default shader if the new target has no shader of its own and
+// belongs to the shader's display. Targets that don't support shaders, such
+// as memory bitmaps, are left alone.
+
+func setTargetBitmap(bmp *Bitmap) {
+	C.set_target_bitmap((*C.ALLEGRO_BITMAP)(bmp),
+		(*C.ALLEGRO_SHADER)(defaultShader), (*C.ALLEGRO_DISPLAY)(defaultShaderDisplay))
+}
+
+func setTargetBackbuffer(d *Display) {
+	C.set_target_backbuffer((*C.ALLEGRO_DISPLAY)(d),
+		(*C.ALLEGRO_SHADER)(defaultShader), (*C.ALLEGRO_DISPLAY)(defaultShaderDisplay))
+}
+
+// ShaderPatch describes a change to a GLSL shader. The patched shader runs the
+// original main() first and then Body, so Body sees the original results: in
+// a pixel shader gl_FragColor holds the color about to be written, and in a
+// vertex shader gl_Position and the varyings have been set. Declarations, such
+// as extra uniforms and helper functions, are inserted before the original
+// main().
+//
+// For example, a global tint:
+//
+//	ShaderPatch{
+//		Declarations: "uniform vec4 tint;",
+//		Body:         "gl_FragColor *= tint;",
+//	}
+type ShaderPatch struct {
+	Declarations string
+	Body         string
+}
+
+var glslMain = regexp.MustCompile(`void\s+main\s*\(\s*(void)?\s*\)`)
+
+// Patch() applies the patch to GLSL source by renaming its main() and adding a
+// new main() that calls it.
+func (p ShaderPatch) Patch(source string) (string, error) {
+	loc := glslMain.FindStringIndex(source)
+	if loc == nil {
+		return "", errors.New("shader source has no main() function")
+	}
+	return source[:loc[0]] + p.Declarations + "\n" +
+		"void al_default_main()" + source[loc[1]:] + "\n" +
+		"void main()\n{\n\tal_default_main();\n\t" + p.Body + "\n}\n", nil
+}
+
+// PatchDefaultShaderSource() returns Allegro's built-in GLSL source for the
+// given shader type with the patch applied.
+func PatchDefaultShaderSource(typ ShaderType, patch ShaderPatch) (string, error) {
+	return patch.Patch(DefaultShaderSource(SHADER_GLSL, typ))
+}
+
+// CreatePatchedDefaultShader() builds a GLSL shader from Allegro's built-in
+// sources with the given patches applied; a nil patch leaves that stage
+// unchanged. The result is suitable for SetDefaultShader(). If building fails,
+// the error includes the shader log.
+func CreatePatchedDefaultShader(vertex, pixel *ShaderPatch) (*Shader, error) {
+	sources := make(map[ShaderType]string, 2)
+	for typ, patch := range map[ShaderType]*ShaderPatch{VERTEX_SHADER: vertex, PIXEL_SHADER: pixel} {
+		src := DefaultShaderSource(SHADER_GLSL, typ)
+		if patch != nil {
+			var err error
+			if src, err = patch.Patch(src); err != nil {
+				return nil, err
+			}
+		}
+		sources[typ] = src
+	}
+	return NewShaderProgram(SHADER_GLSL, sources[VERTEX_SHADER], sources[PIXEL_SHADER])
+}
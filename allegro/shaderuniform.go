@@ -0,0 +1,78 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+
+// CurrentShader() returns the shader used by the target bitmap, or nil if
+// there is none.
+func CurrentShader() *Shader {
+	return (*Shader)(C.al_get_current_shader())
+}
+
+// with() runs f with s as the current shader. If s isn't already current, it
+// is used for the duration of f and the previous shader is restored
+// afterwards; uniforms set meanwhile stay set on s.
+func (s *Shader) with(f func() error) error {
+	if s == nil {
+		return ShaderIsNull
+	}
+	prev := C.al_get_current_shader()
+	if prev == (*C.ALLEGRO_SHADER)(s) {
+		return f()
+	}
+	if !bool(C.al_use_shader((*C.ALLEGRO_SHADER)(s))) {
+		return errUseShader
+	}
+	defer C.al_use_shader(prev)
+	return f()
+}
+
+// The methods below are like the SetShader* functions, but set the uniform on
+// s rather than on whichever shader happens to be current, so code juggling
+// several shaders doesn't depend on the order of UseShader() calls. Like the
+// functions, they need a target bitmap, and s must be compatible with it.
+
+// SetSampler() is like SetShaderSampler(), for s.
+func (s *Shader) SetSampler(name string, bmp *Bitmap, unit int) error {
+	return s.with(func() error { return SetShaderSampler(name, bmp, unit) })
+}
+
+// SetMatrix() is like SetShaderMatrix(), for s.
+func (s *Shader) SetMatrix(name string, matrix *Transform) error {
+	return s.with(func() error { return SetShaderMatrix(name, matrix) })
+}
+
+// SetInt() is like SetShaderInt(), for s.
+func (s *Shader) SetInt(name string, i int) error {
+	return s.with(func() error { return SetShaderInt(name, i) })
+}
+
+// SetFloat() is like SetShaderFloat(), for s.
+func (s *Shader) SetFloat(name string, f float32) error {
+	return s.with(func() error { return SetShaderFloat(name, f) })
+}
+
+// SetBool() is like SetShaderBool(), for s.
+func (s *Shader) SetBool(name string, b bool) error {
+	return s.with(func() error { return SetShaderBool(name, b) })
+}
+
+// SetIntVector() is like SetShaderIntVector(), for s.
+func (s *Shader) SetIntVector(name string, i [][]int) error {
+	return s.with(func() error { return SetShaderIntVector(name, i) })
+}
+
+// SetFloatVector() is like SetShaderFloatVector(), for s.
+func (s *Shader) SetFloatVector(name string, f [][]float32) error {
+	return s.with(func() error { return SetShaderFloatVector(name, f) })
+}
+
+// SetIntSlice() is like SetShaderIntSlice(), for s.
+func (s *Shader) SetIntSlice(name string, comps int, data []int32) error {
+	return s.with(func() error { return SetShaderIntSlice(name, comps, data) })
+}
+
+// SetFloatSlice() is like SetShaderFloatSlice(), for s.
+func (s *Shader) SetFloatSlice(name string, comps int, data []float32) error {
+	return s.with(func() error { return SetShaderFloatSlice(name, comps, data) })
+}
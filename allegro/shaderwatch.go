@@ -0,0 +1,229 @@
+package allegro
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ShaderWatcher rebuilds a shader whenever its source files change, so that
+// shaders can be worked on without restarting the game. It is meant for
+// development builds. The assets package's Manager reloads its shaders the
+// same way, through LoadShaderProgram() and ReplaceShader().
+//
+// The files are watched and read on a background goroutine, but Allegro can
+// only build a shader on the thread that owns the display, so the new shader
+// is built and swapped in by Update(), which should be called once per frame
+// from the drawing code:
+//
+//	w, err := allegro.NewShaderWatcher(allegro.SHADER_GLSL, "blur.vert", "blur.frag")
+//	...
+//	w.Update()
+//	allegro.UseShader(w.Shader())
+//
+// If the new sources fail to build, the previous shader is kept and the error,
+// which includes the shader log, is passed to OnError.
+type ShaderWatcher struct {
+	// OnReload is called by Update() after a new shader has been swapped in.
+	OnReload func(s *Shader)
+
+	// OnError is called by Update() when the files can't be read or the
+	// shader fails to build.
+	OnError func(err error)
+
+	platform    ShaderPlatform
+	vertexFile  string
+	pixelFile   string
+	shader      *Shader
+	done        chan struct{}
+	closeOnce   sync.Once
+	mu          sync.Mutex
+	pending     *shaderSources
+	pendingErr  error
+	lastModTime [2]time.Time
+}
+
+type shaderSources struct {
+	vertex, pixel string
+}
+
+// The interval at which a ShaderWatcher checks its files.
+const shaderWatchInterval = 250 * time.Millisecond
+
+// NewShaderWatcher() builds a shader from the given vertex and pixel source
+// files and starts watching them. An empty filename selects Allegro's default
+// source for that stage, as NewShaderProgram() does. The first build happens
+// immediately, on the calling thread, and its error is returned.
+func NewShaderWatcher(platform ShaderPlatform, vertexFile, pixelFile string) (*ShaderWatcher, error) {
+	w := &ShaderWatcher{
+		platform:   platform,
+		vertexFile: vertexFile,
+		pixelFile:  pixelFile,
+		done:       make(chan struct{}),
+	}
+	w.lastModTime = w.modTimes()
+	s, err := LoadShaderProgram(platform, vertexFile, pixelFile)
+	if err != nil {
+		return nil, err
+	}
+	w.shader = s
+	go w.watch()
+	return w, nil
+}
+
+// Shader() returns the current shader. It changes when Update() swaps in a
+// new one, so it should be fetched each frame rather than kept.
+func (w *ShaderWatcher) Shader() *Shader {
+	return w.shader
+}
+
+func (w *ShaderWatcher) files() [2]string {
+	return [2]string{w.vertexFile, w.pixelFile}
+}
+
+func (w *ShaderWatcher) modTimes() [2]time.Time {
+	var times [2]time.Time
+	for i, filename := range w.files() {
+		if filename == "" {
+			continue
+		}
+		if fi, err := os.Stat(filename); err == nil {
+			times[i] = fi.ModTime()
+		}
+	}
+	return times
+}
+
+func readShaderFiles(vertexFile, pixelFile string) (*shaderSources, error) {
+	var srcs [2]string
+	for i, filename := range [2]string{vertexFile, pixelFile} {
+		if filename == "" {
+			continue
+		}
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		srcs[i] = string(b)
+	}
+	return &shaderSources{vertex: srcs[0], pixel: srcs[1]}, nil
+}
+
+func buildShaderFiles(platform ShaderPlatform, vertexFile, pixelFile string, src *shaderSources) (*Shader, error) {
+	s, err := NewShaderProgram(platform, src.vertex, src.pixel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build shader from '%s' and '%s': %s",
+			vertexFile, pixelFile, err.Error())
+	}
+	return s, nil
+}
+
+// LoadShaderProgram() is like NewShaderProgram(), but reads the sources from
+// the given files. An empty filename selects Allegro's default source for that
+// stage. The error names the files.
+func LoadShaderProgram(platform ShaderPlatform, vertexFile, pixelFile string) (*Shader, error) {
+	src, err := readShaderFiles(vertexFile, pixelFile)
+	if err != nil {
+		return nil, err
+	}
+	return buildShaderFiles(platform, vertexFile, pixelFile, src)
+}
+
+// ReplaceShader() puts s in the place of old, a shader that is being rebuilt,
+// and destroys old. If old was installed with SetDefaultShader(), s is
+// installed instead, and if old is in use for the target bitmap, s is put
+// into use. The error is UseShader()'s, e.g. if s was built for another
+// display; old is destroyed regardless.
+func ReplaceShader(old, s *Shader) error {
+	if defaultShader == old {
+		setDefaultShader(s)
+	}
+	var err error
+	if CurrentShader() == old {
+		err = UseShader(s)
+	}
+	old.Destroy()
+	return err
+}
+
+// watch() polls the files until Close() is called, reading them whenever
+// either one's modification time changes. Editors often write a file in
+// several steps, so a change is only acted on once the times have stayed the
+// same for a whole interval.
+func (w *ShaderWatcher) watch() {
+	ticker := time.NewTicker(shaderWatchInterval)
+	defer ticker.Stop()
+	var changed [2]time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		times := w.modTimes()
+		if times == w.lastModTime {
+			continue
+		}
+		if times != changed {
+			changed = times
+			continue
+		}
+		w.lastModTime = times
+		src, err := readShaderFiles(w.vertexFile, w.pixelFile)
+		w.mu.Lock()
+		w.pending, w.pendingErr = src, err
+		w.mu.Unlock()
+	}
+}
+
+// Update() builds the shader from the most recently changed sources, if any,
+// and swaps it in with ReplaceShader(), returning whether it did. It must be
+// called on the thread that owns the display. If the new shader can't be put
+// into use, it is still swapped in and the error is passed to OnError.
+func (w *ShaderWatcher) Update() bool {
+	w.mu.Lock()
+	src, err := w.pending, w.pendingErr
+	w.pending, w.pendingErr = nil, nil
+	w.mu.Unlock()
+
+	if err != nil {
+		w.reportError(err)
+		return false
+	}
+	if src == nil {
+		return false
+	}
+	s, err := buildShaderFiles(w.platform, w.vertexFile, w.pixelFile, src)
+	if err != nil {
+		w.reportError(err)
+		return false
+	}
+
+	old := w.shader
+	w.shader = s
+	if err := ReplaceShader(old, s); err != nil {
+		w.reportError(err)
+	}
+	if w.OnReload != nil {
+		w.OnReload(s)
+	}
+	return true
+}
+
+func (w *ShaderWatcher) reportError(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// Close() stops watching the files and destroys the current shader.
+func (w *ShaderWatcher) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	if w.shader == nil {
+		return nil
+	}
+	s := w.shader
+	w.shader = nil
+	return s.Close()
+}
@@ -0,0 +1,131 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+typedef struct {
+	ALLEGRO_BITMAP *bmp;
+	ALLEGRO_COLOR tint;
+	float sx, sy, sw, sh;
+	float cx, cy, dx, dy;
+	float xscale, yscale, angle;
+	int flags;
+} go_sprite;
+
+// A negative sw stands for the whole bitmap, whose size is looked up here
+// rather than by a cgo call per sprite when it is queued.
+static void draw_sprites(go_sprite *s, int n) {
+	bool held = al_is_bitmap_drawing_held();
+	int i;
+	if (!held) {
+		al_hold_bitmap_drawing(true);
+	}
+	for (i = 0; i < n; i++) {
+		if (s[i].sw < 0) {
+			s[i].sw = al_get_bitmap_width(s[i].bmp);
+			s[i].sh = al_get_bitmap_height(s[i].bmp);
+		}
+		al_draw_tinted_scaled_rotated_bitmap_region(s[i].bmp,
+			s[i].sx, s[i].sy, s[i].sw, s[i].sh, s[i].tint,
+			s[i].cx, s[i].cy, s[i].dx, s[i].dy,
+			s[i].xscale, s[i].yscale, s[i].angle, s[i].flags);
+	}
+	if (!held) {
+		al_hold_bitmap_drawing(false);
+	}
+}
+*/
+import "C"
+
+// SpriteBatch collects bitmap draws in Go memory and submits all of them with
+// a single cgo call when Flush() is called. The draws are made with deferred
+// bitmap drawing held, so consecutive sprites from the same parent bitmap,
+// such as sub-bitmaps of a sprite sheet, are also batched by Allegro.
+//
+// Unlike primitives.Batcher, it does not need the primitives addon and draws
+// exactly as the Bitmap methods would. Since nothing is drawn until Flush(),
+// it must be called before changing the target bitmap, blender or shader.
+type SpriteBatch struct {
+	sprites []C.go_sprite
+	white   Color
+}
+
+// NewSpriteBatch() creates an empty batch with room for capacity sprites
+// before it needs to grow.
+func NewSpriteBatch(capacity int) *SpriteBatch {
+	return &SpriteBatch{
+		sprites: make([]C.go_sprite, 0, capacity),
+		white:   MapRGBAf(1, 1, 1, 1),
+	}
+}
+
+// Len() returns the number of pending draws.
+func (b *SpriteBatch) Len() int {
+	return len(b.sprites)
+}
+
+// Reset() discards any pending draws.
+func (b *SpriteBatch) Reset() {
+	b.sprites = b.sprites[:0]
+}
+
+// Flush() submits and then discards the pending draws.
+func (b *SpriteBatch) Flush() {
+	if len(b.sprites) > 0 {
+		C.draw_sprites(&b.sprites[0], C.int(len(b.sprites)))
+	}
+	b.sprites = b.sprites[:0]
+}
+
+// wholeBitmap, passed as the region's width, makes Flush() draw the whole
+// bitmap.
+const wholeBitmap = -1
+
+// Draw() is the batched equivalent of Bitmap.Draw().
+func (b *SpriteBatch) Draw(bmp *Bitmap, dx, dy float32, flags DrawFlags) {
+	b.DrawTinted(bmp, b.white, dx, dy, flags)
+}
+
+// DrawTinted() is the batched equivalent of Bitmap.DrawTinted().
+func (b *SpriteBatch) DrawTinted(bmp *Bitmap, tint Color, dx, dy float32, flags DrawFlags) {
+	b.DrawTintedScaledRotatedRegion(bmp, 0, 0, wholeBitmap, wholeBitmap, tint, 0, 0, dx, dy, 1, 1, 0, flags)
+}
+
+// DrawRegion() is the batched equivalent of Bitmap.DrawRegion().
+func (b *SpriteBatch) DrawRegion(bmp *Bitmap, sx, sy, sw, sh, dx, dy float32, flags DrawFlags) {
+	b.DrawTintedScaledRotatedRegion(bmp, sx, sy, sw, sh, b.white, 0, 0, dx, dy, 1, 1, 0, flags)
+}
+
+// DrawTintedRegion() is the batched equivalent of Bitmap.DrawTintedRegion().
+func (b *SpriteBatch) DrawTintedRegion(bmp *Bitmap, tint Color, sx, sy, sw, sh, dx, dy float32, flags DrawFlags) {
+	b.DrawTintedScaledRotatedRegion(bmp, sx, sy, sw, sh, tint, 0, 0, dx, dy, 1, 1, 0, flags)
+}
+
+// DrawRotated() is the batched equivalent of Bitmap.DrawRotated().
+func (b *SpriteBatch) DrawRotated(bmp *Bitmap, cx, cy, dx, dy, angle float32, flags DrawFlags) {
+	b.DrawTintedScaledRotatedRegion(bmp, 0, 0, wholeBitmap, wholeBitmap, b.white, cx, cy, dx, dy, 1, 1, angle, flags)
+}
+
+// DrawTintedScaledRotatedRegion() is the batched equivalent of
+// Bitmap.DrawTintedScaledRotatedRegion(); the other methods are shortcuts for
+// it.
+func (b *SpriteBatch) DrawTintedScaledRotatedRegion(bmp *Bitmap, sx, sy, sw, sh float32, tint Color, cx, cy, dx, dy, xscale, yscale, angle float32, flags DrawFlags) {
+	if bmp == nil {
+		return
+	}
+	b.sprites = append(b.sprites, C.go_sprite{
+		bmp:    (*C.ALLEGRO_BITMAP)(bmp),
+		tint:   C.ALLEGRO_COLOR(tint),
+		sx:     C.float(sx),
+		sy:     C.float(sy),
+		sw:     C.float(sw),
+		sh:     C.float(sh),
+		cx:     C.float(cx),
+		cy:     C.float(cy),
+		dx:     C.float(dx),
+		dy:     C.float(dy),
+		xscale: C.float(xscale),
+		yscale: C.float(yscale),
+		angle:  C.float(angle),
+		flags:  C.int(flags),
+	})
+}
@@ -1,9 +1,14 @@
 package allegro
 
+// #include <errno.h>
+// #include <string.h>
 // #include <allegro5/allegro.h>
 import "C"
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"runtime"
 )
 
 type State C.ALLEGRO_STATE
@@ -22,12 +27,69 @@ const (
 	STATE_ALL                               = C.ALLEGRO_STATE_ALL
 )
 
+// ErrOutOfMemory matches an *Error whose errno is ENOMEM, with errors.Is().
+var ErrOutOfMemory = errors.New("out of memory")
+
+// Error is returned when an Allegro function that sets the error number
+// fails, such as one that opens a file. Use errors.Is() with fs.ErrNotExist,
+// fs.ErrExist, fs.ErrPermission, fs.ErrInvalid or ErrOutOfMemory to tell
+// failures apart.
 type Error struct {
-	Errno int
+	Errno int    // uses the C library's values, e.g. ENOENT
+	Op    string // the failing function, e.g. "al_load_bitmap"
+	Arg   string // the argument it failed on, usually a path
 }
 
 func (e *Error) Error() string {
-	return fmt.Sprintf("errno = %d", e.Errno)
+	if e.Op == "" {
+		return fmt.Sprintf("errno = %d", e.Errno)
+	}
+	s := e.Op
+	if e.Arg != "" {
+		s += " '" + e.Arg + "'"
+	}
+	if e.Errno == 0 {
+		return s + " failed"
+	}
+	return s + ": " + C.GoString(C.strerror(C.int(e.Errno)))
+}
+
+// Is() reports whether the error number corresponds to target.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case fs.ErrNotExist:
+		return e.Errno == C.ENOENT
+	case fs.ErrExist:
+		return e.Errno == C.EEXIST
+	case fs.ErrPermission:
+		return e.Errno == C.EACCES || e.Errno == C.EPERM
+	case fs.ErrInvalid:
+		return e.Errno == C.EINVAL
+	case ErrOutOfMemory:
+		return e.Errno == C.ENOMEM
+	}
+	return false
+}
+
+// checkErrno() calls f, which returns false if the Allegro function op failed,
+// and returns an *Error with the error number it set. The goroutine is kept on
+// one thread meanwhile, since the error number is per thread.
+func checkErrno(op, arg string, f func() bool) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	C.al_set_errno(0)
+	if f() {
+		return nil
+	}
+	return &Error{Errno: int(C.al_get_errno()), Op: op, Arg: arg}
+}
+
+// CheckErrno() is for addon packages, so that their bindings report failures
+// as an *Error the way the core's do. It calls f, which returns false if the
+// Allegro function op failed, and returns an *Error holding op, arg and the
+// error number op set.
+func CheckErrno(op, arg string, f func() bool) error {
+	return checkErrno(op, arg, f)
 }
 
 // Stores part of the state of the current thread in the given ALLEGRO_STATE
@@ -44,11 +106,21 @@ func RestoreState(state *State) {
 	C.al_restore_state((*C.ALLEGRO_STATE)(state))
 }
 
+// WithState() calls f, then restores the parts of the calling thread's state
+// selected by flags, even if f panics. Library code can use it to draw
+// without clobbering the caller's target bitmap, blender or transform, e.g.
+// WithState(STATE_TARGET_BITMAP|STATE_BLENDER, f).
+func WithState(flags StateFlags, f func()) {
+	state := StoreState(flags)
+	defer RestoreState(state)
+	f()
+}
+
 // Some Allegro functions will set an error number as well as returning an
 // error code. Call this function to retrieve the last error number set for the
 // calling thread.
 func LastError() error {
-	return &Error{int(C.al_get_errno())}
+	return &Error{Errno: int(C.al_get_errno())}
 }
 
 // Set the error number for for the calling thread.
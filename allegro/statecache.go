@@ -0,0 +1,119 @@
+package allegro
+
+import (
+	"unsafe"
+)
+
+// StateCache tracks drawing state on the Go side so that redundant state
+// changes can be skipped without crossing into C. It also keeps bitmap
+// drawing held across consecutive draws, so that runs of sprites from the
+// same texture are submitted to the GPU together, and releases the hold
+// whenever a state change requires it.
+//
+// A StateCache only knows about changes made through it. If state is changed
+// some other way, e.g. by calling SetTargetBitmap() directly or from an
+// addon, call Invalidate() afterwards. Like Allegro's own drawing state, a
+// StateCache must only be used from one thread.
+type StateCache struct {
+	target       *Bitmap
+	transform    [16]float32
+	hasTransform bool
+	blender      [6]int
+	hasBlender   bool
+	held         bool
+}
+
+// Invalidate() forgets all cached state, so that the next change of each
+// kind goes through to Allegro. Any held drawing is flushed.
+func (s *StateCache) Invalidate() {
+	s.Flush()
+	*s = StateCache{}
+}
+
+// Flush() draws anything that is being held.
+func (s *StateCache) Flush() {
+	if s.held {
+		HoldBitmapDrawing(false)
+		s.held = false
+	}
+}
+
+func (s *StateCache) hold() {
+	if !s.held {
+		HoldBitmapDrawing(true)
+		s.held = true
+	}
+}
+
+// SetTargetBitmap() changes the target bitmap if it isn't already the target.
+func (s *StateCache) SetTargetBitmap(bmp *Bitmap) {
+	if bmp == s.target && bmp != nil {
+		return
+	}
+	s.Flush()
+	SetTargetBitmap(bmp)
+	s.target = bmp
+
+	// Each bitmap has its own transform.
+	s.hasTransform = false
+}
+
+// UseTransform() sets the transform of the target bitmap if it differs from
+// the one last set through the cache. Changing the transform doesn't require
+// held drawing to be flushed.
+func (s *StateCache) UseTransform(t *Transform) {
+	m := *(*[16]float32)(unsafe.Pointer(t))
+	if s.hasTransform && m == s.transform {
+		return
+	}
+	UseTransform(t)
+	s.transform = m
+	s.hasTransform = true
+}
+
+// SetBlender() sets the blender if it differs from the current one.
+func (s *StateCache) SetBlender(op BlendingOperation, src, dst BlendingValue) {
+	s.SetSeparateBlender(op, src, dst, op, src, dst)
+}
+
+// SetSeparateBlender() sets the blender if it differs from the current one.
+func (s *StateCache) SetSeparateBlender(op BlendingOperation, src, dst BlendingValue, alpha_op BlendingOperation, alpha_src, alpha_dst BlendingValue) {
+	b := [6]int{int(op), int(src), int(dst), int(alpha_op), int(alpha_src), int(alpha_dst)}
+	if s.hasBlender && b == s.blender {
+		return
+	}
+	s.Flush()
+	SetSeparateBlender(op, src, dst, alpha_op, alpha_src, alpha_dst)
+	s.blender = b
+	s.hasBlender = true
+}
+
+// Clear() flushes held drawing and clears the target bitmap.
+func (s *StateCache) Clear(c Color) {
+	s.Flush()
+	ClearToColor(c)
+}
+
+// PrepareDraw() holds bitmap drawing ahead of a bitmap or text draw made
+// outside of the cache, such as by the font addon.
+func (s *StateCache) PrepareDraw() {
+	s.hold()
+}
+
+// DrawBitmap() draws a bitmap with drawing held.
+func (s *StateCache) DrawBitmap(bmp *Bitmap, dx, dy float32, flags DrawFlags) {
+	s.hold()
+	bmp.Draw(dx, dy, flags)
+}
+
+// DrawBitmapRegion() draws part of a bitmap with drawing held.
+func (s *StateCache) DrawBitmapRegion(bmp *Bitmap, sx, sy, sw, sh, dx, dy float32, flags DrawFlags) {
+	s.hold()
+	bmp.DrawRegion(sx, sy, sw, sh, dx, dy, flags)
+}
+
+// DrawTintedBitmap() draws a tinted bitmap with drawing held.
+func (s *StateCache) DrawTintedBitmap(bmp *Bitmap, tint Color, dx, dy float32, flags DrawFlags) {
+	s.hold()
+	bmp.DrawTinted(tint, dx, dy, flags)
+}
@@ -0,0 +1,103 @@
+package allegro
+
+import (
+	"testing"
+)
+
+// These benchmarks need a display. They are skipped if one can't be created,
+// e.g. on a headless machine.
+
+func benchDisplay(b *testing.B) *Display {
+	if err := install(); err != nil {
+		b.Skip(err)
+	}
+	d, err := CreateDisplay(320, 240)
+	if err != nil {
+		b.Skip(err)
+	}
+	return d
+}
+
+func benchBitmap(b *testing.B) *Bitmap {
+	bmp := CreateBitmap(16, 16)
+	if bmp == nil {
+		b.Skip("failed to create bitmap")
+	}
+	return bmp
+}
+
+func BenchmarkUseTransform(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	t := IdentityTransform()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		UseTransform(t)
+	}
+}
+
+func BenchmarkUseTransformCached(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	var s StateCache
+	t := IdentityTransform()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.UseTransform(t)
+	}
+}
+
+func BenchmarkSetBlender(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		SetBlender(ADD, ONE, INVERSE_ALPHA)
+	}
+}
+
+func BenchmarkSetBlenderCached(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	var s StateCache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.SetBlender(ADD, ONE, INVERSE_ALPHA)
+	}
+}
+
+func BenchmarkDrawBitmap(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bmp.Draw(float32(i%300), 0, 0)
+	}
+	FlipDisplay()
+}
+
+func BenchmarkDrawBitmapCached(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	bmp := benchBitmap(b)
+	defer bmp.Destroy()
+	var s StateCache
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.DrawBitmap(bmp, float32(i%300), 0, 0)
+	}
+	s.Flush()
+	FlipDisplay()
+}
+
+func BenchmarkClear(b *testing.B) {
+	d := benchDisplay(b)
+	defer d.Destroy()
+	c := MapRGB(0, 0, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ClearToColor(c)
+	}
+}
@@ -0,0 +1,201 @@
+// Package states manages the top-level states of a game, such as a title
+// menu, gameplay and a pause screen, as a stack.
+//
+// A Machine has the same Update(), Render() and HandleEvent() methods as a
+// game loop expects, so it can be driven directly by the loop.
+package states
+
+// State is a single game state.
+type State interface {
+	// Enter() is called when the state is added to the machine.
+	Enter(m *Machine)
+
+	// Exit() is called when the state is removed from the machine.
+	Exit()
+
+	// Update() advances the state by dt seconds. Only the top state is
+	// updated.
+	Update(dt float64)
+
+	// Render() draws the state.
+	Render()
+
+	// HandleEvent() is passed events from the game's event queue. Only the
+	// top state receives events.
+	HandleEvent(ev interface{})
+}
+
+// Pauser can be implemented by states that need to know when another state is
+// pushed on top of them, and when they become the top state again.
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// Overlay can be implemented by states that don't cover the whole screen,
+// such as a pause menu. When the top state is an overlay, the state beneath it
+// is rendered first.
+type Overlay interface {
+	IsOverlay() bool
+}
+
+// Base provides no-op implementations of the State methods, so that states
+// only need to implement the ones they use.
+type Base struct{}
+
+func (Base) Enter(m *Machine)           {}
+func (Base) Exit()                      {}
+func (Base) Update(dt float64)          {}
+func (Base) Render()                    {}
+func (Base) HandleEvent(ev interface{}) {}
+
+type opKind int
+
+const (
+	opPush opKind = iota
+	opPop
+	opSwitch
+	opClear
+)
+
+type op struct {
+	kind  opKind
+	state State
+}
+
+// Machine is a stack of states. Changes to the stack requested while the
+// machine is updating or handling an event are applied once it has finished,
+// so states can safely push, pop or switch from within their own methods.
+type Machine struct {
+	stack   []State
+	pending []op
+	busy    bool
+}
+
+// New() creates a machine with the given initial state.
+func New(initial State) *Machine {
+	m := &Machine{}
+	if initial != nil {
+		m.Push(initial)
+	}
+	return m
+}
+
+// Top() returns the state at the top of the stack, or nil if it is empty.
+func (m *Machine) Top() State {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// Len() returns the number of states on the stack.
+func (m *Machine) Len() int {
+	return len(m.stack)
+}
+
+// Push() adds a state on top of the current one, which is paused.
+func (m *Machine) Push(s State) {
+	m.request(op{opPush, s})
+}
+
+// Pop() removes the top state and resumes the one beneath it.
+func (m *Machine) Pop() {
+	m.request(op{opPop, nil})
+}
+
+// Switch() replaces the top state with another.
+func (m *Machine) Switch(s State) {
+	m.request(op{opSwitch, s})
+}
+
+// Clear() removes every state.
+func (m *Machine) Clear() {
+	m.request(op{opClear, nil})
+}
+
+func (m *Machine) request(o op) {
+	m.pending = append(m.pending, o)
+	if !m.busy {
+		m.apply()
+	}
+}
+
+func (m *Machine) apply() {
+	for len(m.pending) > 0 {
+		o := m.pending[0]
+		m.pending = m.pending[1:]
+		switch o.kind {
+		case opPush:
+			if p, ok := m.Top().(Pauser); ok {
+				p.Pause()
+			}
+			m.stack = append(m.stack, o.state)
+			o.state.Enter(m)
+		case opPop:
+			if m.pop() {
+				if p, ok := m.Top().(Pauser); ok {
+					p.Resume()
+				}
+			}
+		case opSwitch:
+			m.pop()
+			m.stack = append(m.stack, o.state)
+			o.state.Enter(m)
+		case opClear:
+			for m.pop() {
+			}
+		}
+	}
+}
+
+func (m *Machine) pop() bool {
+	top := m.Top()
+	if top == nil {
+		return false
+	}
+	m.stack[len(m.stack)-1] = nil
+	m.stack = m.stack[:len(m.stack)-1]
+	top.Exit()
+	return true
+}
+
+// Update() updates the top state.
+func (m *Machine) Update(dt float64) {
+	if top := m.Top(); top != nil {
+		m.busy = true
+		top.Update(dt)
+		m.busy = false
+	}
+	m.apply()
+}
+
+// HandleEvent() passes an event to the top state.
+func (m *Machine) HandleEvent(ev interface{}) {
+	if top := m.Top(); top != nil {
+		m.busy = true
+		top.HandleEvent(ev)
+		m.busy = false
+	}
+	m.apply()
+}
+
+// Render() draws the top state, preceded by any states visible beneath it.
+func (m *Machine) Render() {
+	first := len(m.stack) - 1
+	for first > 0 {
+		if o, ok := m.stack[first].(Overlay); !ok || !o.IsOverlay() {
+			break
+		}
+		first--
+	}
+	if first < 0 {
+		return
+	}
+	m.busy = true
+	for _, s := range m.stack[first:] {
+		s.Render()
+	}
+	m.busy = false
+	m.apply()
+}
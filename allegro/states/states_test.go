@@ -0,0 +1,38 @@
+package states
+
+import (
+	"strings"
+	"testing"
+)
+
+type logState struct {
+	Base
+	name    string
+	log     *[]string
+	overlay bool
+}
+
+func (s *logState) Enter(m *Machine) { *s.log = append(*s.log, "enter "+s.name) }
+func (s *logState) Exit()            { *s.log = append(*s.log, "exit "+s.name) }
+func (s *logState) Pause()           { *s.log = append(*s.log, "pause "+s.name) }
+func (s *logState) Resume()          { *s.log = append(*s.log, "resume "+s.name) }
+func (s *logState) Render()          { *s.log = append(*s.log, "render "+s.name) }
+func (s *logState) IsOverlay() bool  { return s.overlay }
+
+func TestStack(t *testing.T) {
+	var log []string
+	game := &logState{name: "game", log: &log}
+	pause := &logState{name: "pause", log: &log, overlay: true}
+
+	m := New(game)
+	m.Push(pause)
+	m.Render()
+	m.Pop()
+	m.Switch(&logState{name: "menu", log: &log})
+
+	want := "enter game,pause game,enter pause,render game,render pause," +
+		"exit pause,resume game,exit game,enter menu"
+	if got := strings.Join(log, ","); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,285 @@
+package allegro
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var pixelFormatNames = map[PixelFormat]string{
+	PIXEL_FORMAT_ANY:                  "PIXEL_FORMAT_ANY",
+	PIXEL_FORMAT_ANY_NO_ALPHA:         "PIXEL_FORMAT_ANY_NO_ALPHA",
+	PIXEL_FORMAT_ANY_WITH_ALPHA:       "PIXEL_FORMAT_ANY_WITH_ALPHA",
+	PIXEL_FORMAT_ANY_15_NO_ALPHA:      "PIXEL_FORMAT_ANY_15_NO_ALPHA",
+	PIXEL_FORMAT_ANY_16_NO_ALPHA:      "PIXEL_FORMAT_ANY_16_NO_ALPHA",
+	PIXEL_FORMAT_ANY_16_WITH_ALPHA:    "PIXEL_FORMAT_ANY_16_WITH_ALPHA",
+	PIXEL_FORMAT_ANY_24_NO_ALPHA:      "PIXEL_FORMAT_ANY_24_NO_ALPHA",
+	PIXEL_FORMAT_ANY_32_NO_ALPHA:      "PIXEL_FORMAT_ANY_32_NO_ALPHA",
+	PIXEL_FORMAT_ANY_32_WITH_ALPHA:    "PIXEL_FORMAT_ANY_32_WITH_ALPHA",
+	PIXEL_FORMAT_ARGB_8888:            "PIXEL_FORMAT_ARGB_8888",
+	PIXEL_FORMAT_RGBA_8888:            "PIXEL_FORMAT_RGBA_8888",
+	PIXEL_FORMAT_ARGB_4444:            "PIXEL_FORMAT_ARGB_4444",
+	PIXEL_FORMAT_RGB_888:              "PIXEL_FORMAT_RGB_888",
+	PIXEL_FORMAT_RGB_565:              "PIXEL_FORMAT_RGB_565",
+	PIXEL_FORMAT_RGB_555:              "PIXEL_FORMAT_RGB_555",
+	PIXEL_FORMAT_RGBA_5551:            "PIXEL_FORMAT_RGBA_5551",
+	PIXEL_FORMAT_ARGB_1555:            "PIXEL_FORMAT_ARGB_1555",
+	PIXEL_FORMAT_ABGR_8888:            "PIXEL_FORMAT_ABGR_8888",
+	PIXEL_FORMAT_XBGR_8888:            "PIXEL_FORMAT_XBGR_8888",
+	PIXEL_FORMAT_BGR_888:              "PIXEL_FORMAT_BGR_888",
+	PIXEL_FORMAT_BGR_565:              "PIXEL_FORMAT_BGR_565",
+	PIXEL_FORMAT_BGR_555:              "PIXEL_FORMAT_BGR_555",
+	PIXEL_FORMAT_RGBX_8888:            "PIXEL_FORMAT_RGBX_8888",
+	PIXEL_FORMAT_XRGB_8888:            "PIXEL_FORMAT_XRGB_8888",
+	PIXEL_FORMAT_ABGR_F32:             "PIXEL_FORMAT_ABGR_F32",
+	PIXEL_FORMAT_ABGR_8888_LE:         "PIXEL_FORMAT_ABGR_8888_LE",
+	PIXEL_FORMAT_RGBA_4444:            "PIXEL_FORMAT_RGBA_4444",
+	PIXEL_FORMAT_SINGLE_CHANNEL_8:     "PIXEL_FORMAT_SINGLE_CHANNEL_8",
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT1: "PIXEL_FORMAT_COMPRESSED_RGBA_DXT1",
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT3: "PIXEL_FORMAT_COMPRESSED_RGBA_DXT3",
+	PIXEL_FORMAT_COMPRESSED_RGBA_DXT5: "PIXEL_FORMAT_COMPRESSED_RGBA_DXT5",
+}
+
+func (f PixelFormat) String() string {
+	if name, ok := pixelFormatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("PixelFormat(%d)", int(f))
+}
+
+func (op BlendingOperation) String() string {
+	switch op {
+	case ADD:
+		return "ADD"
+	case DEST_MINUS_SRC:
+		return "DEST_MINUS_SRC"
+	case SRC_MINUS_DEST:
+		return "SRC_MINUS_DEST"
+	}
+	return fmt.Sprintf("BlendingOperation(%d)", int(op))
+}
+
+var blendingValueNames = map[BlendingValue]string{
+	ZERO:                "ZERO",
+	ONE:                 "ONE",
+	ALPHA:               "ALPHA",
+	INVERSE_ALPHA:       "INVERSE_ALPHA",
+	SRC_COLOR:           "SRC_COLOR",
+	DEST_COLOR:          "DEST_COLOR",
+	INVERSE_SRC_COLOR:   "INVERSE_SRC_COLOR",
+	INVERSE_DEST_COLOR:  "INVERSE_DEST_COLOR",
+	CONST_COLOR:         "CONST_COLOR",
+	INVERSE_CONST_COLOR: "INVERSE_CONST_COLOR",
+}
+
+func (v BlendingValue) String() string {
+	if name, ok := blendingValueNames[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("BlendingValue(%d)", int(v))
+}
+
+var displayFlagNames = []struct {
+	flag DisplayFlags
+	name string
+}{
+	{WINDOWED, "WINDOWED"},
+	{FULLSCREEN, "FULLSCREEN"},
+	{FULLSCREEN_WINDOW, "FULLSCREEN_WINDOW"},
+	{RESIZABLE, "RESIZABLE"},
+	{OPENGL, "OPENGL"},
+	{OPENGL_3_0, "OPENGL_3_0"},
+	{OPENGL_FORWARD_COMPATIBLE, "OPENGL_FORWARD_COMPATIBLE"},
+	{FRAMELESS, "FRAMELESS"},
+	{MAXIMIZED, "MAXIMIZED"},
+	{GENERATE_EXPOSE_EVENTS, "GENERATE_EXPOSE_EVENTS"},
+	{PROGRAMMABLE_PIPELINE, "PROGRAMMABLE_PIPELINE"},
+}
+
+// String() lists the set flags separated by "|". NOFRAME is an alias of
+// FRAMELESS and is reported as such.
+func (flags DisplayFlags) String() string {
+	var names []string
+	for _, f := range displayFlagNames {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+			flags &^= f.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("0x%x", int(flags)))
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// DumpEvent() formats an event returned by the event queue methods for
+// logging, e.g. "KeyDownEvent{Display: 0xc000010000, KeyCode: ESCAPE,
+// Source: 0xc000020000, Timestamp: 12.5}". Every exported accessor of the
+// event is included; values that aren't events are formatted with %v.
+func DumpEvent(ev interface{}) string {
+	v := reflect.ValueOf(ev)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !strings.HasSuffix(v.Elem().Type().Name(), "_event") {
+		return fmt.Sprintf("%v", ev)
+	}
+	var buf strings.Builder
+	buf.WriteString(eventName(v.Elem().Type().Name()))
+	buf.WriteString("{")
+	t := v.Type()
+	n := 0
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Name == "String" {
+			continue
+		}
+		if n > 0 {
+			buf.WriteString(", ")
+		}
+		n++
+		out := v.Method(i).Call(nil)[0]
+		if out.Kind() == reflect.Ptr {
+			fmt.Fprintf(&buf, "%s: %p", m.Name, out.Interface())
+		} else {
+			fmt.Fprintf(&buf, "%s: %v", m.Name, out.Interface())
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// eventName() turns e.g. "key_down_event" into "KeyDownEvent".
+func eventName(typeName string) string {
+	var buf strings.Builder
+	for _, word := range strings.Split(typeName, "_") {
+		if word != "" {
+			buf.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return buf.String()
+}
+
+func (e *joystick_axis_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *joystick_button_down_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *joystick_button_up_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *joystick_configuration_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *key_down_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *key_up_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *key_char_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_axes_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_button_down_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_button_up_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_warped_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_enter_display_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *mouse_leave_display_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *timer_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_expose_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_resize_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_close_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_lost_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_found_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_switch_out_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_switch_in_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_orientation_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_halt_drawing_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *display_resume_drawing_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *touch_begin_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *touch_end_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *touch_move_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *touch_cancel_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *audio_stream_fragment_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *audio_stream_finished_event) String() string {
+	return DumpEvent(e)
+}
+
+func (e *user_event) String() string {
+	return DumpEvent(e)
+}
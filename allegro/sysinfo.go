@@ -0,0 +1,109 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+//
+// extern void go_assert_handler(char *expr, char *file, int line, char *func);
+// extern void go_trace_handler(char *msg);
+import "C"
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Returns the number of CPU cores that the system Allegro is running on has
+// and which could be detected, or -1 if the number could not be determined.
+func CPUCount() int {
+	return int(C.al_get_cpu_count())
+}
+
+// Returns the size in MB of the random access memory that the system Allegro
+// is running on has and which could be detected, or -1 if the amount could not
+// be determined.
+func RAMSize() int {
+	return int(C.al_get_ram_size())
+}
+
+var debugHandlers struct {
+	sync.Mutex
+	assert func(expr, file string, line int, function string)
+	trace  func(msg string)
+}
+
+//export go_assert_handler
+func go_assert_handler(expr, file *C.char, line C.int, function *C.char) {
+	debugHandlers.Lock()
+	f := debugHandlers.assert
+	debugHandlers.Unlock()
+	if f != nil {
+		f(C.GoString(expr), C.GoString(file), int(line), C.GoString(function))
+	}
+}
+
+//export go_trace_handler
+func go_trace_handler(msg *C.char) {
+	debugHandlers.Lock()
+	f := debugHandlers.trace
+	debugHandlers.Unlock()
+	if f != nil {
+		f(C.GoString(msg))
+	}
+}
+
+// Register a function to be called when an internal Allegro assertion fails,
+// in place of the default handler, which prints a message and aborts. If the
+// function returns, the program carries on past the failed assertion.
+// Assertions are only checked by debug builds of Allegro. The function is
+// called from C, so it must not panic. Pass nil to restore the default
+// handler.
+func SetAssertHandler(f func(expr, file string, line int, function string)) {
+	debugHandlers.Lock()
+	debugHandlers.assert = f
+	debugHandlers.Unlock()
+	if f == nil {
+		C.al_register_assert_handler(nil)
+	} else {
+		C.al_register_assert_handler((*[0]byte)(C.go_assert_handler))
+	}
+}
+
+// Register a function to be called with each line of Allegro's trace output,
+// in place of writing it to allegro.log. Which lines are traced depends on
+// the [trace] section of the system config; release builds of Allegro only
+// trace warnings and errors. The function must not panic. Pass nil to restore
+// the default handler.
+func SetTraceHandler(f func(msg string)) {
+	debugHandlers.Lock()
+	debugHandlers.trace = f
+	debugHandlers.Unlock()
+	if f == nil {
+		C.al_register_trace_handler(nil)
+	} else {
+		C.al_register_trace_handler((*[0]byte)(C.go_trace_handler))
+	}
+}
+
+// SetLogger() routes Allegro's trace output and failed assertions to logger,
+// so that they end up in the application's structured logs. Trace lines are
+// logged at slog.LevelDebug and assertions at slog.LevelError, with the
+// assertion's expression and location as attributes. Pass nil to restore
+// Allegro's default handlers.
+func SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		SetTraceHandler(nil)
+		SetAssertHandler(nil)
+		return
+	}
+	SetTraceHandler(func(msg string) {
+		logger.LogAttrs(context.Background(), slog.LevelDebug,
+			strings.TrimRight(msg, "\n"), slog.String("source", "allegro"))
+	})
+	SetAssertHandler(func(expr, file string, line int, function string) {
+		logger.LogAttrs(context.Background(), slog.LevelError, "allegro assertion failed",
+			slog.String("expr", expr),
+			slog.String("file", file),
+			slog.Int("line", line),
+			slog.String("func", function))
+	})
+}
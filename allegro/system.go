@@ -84,6 +84,7 @@ bool _al_init() {
 import "C"
 import (
 	"errors"
+	"fmt"
 )
 
 // Returns the (compiled) version of the Allegro library, packed into a single
@@ -142,6 +143,31 @@ func AppName() string {
 	return C.GoString(C.al_get_app_name())
 }
 
+type StandardPath int
+
+const (
+	RESOURCES_PATH      StandardPath = C.ALLEGRO_RESOURCES_PATH
+	TEMP_PATH                        = C.ALLEGRO_TEMP_PATH
+	USER_HOME_PATH                   = C.ALLEGRO_USER_HOME_PATH
+	USER_DOCUMENTS_PATH              = C.ALLEGRO_USER_DOCUMENTS_PATH
+	USER_DATA_PATH                   = C.ALLEGRO_USER_DATA_PATH
+	USER_SETTINGS_PATH               = C.ALLEGRO_USER_SETTINGS_PATH
+	EXENAME_PATH                     = C.ALLEGRO_EXENAME_PATH
+)
+
+// Gets a system path, depending on the id parameter. Paths ending in a
+// directory are returned with a trailing separator.
+func GetStandardPath(id StandardPath) (string, error) {
+	path := C.al_get_standard_path(C.int(id))
+	if path == nil {
+		return "", fmt.Errorf("failed to get standard path %d", id)
+	}
+	defer C.al_destroy_path(path)
+	return pathStr(path), nil
+}
+
+// install() initializes Allegro. Like al_init, it does nothing if Allegro is
+// already initialized.
 func install() error {
 	if !bool(C._al_init()) {
 		return errors.New("failed to initialize allegro!")
@@ -153,3 +179,16 @@ func install() error {
 func uninstall() {
 	C.al_uninstall_system()
 }
+
+// Returns true if Allegro is initialized, otherwise returns false.
+func IsSystemInstalled() bool {
+	return bool(C.al_is_system_installed())
+}
+
+// UninstallSystem() closes down the Allegro system. Run() does this when its
+// function returns; it is needed after InitHeadless(), or to tear Allegro down
+// early. Every object created through Allegro becomes invalid.
+func UninstallSystem() {
+	uninstall()
+	headless = false
+}
@@ -0,0 +1,165 @@
+package allegro
+
+// TextInput is an editable line of text driven by KeyCharEvent, for name entry
+// screens, in-game consoles and the like. It handles printable characters,
+// Backspace, Delete, Left, Right, Home and End, and Ctrl+C, Ctrl+X and
+// Ctrl+V (Command on OS X) to copy, cut and paste the whole text through the
+// clipboard of the display the event came from. Drawing is left to the
+// caller: draw Text() and place a caret at the width of Text()[:Cursor()].
+type TextInput struct {
+	// MaxLength limits the text to that many characters, if positive.
+	MaxLength int
+
+	// OnChange is called with the new text whenever it changes.
+	OnChange func(text string)
+
+	// OnSubmit is called with the text when Enter is pressed.
+	OnSubmit func(text string)
+
+	text   []rune
+	cursor int
+}
+
+// NewTextInput() creates an input holding text, with the cursor at its end.
+func NewTextInput(text string) *TextInput {
+	t := &TextInput{}
+	t.text = []rune(text)
+	t.cursor = len(t.text)
+	return t
+}
+
+// Text() returns the current text.
+func (t *TextInput) Text() string {
+	return string(t.text)
+}
+
+// SetText() replaces the text and moves the cursor to its end. OnChange is
+// not called.
+func (t *TextInput) SetText(text string) {
+	t.text = []rune(text)
+	t.clamp()
+	t.cursor = len(t.text)
+}
+
+// Cursor() returns the byte offset of the cursor within Text().
+func (t *TextInput) Cursor() int {
+	return len(string(t.text[:t.cursor]))
+}
+
+// CursorIndex() returns the cursor position in characters.
+func (t *TextInput) CursorIndex() int {
+	return t.cursor
+}
+
+// SetCursorIndex() moves the cursor to the given character position, which
+// is clamped to the text.
+func (t *TextInput) SetCursorIndex(i int) {
+	if i < 0 {
+		i = 0
+	} else if i > len(t.text) {
+		i = len(t.text)
+	}
+	t.cursor = i
+}
+
+// HandleEvent() updates the input if ev is a KeyCharEvent it understands and
+// returns whether it did.
+func (t *TextInput) HandleEvent(ev interface{}) bool {
+	e, ok := ev.(KeyCharEvent)
+	if !ok {
+		return false
+	}
+	mods := e.Modifiers()
+	shortcut := mods.Has(KEYMOD_CTRL) || mods.Has(KEYMOD_COMMAND)
+	// The clipboard belongs to a display, so without one the clipboard
+	// shortcuts are ignored like any other.
+	display := e.Display()
+	clipboard := shortcut && display != nil
+	switch {
+	case e.KeyCode() == KEY_BACKSPACE:
+		if t.cursor > 0 {
+			t.cursor--
+			t.remove(t.cursor)
+		}
+	case e.KeyCode() == KEY_DELETE:
+		if t.cursor < len(t.text) {
+			t.remove(t.cursor)
+		}
+	case e.KeyCode() == KEY_LEFT:
+		t.SetCursorIndex(t.cursor - 1)
+	case e.KeyCode() == KEY_RIGHT:
+		t.SetCursorIndex(t.cursor + 1)
+	case e.KeyCode() == KEY_HOME:
+		t.cursor = 0
+	case e.KeyCode() == KEY_END:
+		t.cursor = len(t.text)
+	case e.KeyCode() == KEY_ENTER || e.KeyCode() == KEY_PAD_ENTER:
+		if t.OnSubmit != nil {
+			t.OnSubmit(t.Text())
+		}
+	case clipboard && e.KeyCode() == KEY_C:
+		display.SetClipboardText(t.Text())
+	case clipboard && e.KeyCode() == KEY_X:
+		display.SetClipboardText(t.Text())
+		t.text, t.cursor = t.text[:0], 0
+		t.changed()
+	case clipboard && e.KeyCode() == KEY_V:
+		text, err := display.ClipboardText()
+		if err != nil {
+			return true
+		}
+		t.insert([]rune(text))
+	case shortcut || mods.Has(KEYMOD_ALT):
+		return false
+	case e.Unichar() >= ' ' && e.Unichar() != 0x7f:
+		t.insert([]rune{rune(e.Unichar())})
+	default:
+		return false
+	}
+	return true
+}
+
+// insert() inserts r at the cursor, dropping what doesn't fit in MaxLength,
+// as well as control characters such as the newlines of pasted text.
+func (t *TextInput) insert(r []rune) {
+	filtered := r[:0:0]
+	for _, c := range r {
+		if c >= ' ' && c != 0x7f {
+			filtered = append(filtered, c)
+		}
+	}
+	if t.MaxLength > 0 && len(t.text)+len(filtered) > t.MaxLength {
+		room := t.MaxLength - len(t.text)
+		if room < 0 {
+			room = 0
+		}
+		filtered = filtered[:room]
+	}
+	if len(filtered) == 0 {
+		return
+	}
+	text := make([]rune, 0, len(t.text)+len(filtered))
+	text = append(text, t.text[:t.cursor]...)
+	text = append(text, filtered...)
+	text = append(text, t.text[t.cursor:]...)
+	t.text = text
+	t.cursor += len(filtered)
+	t.changed()
+}
+
+func (t *TextInput) remove(i int) {
+	t.text = append(t.text[:i], t.text[i+1:]...)
+	t.changed()
+}
+
+func (t *TextInput) clamp() {
+	if t.MaxLength > 0 && len(t.text) > t.MaxLength {
+		t.text = t.text[:t.MaxLength]
+	}
+}
+
+func (t *TextInput) changed() {
+	if t.OnChange != nil {
+		t.OnChange(t.Text())
+	}
+}
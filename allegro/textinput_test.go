@@ -0,0 +1,49 @@
+package allegro
+
+import "testing"
+
+type fakeKeyChar struct {
+	keycode KeyCode
+	unichar int
+}
+
+func (e fakeKeyChar) key_char()              {}
+func (e fakeKeyChar) Timestamp() float64     { return 0 }
+func (e fakeKeyChar) Source() *Keyboard      { return nil }
+func (e fakeKeyChar) KeyCode() KeyCode       { return e.keycode }
+func (e fakeKeyChar) Unichar() int           { return e.unichar }
+func (e fakeKeyChar) Modifiers() KeyModifier { return 0 }
+func (e fakeKeyChar) Repeat() bool           { return false }
+func (e fakeKeyChar) Display() *Display      { return nil }
+
+func TestTextInput(t *testing.T) {
+	var changes int
+	in := NewTextInput("hé")
+	in.MaxLength = 4
+	in.OnChange = func(string) { changes++ }
+
+	for _, e := range []fakeKeyChar{
+		{KEY_HOME, 0},
+		{KEY_X, 'x'},
+		{KEY_RIGHT, 0},
+		{KEY_DELETE, 0},
+		{KEY_END, 0},
+		{KEY_Y, 'y'},
+		{KEY_Z, 'z'},
+		{KEY_A, 'a'}, // dropped by MaxLength
+		{KEY_LEFT, 0},
+		{KEY_BACKSPACE, 0},
+	} {
+		in.HandleEvent(e)
+	}
+
+	if got, want := in.Text(), "xhz"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got, want := in.CursorIndex(), 2; got != want {
+		t.Errorf("CursorIndex() = %d, want %d", got, want)
+	}
+	if got, want := changes, 5; got != want {
+		t.Errorf("OnChange called %d times, want %d", got, want)
+	}
+}
@@ -1,4 +1,6 @@
-// Package tiled implements support for the Allegro Tiled addon.
+// Package tiled implements support for the Allegro Tiled addon. Maps can also
+// be loaded without the addon through the tilemap package, which reads TMX
+// and JSON maps in Go.
 package tiled
 
 // #include <allegro5/allegro_tiled.h>
@@ -9,14 +11,12 @@ import (
 	"path/filepath"
 )
 
-type Map *C.ALLEGRO_MAP
+type Map C.ALLEGRO_MAP
 
 func OpenMap(filename string) (*Map, error) {
-	base := filepath.Base(filename)
-	base_ := C.CString(base)
+	base_ := C.CString(filepath.Base(filename))
 	defer C.free_string(base_)
-	dir := filename[:base]
-	dir_ := C.CString(dir)
+	dir_ := C.CString(filepath.Dir(filename))
 	defer C.free_string(dir_)
 	m := C.al_open_map(dir_, base_)
 	if m == nil {
@@ -35,26 +35,26 @@ func (m *Map) Height() int {
 	return int(C.al_get_map_height((*C.ALLEGRO_MAP)(m)))
 }
 
-type Tile *C.ALLEGRO_MAP_TILE
+type Tile C.ALLEGRO_MAP_TILE
 
-type (t *Tile) Prop(name, def string) string {
-    name_ := C.CString(name)
-    defer C.free_string(name_)
-    def_ := C.CString(def)
-    defer C.free_string(def_)
-    p := C.al_get_tile_property((*C.ALLEGRO_MAP_TILE)(t), name_, def_)
-    defer C.free_string(p)
-    return C.GoString(p)
+// Prop() returns the tile's property with the given name, or def if it has
+// none. The string is owned by the map, so it is copied rather than freed.
+func (t *Tile) Prop(name, def string) string {
+	name_ := C.CString(name)
+	defer C.free_string(name_)
+	def_ := C.CString(def)
+	defer C.free_string(def_)
+	return C.GoString(C.al_get_tile_property((*C.ALLEGRO_MAP_TILE)(t), name_, def_))
 }
 
-type Object *C.ALLEGRO_MAP_OBJECT
+type Object C.ALLEGRO_MAP_OBJECT
 
-type (o *Object) Prop(name, def string) string {
-    name_ := C.CString(name)
-    defer C.free_string(name_)
-    def_ := C.CString(def)
-    defer C.free_string(def_)
-    p := C.al_get_tile_property((*C.ALLEGRO_MAP_OBJECT)(o), name_, def_)
-    defer C.free_string(p)
-    return C.GoString(p)
+// Prop() returns the object's property with the given name, or def if it has
+// none.
+func (o *Object) Prop(name, def string) string {
+	name_ := C.CString(name)
+	defer C.free_string(name_)
+	def_ := C.CString(def)
+	defer C.free_string(def_)
+	return C.GoString(C.al_get_object_property((*C.ALLEGRO_MAP_OBJECT)(o), name_, def_))
 }
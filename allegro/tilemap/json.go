@@ -0,0 +1,303 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// This file reads Tiled's JSON map (.tmj) and tileset (.tsj) formats into the
+// same raw representation as their XML counterparts, so that both go through
+// the same loading code.
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID         uint32         `json:"firstgid"`
+	Source           string         `json:"source"`
+	Name             string         `json:"name"`
+	TileWidth        int            `json:"tilewidth"`
+	TileHeight       int            `json:"tileheight"`
+	Spacing          int            `json:"spacing"`
+	Margin           int            `json:"margin"`
+	TileCount        int            `json:"tilecount"`
+	Columns          int            `json:"columns"`
+	Image            string         `json:"image"`
+	ImageWidth       int            `json:"imagewidth"`
+	ImageHeight      int            `json:"imageheight"`
+	TransparentColor string         `json:"transparentcolor"`
+	Properties       []jsonProperty `json:"properties"`
+}
+
+type jsonPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float32        `json:"x"`
+	Y          float32        `json:"y"`
+	Width      float32        `json:"width"`
+	Height     float32        `json:"height"`
+	Rotation   float32        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Opacity     *float32        `json:"opacity"`
+	Visible     *bool           `json:"visible"`
+	OffsetX     float32         `json:"offsetx"`
+	OffsetY     float32         `json:"offsety"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"`
+	Properties  []jsonProperty  `json:"properties"`
+}
+
+type jsonMap struct {
+	Orientation string         `json:"orientation"`
+	Width       int            `json:"width"`
+	Height      int            `json:"height"`
+	TileWidth   int            `json:"tilewidth"`
+	TileHeight  int            `json:"tileheight"`
+	Infinite    bool           `json:"infinite"`
+	Properties  []jsonProperty `json:"properties"`
+	Tilesets    []jsonTileset  `json:"tilesets"`
+	Layers      []jsonLayer    `json:"layers"`
+}
+
+func isJSON(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json", ".tmj", ".tsj":
+		return true
+	}
+	return false
+}
+
+func readJSON(filename string, v interface{}) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse '%s': %s", filename, err.Error())
+	}
+	return nil
+}
+
+func readJSONMap(filename string, raw *xmlMap) error {
+	var jm jsonMap
+	if err := readJSON(filename, &jm); err != nil {
+		return err
+	}
+	if jm.Infinite {
+		return fmt.Errorf("'%s': infinite maps are not supported", filename)
+	}
+	*raw = xmlMap{
+		Orientation: jm.Orientation,
+		Width:       jm.Width,
+		Height:      jm.Height,
+		TileWidth:   jm.TileWidth,
+		TileHeight:  jm.TileHeight,
+		Properties:  jsonProperties(jm.Properties),
+	}
+	for _, jts := range jm.Tilesets {
+		raw.Tilesets = append(raw.Tilesets, jts.toXML())
+	}
+	if err := raw.addJSONLayers(jm.Layers, 0, 0, 1, true); err != nil {
+		return fmt.Errorf("'%s': %s", filename, err.Error())
+	}
+	return nil
+}
+
+// readJSONTileset() reads an external tileset. Like readXML() on a .tsx file,
+// it leaves the first global id of rts alone, since that belongs to the map.
+func readJSONTileset(filename string, rts *xmlTileset) error {
+	var jts jsonTileset
+	if err := readJSON(filename, &jts); err != nil {
+		return err
+	}
+	first := rts.FirstGID
+	*rts = jts.toXML()
+	rts.FirstGID = first
+	return nil
+}
+
+func (jts *jsonTileset) toXML() xmlTileset {
+	return xmlTileset{
+		FirstGID:   jts.FirstGID,
+		Source:     jts.Source,
+		Name:       jts.Name,
+		TileWidth:  jts.TileWidth,
+		TileHeight: jts.TileHeight,
+		Spacing:    jts.Spacing,
+		Margin:     jts.Margin,
+		TileCount:  jts.TileCount,
+		Columns:    jts.Columns,
+		Image: xmlImage{
+			Source: jts.Image,
+			Trans:  strings.TrimPrefix(jts.TransparentColor, "#"),
+			Width:  jts.ImageWidth,
+			Height: jts.ImageHeight,
+		},
+		Properties: jsonProperties(jts.Properties),
+	}
+}
+
+// addJSONLayers() appends the tile and object layers to the map, flattening
+// group layers: a group's offset, opacity and visibility are applied to the
+// layers inside it.
+func (raw *xmlMap) addJSONLayers(layers []jsonLayer, offsetX, offsetY, opacity float32, visible bool) error {
+	for _, jl := range layers {
+		lOpacity := opacity
+		if jl.Opacity != nil {
+			lOpacity *= *jl.Opacity
+		}
+		lVisible := visible && (jl.Visible == nil || *jl.Visible)
+		lOffsetX, lOffsetY := offsetX+jl.OffsetX, offsetY+jl.OffsetY
+
+		switch jl.Type {
+		case "tilelayer":
+			data, err := jl.xmlData()
+			if err != nil {
+				return fmt.Errorf("layer '%s': %s", jl.Name, err.Error())
+			}
+			raw.Layers = append(raw.Layers, xmlMapLayer{Layer: &xmlLayer{
+				Name:       jl.Name,
+				Width:      jl.Width,
+				Height:     jl.Height,
+				Opacity:    formatOpacity(lOpacity),
+				Visible:    formatVisible(lVisible),
+				OffsetX:    lOffsetX,
+				OffsetY:    lOffsetY,
+				Properties: jsonProperties(jl.Properties),
+				Data:       data,
+			}})
+
+		case "objectgroup":
+			g := xmlObjectGroup{
+				Name:       jl.Name,
+				Opacity:    formatOpacity(lOpacity),
+				Visible:    formatVisible(lVisible),
+				Properties: jsonProperties(jl.Properties),
+			}
+			for _, jo := range jl.Objects {
+				g.Objects = append(g.Objects, jo.toXML(lOffsetX, lOffsetY))
+			}
+			raw.Layers = append(raw.Layers, xmlMapLayer{ObjectGroup: &g})
+
+		case "group":
+			if err := raw.addJSONLayers(jl.Layers, lOffsetX, lOffsetY, lOpacity, lVisible); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// xmlData() converts the layer's data, which is either an array of global
+// tile ids or a base64 string, into the form used by the XML <data> element.
+func (jl *jsonLayer) xmlData() (xmlData, error) {
+	var s string
+	if err := json.Unmarshal(jl.Data, &s); err == nil {
+		return xmlData{
+			Encoding:    "base64",
+			Compression: jl.Compression,
+			Raw:         s,
+		}, nil
+	}
+	var gids []uint32
+	if err := json.Unmarshal(jl.Data, &gids); err != nil {
+		return xmlData{}, err
+	}
+	tiles := make([]xmlTile, len(gids))
+	for i, gid := range gids {
+		tiles[i].GID = gid
+	}
+	return xmlData{Tiles: tiles}, nil
+}
+
+func (jo *jsonObject) toXML(offsetX, offsetY float32) xmlObject {
+	ro := xmlObject{
+		ID:         jo.ID,
+		Name:       jo.Name,
+		Type:       jo.Type,
+		Class:      jo.Class,
+		X:          jo.X + offsetX,
+		Y:          jo.Y + offsetY,
+		Width:      jo.Width,
+		Height:     jo.Height,
+		Rotation:   jo.Rotation,
+		GID:        jo.GID,
+		Visible:    formatVisible(jo.Visible == nil || *jo.Visible),
+		Properties: jsonProperties(jo.Properties),
+	}
+	switch {
+	case jo.Ellipse:
+		ro.Ellipse = &struct{}{}
+	case jo.Point:
+		ro.Point = &struct{}{}
+	case jo.Polygon != nil:
+		ro.Polygon = &xmlPoints{formatPoints(jo.Polygon)}
+	case jo.Polyline != nil:
+		ro.Polyline = &xmlPoints{formatPoints(jo.Polyline)}
+	}
+	return ro
+}
+
+func jsonProperties(props []jsonProperty) []xmlProperty {
+	xps := make([]xmlProperty, len(props))
+	for i, p := range props {
+		xps[i].Name = p.Name
+		switch v := p.Value.(type) {
+		case nil:
+		case string:
+			xps[i].Value = v
+		default:
+			xps[i].Value = fmt.Sprint(v)
+		}
+	}
+	return xps
+}
+
+func formatOpacity(opacity float32) string {
+	return strconv.FormatFloat(float64(opacity), 'g', -1, 32)
+}
+
+func formatVisible(visible bool) string {
+	if visible {
+		return "1"
+	}
+	return "0"
+}
+
+func formatPoints(points []jsonPoint) string {
+	pairs := make([]string, len(points))
+	for i, p := range points {
+		pairs[i] = fmt.Sprintf("%g,%g", p.X, p.Y)
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,563 @@
+// Package tilemap loads maps created with the Tiled editor, saved either as
+// .tmx or in its JSON format (.tmj), with optional external tilesets, and
+// renders them using the primitives addon.
+//
+// Only orthogonal maps are supported. Both the image and primitives addons
+// must be installed before a map is loaded or drawn.
+package tilemap
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/primitives"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Bits used by Tiled to store flipping information in a global tile id.
+const (
+	FLIPPED_HORIZONTALLY uint32 = 0x80000000
+	FLIPPED_VERTICALLY          = 0x40000000
+	FLIPPED_DIAGONALLY          = 0x20000000
+
+	gidMask = ^(FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY | FLIPPED_DIAGONALLY)
+)
+
+var ErrUnsupportedOrientation = errors.New("only orthogonal maps are supported")
+
+// Map is a loaded Tiled map.
+type Map struct {
+	Width, Height         int // in tiles
+	TileWidth, TileHeight int // in pixels
+	Properties            map[string]string
+	Tilesets              []*Tileset
+	Layers                []*Layer
+	ObjectGroups          []*ObjectGroup
+
+	// Stack holds the same tile layers and object groups as Layers and
+	// ObjectGroups, each a *Layer or an *ObjectGroup, in the order they
+	// appear in the file, bottom first. It tells which tile layers objects
+	// should be drawn between.
+	Stack []interface{}
+}
+
+// Tileset is a single image divided into equally-sized tiles. All tiles of a
+// tileset are drawn from the same texture, so each tileset is drawn with a
+// single primitive call per layer.
+type Tileset struct {
+	FirstGID              uint32
+	Name                  string
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	TileCount, Columns    int
+	Properties            map[string]string
+	Image                 *allegro.Bitmap
+
+	tiles []*allegro.Bitmap
+}
+
+// Layer is a grid of tiles.
+type Layer struct {
+	Name             string
+	Width, Height    int
+	Opacity          float32
+	Visible          bool
+	OffsetX, OffsetY float32
+	Properties       map[string]string
+
+	m       *Map
+	gids    []uint32
+	batches [][]primitives.Vertex // indexed like Map.Tilesets
+}
+
+// ObjectShape describes the geometry of an Object.
+type ObjectShape int
+
+const (
+	SHAPE_RECTANGLE ObjectShape = iota
+	SHAPE_ELLIPSE
+	SHAPE_POINT
+	SHAPE_POLYGON
+	SHAPE_POLYLINE
+	SHAPE_TILE
+)
+
+// Object is a single entry in an object layer, typically used to mark spawn
+// points, triggers and collision shapes.
+type Object struct {
+	ID                  int
+	Name, Type          string
+	X, Y, Width, Height float32
+	Rotation            float32
+	GID                 uint32
+	Visible             bool
+	Shape               ObjectShape
+	Points              []primitives.Point // relative to X, Y for polygons and polylines
+	Properties          map[string]string
+}
+
+// ObjectGroup is an object layer.
+type ObjectGroup struct {
+	Name       string
+	Opacity    float32
+	Visible    bool
+	Properties map[string]string
+	Objects    []*Object
+}
+
+// Load() reads a .tmx file, or a map in Tiled's JSON format if the name ends
+// in .tmj or .json, along with any external tilesets (.tsx, .tsj or .json)
+// and tileset images it references. Paths are resolved relative to the file
+// that references them.
+func Load(filename string) (*Map, error) {
+	var raw xmlMap
+	var err error
+	if isJSON(filename) {
+		err = readJSONMap(filename, &raw)
+	} else {
+		err = readXML(filename, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw.Orientation != "orthogonal" {
+		return nil, ErrUnsupportedOrientation
+	}
+
+	m := Map{
+		Width:      raw.Width,
+		Height:     raw.Height,
+		TileWidth:  raw.TileWidth,
+		TileHeight: raw.TileHeight,
+		Properties: properties(raw.Properties),
+	}
+	dir := filepath.Dir(filename)
+
+	for _, rts := range raw.Tilesets {
+		ts, err := loadTileset(rts, dir)
+		if err != nil {
+			m.Destroy()
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool {
+		return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID
+	})
+
+	for _, rml := range raw.Layers {
+		switch {
+		case rml.Layer != nil:
+			l, err := newLayer(&m, rml.Layer)
+			if err != nil {
+				m.Destroy()
+				return nil, err
+			}
+			m.Layers = append(m.Layers, l)
+			m.Stack = append(m.Stack, l)
+		case rml.ObjectGroup != nil:
+			g, err := newObjectGroup(rml.ObjectGroup)
+			if err != nil {
+				m.Destroy()
+				return nil, err
+			}
+			m.ObjectGroups = append(m.ObjectGroups, g)
+			m.Stack = append(m.Stack, g)
+		}
+	}
+
+	return &m, nil
+}
+
+func newLayer(m *Map, rl *xmlLayer) (*Layer, error) {
+	gids, err := rl.Data.decode(rl.Width * rl.Height)
+	if err != nil {
+		return nil, fmt.Errorf("layer '%s': %s", rl.Name, err.Error())
+	}
+	return &Layer{
+		Name:       rl.Name,
+		Width:      rl.Width,
+		Height:     rl.Height,
+		Opacity:    parseOpacity(rl.Opacity),
+		Visible:    parseVisible(rl.Visible),
+		OffsetX:    rl.OffsetX,
+		OffsetY:    rl.OffsetY,
+		Properties: properties(rl.Properties),
+		m:          m,
+		gids:       gids,
+	}, nil
+}
+
+func newObjectGroup(rg *xmlObjectGroup) (*ObjectGroup, error) {
+	g := ObjectGroup{
+		Name:       rg.Name,
+		Opacity:    parseOpacity(rg.Opacity),
+		Visible:    parseVisible(rg.Visible),
+		Properties: properties(rg.Properties),
+	}
+	for _, ro := range rg.Objects {
+		ob, err := newObject(ro)
+		if err != nil {
+			return nil, fmt.Errorf("object group '%s': %s", rg.Name, err.Error())
+		}
+		g.Objects = append(g.Objects, ob)
+	}
+	return &g, nil
+}
+
+func loadTileset(rts xmlTileset, dir string) (*Tileset, error) {
+	if rts.Source != "" {
+		first := rts.FirstGID
+		path := filepath.Join(dir, rts.Source)
+		var err error
+		if isJSON(path) {
+			err = readJSONTileset(path, &rts)
+		} else {
+			err = readXML(path, &rts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		rts.FirstGID = first
+		dir = filepath.Dir(path)
+	}
+	if rts.Image.Source == "" {
+		return nil, fmt.Errorf("tileset '%s' has no image; image collection tilesets are not supported", rts.Name)
+	}
+
+	img, err := allegro.LoadBitmap(filepath.Join(dir, rts.Image.Source))
+	if err != nil {
+		return nil, err
+	}
+	if len(rts.Image.Trans) == 6 {
+		if v, err := strconv.ParseUint(rts.Image.Trans, 16, 32); err == nil {
+			img.ConvertMaskToAlpha(allegro.MapRGB(byte(v>>16), byte(v>>8), byte(v)))
+		}
+	}
+
+	ts := Tileset{
+		FirstGID:   rts.FirstGID,
+		Name:       rts.Name,
+		TileWidth:  rts.TileWidth,
+		TileHeight: rts.TileHeight,
+		Spacing:    rts.Spacing,
+		Margin:     rts.Margin,
+		TileCount:  rts.TileCount,
+		Columns:    rts.Columns,
+		Properties: properties(rts.Properties),
+		Image:      img,
+	}
+	if ts.Columns == 0 && ts.TileWidth > 0 {
+		ts.Columns = (img.Width() - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	return &ts, nil
+}
+
+func newObject(ro xmlObject) (*Object, error) {
+	ob := Object{
+		ID:         ro.ID,
+		Name:       ro.Name,
+		Type:       ro.Type,
+		X:          ro.X,
+		Y:          ro.Y,
+		Width:      ro.Width,
+		Height:     ro.Height,
+		Rotation:   ro.Rotation,
+		GID:        ro.GID,
+		Visible:    parseVisible(ro.Visible),
+		Properties: properties(ro.Properties),
+	}
+	if ob.Type == "" {
+		ob.Type = ro.Class
+	}
+	var err error
+	switch {
+	case ro.GID != 0:
+		ob.Shape = SHAPE_TILE
+	case ro.Ellipse != nil:
+		ob.Shape = SHAPE_ELLIPSE
+	case ro.Point != nil:
+		ob.Shape = SHAPE_POINT
+	case ro.Polygon != nil:
+		ob.Shape = SHAPE_POLYGON
+		ob.Points, err = parsePoints(ro.Polygon.Points)
+	case ro.Polyline != nil:
+		ob.Shape = SHAPE_POLYLINE
+		ob.Points, err = parsePoints(ro.Polyline.Points)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ob, nil
+}
+
+// Destroy() frees the tileset bitmaps owned by the map, including the
+// sub-bitmaps returned by Tile().
+func (m *Map) Destroy() {
+	for _, ts := range m.Tilesets {
+		for _, tile := range ts.tiles {
+			if tile != nil {
+				tile.Destroy()
+			}
+		}
+		ts.tiles = nil
+		if ts.Image != nil {
+			ts.Image.Destroy()
+			ts.Image = nil
+		}
+	}
+}
+
+// PixelWidth() returns the width of the map in pixels.
+func (m *Map) PixelWidth() int {
+	return m.Width * m.TileWidth
+}
+
+// PixelHeight() returns the height of the map in pixels.
+func (m *Map) PixelHeight() int {
+	return m.Height * m.TileHeight
+}
+
+// Layer() returns the tile layer with the given name, or nil if there is none.
+func (m *Map) Layer(name string) *Layer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// ObjectGroup() returns the object layer with the given name, or nil if there
+// is none.
+func (m *Map) ObjectGroup(name string) *ObjectGroup {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Tileset() returns the tileset that contains the given global tile id, or
+// nil if the id is empty or out of range.
+func (m *Map) Tileset(gid uint32) *Tileset {
+	if i := m.tilesetIndex(gid); i >= 0 {
+		return m.Tilesets[i]
+	}
+	return nil
+}
+
+// tilesetIndex() is like Tileset(), but returns the tileset's index in
+// m.Tilesets, or -1.
+func (m *Map) tilesetIndex(gid uint32) int {
+	gid &= gidMask
+	if gid == 0 {
+		return -1
+	}
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		if m.Tilesets[i].FirstGID <= gid {
+			return i
+		}
+	}
+	return -1
+}
+
+// Tile() returns a sub-bitmap of the tileset image for the given global tile
+// id, e.g. to draw a tile object or a single tile elsewhere, or nil if the id
+// is empty or out of range. Flip bits are ignored; see Tileset.Tile().
+func (m *Map) Tile(gid uint32) *allegro.Bitmap {
+	ts := m.Tileset(gid)
+	if ts == nil {
+		return nil
+	}
+	return ts.Tile(int((gid & gidMask) - ts.FirstGID))
+}
+
+// Tile() returns a sub-bitmap for the tile with the given local id, or nil if
+// it is out of range. Sub-bitmaps are created on first use and owned by the
+// map, which destroys them along with the tileset image.
+func (ts *Tileset) Tile(id int) *allegro.Bitmap {
+	count := ts.tileCount()
+	if id < 0 || id >= count {
+		return nil
+	}
+	if ts.tiles == nil {
+		ts.tiles = make([]*allegro.Bitmap, count)
+	}
+	if ts.tiles[id] == nil {
+		x := ts.Margin + (id%ts.Columns)*(ts.TileWidth+ts.Spacing)
+		y := ts.Margin + (id/ts.Columns)*(ts.TileHeight+ts.Spacing)
+		tile, err := ts.Image.CreateSubBitmap(x, y, ts.TileWidth, ts.TileHeight)
+		if err != nil {
+			return nil
+		}
+		ts.tiles[id] = tile
+	}
+	return ts.tiles[id]
+}
+
+// Draw() draws every visible tile layer, in order. The view rectangle is the
+// region of the map, in pixels, that is currently visible; tiles outside of
+// it are skipped.
+func (m *Map) Draw(viewX, viewY, viewW, viewH float32) {
+	for _, l := range m.Layers {
+		if l.Visible {
+			l.Draw(viewX, viewY, viewW, viewH)
+		}
+	}
+}
+
+// GID() returns the raw global tile id at the given tile position, including
+// flip bits. Positions outside of the layer return 0.
+func (l *Layer) GID(x, y int) uint32 {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+	return l.gids[y*l.Width+x]
+}
+
+// SetGID() changes the global tile id at the given tile position.
+func (l *Layer) SetGID(x, y int, gid uint32) {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return
+	}
+	l.gids[y*l.Width+x] = gid
+}
+
+// Draw() draws the tiles of the layer that intersect the view rectangle,
+// issuing one primitive call per tileset.
+func (l *Layer) Draw(viewX, viewY, viewW, viewH float32) {
+	m := l.m
+	if m.TileWidth == 0 || m.TileHeight == 0 {
+		return
+	}
+	tw, th := float32(m.TileWidth), float32(m.TileHeight)
+
+	// Expand the range by a tile on each side to allow for oversized tiles.
+	x0 := int((viewX-l.OffsetX)/tw) - 1
+	y0 := int((viewY-l.OffsetY)/th) - 1
+	x1 := int((viewX-l.OffsetX+viewW)/tw) + 1
+	y1 := int((viewY-l.OffsetY+viewH)/th) + 1
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 >= l.Width {
+		x1 = l.Width - 1
+	}
+	if y1 >= l.Height {
+		y1 = l.Height - 1
+	}
+
+	if len(l.batches) != len(m.Tilesets) {
+		l.batches = make([][]primitives.Vertex, len(m.Tilesets))
+	}
+	for i := range l.batches {
+		l.batches[i] = l.batches[i][:0]
+	}
+
+	tint := allegro.MapRGBAf(l.Opacity, l.Opacity, l.Opacity, l.Opacity)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			gid := l.gids[y*l.Width+x]
+			i := m.tilesetIndex(gid)
+			if i < 0 || m.Tilesets[i].Columns == 0 {
+				continue
+			}
+			ts := m.Tilesets[i]
+			dx := l.OffsetX + float32(x)*tw
+			dy := l.OffsetY + float32(y+1)*th - float32(ts.TileHeight)
+			l.batches[i] = ts.appendQuad(l.batches[i], gid, dx, dy, tint)
+		}
+	}
+
+	// Tilesets are drawn in order of their first global id, so that where
+	// tiles from different tilesets overlap, the result is the same every
+	// frame.
+	for i, vertices := range l.batches {
+		if len(vertices) > 0 {
+			primitives.DrawPrim(vertices, nil, m.Tilesets[i].Image, 0, len(vertices), primitives.PRIM_TRIANGLE_LIST)
+		}
+	}
+}
+
+// flipCorners() reorders the top-left, top-right, bottom-right and
+// bottom-left corners of a tile as its flip flags ask for.
+func flipCorners(gid uint32, c [4][2]float32) [4][2]float32 {
+	if gid&FLIPPED_DIAGONALLY != 0 {
+		c[1], c[3] = c[3], c[1]
+	}
+	if gid&FLIPPED_HORIZONTALLY != 0 {
+		c[0], c[1] = c[1], c[0]
+		c[2], c[3] = c[3], c[2]
+	}
+	if gid&FLIPPED_VERTICALLY != 0 {
+		c[0], c[3] = c[3], c[0]
+		c[1], c[2] = c[2], c[1]
+	}
+	return c
+}
+
+// tileCount() returns the number of tiles in the tileset, working it out from
+// the image if the tileset doesn't say, or 0 if it has no image.
+func (ts *Tileset) tileCount() int {
+	if ts.Image == nil || ts.Columns == 0 || ts.TileHeight == 0 {
+		return 0
+	}
+	if ts.TileCount > 0 {
+		return ts.TileCount
+	}
+	rows := (ts.Image.Height() - 2*ts.Margin + ts.Spacing) / (ts.TileHeight + ts.Spacing)
+	return rows * ts.Columns
+}
+
+// appendQuad() adds the two triangles needed to draw a tile to the vertex
+// buffer, applying any flip flags present in the global tile id. Tiles past
+// the end of the tileset are skipped, like in Tile().
+func (ts *Tileset) appendQuad(buf []primitives.Vertex, gid uint32, dx, dy float32, tint allegro.Color) []primitives.Vertex {
+	local := int((gid & gidMask) - ts.FirstGID)
+	if local < 0 || local >= ts.tileCount() {
+		return buf
+	}
+	sx := float32(ts.Margin + (local%ts.Columns)*(ts.TileWidth+ts.Spacing))
+	sy := float32(ts.Margin + (local/ts.Columns)*(ts.TileHeight+ts.Spacing))
+	w, h := float32(ts.TileWidth), float32(ts.TileHeight)
+
+	// Texture coordinates for the top-left, top-right, bottom-right and
+	// bottom-left corners of the tile.
+	uv := flipCorners(gid, [4][2]float32{{sx, sy}, {sx + w, sy}, {sx + w, sy + h}, {sx, sy + h}})
+
+	tl := primitives.Vertex{X: dx, Y: dy, Color: tint, U: uv[0][0], V: uv[0][1]}
+	tr := primitives.Vertex{X: dx + w, Y: dy, Color: tint, U: uv[1][0], V: uv[1][1]}
+	br := primitives.Vertex{X: dx + w, Y: dy + h, Color: tint, U: uv[2][0], V: uv[2][1]}
+	bl := primitives.Vertex{X: dx, Y: dy + h, Color: tint, U: uv[3][0], V: uv[3][1]}
+	return append(buf, tl, tr, br, tl, br, bl)
+}
+
+// ObjectsOfType() returns all objects in the group with the given type (or
+// class, for maps saved by newer versions of Tiled).
+func (g *ObjectGroup) ObjectsOfType(typ string) []*Object {
+	var obs []*Object
+	for _, ob := range g.Objects {
+		if ob.Type == typ {
+			obs = append(obs, ob)
+		}
+	}
+	return obs
+}
+
+// Object() returns the first object in the group with the given name, or nil
+// if there is none.
+func (g *ObjectGroup) Object(name string) *Object {
+	for _, ob := range g.Objects {
+		if ob.Name == name {
+			return ob
+		}
+	}
+	return nil
+}
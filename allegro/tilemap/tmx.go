@@ -0,0 +1,251 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"github.com/ccollins476ad/go-allegro/allegro/primitives"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// This file contains the raw XML representation of TMX and TSX files.
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlImage struct {
+	Source string `xml:"source,attr"`
+	Trans  string `xml:"trans,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type xmlTileset struct {
+	FirstGID   uint32        `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	Image      xmlImage      `xml:"image"`
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+type xmlTile struct {
+	GID uint32 `xml:"gid,attr"`
+}
+
+type xmlData struct {
+	Encoding    string    `xml:"encoding,attr"`
+	Compression string    `xml:"compression,attr"`
+	Raw         string    `xml:",chardata"`
+	Tiles       []xmlTile `xml:"tile"`
+}
+
+type xmlLayer struct {
+	Name       string        `xml:"name,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Opacity    string        `xml:"opacity,attr"`
+	Visible    string        `xml:"visible,attr"`
+	OffsetX    float32       `xml:"offsetx,attr"`
+	OffsetY    float32       `xml:"offsety,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Data       xmlData       `xml:"data"`
+}
+
+type xmlPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+type xmlObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float32       `xml:"x,attr"`
+	Y          float32       `xml:"y,attr"`
+	Width      float32       `xml:"width,attr"`
+	Height     float32       `xml:"height,attr"`
+	Rotation   float32       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *xmlPoints    `xml:"polygon"`
+	Polyline   *xmlPoints    `xml:"polyline"`
+}
+
+type xmlObjectGroup struct {
+	Name       string        `xml:"name,attr"`
+	Opacity    string        `xml:"opacity,attr"`
+	Visible    string        `xml:"visible,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+	Objects    []xmlObject   `xml:"object"`
+}
+
+// xmlMapLayer is a tile layer or an object group. They are collected into a
+// single slice so that the order in which they appear in the file, which is
+// the order they are stacked in, is kept.
+type xmlMapLayer struct {
+	Layer       *xmlLayer
+	ObjectGroup *xmlObjectGroup
+}
+
+func (ml *xmlMapLayer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	switch start.Name.Local {
+	case "layer":
+		ml.Layer = new(xmlLayer)
+		return d.DecodeElement(ml.Layer, &start)
+	case "objectgroup":
+		ml.ObjectGroup = new(xmlObjectGroup)
+		return d.DecodeElement(ml.ObjectGroup, &start)
+	}
+	return d.Skip()
+}
+
+type xmlMap struct {
+	Orientation string        `xml:"orientation,attr"`
+	Width       int           `xml:"width,attr"`
+	Height      int           `xml:"height,attr"`
+	TileWidth   int           `xml:"tilewidth,attr"`
+	TileHeight  int           `xml:"tileheight,attr"`
+	Properties  []xmlProperty `xml:"properties>property"`
+	Tilesets    []xmlTileset  `xml:"tileset"`
+	Layers      []xmlMapLayer `xml:",any"`
+}
+
+func readXML(filename string, v interface{}) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse '%s': %s", filename, err.Error())
+	}
+	return nil
+}
+
+func properties(props []xmlProperty) map[string]string {
+	m := make(map[string]string, len(props))
+	for _, p := range props {
+		m[p.Name] = p.Value
+	}
+	return m
+}
+
+func parseOpacity(s string) float32 {
+	if s == "" {
+		return 1
+	}
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return 1
+	}
+	return float32(v)
+}
+
+func parseVisible(s string) bool {
+	return s != "0"
+}
+
+func parsePoints(s string) ([]primitives.Point, error) {
+	var points []primitives.Point
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("invalid point '%s'", pair)
+		}
+		x, err := strconv.ParseFloat(xy[0], 32)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 32)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, primitives.Point{X: float32(x), Y: float32(y)})
+	}
+	return points, nil
+}
+
+// decode() converts a layer's <data> element into a slice of global tile
+// ids, handling each of the encodings that Tiled can produce.
+func (d *xmlData) decode(count int) ([]uint32, error) {
+	switch d.Encoding {
+	case "":
+		if len(d.Tiles) != count {
+			return nil, fmt.Errorf("expected %d tiles, found %d", count, len(d.Tiles))
+		}
+		gids := make([]uint32, count)
+		for i, t := range d.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+
+	case "csv":
+		fields := strings.Split(strings.TrimSpace(d.Raw), ",")
+		if len(fields) != count {
+			return nil, fmt.Errorf("expected %d tiles, found %d", count, len(fields))
+		}
+		gids := make([]uint32, count)
+		for i, f := range fields {
+			v, err := strconv.ParseUint(strings.TrimSpace(f), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids[i] = uint32(v)
+		}
+		return gids, nil
+
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(d.Raw))
+		if err != nil {
+			return nil, err
+		}
+		switch d.Compression {
+		case "":
+		case "zlib":
+			r, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				return nil, err
+			}
+			if raw, err = ioutil.ReadAll(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			r, err := gzip.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				return nil, err
+			}
+			if raw, err = ioutil.ReadAll(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported layer compression '%s'", d.Compression)
+		}
+		if len(raw) != count*4 {
+			return nil, fmt.Errorf("expected %d bytes of tile data, found %d", count*4, len(raw))
+		}
+		gids := make([]uint32, count)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	}
+	return nil, fmt.Errorf("unsupported layer encoding '%s'", d.Encoding)
+}
@@ -0,0 +1,106 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+)
+
+var testGIDs = []uint32{0, 1, 2, FLIPPED_HORIZONTALLY | 3}
+
+// encodeGIDs() produces base64 tile data the way Tiled writes it, compressed
+// with w if it isn't nil.
+func encodeGIDs(t *testing.T, gids []uint32, w func(io.Writer) io.WriteCloser) string {
+	var raw bytes.Buffer
+	for _, gid := range gids {
+		binary.Write(&raw, binary.LittleEndian, gid)
+	}
+	if w != nil {
+		var buf bytes.Buffer
+		zw := w(&buf)
+		if _, err := zw.Write(raw.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		raw = buf
+	}
+	return base64.StdEncoding.EncodeToString(raw.Bytes())
+}
+
+func TestDecode(t *testing.T) {
+	gz := func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	zl := func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	tests := []struct {
+		name string
+		data xmlData
+	}{
+		{"xml", xmlData{Tiles: []xmlTile{{0}, {1}, {2}, {FLIPPED_HORIZONTALLY | 3}}}},
+		{"csv", xmlData{Encoding: "csv", Raw: "\n0,1,2,\n2147483651\n"}},
+		{"base64", xmlData{Encoding: "base64", Raw: encodeGIDs(t, testGIDs, nil)}},
+		{"zlib", xmlData{Encoding: "base64", Compression: "zlib", Raw: encodeGIDs(t, testGIDs, zl)}},
+		{"gzip", xmlData{Encoding: "base64", Compression: "gzip", Raw: "\n  " + encodeGIDs(t, testGIDs, gz) + "\n"}},
+	}
+	for _, tt := range tests {
+		gids, err := tt.data.decode(len(testGIDs))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(gids, testGIDs) {
+			t.Errorf("%s: decoded %v, want %v", tt.name, gids, testGIDs)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data xmlData
+	}{
+		{"xml count", xmlData{Tiles: []xmlTile{{1}}}},
+		{"csv count", xmlData{Encoding: "csv", Raw: "1,2"}},
+		{"csv value", xmlData{Encoding: "csv", Raw: "1,2,x,4"}},
+		{"base64 length", xmlData{Encoding: "base64", Raw: encodeGIDs(t, testGIDs[:3], nil)}},
+		{"base64 corrupt", xmlData{Encoding: "base64", Raw: "!!!"}},
+		{"zlib corrupt", xmlData{Encoding: "base64", Compression: "zlib", Raw: encodeGIDs(t, testGIDs, nil)}},
+		{"compression", xmlData{Encoding: "base64", Compression: "zstd", Raw: encodeGIDs(t, testGIDs, nil)}},
+		{"encoding", xmlData{Encoding: "hex"}},
+	}
+	for _, tt := range tests {
+		if gids, err := tt.data.decode(len(testGIDs)); err == nil {
+			t.Errorf("%s: decoded %v, want an error", tt.name, gids)
+		}
+	}
+}
+
+func TestFlipFlags(t *testing.T) {
+	m := &Map{Tilesets: []*Tileset{{FirstGID: 1}, {FirstGID: 10}}}
+	corners := [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	tests := []struct {
+		gid     uint32
+		tileset int
+		corners [4][2]float32
+	}{
+		{3, 0, corners},
+		{FLIPPED_HORIZONTALLY | 3, 0, [4][2]float32{{1, 0}, {0, 0}, {0, 1}, {1, 1}}},
+		{FLIPPED_VERTICALLY | 12, 1, [4][2]float32{{0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+		{FLIPPED_DIAGONALLY | 12, 1, [4][2]float32{{0, 0}, {0, 1}, {1, 1}, {1, 0}}},
+		{FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY | 9, 0, [4][2]float32{{1, 1}, {0, 1}, {0, 0}, {1, 0}}},
+		{FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY | FLIPPED_DIAGONALLY, -1, [4][2]float32{{1, 1}, {1, 0}, {0, 0}, {0, 1}}},
+	}
+	for _, tt := range tests {
+		if i := m.tilesetIndex(tt.gid); i != tt.tileset {
+			t.Errorf("tilesetIndex(%#x) = %d, want %d", tt.gid, i, tt.tileset)
+		}
+		if c := flipCorners(tt.gid, corners); c != tt.corners {
+			t.Errorf("flipCorners(%#x) = %v, want %v", tt.gid, c, tt.corners)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package allegro
 
 // #include <allegro5/allegro.h>
 import "C"
+import (
+	"time"
+)
 
 type Timeout C.ALLEGRO_TIMEOUT
 
@@ -12,6 +15,22 @@ func NewTimeout(seconds float64) *Timeout {
 	return (*Timeout)(&timeout)
 }
 
+// NewTimeoutDuration() is like NewTimeout(), but takes a time.Duration.
+func NewTimeoutDuration(d time.Duration) *Timeout {
+	return NewTimeout(d.Seconds())
+}
+
+// NewTimeoutAt() returns a timeout that expires when Time() reaches t, e.g.
+// the time the next frame is due. A t in the past gives a timeout that has
+// already expired.
+func NewTimeoutAt(t float64) *Timeout {
+	secs := t - Time()
+	if secs < 0 {
+		secs = 0
+	}
+	return NewTimeout(secs)
+}
+
 // Waits for the specified number seconds. This tells the system to pause the
 // current thread for the given amount of time. With some operating systems,
 // the accuracy can be in the order of 10ms. That is, even
@@ -19,6 +38,11 @@ func Rest(seconds float64) {
 	C.al_rest(C.double(seconds))
 }
 
+// RestDuration() is like Rest(), but takes a time.Duration.
+func RestDuration(d time.Duration) {
+	Rest(d.Seconds())
+}
+
 // Return the number of seconds since the Allegro library was initialised. The
 // return value is undefined if Allegro is uninitialised. The resolution
 // depends on the used driver, but typically can be in the order of
@@ -4,6 +4,8 @@ package allegro
 import "C"
 import (
 	"errors"
+	"time"
+	"unsafe"
 )
 
 type Timer C.ALLEGRO_TIMER
@@ -12,19 +14,47 @@ type Timer C.ALLEGRO_TIMER
 // object is returned, otherwise NULL is returned. speed_secs is in seconds per
 // "tick", and must be positive. The new timer is initially stopped.
 func CreateTimer(speed float64) (*Timer, error) {
-	t := C.al_create_timer(C.double(speed))
-	if t == nil {
-		return nil, errors.New("failed to create timer")
+	var t *C.ALLEGRO_TIMER
+	err := checkErrno("al_create_timer", "", func() bool {
+		t = C.al_create_timer(C.double(speed))
+		return t != nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	timer := (*Timer)(t)
-	//runtime.SetFinalizer(timer, timer.Destroy)
+	trackResource(unsafe.Pointer(timer), "timer")
 	return timer, nil
 }
 
+// CreateTimerDuration() is like CreateTimer(), but takes the time per tick
+// as a time.Duration.
+func CreateTimerDuration(d time.Duration) (*Timer, error) {
+	if d <= 0 {
+		return nil, errors.New("timer period must be positive")
+	}
+	return CreateTimer(d.Seconds())
+}
+
+// CreateTimerFPS() creates a timer that ticks fps times per second.
+func CreateTimerFPS(fps float64) (*Timer, error) {
+	if fps <= 0 {
+		return nil, errors.New("timer rate must be positive")
+	}
+	return CreateTimer(1 / fps)
+}
+
 // Uninstall the timer specified. If the timer is started, it will
 // automatically be stopped before uninstallation. It will also automatically
 // unregister the timer with any event queues.
 func (t *Timer) Destroy() {
+	if releaseResource(unsafe.Pointer(t), "timer") == nil {
+		t.destroy()
+	}
+}
+
+func (t *Timer) destroy() {
+	forgetEventSource(t.EventSource())
 	C.al_destroy_timer((*C.ALLEGRO_TIMER)(t))
 }
 
@@ -42,6 +72,15 @@ func (t *Timer) Stop() {
 	C.al_stop_timer((*C.ALLEGRO_TIMER)(t))
 }
 
+// Resume the timer specified. From then, the timer's counter will increment
+// at a constant rate, and it will begin generating events. Resuming a timer
+// that is already started does nothing. Resuming a stopped timer that hasn't
+// ever been started before will restart the counter (effectively the same as
+// Start()).
+func (t *Timer) Resume() {
+	C.al_resume_timer((*C.ALLEGRO_TIMER)(t))
+}
+
 // Return true if the timer specified is currently started.
 func (t *Timer) IsStarted() bool {
 	return bool(C.al_get_timer_started((*C.ALLEGRO_TIMER)(t)))
@@ -61,6 +100,22 @@ func (t *Timer) SetSpeed(speed float64) {
 	C.al_set_timer_speed((*C.ALLEGRO_TIMER)(t), C.double(speed))
 }
 
+// Interval() returns the timer's speed as a time.Duration.
+func (t *Timer) Interval() time.Duration {
+	return time.Duration(t.Speed() * float64(time.Second))
+}
+
+// SetInterval() sets the timer's speed from a time.Duration.
+func (t *Timer) SetInterval(d time.Duration) {
+	t.SetSpeed(d.Seconds())
+}
+
+// Elapsed() returns the time represented by the timer's counter, i.e. the
+// count multiplied by the speed.
+func (t *Timer) Elapsed() time.Duration {
+	return time.Duration(t.Count()) * t.Interval()
+}
+
 // Return the timer's counter value. The timer can be started or stopped.
 func (t *Timer) Count() int64 {
 	return int64(C.al_get_timer_count((*C.ALLEGRO_TIMER)(t)))
@@ -73,7 +128,8 @@ func (t *Timer) SetCount(count int64) {
 	C.al_set_timer_count((*C.ALLEGRO_TIMER)(t), C.int64_t(count))
 }
 
-// Add diff to the timer's counter value. This is similar to writing:
+// Add diff to the timer's counter value. This is similar to writing
+// t.SetCount(t.Count() + diff), except that the addition is atomic.
 func (t *Timer) AddCount(diff int64) {
 	C.al_add_timer_count((*C.ALLEGRO_TIMER)(t), C.int64_t(diff))
 }
@@ -0,0 +1,95 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+type TouchInput C.ALLEGRO_TOUCH_INPUT
+
+type TouchInputState C.ALLEGRO_TOUCH_INPUT_STATE
+
+type TouchState C.ALLEGRO_TOUCH_STATE
+
+// The maximum amount of simultaneous touches that can be detected.
+const TOUCH_INPUT_MAX_TOUCH_COUNT = C.ALLEGRO_TOUCH_INPUT_MAX_TOUCH_COUNT
+
+// Install a touch input driver, returning true if successful. If a touch input
+// driver was already installed, returns true immediately.
+func InstallTouchInput() error {
+	if !bool(C.al_install_touch_input()) {
+		return errors.New("failed to install touch input")
+	}
+	return nil
+}
+
+// Returns true if al_install_touch_input was called successfully.
+func IsTouchInputInstalled() bool {
+	return bool(C.al_is_touch_input_installed())
+}
+
+// Uninstalls the active touch input driver. If no touch input driver was
+// active, this function does nothing.
+func UninstallTouchInput() {
+	forgetEventSource(TouchEvents.EventSource())
+	C.al_uninstall_touch_input()
+}
+
+// Returns the global touch input event source. This event source generates
+// touch input events.
+func TouchInputEventSource() (*EventSource, error) {
+	source := C.al_get_touch_input_event_source()
+	if source == nil {
+		return nil, errors.New("failed to get touch input event source; did you call InstallTouchInput() first?")
+	}
+	return (*EventSource)(source), nil
+}
+
+// Gets the current touch input state. The touch information is copied into
+// the ALLEGRO_TOUCH_INPUT_STATE you provide to this function.
+func (state *TouchInputState) Get() {
+	C.al_get_touch_input_state((*C.ALLEGRO_TOUCH_INPUT_STATE)(state))
+}
+
+// Touches() returns the currently active touches, i.e. the entries of the
+// state whose id is not negative.
+func (state *TouchInputState) Touches() []*TouchState {
+	var touches []*TouchState
+	for i := range state.touches {
+		t := &state.touches[i]
+		if t.id >= 0 {
+			touches = append(touches, (*TouchState)(unsafe.Pointer(t)))
+		}
+	}
+	return touches
+}
+
+func (t *TouchState) Id() int {
+	return int(t.id)
+}
+
+func (t *TouchState) X() float32 {
+	return float32(t.x)
+}
+
+func (t *TouchState) Y() float32 {
+	return float32(t.y)
+}
+
+func (t *TouchState) Dx() float32 {
+	return float32(t.dx)
+}
+
+func (t *TouchState) Dy() float32 {
+	return float32(t.dy)
+}
+
+func (t *TouchState) Primary() bool {
+	return bool(t.primary)
+}
+
+func (t *TouchState) Display() *Display {
+	return (*Display)(t.display)
+}
@@ -0,0 +1,45 @@
+package allegro
+
+// #define ALLEGRO_UNSTABLE
+// #include <allegro5/allegro.h>
+import "C"
+import (
+	"errors"
+)
+
+// Mouse emulation is part of Allegro's unstable API, so it lives in its own
+// file.
+
+type MouseEmulationMode int
+
+const (
+	MOUSE_EMULATION_NONE        MouseEmulationMode = C.ALLEGRO_MOUSE_EMULATION_NONE
+	MOUSE_EMULATION_TRANSPARENT                    = C.ALLEGRO_MOUSE_EMULATION_TRANSPARENT
+	MOUSE_EMULATION_INCLUSIVE                      = C.ALLEGRO_MOUSE_EMULATION_INCLUSIVE
+	MOUSE_EMULATION_EXCLUSIVE                      = C.ALLEGRO_MOUSE_EMULATION_EXCLUSIVE
+	MOUSE_EMULATION_5_0_x                          = C.ALLEGRO_MOUSE_EMULATION_5_0_x
+)
+
+// Sets the kind of mouse emulation for the touch input subsystem to perform,
+// so that games written for the mouse work on touch screens. With
+// MOUSE_EMULATION_TRANSPARENT, emulated mouse events are generated on the
+// mouse emulation event source instead of alongside the touch events.
+func SetMouseEmulationMode(mode MouseEmulationMode) {
+	C.al_set_mouse_emulation_mode(C.int(mode))
+}
+
+// Returns the kind of mouse emulation which the touch input subsystem is set
+// to perform.
+func GetMouseEmulationMode() MouseEmulationMode {
+	return MouseEmulationMode(C.al_get_mouse_emulation_mode())
+}
+
+// Returns the global touch input event source that emits the mouse events
+// emulated from touch input.
+func TouchInputMouseEmulationEventSource() (*EventSource, error) {
+	source := C.al_get_touch_input_mouse_emulation_event_source()
+	if source == nil {
+		return nil, errors.New("failed to get mouse emulation event source; did you call InstallTouchInput() first?")
+	}
+	return (*EventSource)(source), nil
+}
@@ -76,7 +76,7 @@ func (t *Transform) Scale(sx, sy float32) {
 func (t *Transform) Coordinates(x, y float32) (float32, float32) {
 	var cx, cy = C.float(x), C.float(y)
 	C.al_transform_coordinates((*C.ALLEGRO_TRANSFORM)(t), &cx, &cy)
-	return float32(x), float32(y)
+	return float32(cx), float32(cy)
 }
 
 // Compose (combine) two transformations by a matrix multiplication.
@@ -98,3 +98,171 @@ func (t *Transform) Invert() {
 func (t *Transform) CheckInverse(tol float32) bool {
 	return int(C.al_check_inverse((*C.ALLEGRO_TRANSFORM)(t), C.float(tol))) != 0
 }
+
+// Returns the inverse of the current transformation of the target bitmap. If
+// there is no target bitmap, this function returns NULL.
+func CurrentInverseTransform() *Transform {
+	return (*Transform)(C.al_get_current_inverse_transform())
+}
+
+// Sets the projection transformation to be used for the drawing operations on
+// the target bitmap (each bitmap maintains its own projection transformation).
+// Every drawing operation after this call will be transformed using this
+// transformation.
+func UseProjectionTransform(trans *Transform) {
+	C.al_use_projection_transform((*C.ALLEGRO_TRANSFORM)(trans))
+}
+
+// If there is no target bitmap, this function returns NULL, otherwise returns
+// the projection transformation of the current target bitmap.
+func CurrentProjectionTransform() *Transform {
+	return (*Transform)(C.al_get_current_projection_transform())
+}
+
+// Apply a horizontal shear to the transform.
+func (t *Transform) HorizontalShear(theta float32) {
+	C.al_horizontal_shear_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(theta))
+}
+
+// Apply a vertical shear to the transform.
+func (t *Transform) VerticalShear(theta float32) {
+	C.al_vertical_shear_transform((*C.ALLEGRO_TRANSFORM)(t), C.float(theta))
+}
+
+// Combines the given transformation with a transformation which translates
+// coordinates by the given vector.
+func (t *Transform) Translate3D(x, y, z float32) {
+	C.al_translate_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y), C.float(z))
+}
+
+// Combines the given transformation with a transformation which rotates
+// coordinates around the given vector by the given angle in radians.
+func (t *Transform) Rotate3D(x, y, z, angle float32) {
+	C.al_rotate_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(x), C.float(y), C.float(z), C.float(angle))
+}
+
+// Combines the given transformation with a transformation which scales
+// coordinates by the given vector.
+func (t *Transform) Scale3D(sx, sy, sz float32) {
+	C.al_scale_transform_3d((*C.ALLEGRO_TRANSFORM)(t), C.float(sx), C.float(sy), C.float(sz))
+}
+
+// Transforms the given coordinates by the transformation.
+func (t *Transform) Coordinates3D(x, y, z float32) (float32, float32, float32) {
+	var cx, cy, cz = C.float(x), C.float(y), C.float(z)
+	C.al_transform_coordinates_3d((*C.ALLEGRO_TRANSFORM)(t), &cx, &cy, &cz)
+	return float32(cx), float32(cy), float32(cz)
+}
+
+// Combines the given transformation with an orthographic transformation which
+// maps the screen rectangle to the given left/top and right/bottom
+// coordinates. near/far is the z range, coordinates outside of which will get
+// clipped.
+func (t *Transform) Orthographic(left, top, n, right, bottom, f float32) {
+	C.al_orthographic_transform((*C.ALLEGRO_TRANSFORM)(t),
+		C.float(left), C.float(top), C.float(n),
+		C.float(right), C.float(bottom), C.float(f))
+}
+
+// Like Orthographic() but honors perspective. If everything is at a z-position
+// of -near it will look the same as with an orthographic transformation.
+func (t *Transform) Perspective(left, top, n, right, bottom, f float32) {
+	C.al_perspective_transform((*C.ALLEGRO_TRANSFORM)(t),
+		C.float(left), C.float(top), C.float(n),
+		C.float(right), C.float(bottom), C.float(f))
+}
+
+// Builds a transformation which can be used to transform 3D coordinates in
+// world space to camera space. This involves translation and a rotation. The
+// function expects three coordinate triplets: The camera's position, the
+// position the camera is looking at and an up vector.
+func BuildCameraTransform(positionX, positionY, positionZ, lookX, lookY, lookZ, upX, upY, upZ float32) *Transform {
+	var t Transform
+	C.al_build_camera_transform((*C.ALLEGRO_TRANSFORM)(&t),
+		C.float(positionX), C.float(positionY), C.float(positionZ),
+		C.float(lookX), C.float(lookY), C.float(lookZ),
+		C.float(upX), C.float(upY), C.float(upZ))
+	return &t
+}
+
+// ComposeTransforms() returns a new transformation that applies ts in order,
+// i.e. the first one is applied to coordinates first.
+func ComposeTransforms(ts ...*Transform) *Transform {
+	t := IdentityTransform()
+	for _, other := range ts {
+		t.Compose(other)
+	}
+	return t
+}
+
+// TransformBuilder builds a transformation from a chain of steps, which are
+// applied to coordinates in the order they are added:
+//
+//	t := allegro.NewTransformBuilder().
+//		Translate(-w/2, -h/2).
+//		Rotate(angle).
+//		Scale(zoom, zoom).
+//		Translate(x, y).
+//		Build()
+type TransformBuilder struct {
+	t Transform
+}
+
+// NewTransformBuilder() starts a chain from the identity transformation.
+func NewTransformBuilder() *TransformBuilder {
+	b := &TransformBuilder{}
+	b.t.Identity()
+	return b
+}
+
+func (b *TransformBuilder) Translate(x, y float32) *TransformBuilder {
+	b.t.Translate(x, y)
+	return b
+}
+
+func (b *TransformBuilder) Rotate(theta float32) *TransformBuilder {
+	b.t.Rotate(theta)
+	return b
+}
+
+func (b *TransformBuilder) Scale(sx, sy float32) *TransformBuilder {
+	b.t.Scale(sx, sy)
+	return b
+}
+
+func (b *TransformBuilder) HorizontalShear(theta float32) *TransformBuilder {
+	b.t.HorizontalShear(theta)
+	return b
+}
+
+func (b *TransformBuilder) VerticalShear(theta float32) *TransformBuilder {
+	b.t.VerticalShear(theta)
+	return b
+}
+
+func (b *TransformBuilder) Translate3D(x, y, z float32) *TransformBuilder {
+	b.t.Translate3D(x, y, z)
+	return b
+}
+
+func (b *TransformBuilder) Rotate3D(x, y, z, angle float32) *TransformBuilder {
+	b.t.Rotate3D(x, y, z, angle)
+	return b
+}
+
+func (b *TransformBuilder) Scale3D(sx, sy, sz float32) *TransformBuilder {
+	b.t.Scale3D(sx, sy, sz)
+	return b
+}
+
+// Then() appends another transformation to the chain.
+func (b *TransformBuilder) Then(other *Transform) *TransformBuilder {
+	b.t.Compose(other)
+	return b
+}
+
+// Build() returns a copy of the transformation built so far; the builder can
+// be extended further afterwards.
+func (b *TransformBuilder) Build() *Transform {
+	return b.t.Copy()
+}
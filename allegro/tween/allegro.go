@@ -0,0 +1,39 @@
+package tween
+
+import (
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Color() animates *c from its value when the tween starts to the given color,
+// interpolating each channel.
+func Color(c *allegro.Color, to allegro.Color, duration float64, ease Easing) *Tween {
+	var r0, g0, b0, a0 float32
+	r1, g1, b1, a1 := to.UnmapRGBAf()
+	return New(duration, ease,
+		func() { r0, g0, b0, a0 = c.UnmapRGBAf() },
+		func(t float64) {
+			f := float32(t)
+			*c = allegro.MapRGBAf(r0+(r1-r0)*f, g0+(g1-g0)*f, b0+(b1-b0)*f, a0+(a1-a0)*f)
+		})
+}
+
+// Pose describes a transformation as its components, which unlike the matrix
+// of a Transform can be interpolated meaningfully.
+type Pose struct {
+	X, Y           float32
+	ScaleX, ScaleY float32
+	Angle          float32
+}
+
+// Transform() animates t from one pose to another, rebuilding it with
+// BuildTransform() on every update.
+func Transform(t *allegro.Transform, from, to Pose, duration float64, ease Easing) *Tween {
+	return New(duration, ease, nil, func(p float64) {
+		f := float32(p)
+		lerp := func(a, b float32) float32 { return a + (b-a)*f }
+		*t = *allegro.BuildTransform(
+			lerp(from.X, to.X), lerp(from.Y, to.Y),
+			lerp(from.ScaleX, to.ScaleX), lerp(from.ScaleY, to.ScaleY),
+			lerp(from.Angle, to.Angle))
+	})
+}
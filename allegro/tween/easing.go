@@ -0,0 +1,76 @@
+package tween
+
+import (
+	"math"
+)
+
+// Easing maps linear progress in [0, 1] to eased progress. Eased progress
+// starts at 0 and ends at 1, but may leave that range in between, e.g. with
+// InBack or OutElastic.
+type Easing func(t float64) float64
+
+func Linear(t float64) float64 { return t }
+
+func InQuad(t float64) float64    { return t * t }
+func OutQuad(t float64) float64   { return 1 - InQuad(1-t) }
+func InOutQuad(t float64) float64 { return inOut(InQuad, t) }
+
+func InCubic(t float64) float64    { return t * t * t }
+func OutCubic(t float64) float64   { return 1 - InCubic(1-t) }
+func InOutCubic(t float64) float64 { return inOut(InCubic, t) }
+
+func InSine(t float64) float64    { return 1 - math.Cos(t*math.Pi/2) }
+func OutSine(t float64) float64   { return math.Sin(t * math.Pi / 2) }
+func InOutSine(t float64) float64 { return (1 - math.Cos(t*math.Pi)) / 2 }
+
+func InExpo(t float64) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Pow(2, 10*(t-1))
+}
+func OutExpo(t float64) float64   { return 1 - InExpo(1-t) }
+func InOutExpo(t float64) float64 { return inOut(InExpo, t) }
+
+func InBack(t float64) float64 {
+	const s = 1.70158
+	return t * t * ((s+1)*t - s)
+}
+func OutBack(t float64) float64   { return 1 - InBack(1-t) }
+func InOutBack(t float64) float64 { return inOut(InBack, t) }
+
+func OutBounce(t float64) float64 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	}
+	t -= 2.625 / d
+	return n*t*t + 0.984375
+}
+func InBounce(t float64) float64    { return 1 - OutBounce(1-t) }
+func InOutBounce(t float64) float64 { return inOut(InBounce, t) }
+
+func InElastic(t float64) float64 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return -math.Pow(2, 10*(t-1)) * math.Sin((t-1.075)*2*math.Pi/0.3)
+}
+func OutElastic(t float64) float64   { return 1 - InElastic(1-t) }
+func InOutElastic(t float64) float64 { return inOut(InElastic, t) }
+
+// inOut() builds an ease-in-out curve from an ease-in curve by running it
+// forwards over the first half and mirrored over the second.
+func inOut(in Easing, t float64) float64 {
+	if t < 0.5 {
+		return in(t*2) / 2
+	}
+	return 1 - in((1-t)*2)/2
+}
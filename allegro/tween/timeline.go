@@ -0,0 +1,148 @@
+package tween
+
+import (
+	"math"
+)
+
+type entry struct {
+	at   float64
+	anim Animator
+}
+
+// Timeline runs animators at fixed offsets from its start. Timelines are
+// themselves animators, so they can be nested.
+type Timeline struct {
+	// OnDone, if set, is called when every animator has finished.
+	OnDone func()
+
+	entries []entry
+	elapsed float64
+	done    bool
+}
+
+// NewTimeline() creates an empty timeline.
+func NewTimeline() *Timeline {
+	return &Timeline{}
+}
+
+// At() schedules a to start at seconds after the timeline starts.
+func (tl *Timeline) At(at float64, a Animator) *Timeline {
+	tl.entries = append(tl.entries, entry{at, a})
+	return tl
+}
+
+// Then() schedules a to start when the last animator added so far ends.
+func (tl *Timeline) Then(a Animator) *Timeline {
+	return tl.At(tl.end(), a)
+}
+
+// With() schedules a to start together with the last animator added so far.
+func (tl *Timeline) With(a Animator) *Timeline {
+	at := 0.0
+	if n := len(tl.entries); n > 0 {
+		at = tl.entries[n-1].at
+	}
+	return tl.At(at, a)
+}
+
+// end() returns when the last added entry ends.
+func (tl *Timeline) end() float64 {
+	if n := len(tl.entries); n > 0 {
+		e := tl.entries[n-1]
+		return e.at + e.anim.TotalDuration()
+	}
+	return 0
+}
+
+func (tl *Timeline) Update(dt float64) bool {
+	if tl.done {
+		return true
+	}
+	prev := tl.elapsed
+	tl.elapsed += dt
+	done := true
+	for _, e := range tl.entries {
+		if e.anim.Done() {
+			continue
+		}
+		if tl.elapsed < e.at {
+			done = false
+			continue
+		}
+		// Only the part of dt after the entry's start time applies to it.
+		if !e.anim.Update(tl.elapsed - math.Max(prev, e.at)) {
+			done = false
+		}
+	}
+	if done {
+		tl.done = true
+		if tl.OnDone != nil {
+			tl.OnDone()
+		}
+	}
+	return tl.done
+}
+
+func (tl *Timeline) Done() bool {
+	return tl.done
+}
+
+func (tl *Timeline) Reset() {
+	tl.elapsed = 0
+	tl.done = false
+	for _, e := range tl.entries {
+		e.anim.Reset()
+	}
+}
+
+func (tl *Timeline) TotalDuration() float64 {
+	total := 0.0
+	for _, e := range tl.entries {
+		total = math.Max(total, e.at+e.anim.TotalDuration())
+	}
+	return total
+}
+
+// Group runs any number of animators side by side, dropping them as they
+// finish.
+type Group struct {
+	anims []Animator
+}
+
+// Add() starts running a.
+func (g *Group) Add(a Animator) Animator {
+	g.anims = append(g.anims, a)
+	return a
+}
+
+// Remove() stops running a without finishing it. a is found with ==, so it
+// must be the same pointer that was added.
+func (g *Group) Remove(a Animator) {
+	for i, b := range g.anims {
+		if a == b {
+			g.anims = append(g.anims[:i], g.anims[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update() advances every animator by dt seconds.
+func (g *Group) Update(dt float64) {
+	// Iterate over a snapshot, since callbacks may add animators.
+	anims := append([]Animator(nil), g.anims...)
+	for _, a := range anims {
+		if a.Update(dt) {
+			g.Remove(a)
+		}
+	}
+}
+
+// Len() returns the number of running animators.
+func (g *Group) Len() int {
+	return len(g.anims)
+}
+
+// Clear() stops all animators.
+func (g *Group) Clear() {
+	g.anims = nil
+}
@@ -0,0 +1,156 @@
+// Package tween animates values over time with easing functions. Single
+// property animations are built with Float32(), Float64(), Color() and
+// Transform(), combined with a Timeline, and run by a Group.
+//
+// Everything advances through Update(dt float64), the same method that
+// states.Machine and the game loop use, so animations are ticked from the
+// game's own update step.
+package tween
+
+import (
+	"math"
+)
+
+// Animator is anything that progresses with time: a Tween, a Timeline or a
+// custom type. Group compares animators with ==, so a custom type added to
+// one must be comparable; implement it on a pointer, as Tween and Timeline
+// are, since == panics on structs with slice, map or func fields.
+type Animator interface {
+	// Update() advances the animation by dt seconds and returns whether it
+	// has finished.
+	Update(dt float64) bool
+
+	// Done() returns whether the animation has finished.
+	Done() bool
+
+	// Reset() rewinds the animation so that it can be played again.
+	Reset()
+
+	// TotalDuration() returns how long the animation runs, including any
+	// delay and repeats, or +Inf if it repeats forever.
+	TotalDuration() float64
+}
+
+// Tween interpolates a single value.
+type Tween struct {
+	// Duration is the length of one run, in seconds.
+	Duration float64
+
+	// Delay is the time to wait before starting.
+	Delay float64
+
+	// Ease shapes the progress. nil means Linear.
+	Ease Easing
+
+	// Repeat is the number of extra runs after the first. -1 repeats
+	// forever.
+	Repeat int
+
+	// Yoyo makes every other run play backwards.
+	Yoyo bool
+
+	// OnDone, if set, is called when the tween finishes.
+	OnDone func()
+
+	start   func()
+	apply   func(t float64)
+	elapsed float64
+	started bool
+	done    bool
+}
+
+// New() creates a tween that calls apply with eased progress on every update.
+// start, which may be nil, is called when the tween begins after its delay,
+// which is the time to capture starting values.
+func New(duration float64, ease Easing, start func(), apply func(t float64)) *Tween {
+	return &Tween{Duration: duration, Ease: ease, start: start, apply: apply}
+}
+
+// Float32() animates *v from its value when the tween starts to the given
+// value.
+func Float32(v *float32, to float32, duration float64, ease Easing) *Tween {
+	var from float32
+	return New(duration, ease,
+		func() { from = *v },
+		func(t float64) { *v = from + (to-from)*float32(t) })
+}
+
+// Float64() animates *v from its value when the tween starts to the given
+// value.
+func Float64(v *float64, to float64, duration float64, ease Easing) *Tween {
+	var from float64
+	return New(duration, ease,
+		func() { from = *v },
+		func(t float64) { *v = from + (to-from)*t })
+}
+
+// Call() creates a zero-length tween that calls f, for running code at a
+// point in a timeline.
+func Call(f func()) *Tween {
+	return New(0, nil, f, func(float64) {})
+}
+
+func (tw *Tween) Update(dt float64) bool {
+	if tw.done {
+		return true
+	}
+	tw.elapsed += dt
+	active := tw.elapsed - tw.Delay
+	if active < 0 {
+		return false
+	}
+	if !tw.started {
+		tw.started = true
+		if tw.start != nil {
+			tw.start()
+		}
+	}
+
+	var p float64
+	run := 0
+	if tw.Duration <= 0 {
+		tw.done = true
+		run = tw.Repeat
+	} else {
+		run = int(math.Floor(active / tw.Duration))
+		if tw.Repeat >= 0 && run > tw.Repeat {
+			tw.done = true
+			run = tw.Repeat
+		} else {
+			p = active/tw.Duration - float64(run)
+		}
+	}
+	if tw.done {
+		p = 1
+	}
+	if tw.Yoyo && run%2 == 1 {
+		p = 1 - p
+	}
+	ease := tw.Ease
+	if ease == nil {
+		ease = Linear
+	}
+	tw.apply(ease(p))
+
+	if tw.done && tw.OnDone != nil {
+		tw.OnDone()
+	}
+	return tw.done
+}
+
+func (tw *Tween) Done() bool {
+	return tw.done
+}
+
+func (tw *Tween) Reset() {
+	tw.elapsed = 0
+	tw.started = false
+	tw.done = false
+}
+
+func (tw *Tween) TotalDuration() float64 {
+	if tw.Repeat < 0 {
+		return math.Inf(1)
+	}
+	return tw.Delay + tw.Duration*float64(tw.Repeat+1)
+}
@@ -0,0 +1,102 @@
+package tween
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEasingEndpoints(t *testing.T) {
+	easings := []Easing{Linear, InQuad, OutQuad, InOutQuad, InCubic, OutCubic,
+		InOutCubic, InSine, OutSine, InOutSine, InExpo, OutExpo, InOutExpo,
+		InBack, OutBack, InOutBack, InBounce, OutBounce, InOutBounce,
+		InElastic, OutElastic, InOutElastic}
+	for i, e := range easings {
+		if v := e(0); math.Abs(v) > 1e-3 {
+			t.Errorf("easing %d: f(0) = %v, want 0", i, v)
+		}
+		if v := e(1); math.Abs(v-1) > 1e-3 {
+			t.Errorf("easing %d: f(1) = %v, want 1", i, v)
+		}
+	}
+}
+
+func TestTween(t *testing.T) {
+	v := 10.0
+	done := false
+	tw := Float64(&v, 20, 2, Linear)
+	tw.Delay = 1
+	tw.OnDone = func() { done = true }
+
+	tw.Update(0.5)
+	if v != 10 {
+		t.Fatalf("value changed during delay: %v", v)
+	}
+	v = 0 // the start value is captured after the delay
+	tw.Update(1.5)
+	if !near(v, 10) {
+		t.Fatalf("v = %v, want 10", v)
+	}
+	if !tw.Update(1.5) || !done || v != 20 {
+		t.Fatalf("tween didn't finish: v = %v, done = %v", v, done)
+	}
+}
+
+func TestYoyo(t *testing.T) {
+	v := 0.0
+	tw := Float64(&v, 1, 1, Linear)
+	tw.Repeat = 1
+	tw.Yoyo = true
+	tw.Update(1.25)
+	if !near(v, 0.75) {
+		t.Fatalf("v = %v, want 0.75 while playing backwards", v)
+	}
+	if !tw.Update(1) || v != 0 {
+		t.Fatalf("v = %v, want 0 after the backwards run", v)
+	}
+	if d := tw.TotalDuration(); d != 2 {
+		t.Errorf("TotalDuration() = %v, want 2", d)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	var a, b float64
+	var order []string
+	tl := NewTimeline().
+		Then(Float64(&a, 1, 1, Linear)).
+		Then(Call(func() { order = append(order, "call") })).
+		Then(Float64(&b, 1, 1, Linear))
+	tl.OnDone = func() { order = append(order, "done") }
+
+	tl.Update(1.5)
+	if a != 1 || !near(b, 0.5) {
+		t.Fatalf("a = %v, b = %v, want 1 and 0.5", a, b)
+	}
+	if tl.Update(0.25) {
+		t.Fatal("timeline finished early")
+	}
+	if !tl.Update(0.25) || b != 1 {
+		t.Fatalf("timeline didn't finish: b = %v", b)
+	}
+	if len(order) != 2 || order[0] != "call" || order[1] != "done" {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	var g Group
+	var a, b float64
+	g.Add(Float64(&a, 1, 1, Linear))
+	g.Add(Float64(&b, 1, 2, Linear))
+	g.Update(1)
+	if g.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", g.Len())
+	}
+	g.Update(1)
+	if g.Len() != 0 || a != 1 || b != 1 {
+		t.Fatalf("Len() = %d, a = %v, b = %v", g.Len(), a, b)
+	}
+}
@@ -0,0 +1,110 @@
+package allegro
+
+// #include <stdlib.h>
+// #include <allegro5/allegro.h>
+//
+// enum { VALUE_EVENT = ALLEGRO_GET_EVENT_TYPE('G', 'V', 'a', 'l') };
+//
+// extern void go_value_event_dtor(ALLEGRO_USER_EVENT *event);
+import "C"
+import (
+	"errors"
+	"runtime/cgo"
+	"unsafe"
+)
+
+func init() {
+	RegisterEventType(C.VALUE_EVENT, func(e *Event) interface{} {
+		return (*value_event)(unsafe.Pointer(e))
+	})
+}
+
+// UserEventSource is an event source for posting Go values into event
+// queues, e.g. so that a goroutine loading assets or talking to a server can
+// hand its results to the main loop. Its memory is allocated by C, so unlike
+// an EventSource declared in Go it can safely be registered with a queue.
+//
+// Emitting is safe from any goroutine. Each value is kept alive by a
+// cgo.Handle until every queue that received the event has released it, so
+// ValueEvent.Unref() must be called once the event has been handled.
+type UserEventSource struct {
+	source *C.ALLEGRO_EVENT_SOURCE
+}
+
+// NewUserEventSource() creates a user event source. It must be destroyed with
+// Destroy().
+func NewUserEventSource() *UserEventSource {
+	source := (*C.ALLEGRO_EVENT_SOURCE)(C.malloc(C.sizeof_ALLEGRO_EVENT_SOURCE))
+	C.al_init_user_event_source(source)
+	return &UserEventSource{source: source}
+}
+
+// EventSource() returns the event source, for registering with a queue.
+func (s *UserEventSource) EventSource() *EventSource {
+	return (*EventSource)(s.source)
+}
+
+// Emit() posts v to every queue the source is registered with. If it isn't
+// registered with any, v is dropped and an error is returned.
+func (s *UserEventSource) Emit(v interface{}) error {
+	var event C.ALLEGRO_EVENT
+	user := (*C.ALLEGRO_USER_EVENT)(unsafe.Pointer(&event))
+	user._type = C.VALUE_EVENT
+	user.data1 = C.intptr_t(cgo.NewHandle(v))
+	if !bool(C.al_emit_user_event(s.source, &event, (*[0]byte)(C.go_value_event_dtor))) {
+		return errors.New("user event source is not registered with any queue")
+	}
+	return nil
+}
+
+// Destroy() destroys the event source, unregistering it from any queues.
+// Events already in a queue stay valid.
+func (s *UserEventSource) Destroy() {
+	if s.source == nil {
+		return
+	}
+	forgetEventSource(s.EventSource())
+	C.al_destroy_user_event_source(s.source)
+	C.free(unsafe.Pointer(s.source))
+	s.source = nil
+}
+
+// Called by Allegro once every copy of the event has been unreferenced.
+//
+//export go_value_event_dtor
+func go_value_event_dtor(event *C.ALLEGRO_USER_EVENT) {
+	cgo.Handle(event.data1).Delete()
+}
+
+/* -- Value -- */
+
+type ValueEvent interface {
+	value()
+	Timestamp() float64
+	Source() *EventSource
+	Value() interface{}
+	Unref()
+}
+
+type value_event C.struct_ALLEGRO_USER_EVENT
+
+func (e *value_event) value() {}
+
+func (e *value_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *value_event) Source() *EventSource {
+	return (*EventSource)(e.source)
+}
+
+// Value() returns the value passed to Emit(). It must not be called after
+// Unref().
+func (e *value_event) Value() interface{} {
+	return cgo.Handle(e.data1).Value()
+}
+
+// Unref() releases the event's reference to its value.
+func (e *value_event) Unref() {
+	C.al_unref_user_event((*C.ALLEGRO_USER_EVENT)(e))
+}
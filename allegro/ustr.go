@@ -0,0 +1,151 @@
+package allegro
+
+// #include <allegro5/allegro.h>
+/*
+static ALLEGRO_USTR *ustr_new_gostring(_GoString_ s) {
+	return al_ustr_new_from_buffer(_GoStringPtr(s), _GoStringLen(s));
+}
+
+static bool ustr_append_gostring(ALLEGRO_USTR *us, _GoString_ s) {
+	ALLEGRO_USTR_INFO info;
+	return al_ustr_append(us, al_ref_buffer(&info, _GoStringPtr(s), _GoStringLen(s)));
+}
+
+static bool ustr_insert_gostring(ALLEGRO_USTR *us, int pos, _GoString_ s) {
+	ALLEGRO_USTR_INFO info;
+	return al_ustr_insert(us, pos, al_ref_buffer(&info, _GoStringPtr(s), _GoStringLen(s)));
+}
+
+static bool ustr_assign_gostring(ALLEGRO_USTR *us, _GoString_ s) {
+	ALLEGRO_USTR_INFO info;
+	return al_ustr_assign(us, al_ref_buffer(&info, _GoStringPtr(s), _GoStringLen(s)));
+}
+*/
+import "C"
+
+// Ustr is Allegro's UTF-8 string type. Go strings are already UTF-8, so most
+// code never needs one; it is useful for text that is edited a character at
+// a time, such as an input field fed by KeyCharEvent.Unichar(). Positions
+// are byte offsets, as with Go strings, and Offset() converts a code point
+// index to one.
+type Ustr C.ALLEGRO_USTR
+
+// Create a new string containing a copy of the given text.
+func NewUstr(s string) *Ustr {
+	return (*Ustr)(C.ustr_new_gostring(s))
+}
+
+// Free a previously allocated string.
+func (us *Ustr) Free() {
+	C.al_ustr_free((*C.ALLEGRO_USTR)(us))
+}
+
+// Return a newly allocated copy of the string.
+func (us *Ustr) Dup() *Ustr {
+	return (*Ustr)(C.al_ustr_dup((*C.ALLEGRO_USTR)(us)))
+}
+
+// String() returns a copy of the string's contents as a Go string.
+func (us *Ustr) String() string {
+	p := (*C.ALLEGRO_USTR)(us)
+	return C.GoStringN(C.al_cstr(p), C.int(C.al_ustr_size(p)))
+}
+
+// Return the size of the string in bytes. This is equal to the number of code
+// points in the string if the string is empty or contains only 7-bit ASCII
+// characters.
+func (us *Ustr) Size() int {
+	return int(C.al_ustr_size((*C.ALLEGRO_USTR)(us)))
+}
+
+// Return the number of code points in the string.
+func (us *Ustr) Length() int {
+	return int(C.al_ustr_length((*C.ALLEGRO_USTR)(us)))
+}
+
+// Return the byte offset (from the start of the string) of the code point at
+// the specified index in the string. A zero index parameter will return the
+// first character of the string. If index is negative, it counts backward
+// from the end of the string, so an index of -1 will return an offset to the
+// last code point.
+func (us *Ustr) Offset(index int) int {
+	return int(C.al_ustr_offset((*C.ALLEGRO_USTR)(us), C.int(index)))
+}
+
+// Return the code point in us beginning at byte offset pos. On success
+// returns the code point value. If pos was out of bounds (e.g. past the end
+// of the string), return -1. On an error, such as an invalid byte sequence,
+// return -2.
+func (us *Ustr) Get(pos int) rune {
+	return rune(C.al_ustr_get((*C.ALLEGRO_USTR)(us), C.int(pos)))
+}
+
+// Next() returns the byte offset of the code point following the one at pos,
+// or pos itself if it is at the end of the string.
+func (us *Ustr) Next(pos int) int {
+	p := C.int(pos)
+	C.al_ustr_next((*C.ALLEGRO_USTR)(us), &p)
+	return int(p)
+}
+
+// Prev() returns the byte offset of the code point before the one at pos, or
+// pos itself if it is at the start of the string.
+func (us *Ustr) Prev(pos int) int {
+	p := C.int(pos)
+	C.al_ustr_prev((*C.ALLEGRO_USTR)(us), &p)
+	return int(p)
+}
+
+// Append s to the end of the string.
+func (us *Ustr) Append(s string) bool {
+	return bool(C.ustr_append_gostring((*C.ALLEGRO_USTR)(us), s))
+}
+
+// Append a code point to the end of us. Returns the number of bytes added, or
+// 0 on error.
+func (us *Ustr) AppendChr(c rune) int {
+	return int(C.al_ustr_append_chr((*C.ALLEGRO_USTR)(us), C.int32_t(c)))
+}
+
+// Insert s into the string at byte offset pos.
+func (us *Ustr) Insert(pos int, s string) bool {
+	return bool(C.ustr_insert_gostring((*C.ALLEGRO_USTR)(us), C.int(pos), s))
+}
+
+// Insert a code point into us at byte offset pos. Returns the number of bytes
+// inserted, or 0 on error.
+func (us *Ustr) InsertChr(pos int, c rune) int {
+	return int(C.al_ustr_insert_chr((*C.ALLEGRO_USTR)(us), C.int(pos), C.int32_t(c)))
+}
+
+// Remove the code point beginning at byte offset pos. Returns true on
+// success. If pos is out of range or pos is not the beginning of a valid code
+// point, returns false leaving the string unmodified.
+func (us *Ustr) RemoveChr(pos int) bool {
+	return bool(C.al_ustr_remove_chr((*C.ALLEGRO_USTR)(us), C.int(pos)))
+}
+
+// Remove the interval [start_pos, end_pos) from a string. start_pos and
+// end_pos are byte offsets. Both may be past the end of the string but cannot
+// be less than 0 (the start of the string).
+func (us *Ustr) RemoveRange(start_pos, end_pos int) bool {
+	return bool(C.al_ustr_remove_range((*C.ALLEGRO_USTR)(us), C.int(start_pos), C.int(end_pos)))
+}
+
+// Truncate a portion of a string from byte offset start_pos onwards.
+// start_pos can be past the end of the string (has no effect) but cannot be
+// less than 0.
+func (us *Ustr) Truncate(start_pos int) bool {
+	return bool(C.al_ustr_truncate((*C.ALLEGRO_USTR)(us), C.int(start_pos)))
+}
+
+// Overwrite the string with the contents of s.
+func (us *Ustr) Assign(s string) bool {
+	return bool(C.ustr_assign_gostring((*C.ALLEGRO_USTR)(us), s))
+}
+
+// Return true iff the two strings are equal. This function is more efficient
+// than al_ustr_compare so is preferable if ordering is not important.
+func (us *Ustr) Equal(other *Ustr) bool {
+	return bool(C.al_ustr_equal((*C.ALLEGRO_USTR)(us), (*C.ALLEGRO_USTR)(other)))
+}
@@ -0,0 +1,252 @@
+// Package video provides support for Allegro's video addon.
+package video
+
+// #include <allegro5/allegro.h>
+// #include <allegro5/allegro_audio.h>
+// #include <allegro5/allegro_video.h>
+// #include "../util.c"
+/*
+// al_open_video_f() appeared in 5.2.9; older versions can't open a video
+// from a file stream.
+static ALLEGRO_VIDEO *open_video_f(ALLEGRO_FILE *fp, const char *ident) {
+#if ALLEGRO_VERSION_INT >= ((5 << 24) | (2 << 16) | (9 << 8))
+	return al_open_video_f(fp, ident);
+#else
+	return NULL;
+#endif
+}
+*/
+import "C"
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+	"github.com/ccollins476ad/go-allegro/allegro/audio"
+	"unsafe"
+)
+
+type Video C.ALLEGRO_VIDEO
+
+type PositionType int
+
+const (
+	POSITION_ACTUAL       PositionType = C.ALLEGRO_VIDEO_POSITION_ACTUAL
+	POSITION_VIDEO_DECODE              = C.ALLEGRO_VIDEO_POSITION_VIDEO_DECODE
+	POSITION_AUDIO_DECODE              = C.ALLEGRO_VIDEO_POSITION_AUDIO_DECODE
+)
+
+func init() {
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_VIDEO_FRAME_SHOW, func(e *allegro.Event) interface{} {
+		return (*frame_show_event)(unsafe.Pointer(e))
+	})
+	allegro.RegisterEventType(C.ALLEGRO_EVENT_VIDEO_FINISHED, func(e *allegro.Event) interface{} {
+		return (*finished_event)(unsafe.Pointer(e))
+	})
+}
+
+// Initializes the video addon.
+func Install() error {
+	ok := bool(C.al_init_video_addon())
+	if !ok {
+		return errors.New("failed to initialize video addon")
+	}
+	return nil
+}
+
+// Shut down the video addon. This is done automatically at program exit, but
+// can be called any time the user wishes as well.
+func Uninstall() {
+	C.al_shutdown_video_addon()
+}
+
+// Addon installs the video addon through allegro.Init().
+var Addon = allegro.Addon{Name: "video", Install: Install, Uninstall: Uninstall, Installed: IsInstalled}
+
+// Returns true if the video addon is initialized, otherwise returns false.
+func IsInstalled() bool {
+	return bool(C.al_is_video_addon_initialized())
+}
+
+// Returns the (compiled) version of the addon, in the same format as
+// al_get_allegro_version.
+func Version() (major, minor, revision, release uint8) {
+	v := uint32(C.al_get_allegro_video_version())
+	major = uint8(v >> 24)
+	minor = uint8((v >> 16) & 255)
+	revision = uint8((v >> 8) & 255)
+	release = uint8(v & 255)
+	return
+}
+
+// Reads a video file. This does not start streaming yet but reads the meta
+// info so you can query e.g. the size or audio rate.
+func Open(filename string) (*Video, error) {
+	filename_ := C.CString(filename)
+	defer C.free_string(filename_)
+	var v *C.ALLEGRO_VIDEO
+	err := allegro.CheckErrno("al_open_video", filename, func() bool {
+		v = C.al_open_video(filename_)
+		return v != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*Video)(v), nil
+}
+
+// Reads a video from an ALLEGRO_FILE stream, like Open(). The file type is
+// determined by the passed 'ident' parameter, which is a file name extension
+// including the leading dot. It needs Allegro 5.2.9 or later.
+func OpenF(f *allegro.File, ident string) (*Video, error) {
+	ident_ := C.CString(ident)
+	defer C.free_string(ident_)
+	var v *C.ALLEGRO_VIDEO
+	err := allegro.CheckErrno("al_open_video_f", ident, func() bool {
+		v = C.open_video_f((*C.ALLEGRO_FILE)(unsafe.Pointer(f)), ident_)
+		return v != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return (*Video)(v), nil
+}
+
+// Closes the video and frees all allocated resources. The video pointer is
+// invalid after the function returns.
+func (v *Video) Close() {
+	C.al_close_video((*C.ALLEGRO_VIDEO)(v))
+}
+
+// Starts streaming the video from the beginning. The video's audio is played
+// through mixer; pass audio.DefaultMixer() to use the default one.
+func (v *Video) Start(mixer *audio.Mixer) {
+	C.al_start_video((*C.ALLEGRO_VIDEO)(v), (*C.ALLEGRO_MIXER)(unsafe.Pointer(mixer)))
+}
+
+// Like Start() but audio is routed to the provided voice.
+func (v *Video) StartWithVoice(voice *audio.Voice) {
+	C.al_start_video_with_voice((*C.ALLEGRO_VIDEO)(v), (*C.ALLEGRO_VOICE)(unsafe.Pointer(voice)))
+}
+
+// Get an event source for the video. The possible events are described in
+// FrameShowEvent and FinishedEvent.
+func (v *Video) EventSource() *allegro.EventSource {
+	return (*allegro.EventSource)(unsafe.Pointer(
+		C.al_get_video_event_source((*C.ALLEGRO_VIDEO)(v))))
+}
+
+// Pauses or resumes playback.
+func (v *Video) SetPlaying(playing bool) {
+	C.al_set_video_playing((*C.ALLEGRO_VIDEO)(v), C.bool(playing))
+}
+
+// Returns true if the video is currently playing.
+func (v *Video) IsPlaying() bool {
+	return bool(C.al_is_video_playing((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the audio rate of the video, in Hz.
+func (v *Video) AudioRate() float64 {
+	return float64(C.al_get_video_audio_rate((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the speed of the video in frames per second. Often this will not be
+// an integer value.
+func (v *Video) FPS() float64 {
+	return float64(C.al_get_video_fps((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the width with which the video frame should be drawn. Videos often
+// do not use square pixels, so this may differ from the width of the frame
+// bitmap.
+func (v *Video) ScaledWidth() float32 {
+	return float32(C.al_get_video_scaled_width((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the height with which the video frame should be drawn.
+func (v *Video) ScaledHeight() float32 {
+	return float32(C.al_get_video_scaled_height((*C.ALLEGRO_VIDEO)(v)))
+}
+
+// Returns the current video frame, or nil if no frame is available yet. The
+// bitmap belongs to the video and is overwritten by later frames, so it must
+// not be destroyed; copy it to keep it.
+func (v *Video) Frame() *allegro.Bitmap {
+	return (*allegro.Bitmap)(unsafe.Pointer(C.al_get_video_frame((*C.ALLEGRO_VIDEO)(v))))
+}
+
+// Returns the current position of the video stream in seconds since the
+// beginning.
+func (v *Video) Position(which PositionType) float64 {
+	return float64(C.al_get_video_position((*C.ALLEGRO_VIDEO)(v), C.ALLEGRO_VIDEO_POSITION_TYPE(which)))
+}
+
+// Seek to a different position in the video. Currently only seeking to the
+// beginning of the video is supported.
+func (v *Video) Seek(pos_in_seconds float64) error {
+	if !bool(C.al_seek_video((*C.ALLEGRO_VIDEO)(v), C.double(pos_in_seconds))) {
+		return errors.New("failed to seek video")
+	}
+	return nil
+}
+
+// Draw() draws the current frame scaled to fit inside the given box while
+// keeping the video's aspect ratio, centred in it. Nothing is drawn if there
+// is no frame yet.
+func (v *Video) Draw(x, y, w, h float32) {
+	frame := v.Frame()
+	if frame == nil {
+		return
+	}
+	sw, sh := v.ScaledWidth(), v.ScaledHeight()
+	if sw <= 0 || sh <= 0 {
+		return
+	}
+	scale := w / sw
+	if h/sh < scale {
+		scale = h / sh
+	}
+	dw, dh := sw*scale, sh*scale
+	frame.DrawScaled(0, 0, float32(frame.Width()), float32(frame.Height()),
+		x+(w-dw)/2, y+(h-dh)/2, dw, dh, 0)
+}
+
+/* -- Events -- */
+
+// FrameShowEvent is emitted when a new frame should be shown; Frame() then
+// returns it.
+type FrameShowEvent interface {
+	frame_show()
+	Timestamp() float64
+	Video() *Video
+}
+
+type frame_show_event C.ALLEGRO_USER_EVENT // C.ALLEGRO_EVENT_VIDEO_FRAME_SHOW
+
+func (e *frame_show_event) frame_show() {}
+
+func (e *frame_show_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *frame_show_event) Video() *Video {
+	return (*Video)(unsafe.Pointer(uintptr(e.data1)))
+}
+
+// FinishedEvent is emitted when the video has played to the end.
+type FinishedEvent interface {
+	finished()
+	Timestamp() float64
+	Video() *Video
+}
+
+type finished_event C.ALLEGRO_USER_EVENT // C.ALLEGRO_EVENT_VIDEO_FINISHED
+
+func (e *finished_event) finished() {}
+
+func (e *finished_event) Timestamp() float64 {
+	return float64(e.timestamp)
+}
+
+func (e *finished_event) Video() *Video {
+	return (*Video)(unsafe.Pointer(uintptr(e.data1)))
+}
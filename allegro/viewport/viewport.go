@@ -0,0 +1,239 @@
+// Package viewport renders a game at a fixed virtual resolution and scales
+// the result to fit the display, adding black bars where the aspect ratios
+// differ.
+//
+// A viewport made by New() draws the game to an offscreen canvas and scales
+// the canvas onto the display in Present(). One made by NewDirect() has no
+// canvas; instead Begin() installs a transform that maps the virtual
+// resolution onto the display, so drawing goes straight to the backbuffer at
+// full display resolution, which keeps text and rotated sprites sharp.
+package viewport
+
+import (
+	"errors"
+	"github.com/ccollins476ad/go-allegro/allegro"
+)
+
+// Viewport is an offscreen canvas of a fixed size that is scaled onto a
+// display.
+type Viewport struct {
+	// Width and Height are the virtual resolution.
+	Width, Height int
+
+	// IntegerScale restricts scaling to whole multiples of the virtual
+	// resolution, which keeps pixel art crisp at the cost of larger borders.
+	IntegerScale bool
+
+	// BorderColor is used to fill the area outside of the scaled canvas.
+	BorderColor allegro.Color
+
+	canvas  *allegro.Bitmap
+	display *allegro.Display
+	old     *allegro.Bitmap
+
+	// The placement of the canvas on the display, updated by Present().
+	x, y, scale float32
+
+	// The size from the last resize event, used until the display reports
+	// it too, i.e. until the resize is acknowledged.
+	resizeW, resizeH int
+}
+
+// New() creates a viewport with the given virtual resolution for a display.
+// The canvas is created using the current new bitmap flags, so set
+// MIN_LINEAR/MAG_LINEAR beforehand for smooth scaling.
+func New(d *allegro.Display, width, height int) (*Viewport, error) {
+	canvas := allegro.CreateBitmap(width, height)
+	if canvas == nil {
+		return nil, errors.New("failed to create viewport canvas")
+	}
+	v := Viewport{
+		Width:       width,
+		Height:      height,
+		BorderColor: allegro.MapRGB(0, 0, 0),
+		canvas:      canvas,
+		display:     d,
+	}
+	v.layout()
+	return &v, nil
+}
+
+// NewDirect() creates a viewport with the given virtual resolution for a
+// display that draws through a transform rather than a canvas.
+func NewDirect(d *allegro.Display, width, height int) *Viewport {
+	v := Viewport{
+		Width:       width,
+		Height:      height,
+		BorderColor: allegro.MapRGB(0, 0, 0),
+		display:     d,
+	}
+	v.layout()
+	return &v
+}
+
+// Destroy() frees the viewport's canvas.
+func (v *Viewport) Destroy() {
+	if v.canvas != nil {
+		v.canvas.Destroy()
+		v.canvas = nil
+	}
+}
+
+// Canvas() returns the bitmap that the game is drawn to, or nil for a
+// viewport made by NewDirect().
+func (v *Viewport) Canvas() *allegro.Bitmap {
+	return v.canvas
+}
+
+// Begin() makes the canvas the target bitmap. Everything drawn until End() is
+// called is drawn at the virtual resolution. Without a canvas, Begin() calls
+// Apply() instead.
+func (v *Viewport) Begin() {
+	v.old = allegro.TargetBitmap()
+	if v.canvas == nil {
+		v.Apply()
+		return
+	}
+	allegro.SetTargetBitmap(v.canvas)
+}
+
+// End() restores the target bitmap that was in use when Begin() was called.
+// Without a canvas, it also resets the transform and clipping rectangle set
+// by Apply().
+func (v *Viewport) End() {
+	if v.canvas == nil {
+		allegro.UseTransform(allegro.IdentityTransform())
+		allegro.ResetClippingRectangle()
+	}
+	allegro.SetTargetBitmap(v.old)
+	v.old = nil
+}
+
+// Transform() returns the transform that maps virtual coordinates onto the
+// display.
+func (v *Viewport) Transform() *allegro.Transform {
+	t := allegro.IdentityTransform()
+	t.Scale(v.scale, v.scale)
+	t.Translate(v.x, v.y)
+	return t
+}
+
+// Apply() makes the display's backbuffer the target, fills the borders with
+// BorderColor, and uses Transform() with the clipping rectangle set to the
+// letterboxed area, so that what follows is drawn at the virtual resolution.
+func (v *Viewport) Apply() {
+	v.layout()
+	allegro.SetTargetBackbuffer(v.display)
+	allegro.UseTransform(allegro.IdentityTransform())
+	allegro.ResetClippingRectangle()
+	allegro.ClearToColor(v.BorderColor)
+	x, y, w, h := v.Rect()
+	allegro.SetClippingRectangle(int(x), int(y), int(w+0.5), int(h+0.5))
+	allegro.UseTransform(v.Transform())
+}
+
+// HandleEvent() recalculates the letterboxing when ev is a resize event for
+// the viewport's display, and returns whether it was. The event's size is
+// used, so this works before the resize is acknowledged.
+func (v *Viewport) HandleEvent(ev interface{}) bool {
+	e, ok := ev.(allegro.DisplayResizeEvent)
+	if !ok || e.Source() != v.display {
+		return false
+	}
+	v.resizeW, v.resizeH = e.Width(), e.Height()
+	v.layout()
+	return true
+}
+
+// layout() recalculates where the canvas is placed on the display.
+func (v *Viewport) layout() {
+	w, h := v.display.Width(), v.display.Height()
+	if v.resizeW != 0 {
+		if w == v.resizeW && h == v.resizeH {
+			v.resizeW, v.resizeH = 0, 0
+		} else {
+			w, h = v.resizeW, v.resizeH
+		}
+	}
+	v.layoutFor(float32(w), float32(h))
+}
+
+func (v *Viewport) layoutFor(dw, dh float32) {
+	sx, sy := dw/float32(v.Width), dh/float32(v.Height)
+	scale := sx
+	if sy < sx {
+		scale = sy
+	}
+	if v.IntegerScale && scale >= 1 {
+		scale = float32(int(scale))
+	}
+	v.scale = scale
+	v.x = float32(int((dw - float32(v.Width)*scale) / 2))
+	v.y = float32(int((dh - float32(v.Height)*scale) / 2))
+}
+
+// Present() draws the canvas onto the display's backbuffer, scaled to fit
+// while preserving its aspect ratio. The display must still be flipped
+// afterwards. It does nothing for a viewport without a canvas.
+func (v *Viewport) Present() {
+	if v.canvas == nil {
+		return
+	}
+	v.layout()
+	allegro.SetTargetBackbuffer(v.display)
+	allegro.UseTransform(allegro.IdentityTransform())
+	allegro.ClearToColor(v.BorderColor)
+	w, h := float32(v.Width), float32(v.Height)
+	v.canvas.DrawScaled(0, 0, w, h, v.x, v.y, w*v.scale, h*v.scale, 0)
+}
+
+// Scale() returns the factor the canvas is scaled by when presented.
+func (v *Viewport) Scale() float32 {
+	return v.scale
+}
+
+// Rect() returns the area of the display, in pixels, covered by the canvas.
+func (v *Viewport) Rect() (x, y, w, h float32) {
+	return v.x, v.y, float32(v.Width) * v.scale, float32(v.Height) * v.scale
+}
+
+// ScreenToVirtual() converts display coordinates, such as those reported by
+// mouse and touch events, to virtual coordinates. ok is false if the point
+// lies in the border around the canvas.
+func (v *Viewport) ScreenToVirtual(x, y float32) (vx, vy float32, ok bool) {
+	if v.scale == 0 {
+		return 0, 0, false
+	}
+	vx = (x - v.x) / v.scale
+	vy = (y - v.y) / v.scale
+	ok = vx >= 0 && vy >= 0 && vx < float32(v.Width) && vy < float32(v.Height)
+	return vx, vy, ok
+}
+
+// VirtualToScreen() converts virtual coordinates to display coordinates.
+func (v *Viewport) VirtualToScreen(x, y float32) (float32, float32) {
+	return v.x + x*v.scale, v.y + y*v.scale
+}
+
+// EventToVirtual() returns the position of a mouse or touch event in virtual
+// coordinates. ok is false if ev has no position or it lies in the border
+// around the canvas.
+func (v *Viewport) EventToVirtual(ev interface{}) (x, y float32, ok bool) {
+	switch e := ev.(type) {
+	case allegro.MouseAxesEvent:
+		return v.ScreenToVirtual(float32(e.X()), float32(e.Y()))
+	case allegro.MouseButtonDownEvent:
+		return v.ScreenToVirtual(float32(e.X()), float32(e.Y()))
+	case allegro.MouseButtonUpEvent:
+		return v.ScreenToVirtual(float32(e.X()), float32(e.Y()))
+	case allegro.TouchBeginEvent:
+		return v.ScreenToVirtual(e.X(), e.Y())
+	case allegro.TouchEndEvent:
+		return v.ScreenToVirtual(e.X(), e.Y())
+	case allegro.TouchMoveEvent:
+		return v.ScreenToVirtual(e.X(), e.Y())
+	case allegro.TouchCancelEvent:
+		return v.ScreenToVirtual(e.X(), e.Y())
+	}
+	return 0, 0, false
+}
@@ -25,6 +25,7 @@ var modules = []mod{
 	mod{name: "physfs", decl: buildRegex("ALLEGRO_PHYSFS_FUNC")},
 	mod{name: "primitives", decl: buildRegex("ALLEGRO_PRIM_FUNC")},
 	mod{name: "ttf", decl: buildRegex("ALLEGRO_TTF_FUNC"), path: "font/ttf"},
+	mod{name: "video", decl: buildRegex("ALLEGRO_VIDEO_FUNC")},
 }
 
 type mod struct {